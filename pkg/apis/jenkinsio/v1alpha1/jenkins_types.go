@@ -14,18 +14,617 @@ type JenkinsSpec struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	Master   JenkinsMaster `json:"master,omitempty"`
 	SeedJobs []SeedJob     `json:"seedJobs,omitempty"`
+	// SeedJobAgent is the default label expression restricting where seed jobs can run
+	SeedJobAgent string `json:"seedJobAgent,omitempty"`
+	// SeedAgent defines the Kubernetes agent pod template used to run seed jobs
+	SeedAgent SeedAgent `json:"seedAgent,omitempty"`
+	// Agent defines the defaults of Kubernetes agent pod templates configured by operator
+	Agent JenkinsAgent `json:"agent,omitempty"`
+	// Proxy defines the HTTP proxy used by Jenkins master and agents to reach hosts outside of the cluster
+	Proxy *Proxy `json:"proxy,omitempty"`
+	// ConfigurationAsCode references Jenkins Configuration as Code (JCasC) YAML files applied by the configuration-as-code plugin
+	ConfigurationAsCode *ConfigurationAsCode `json:"configurationAsCode,omitempty"`
+	// Credentials are created in the Jenkins system credentials store from Secrets by operator, they are updated when
+	// Secrets change and deleted when they are removed from the list
+	Credentials []Credentials `json:"credentials,omitempty"`
+	// CredentialsAutoDiscovery mirrors Secrets labeled with the credentials type into Jenkins credentials,
+	// explicitly declared Credentials take precedence
+	CredentialsAutoDiscovery *CredentialsAutoDiscovery `json:"credentialsAutoDiscovery,omitempty"`
+	// Vault defines the HashiCorp Vault server used to resolve secret references with the vault: scheme
+	Vault *Vault `json:"vault,omitempty"`
+	// Configuration defines ConfigMaps with groovy scripts used to configure Jenkins by user
+	Configuration Configuration `json:"configuration,omitempty"`
+	// JobNamePrefix is prepended to names of all jobs created by operator, jobs are recreated under new names
+	// when it changes, defaults to empty
+	JobNamePrefix string `json:"jobNamePrefix,omitempty"`
+	// Security defines how operator secures its access to Jenkins
+	Security *Security `json:"security,omitempty"`
+	// Notifications defines how Jenkins notifies users
+	Notifications *Notifications `json:"notifications,omitempty"`
+	// SharedLibraries are global pipeline shared libraries registered in Jenkins by operator, a library is configured
+	// again when its entry changes and unregistered when it's removed from the list
+	SharedLibraries []SharedLibrary `json:"sharedLibraries,omitempty"`
+	// Tools defines global tool installations of Jenkins, installations of a tool not listed here are removed
+	// once the section is set
+	Tools *Tools `json:"tools,omitempty"`
+	// Views are views created in Jenkins by operator, a view is configured again when its entry changes and deleted
+	// when it's removed from the list, views created otherwise in Jenkins are left untouched
+	Views []View `json:"views,omitempty"`
 }
 
+// Security defines how operator secures its access to Jenkins
+type Security struct {
+	// TokenRotationInterval is the maximum age of the Jenkins API token of operator, e.g. 720h, an older token
+	// is replaced by a new one and revoked, by default the token is rotated only when Jenkins master pod is recreated
+	TokenRotationInterval *metav1.Duration `json:"tokenRotationInterval,omitempty"`
+	// DisableLocalAdmin switches the Jenkins API access of operator to the dedicated service account user after
+	// the user configuration completes and removes the admin user of operator from Jenkins, e.g. when the external
+	// security realm is configured by the user configuration, the admin user is restored when it's turned off
+	DisableLocalAdmin bool `json:"disableLocalAdmin,omitempty"`
+	// LDAP configures the LDAP security realm of Jenkins in the base configuration, the previous security realm
+	// is restored when it's removed
+	LDAP *LDAP `json:"ldap,omitempty"`
+	// OIDC configures the OpenID Connect security realm of Jenkins in the base configuration, the previous security realm
+	// is restored when it's removed, it can't be set together with LDAP
+	OIDC *OIDC `json:"oidc,omitempty"`
+	// Authorization configures the authorization strategy of Jenkins in the base configuration, the previous strategy
+	// is restored when it's removed, users of operator always keep the Overall/Administer permission
+	Authorization *Authorization `json:"authorization,omitempty"`
+}
+
+// Authorization defines the authorization strategy of Jenkins, exactly one of Matrix and RoleStrategy has to be set,
+// permissions are written in the <group>/<name> format, e.g. Overall/Administer or Job/Build
+type Authorization struct {
+	// Matrix configures the global matrix authorization strategy, the matrix-auth plugin is installed when it's set
+	Matrix []MatrixPermissions `json:"matrix,omitempty"`
+	// RoleStrategy configures the role-based authorization strategy, the role-strategy plugin is installed when it's set
+	RoleStrategy *RoleStrategy `json:"roleStrategy,omitempty"`
+}
+
+// MatrixPermissions defines permissions granted to the user or the group by the matrix authorization strategy
+type MatrixPermissions struct {
+	// Name is the user or the group name, e.g. authenticated or anonymous
+	Name string `json:"name"`
+	// Permissions are granted to the user or the group
+	Permissions []string `json:"permissions"`
+}
+
+// RoleStrategy defines roles of the role-based authorization strategy
+type RoleStrategy struct {
+	// GlobalRoles are granted for the whole Jenkins
+	GlobalRoles []Role `json:"globalRoles,omitempty"`
+	// ItemRoles are granted for jobs and folders which names match the pattern of the role
+	ItemRoles []Role `json:"itemRoles,omitempty"`
+}
+
+// Role defines permissions of the role and users or groups the role is assigned to
+type Role struct {
+	// Name is the unique name of the role
+	Name string `json:"name"`
+	// Pattern is the regular expression of job and folder names, it's required by item roles and ignored by global roles
+	Pattern string `json:"pattern,omitempty"`
+	// Permissions are granted by the role
+	Permissions []string `json:"permissions"`
+	// Assignments are users and groups the role is assigned to
+	Assignments []string `json:"assignments,omitempty"`
+}
+
+// OIDC defines the OpenID Connect security realm of Jenkins, the oic-auth plugin is installed when it's set
+type OIDC struct {
+	// IssuerURL is the URL of the OpenID Connect provider, its discovery document is read from
+	// <issuerURL>/.well-known/openid-configuration
+	IssuerURL string `json:"issuerURL"`
+	// ClientID is the client ID of Jenkins registered in the OpenID Connect provider
+	ClientID string `json:"clientID"`
+	// ClientSecretRef points at the client secret of Jenkins, Jenkins master pod is recreated when it changes
+	ClientSecretRef corev1.SecretKeySelector `json:"clientSecretRef"`
+	// Scopes are requested from the OpenID Connect provider, defaults to openid, email and profile
+	Scopes []string `json:"scopes,omitempty"`
+	// UserNameClaim is the claim with the Jenkins user name, defaults to sub
+	UserNameClaim string `json:"userNameClaim,omitempty"`
+	// GroupsClaim is the claim with groups of the user, groups aren't read when empty
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+	// EscapeHatch enables the admin which can log in when the OpenID Connect provider isn't available
+	EscapeHatch *OIDCEscapeHatch `json:"escapeHatch,omitempty"`
+	// ValidateIssuer checks the discovery document of the issuer is reachable from operator during the validation of Jenkins CR
+	ValidateIssuer bool `json:"validateIssuer,omitempty"`
+}
+
+// OIDCEscapeHatch defines the admin which can log in to Jenkins when the OpenID Connect provider isn't available
+type OIDCEscapeHatch struct {
+	// CredentialsSecretRef points at the secret with user and password keys, Jenkins master pod is recreated when it changes
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+	// Group is the group of the escape hatch admin
+	Group string `json:"group,omitempty"`
+}
+
+// LDAP defines the LDAP security realm of Jenkins, the ldap plugin is installed when it's set
+type LDAP struct {
+	// Server is the LDAP server host with the optional port, e.g. ldap.example.com:389, or the ldap:// or ldaps:// URL
+	Server string `json:"server"`
+	// RootDN is the root DN of the directory, it's inferred from the server when empty
+	RootDN string `json:"rootDN,omitempty"`
+	// InhibitInferRootDN disables inferring the root DN from the server when RootDN is empty
+	InhibitInferRootDN bool `json:"inhibitInferRootDN,omitempty"`
+	// UserSearchBase is the DN relative to RootDN users are searched in
+	UserSearchBase string `json:"userSearchBase,omitempty"`
+	// UserSearch is the filter users are searched with, {0} is replaced by the user name, defaults to uid={0}
+	UserSearch string `json:"userSearch,omitempty"`
+	// GroupSearchBase is the DN relative to RootDN groups are searched in
+	GroupSearchBase string `json:"groupSearchBase,omitempty"`
+	// GroupSearchFilter is the filter groups are searched with, {0} is replaced by the group name
+	GroupSearchFilter string `json:"groupSearchFilter,omitempty"`
+	// ManagerDN is the DN Jenkins binds with to search users and groups, the anonymous bind is used when empty
+	ManagerDN string `json:"managerDN,omitempty"`
+	// ManagerPasswordSecretRef points at the password of ManagerDN, Jenkins master pod is recreated when it changes
+	ManagerPasswordSecretRef *corev1.SecretKeySelector `json:"managerPasswordSecretRef,omitempty"`
+	// TLS connects to the LDAP server over TLS (LDAPS) when Server is the host, the certificate of the server has to be
+	// trusted by the JVM, see Jenkins.Spec.Master.AdditionalCACerts
+	TLS bool `json:"tls,omitempty"`
+	// ConnectTimeout is the timeout of connecting to the LDAP server, the JVM default is used when not set
+	ConnectTimeout *metav1.Duration `json:"connectTimeout,omitempty"`
+	// ReadTimeout is the timeout of LDAP operations, the JVM default is used when not set
+	ReadTimeout *metav1.Duration `json:"readTimeout,omitempty"`
+}
+
+// OperatorAuthentication defines which Jenkins user operator uses to access Jenkins API
+type OperatorAuthentication string
+
+const (
+	// OperatorAuthenticationLocalAdmin - operator uses the admin user from the operator credentials secret
+	OperatorAuthenticationLocalAdmin OperatorAuthentication = "localAdmin"
+	// OperatorAuthenticationServiceAccount - operator uses the API token of the service account user,
+	// the admin user is removed from Jenkins, see Jenkins.Spec.Security.DisableLocalAdmin
+	OperatorAuthenticationServiceAccount OperatorAuthentication = "serviceAccount"
+)
+
 // JenkinsMaster defines the Jenkins master pod attributes and plugins,
 // every single change requires Jenkins master pod restart
 type JenkinsMaster struct {
 	Image       string                      `json:"image,omitempty"`
 	Annotations map[string]string           `json:"masterAnnotations,omitempty"`
 	Resources   corev1.ResourceRequirements `json:"resources,omitempty"`
+	// AdminCredentialsSecretRef references the Secret with user and password keys used for the Jenkins user of operator
+	// instead of generated credentials, changes of the Secret are applied to the user without Jenkins master pod restart
+	AdminCredentialsSecretRef *corev1.LocalObjectReference `json:"adminCredentialsSecretRef,omitempty"`
 	// OperatorPlugins contains plugins required by operator
 	OperatorPlugins map[string][]string `json:"basePlugins,omitempty"`
-	// Plugins contains plugins required by user
-	Plugins map[string][]string `json:"plugins,omitempty"`
+	// BasePluginsOverride replaces versions of plugins required by operator, the key is the name of the plugin
+	// and the value is the version, e.g. to install the fix of a vulnerability before the operator is released
+	BasePluginsOverride map[string]string `json:"basePluginsOverride,omitempty"`
+	// Plugins contains plugins required by user, the version can be followed by the SHA-256 checksum
+	// of the plugin archive, e.g. git:3.9.1@sha256:<checksum>, the downloaded archive is verified against it,
+	// the latest version, e.g. git:latest, is resolved against the update center, dependent plugins can be declared
+	// with version constraints, e.g. workflow-api:>=2.40 or credentials:2.x, simple-theme-plugin is installed
+	// when it isn't set, an empty map installs no plugins besides plugins required by operator
+	Plugins map[string][]string `json:"plugins"`
+	// PluginsConfigMapRef references the ConfigMap with plugins required by user in the plugins.txt key,
+	// one plugin per line in the name:version format like plugins.txt of Jenkins docker image,
+	// they are merged with Plugins and plugins declared in Plugins win
+	PluginsConfigMapRef *corev1.LocalObjectReference `json:"pluginsConfigMapRef,omitempty"`
+	// EnforcePluginVersions restarts Jenkins master pod to reinstall declared versions of plugins when installed versions
+	// drift from them, e.g. after plugins have been upgraded in Jenkins UI, by default the drift is only reported
+	EnforcePluginVersions bool `json:"enforcePluginVersions,omitempty"`
+	// ResolvePluginDependencies resolves dependencies of plugins required by user against the update center metadata,
+	// missing dependencies are installed in the latest version, declared plugins older than required fail the validation
+	ResolvePluginDependencies bool `json:"resolvePluginDependencies,omitempty"`
+	// PluginCleanup uninstalls plugins which aren't declared in Jenkins CR and restarts Jenkins, dependencies of declared plugins,
+	// bundled and detached plugins are kept
+	PluginCleanup bool `json:"pluginCleanup,omitempty"`
+	// AllowPluginDowngrades allows to install declared plugin versions older than versions installed in Jenkins,
+	// by default such downgrades fail the validation because Jenkins home can contain data written by the newer version
+	AllowPluginDowngrades bool `json:"allowPluginDowngrades,omitempty"`
+	// FailOnSecurityWarnings fails the validation of Jenkins CR when declared plugin versions are affected
+	// by security warnings of the update center, by default they are only reported
+	FailOnSecurityWarnings bool `json:"failOnSecurityWarnings,omitempty"`
+	// SkipPluginCompatibilityCheck disables the check of the Jenkins core version required by declared plugin versions
+	// against the update center metadata, e.g. for air-gapped setups
+	SkipPluginCompatibilityCheck bool `json:"skipPluginCompatibilityCheck,omitempty"`
+	// UpdateCenterURL is the URL of the Jenkins update center used to install plugins instead of https://updates.jenkins.io,
+	// e.g. an internal mirror, its update-center.json is configured as the default update site of Jenkins
+	UpdateCenterURL string `json:"updateCenterURL,omitempty"`
+	// SkipUpdateCenterSignatureCheck disables the signature check of the update center metadata,
+	// e.g. for mirrors which don't sign update-center.json with the Jenkins certificate
+	SkipUpdateCenterSignatureCheck bool `json:"skipUpdateCenterSignatureCheck,omitempty"`
+	// PluginDownloadRetries configures retries of plugin downloads during the start of Jenkins master pod
+	PluginDownloadRetries *PluginDownloadRetries `json:"pluginDownloadRetries,omitempty"`
+	// SSHKnownHosts contains known_hosts entries of git servers used by all seed jobs
+	SSHKnownHosts *SSHKnownHosts `json:"sshKnownHosts,omitempty"`
+	// AdditionalCACerts points at PEM encoded CA certificates trusted by git and the JVM in addition to the default ones
+	AdditionalCACerts *corev1.SecretKeySelector `json:"additionalCACerts,omitempty"`
+	// BaseConfigurationOverrides points at the ConfigMap with groovy scripts which replace same-named base configuration
+	// scripts, other scripts are applied after base configuration scripts, changes don't require restart
+	BaseConfigurationOverrides *corev1.LocalObjectReference `json:"baseConfigurationOverrides,omitempty"`
+	// ExtraFiles contains files copied from ConfigMaps or Secrets into Jenkins home before Jenkins starts
+	ExtraFiles []ExtraFile `json:"extraFiles,omitempty"`
+	// InitScripts references ConfigMaps and Secrets with groovy scripts run by Jenkins on startup from init.groovy.d
+	InitScripts *InitScripts `json:"initScripts,omitempty"`
+	// GlobalEnv contains environment variables of the global node properties of Jenkins visible to all builds, values can
+	// reference secrets with ${secret:<secret name>:<key>} tokens, variables not listed here are removed from Jenkins
+	GlobalEnv map[string]string `json:"globalEnv,omitempty"`
+	// NumExecutors is the number of executors of Jenkins master, defaults to 3 or 1 when the seed agent restricts
+	// master executors, changes made in Jenkins are reverted, operator jobs waiting for an executor get one when it's 0
+	NumExecutors *int32 `json:"numExecutors,omitempty"`
+	// SystemMessage is the message shown on the top of the Jenkins main page, it can contain basic HTML rendered by
+	// the markup formatter of Jenkins, changes made in Jenkins are reverted, the empty value clears the message
+	SystemMessage string `json:"systemMessage,omitempty"`
+	// Timezone is the IANA time zone name, e.g. Europe/Warsaw, used by Jenkins master to show times, it's set
+	// as the TZ environment variable and the timezone system properties of Jenkins, changes recreate Jenkins master pod
+	Timezone string `json:"timezone,omitempty"`
+	// DisableUsageStats disables submitting anonymous usage statistics to the Jenkins project, defaults to true,
+	// changes made in Jenkins are reverted
+	DisableUsageStats *bool `json:"disableUsageStats,omitempty"`
+	// DisabledAdministrativeMonitors contains IDs of administrative monitors hidden in Jenkins, e.g.
+	// hudson.diagnosis.ReverseProxySetupMonitor, monitors enabled in Jenkins are disabled again
+	DisabledAdministrativeMonitors []string `json:"disabledAdministrativeMonitors,omitempty"`
+}
+
+// InitScripts references ConfigMaps and Secrets with init.groovy.d scripts, every key is a separate script,
+// scripts run after init scripts of operator and changes of their content restart Jenkins master pod
+type InitScripts struct {
+	// ConfigMaps contain groovy scripts
+	ConfigMaps []corev1.LocalObjectReference `json:"configMaps,omitempty"`
+	// Secrets contain groovy scripts with sensitive values
+	Secrets []corev1.LocalObjectReference `json:"secrets,omitempty"`
+}
+
+// ExtraFile defines the file copied into Jenkins home from the ConfigMap or the Secret key, exactly one of them has to be set
+type ExtraFile struct {
+	// Path is the path of the file relative to Jenkins home, e.g. scriptApproval.xml or .m2/settings.xml
+	Path string `json:"path"`
+	// ConfigMapKeyRef points at the ConfigMap key with the content of the file
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// SecretKeyRef points at the Secret key with the content of the file
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// JenkinsAgent defines the JNLP agent used by Kubernetes pod templates configured by operator,
+// changes are applied without Jenkins master pod restart
+type JenkinsAgent struct {
+	// Image is the JNLP agent docker image, defaults to jenkins/jnlp-slave
+	Image            string                        `json:"image,omitempty"`
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// SeedAgent defines the Kubernetes agent pod template configured by operator to run seed jobs instead of Jenkins master,
+// changes are applied without Jenkins master pod restart
+type SeedAgent struct {
+	// Enabled configures the pod template and runs seed jobs without agent label on it
+	Enabled bool `json:"enabled,omitempty"`
+	// Image is the JNLP agent docker image, overrides Jenkins.Spec.Agent.Image
+	Image              string                      `json:"image,omitempty"`
+	Resources          corev1.ResourceRequirements `json:"resources,omitempty"`
+	ServiceAccountName string                      `json:"serviceAccountName,omitempty"`
+	// RestrictMasterExecutors leaves at most one master executor which is required by operator configuration jobs
+	RestrictMasterExecutors bool `json:"restrictMasterExecutors,omitempty"`
+}
+
+// Proxy defines the HTTP proxy injected as environment variables into Jenkins master and agent pods
+// and configured in Jenkins, every single change requires Jenkins master pod restart
+type Proxy struct {
+	// HTTPProxy is the proxy url used for http requests, e.g. http://proxy.example.com:3128
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// HTTPSProxy is the proxy url used for https requests, defaults to HTTPProxy in Jenkins proxy configuration
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy contains comma separated hosts which aren't proxied, in-cluster hosts are always added
+	NoProxy string `json:"noProxy,omitempty"`
+	// CredentialsSecretRef points at the secret with username and password keys used to authenticate to the proxy
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+}
+
+// Configuration defines groovy scripts provided by user, changes of the lists require Jenkins master pod restart
+type Configuration struct {
+	// Enabled set to false skips the user configuration phase, neither the user configuration ConfigMap
+	// nor the Jenkins job is created, defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+	// ConfigMaps contains names of ConfigMaps with groovy scripts applied in the list order,
+	// defaults to the ConfigMap created by operator for Jenkins CR
+	ConfigMaps []string `json:"configMaps,omitempty"`
+	// Secrets contains names of Secrets with groovy scripts applied in the list order after scripts from ConfigMaps,
+	// their content is never logged
+	Secrets []string `json:"secrets,omitempty"`
+	// ExecutionMode is one of job or scriptConsole, defaults to job
+	ExecutionMode ExecutionMode `json:"executionMode,omitempty"`
+	// FailFast stops applying scripts on the first failed one and failed scripts aren't retried until they change,
+	// by default every script is applied independently and failed scripts are retried
+	FailFast bool `json:"failFast,omitempty"`
+	// Git makes operator fetch groovy scripts from the git repository, they are applied after scripts from ConfigMaps and Secrets
+	Git *GitConfiguration `json:"git,omitempty"`
+	// URLs makes operator download groovy scripts from HTTP(S) URLs, they are applied after scripts from the git repository
+	URLs []URLConfiguration `json:"urls,omitempty"`
+	// BuildRetention is the number of the last builds of the user configuration job kept by Jenkins, defaults to 10
+	BuildRetention *int `json:"buildRetention,omitempty"`
+}
+
+// URLConfiguration references the groovy script downloaded by operator, the script is copied into the ConfigMap
+// created by operator under the last segment of the URL path
+type URLConfiguration struct {
+	// URL is the http or https URL of the script, the last segment of its path has to be a valid ConfigMap key
+	URL string `json:"url"`
+	// Checksum is the hex encoded SHA-256 checksum of the script, the downloaded script has to match it
+	Checksum string `json:"checksum,omitempty"`
+	// HeadersSecret is the name of the Secret with HTTP headers sent with the request, e.g. Authorization,
+	// keys of the Secret are header names
+	HeadersSecret string `json:"headersSecret,omitempty"`
+}
+
+// GitConfiguration references the git repository with user configuration groovy scripts, the repository is fetched
+// by operator and its scripts are copied into the ConfigMap created by operator
+type GitConfiguration struct {
+	// RepositoryURL is the https or ssh URL of the repository
+	RepositoryURL string `json:"repositoryUrl"`
+	// RepositoryBranch is the branch with scripts, defaults to master
+	RepositoryBranch string `json:"repositoryBranch,omitempty"`
+	// Revision pins scripts to a tag or a full commit SHA, it takes precedence over the branch
+	Revision string `json:"revision,omitempty"`
+	// Path is the directory of the repository with groovy scripts, defaults to the repository root
+	Path string `json:"path,omitempty"`
+	// CredentialsSecret is the name of the Secret with username and password keys used with https URL
+	// or the privateKey key used with ssh URL
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// KnownHosts contains known_hosts entries of the git server used with ssh URL
+	KnownHosts string `json:"knownHosts,omitempty"`
+	// InsecureSkipHostKeyVerification disables host key verification of the ssh URL host
+	InsecureSkipHostKeyVerification bool `json:"insecureSkipHostKeyVerification,omitempty"`
+	// PollInterval is the interval of fetching the repository, defaults to 5 minutes
+	PollInterval *metav1.Duration `json:"pollInterval,omitempty"`
+}
+
+// ExecutionMode defines how user configuration groovy scripts are executed
+type ExecutionMode string
+
+const (
+	// ExecutionModeJob - scripts are executed by the user configuration Jenkins job, it's the default
+	ExecutionModeJob ExecutionMode = "job"
+	// ExecutionModeScriptConsole - scripts are executed one by one via the Jenkins script console API,
+	// the user configuration job isn't created
+	ExecutionModeScriptConsole ExecutionMode = "scriptConsole"
+)
+
+// ConfigurationAsCode references ConfigMaps and Secrets with JCasC YAML files, every key is a separate file,
+// changes of referenced objects are reloaded without Jenkins master pod restart
+type ConfigurationAsCode struct {
+	// ConfigMaps contain JCasC YAML files
+	ConfigMaps []corev1.LocalObjectReference `json:"configMaps,omitempty"`
+	// Secrets contain JCasC YAML files with sensitive values
+	Secrets []corev1.LocalObjectReference `json:"secrets,omitempty"`
+}
+
+// CredentialsType defines the type of Jenkins credentials created from the Secret
+type CredentialsType string
+
+const (
+	// CredentialsTypeUsernamePassword - username and password keys of the Secret
+	CredentialsTypeUsernamePassword CredentialsType = "usernamePassword"
+	// CredentialsTypeSecretText - secret key of the Secret
+	CredentialsTypeSecretText CredentialsType = "secretText"
+	// CredentialsTypeBasicSSHUserPrivateKey - username and privateKey keys and the optional passphrase key of the Secret
+	CredentialsTypeBasicSSHUserPrivateKey CredentialsType = "basicSSHUserPrivateKey"
+	// CredentialsTypeCertificate - certificate key with the PKCS#12 keystore and the optional password key of the Secret
+	CredentialsTypeCertificate CredentialsType = "certificate"
+)
+
+// Credentials defines Jenkins credentials created from the Secret in the global domain of the system credentials store
+type Credentials struct {
+	// ID is the unique ID of the credentials in Jenkins
+	ID string `json:"id"`
+	// Description is the description of the credentials in Jenkins
+	Description string `json:"description,omitempty"`
+	// Type is the type of the credentials, it defines keys read from the Secret
+	Type CredentialsType `json:"type"`
+	// SecretRef points at the Secret with values of the credentials
+	SecretRef corev1.LocalObjectReference `json:"secretRef"`
+}
+
+// CredentialsAutoDiscovery defines how Secrets mirrored into Jenkins credentials are discovered, the value of the label
+// is the credentials type, the ID defaults to the name of the Secret and can be set by the jenkins.io/credentials-id
+// annotation, the description is set by the jenkins.io/credentials-description annotation
+type CredentialsAutoDiscovery struct {
+	// LabelSelector is the key of the label with the credentials type, defaults to jenkins.io/credentials-type
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// Vault defines how operator reads secrets from HashiCorp Vault, secret references with the vault:<path>#<key> scheme
+// are resolved at reconcile time and re-resolved every RefreshInterval
+type Vault struct {
+	// Address is the URL of the Vault server, e.g. https://vault.vault.svc:8200
+	Address string `json:"address"`
+	// Auth defines how operator authenticates to Vault
+	Auth VaultAuth `json:"auth"`
+	// TLS defines how the certificate of the Vault server is verified
+	TLS *VaultTLS `json:"tls,omitempty"`
+	// RefreshInterval defines how long values read from Vault are cached, defaults to 5m
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// VaultAuth defines the Vault auth method used by operator
+type VaultAuth struct {
+	// Kubernetes authenticates by the token of the operator ServiceAccount
+	Kubernetes *VaultKubernetesAuth `json:"kubernetes,omitempty"`
+}
+
+// VaultKubernetesAuth defines the Vault Kubernetes auth method
+type VaultKubernetesAuth struct {
+	// Role is the Vault role bound to the operator ServiceAccount
+	Role string `json:"role"`
+	// MountPath is the path the auth method is mounted at, defaults to kubernetes
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// VaultTLS defines how the certificate of the Vault server is verified
+type VaultTLS struct {
+	// CACertSecretRef points at the PEM encoded CA certificate of the Vault server
+	CACertSecretRef *corev1.SecretKeySelector `json:"caCertSecretRef,omitempty"`
+	// ServerName is the name used to verify the certificate of the Vault server, defaults to the host of the address
+	ServerName string `json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the verification of the certificate of the Vault server
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// Notifications defines how Jenkins notifies users
+type Notifications struct {
+	// SMTP configures the SMTP server used by the mailer plugin to send emails
+	SMTP *SMTP `json:"smtp,omitempty"`
+	// Slack forwards events of the Jenkins CR to the Slack incoming webhook
+	Slack *Slack `json:"slack,omitempty"`
+	// Webhooks forward events of the Jenkins CR to HTTP endpoints, each webhook has its own event reasons
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// SMTP defines the SMTP server of the mailer plugin, the configuration is applied by the base configuration
+type SMTP struct {
+	// Host is the host name of the SMTP server
+	Host string `json:"host"`
+	// Port is the port of the SMTP server, defaults to 25 or 465 when SSL is enabled
+	Port int32 `json:"port,omitempty"`
+	// SSL enables SMTP over SSL
+	SSL bool `json:"ssl,omitempty"`
+	// StartTLS enables the STARTTLS command to switch the connection to TLS
+	StartTLS bool `json:"startTLS,omitempty"`
+	// CredentialsSecretRef points at the secret with username and password keys used to authenticate to the SMTP server
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+	// DefaultSender is the address emails are sent from, it's the Jenkins system admin email address
+	DefaultSender string `json:"defaultSender,omitempty"`
+	// ReplyTo is the default Reply-To address of emails
+	ReplyTo string `json:"replyTo,omitempty"`
+	// SendTestEmail is the address the test email is sent to after the SMTP configuration is applied
+	SendTestEmail string `json:"sendTestEmail,omitempty"`
+}
+
+// Slack defines the Slack incoming webhook the operator posts lifecycle events of the Jenkins CR to
+type Slack struct {
+	// WebhookURLSecretKeyRef points at the secret key with the URL of the Slack incoming webhook
+	WebhookURLSecretKeyRef corev1.SecretKeySelector `json:"webhookURLSecretKeyRef"`
+	// Reasons are reasons of events forwarded to Slack, e.g. CRValidationFailure or JenkinsPodRecreated
+	Reasons []string `json:"reasons"`
+}
+
+// WebhookFormat defines the payload posted to the webhook
+type WebhookFormat string
+
+const (
+	// WebhookFormatGeneric - JSON with the Jenkins CR reference, the event type, the reason, the message and the timestamp
+	WebhookFormatGeneric WebhookFormat = "generic"
+	// WebhookFormatMSTeams - the message card of the Microsoft Teams incoming webhook
+	WebhookFormatMSTeams WebhookFormat = "msTeams"
+	// WebhookFormatSlack - the message of the Slack incoming webhook
+	WebhookFormatSlack WebhookFormat = "slack"
+)
+
+// Webhook defines the HTTP endpoint the operator posts lifecycle events of the Jenkins CR to
+type Webhook struct {
+	// Name identifies the webhook in logs
+	Name string `json:"name"`
+	// URLSecretKeyRef points at the secret key with the URL of the webhook
+	URLSecretKeyRef corev1.SecretKeySelector `json:"urlSecretKeyRef"`
+	// Format is the payload format, defaults to generic
+	Format WebhookFormat `json:"format,omitempty"`
+	// Reasons are reasons of events forwarded to the webhook, e.g. CRValidationFailure or JenkinsPodRecreated
+	Reasons []string `json:"reasons"`
+}
+
+// SSHKnownHosts references known_hosts entries stored in a ConfigMap or a Secret
+type SSHKnownHosts struct {
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *corev1.SecretKeySelector    `json:"secretKeyRef,omitempty"`
+}
+
+// SharedLibrary defines the global pipeline shared library of the workflow-cps-global-lib plugin
+type SharedLibrary struct {
+	// Name is the name pipelines load the library by, e.g. @Library('name')
+	Name string `json:"name"`
+	// DefaultVersion is the branch, the tag or the commit loaded when the pipeline doesn't select the version,
+	// it's required by implicit libraries and libraries which version can't be overridden
+	DefaultVersion string `json:"defaultVersion,omitempty"`
+	// Retrieval defines where the library is retrieved from
+	Retrieval SharedLibraryRetrieval `json:"retrieval"`
+	// Implicit loads the library in all pipelines without @Library
+	Implicit bool `json:"implicit,omitempty"`
+	// AllowVersionOverride allows pipelines to select another version of the library, defaults to true
+	AllowVersionOverride *bool `json:"allowVersionOverride,omitempty"`
+}
+
+// SharedLibraryRetrieval defines the source control the shared library is retrieved from
+type SharedLibraryRetrieval struct {
+	// Git retrieves the library from the git repository
+	Git *SharedLibraryGitRetrieval `json:"git,omitempty"`
+}
+
+// SharedLibraryGitRetrieval defines the git repository of the shared library
+type SharedLibraryGitRetrieval struct {
+	// RepositoryURL is the https or ssh URL of the repository
+	RepositoryURL string `json:"repositoryUrl"`
+	// CredentialsID is the ID of Jenkins credentials used to access the repository, e.g. credentials declared
+	// in Jenkins.Spec.Credentials
+	CredentialsID string `json:"credentialsId,omitempty"`
+}
+
+// SharedLibraryStatus defines the global pipeline shared library registered by operator
+type SharedLibraryStatus struct {
+	// Name is the name of the library
+	Name string `json:"name"`
+	// Checksum is the checksum of the library configuration
+	Checksum string `json:"checksum"`
+}
+
+// Tools defines installations of the global tool configuration of Jenkins
+type Tools struct {
+	// JDK contains JDK installations
+	JDK []ToolInstallation `json:"jdk,omitempty"`
+	// Maven contains Maven installations
+	Maven []ToolInstallation `json:"maven,omitempty"`
+	// Gradle contains Gradle installations of the gradle plugin
+	Gradle []ToolInstallation `json:"gradle,omitempty"`
+	// NodeJS contains NodeJS installations of the nodejs plugin
+	NodeJS []ToolInstallation `json:"nodejs,omitempty"`
+}
+
+// ToolInstallation defines the tool installation, it's either installed automatically or preinstalled on agents
+type ToolInstallation struct {
+	// Name is the name pipelines and jobs select the installation by
+	Name string `json:"name"`
+	// Version is the version installed automatically from the update center, e.g. 3.6.0 for Maven
+	Version string `json:"version,omitempty"`
+	// Home is the path of the tool preinstalled on agents
+	Home string `json:"home,omitempty"`
+}
+
+// ViewType defines the type of the Jenkins view
+type ViewType string
+
+const (
+	// ViewTypeList - the list view showing selected jobs
+	ViewTypeList ViewType = "list"
+)
+
+// View defines the view of Jenkins
+type View struct {
+	// Name is the name of the view
+	Name string `json:"name"`
+	// Type is the type of the view, defaults to list
+	Type ViewType `json:"type,omitempty"`
+	// IncludeRegex shows jobs which names match the Java regular expression
+	IncludeRegex string `json:"includeRegex,omitempty"`
+	// JobNames contains names of jobs shown in the view, relative to the folder of the view
+	JobNames []string `json:"jobNames,omitempty"`
+	// Recurse shows also jobs in folders, IncludeRegex is matched against their full names, e.g. team-a/.*
+	Recurse bool `json:"recurse,omitempty"`
+}
+
+// ViewStatus defines the view created by operator
+type ViewStatus struct {
+	// Name is the name of the view
+	Name string `json:"name"`
+	// Checksum is the checksum of the view configuration
+	Checksum string `json:"checksum"`
+}
+
+// CredentialsStatus defines Jenkins credentials managed by operator
+type CredentialsStatus struct {
+	// ID is the ID of the credentials in Jenkins
+	ID string `json:"id"`
+	// Checksum is the checksum of the type, the description and values of the credentials
+	Checksum string `json:"checksum"`
 }
 
 // JenkinsStatus defines the observed state of Jenkins
@@ -36,6 +635,257 @@ type JenkinsStatus struct {
 	BaseConfigurationCompletedTime *metav1.Time `json:"baseConfigurationCompletedTime,omitempty"`
 	UserConfigurationCompletedTime *metav1.Time `json:"userConfigurationCompletedTime,omitempty"`
 	Builds                         []Build      `json:"builds,omitempty"`
+	// URL is the Jenkins URL exposed by the Kubernetes load balancer service
+	URL string `json:"url,omitempty"`
+	// SeedJobs contains seed jobs created by operator
+	SeedJobs []SeedJobStatus `json:"seedJobs,omitempty"`
+	// ConfigurationAsCodeChecksum is the checksum of the last applied JCasC YAML files
+	ConfigurationAsCodeChecksum string `json:"configurationAsCodeChecksum,omitempty"`
+	// AppliedConfiguration contains user configuration groovy scripts applied by operator,
+	// only new scripts and scripts with changed checksum are applied again
+	AppliedConfiguration []AppliedScript `json:"appliedConfiguration,omitempty"`
+	// AppliedConfigurationAsCode contains JCasC YAML files from user configuration sources applied by operator,
+	// only new files and files with changed checksum are applied again
+	AppliedConfigurationAsCode []AppliedScript `json:"appliedConfigurationAsCode,omitempty"`
+	// SkippedUserConfigurationFilesChecksum is the checksum of files from user configuration sources which are neither
+	// groovy scripts nor JCasC YAML files, they are reported again only when the set of skipped files changes
+	SkippedUserConfigurationFilesChecksum string `json:"skippedUserConfigurationFilesChecksum,omitempty"`
+	// GitConfiguration contains the state of user configuration scripts fetched from the git repository
+	GitConfiguration *GitConfigurationStatus `json:"gitConfiguration,omitempty"`
+	// Jobs contains names of jobs created by operator
+	Jobs *OperatorJobs `json:"jobs,omitempty"`
+	// InstalledPlugins contains plugins installed in Jenkins, they are refreshed in every reconciliation loop
+	InstalledPlugins []InstalledPlugin `json:"installedPlugins,omitempty"`
+	// PluginsInSync informs whether installed versions of plugins match versions declared in Jenkins CR
+	PluginsInSync *bool `json:"pluginsInSync,omitempty"`
+	// PluginVersionsDrift contains plugins which installed versions differ from versions declared in Jenkins CR
+	PluginVersionsDrift []PluginVersionDrift `json:"pluginVersionsDrift,omitempty"`
+	// PluginsHealthy informs whether all plugins installed in Jenkins have been loaded, base configuration isn't completed
+	// until they are
+	PluginsHealthy *bool `json:"pluginsHealthy,omitempty"`
+	// FailedPlugins contains plugins which Jenkins failed to load, e.g. because of the missing dependency or too old Jenkins core
+	FailedPlugins []string `json:"failedPlugins,omitempty"`
+	// PluginChecksums contains SHA-256 checksums of archives of plugins installed in Jenkins master pod,
+	// they can be used to pin plugins in Jenkins.Spec.Master.Plugins
+	PluginChecksums []PluginChecksum `json:"pluginChecksums,omitempty"`
+	// ResolvedPlugins contains versions of plugins declared with the latest version resolved against the update center
+	ResolvedPlugins []ResolvedPlugin `json:"resolvedPlugins,omitempty"`
+	// PluginSecurityWarnings contains security warnings of the update center which affect declared plugin versions
+	PluginSecurityWarnings []PluginSecurityWarning `json:"pluginSecurityWarnings,omitempty"`
+	// PluginDependencies contains dependencies of plugins required by user which have been resolved against the update center
+	// and are installed with them, see Jenkins.Spec.Master.ResolvePluginDependencies
+	PluginDependencies []PluginDependency `json:"pluginDependencies,omitempty"`
+	// ValidationErrors contains reasons why Jenkins CR is invalid, e.g. conflicting versions of plugins,
+	// it's cleared when Jenkins CR is valid again
+	ValidationErrors []string `json:"validationErrors,omitempty"`
+	// Conditions contains the latest observations of the provisioning state of Jenkins, e.g. the Ready condition
+	// used by kubectl wait --for=condition=Ready
+	Conditions []JenkinsCondition `json:"conditions,omitempty"`
+	// Phase summarizes conditions, it's shown by kubectl get jenkins
+	Phase JenkinsPhase `json:"phase,omitempty"`
+	// JenkinsVersion is the version of Jenkins reported by Jenkins API
+	JenkinsVersion string `json:"jenkinsVersion,omitempty"`
+	// OperatorAuthentication informs which Jenkins user operator uses to access Jenkins API
+	OperatorAuthentication OperatorAuthentication `json:"operatorAuthentication,omitempty"`
+	// OperatorUserName is the name of the Jenkins user operator uses to access Jenkins API
+	OperatorUserName string `json:"operatorUserName,omitempty"`
+	// LDAPConnected informs whether Jenkins can connect to all LDAP servers of Jenkins.Spec.Security.LDAP,
+	// it's checked after the base configuration is applied
+	LDAPConnected *bool `json:"ldapConnected,omitempty"`
+	// LDAPConnectionErrors contains errors of connections to LDAP servers
+	LDAPConnectionErrors []string `json:"ldapConnectionErrors,omitempty"`
+	// Credentials contains Jenkins credentials created from Secrets by operator, credentials are updated
+	// only when their checksum changes
+	Credentials []CredentialsStatus `json:"credentials,omitempty"`
+	// SMTPTestEmailChecksum is the checksum of SMTP settings the test email has been sent with, the test email
+	// is sent again when the settings change
+	SMTPTestEmailChecksum string `json:"smtpTestEmailChecksum,omitempty"`
+	// SharedLibraries contains global pipeline shared libraries registered by operator, libraries are configured
+	// again only when their checksum changes
+	SharedLibraries []SharedLibraryStatus `json:"sharedLibraries,omitempty"`
+	// ToolsChecksum is the checksum of Jenkins.Spec.Tools applied by operator, tools are configured again
+	// only when the checksum changes
+	ToolsChecksum string `json:"toolsChecksum,omitempty"`
+	// Views contains views created by operator, views are configured again only when their checksum changes
+	Views []ViewStatus `json:"views,omitempty"`
+	// GlobalEnvManaged is set when operator applied Jenkins.Spec.Master.GlobalEnv, global environment variables
+	// are removed from Jenkins when the section is removed afterwards
+	GlobalEnvManaged bool `json:"globalEnvManaged,omitempty"`
+	// SystemMessageChecksum is the checksum of Jenkins.Spec.Master.SystemMessage applied by operator, it's empty
+	// when the message isn't managed by operator
+	SystemMessageChecksum string `json:"systemMessageChecksum,omitempty"`
+	// DisabledAdministrativeMonitors contains IDs of administrative monitors disabled by operator, monitors removed
+	// from Jenkins.Spec.Master.DisabledAdministrativeMonitors are enabled again
+	DisabledAdministrativeMonitors []string `json:"disabledAdministrativeMonitors,omitempty"`
+}
+
+// JenkinsPhase defines the summary of the provisioning state of Jenkins
+type JenkinsPhase string
+
+const (
+	// JenkinsPhaseProvisioning informs Jenkins master pod is being created or the base configuration is being applied
+	JenkinsPhaseProvisioning JenkinsPhase = "Provisioning"
+	// JenkinsPhaseBaseConfigured informs the base configuration has been applied and the user configuration
+	// is being applied
+	JenkinsPhaseBaseConfigured JenkinsPhase = "BaseConfigured"
+	// JenkinsPhaseReady informs Jenkins is ready, see JenkinsConditionReady
+	JenkinsPhaseReady JenkinsPhase = "Ready"
+	// JenkinsPhaseValidationFailed informs Jenkins CR is invalid, operator waits until it's corrected
+	JenkinsPhaseValidationFailed JenkinsPhase = "ValidationFailed"
+	// JenkinsPhaseError informs the reconciliation failed, it's retried
+	JenkinsPhaseError JenkinsPhase = "Error"
+)
+
+// JenkinsConditionType defines the type of the condition of Jenkins
+type JenkinsConditionType string
+
+const (
+	// JenkinsConditionBaseConfigurationReady informs the base configuration has been applied
+	JenkinsConditionBaseConfigurationReady JenkinsConditionType = "BaseConfigurationReady"
+	// JenkinsConditionUserConfigurationReady informs the user configuration has been applied
+	JenkinsConditionUserConfigurationReady JenkinsConditionType = "UserConfigurationReady"
+	// JenkinsConditionJenkinsAPIReachable informs operator can access Jenkins API
+	JenkinsConditionJenkinsAPIReachable JenkinsConditionType = "JenkinsAPIReachable"
+	// JenkinsConditionReady informs Jenkins is reachable and both configuration phases have been applied
+	JenkinsConditionReady JenkinsConditionType = "Ready"
+)
+
+// JenkinsCondition defines the observation of the state of Jenkins, it follows the pattern of Kubernetes conditions
+type JenkinsCondition struct {
+	// Type is the type of the condition
+	Type JenkinsConditionType `json:"type"`
+	// Status is one of True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the status has changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is the CamelCase reason of the last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is the human readable message with details about the last transition
+	Message string `json:"message,omitempty"`
+}
+
+// PluginDependency defines the plugin installed as the dependency of plugins required by user
+type PluginDependency struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is the latest version of the plugin in the update center
+	Version string `json:"version"`
+	// RequiredBy contains names of plugins which depend on the plugin
+	RequiredBy []string `json:"requiredBy"`
+}
+
+// InstalledPlugin defines the plugin installed in Jenkins
+type InstalledPlugin struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is the installed version of the plugin
+	Version string `json:"version"`
+	// Enabled informs whether the plugin is enabled in Jenkins
+	Enabled bool `json:"enabled"`
+	// HasUpdate informs whether a newer version of the plugin is available in the update center
+	HasUpdate bool `json:"hasUpdate"`
+}
+
+// PluginSecurityWarning defines the security warning which affects the declared version of the plugin
+type PluginSecurityWarning struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is the declared version of the plugin
+	Version string `json:"version"`
+	// ID is the ID of the security advisory, e.g. SECURITY-1234
+	ID string `json:"id"`
+	// URL is the URL of the security advisory
+	URL string `json:"url,omitempty"`
+}
+
+// ResolvedPlugin defines the version of the plugin declared with the latest version in Jenkins CR
+type ResolvedPlugin struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is the latest version of the plugin in the update center
+	Version string `json:"version"`
+}
+
+// PluginDownloadRetries defines how failed plugin downloads are retried, the delay is doubled after every attempt
+type PluginDownloadRetries struct {
+	// Attempts is the maximum number of attempts to download a single plugin, 5 by default
+	Attempts int `json:"attempts,omitempty"`
+	// DelaySeconds is the delay before the first retry in seconds, 2 by default
+	DelaySeconds int `json:"delaySeconds,omitempty"`
+}
+
+// PluginChecksum defines the checksum of the plugin installed in Jenkins
+type PluginChecksum struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// Version is the installed version of the plugin
+	Version string `json:"version"`
+	// SHA256 is the hex encoded SHA-256 checksum of the plugin archive
+	SHA256 string `json:"sha256"`
+}
+
+// PluginVersionDrift defines the plugin installed in Jenkins in another version than declared in Jenkins CR
+type PluginVersionDrift struct {
+	// Name is the name of the plugin
+	Name string `json:"name"`
+	// DeclaredVersion is the version of the plugin declared in Jenkins CR
+	DeclaredVersion string `json:"declaredVersion"`
+	// InstalledVersion is the version of the plugin installed in Jenkins
+	InstalledVersion string `json:"installedVersion"`
+}
+
+// OperatorJobs defines names of jobs created by operator in Jenkins, they are prefixed with Jenkins.Spec.JobNamePrefix
+type OperatorJobs struct {
+	// BaseConfiguration is the name of the job which applies the base configuration
+	BaseConfiguration string `json:"baseConfiguration,omitempty"`
+	// UserConfiguration is the name of the job which applies the user configuration
+	UserConfiguration string `json:"userConfiguration,omitempty"`
+	// ConfigureSeedJobs is the name of the job which creates and updates seed jobs
+	ConfigureSeedJobs string `json:"configureSeedJobs,omitempty"`
+	// DeleteSeedJobs is the name of the job which deletes seed jobs removed from Jenkins CR
+	DeleteSeedJobs string `json:"deleteSeedJobs,omitempty"`
+}
+
+// GitConfigurationStatus defines observed state of user configuration scripts fetched from the git repository
+type GitConfigurationStatus struct {
+	// Source is the repository URL, the branch or the revision and the path scripts are fetched from
+	Source string `json:"source,omitempty"`
+	// FetchedCommit is the SHA of the last fetched commit
+	FetchedCommit string `json:"fetchedCommit,omitempty"`
+	// AppliedCommit is the SHA of the commit which scripts have been applied
+	AppliedCommit string `json:"appliedCommit,omitempty"`
+	// LastFetchTime is the time of the last attempt to fetch the repository
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+}
+
+// AppliedScriptResult defines the result of the user configuration groovy script or JCasC YAML file
+type AppliedScriptResult string
+
+const (
+	// AppliedScriptRunningResult - the script is being applied by the job
+	AppliedScriptRunningResult AppliedScriptResult = "running"
+	// AppliedScriptSuccessResult - the script has been applied successfully
+	AppliedScriptSuccessResult AppliedScriptResult = "success"
+	// AppliedScriptFailureResult - the job applying the script has failed, the script is applied again when it changes
+	AppliedScriptFailureResult AppliedScriptResult = "failure"
+	// AppliedScriptSyntaxErrorResult - the script can't be compiled, scripts aren't applied until it's fixed
+	AppliedScriptSyntaxErrorResult AppliedScriptResult = "syntax_error"
+)
+
+// AppliedScript defines the state of the user configuration groovy script or JCasC YAML file applied by operator
+type AppliedScript struct {
+	// Source is the kind and the name of the object with the script, e.g. configmap/name or secret/name
+	Source string `json:"source"`
+	// Key is the key of the script in the source
+	Key string `json:"key"`
+	// Checksum is the checksum of the script content and values of secret references used by it
+	Checksum string `json:"checksum,omitempty"`
+	// LastAppliedTime is the time when the job applying the script has finished
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// Result is the result of the last application of the script
+	Result AppliedScriptResult `json:"result,omitempty"`
+	// Failures is the number of consecutive failed applications of the script with the checksum
+	Failures int `json:"failures,omitempty"`
 }
 
 // BuildStatus defines type of Jenkins build job status
@@ -63,12 +913,59 @@ type Build struct {
 	JobName        string       `json:"jobName,omitempty"`
 	Hash           string       `json:"hash,omitempty"`
 	Number         int64        `json:"number,omitempty"`
+	URL            string       `json:"url,omitempty"`
 	Status         BuildStatus  `json:"status,omitempty"`
 	Retires        int          `json:"retries,omitempty"`
 	CreateTime     *metav1.Time `json:"createTime,omitempty"`
 	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
+// SeedJobGCPolicy defines what happens with jobs generated by the seed job removed from Jenkins CR
+type SeedJobGCPolicy string
+
+const (
+	// SeedJobGCPolicyOrphan - jobs generated by the removed seed job are left in Jenkins
+	SeedJobGCPolicyOrphan SeedJobGCPolicy = "orphan"
+	// SeedJobGCPolicyDelete - jobs generated by the removed seed job are deleted from Jenkins
+	SeedJobGCPolicyDelete SeedJobGCPolicy = "delete"
+)
+
+// SeedJobMode defines which kind of job is created for the seed job
+type SeedJobMode string
+
+const (
+	// SeedJobModeJobDSL - the seed job runs Job DSL scripts from the repository, it's the default
+	SeedJobModeJobDSL SeedJobMode = "jobDSL"
+	// SeedJobModeMultibranch - the seed job is a multibranch pipeline building Jenkinsfile of every repository branch
+	SeedJobModeMultibranch SeedJobMode = "multibranch"
+	// SeedJobModeOrganizationFolder - the seed job is a GitHub organization folder building Jenkinsfile of every organization repository
+	SeedJobModeOrganizationFolder SeedJobMode = "organizationFolder"
+)
+
+// SeedJobStatus defines observed state of the seed job created by operator
+type SeedJobStatus struct {
+	ID       string          `json:"id"`
+	GCPolicy SeedJobGCPolicy `json:"gcPolicy,omitempty"`
+	Mode     SeedJobMode     `json:"mode,omitempty"`
+	// LastBuildNumber is the number of the last build which configured the seed job
+	LastBuildNumber int64 `json:"lastBuildNumber,omitempty"`
+	// LastBuildURL is the Jenkins URL of the last build which configured the seed job
+	LastBuildURL string `json:"lastBuildURL,omitempty"`
+	// LastBuildResult is the Jenkins result of the last build, e.g. SUCCESS, FAILURE or UNSTABLE
+	LastBuildResult string `json:"lastBuildResult,omitempty"`
+	// LastBuildTime is the time of the last build status change
+	LastBuildTime *metav1.Time `json:"lastBuildTime,omitempty"`
+	// LastSuccessfulBuildTime is the time the last successful build finished
+	LastSuccessfulBuildTime *metav1.Time `json:"lastSuccessfulBuildTime,omitempty"`
+	// FailureReason describes why the last build failed
+	FailureReason string `json:"failureReason,omitempty"`
+	// DiscoveredBranches is the number of branches discovered by the multibranch pipeline scan
+	DiscoveredBranches int `json:"discoveredBranches,omitempty"`
+	// GitHubWebhookChecksum is the checksum of the repository URL and Jenkins URL the GitHub push webhook has been
+	// registered with, the webhook is registered again when one of them changes
+	GitHubWebhookChecksum string `json:"githubWebhookChecksum,omitempty"`
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // Jenkins is the Schema for the jenkins API
@@ -98,11 +995,168 @@ type SeedJob struct {
 	RepositoryBranch string     `json:"repositoryBranch,omitempty"`
 	RepositoryURL    string     `json:"repositoryUrl"`
 	PrivateKey       PrivateKey `json:"privateKey,omitempty"`
+	// Revision is the tag or the full commit SHA the seed job is pinned to, it takes precedence over RepositoryBranch
+	// and disables SCM triggers of the seed job
+	Revision string `json:"revision,omitempty"`
+	// DisplayName is the display name of the seed job in Jenkins, defaults to Seed Job from <id>
+	DisplayName string `json:"displayName,omitempty"`
+	// UsernamePassword contains credentials used with https repository url, e.g. Bitbucket app password
+	UsernamePassword UsernamePassword `json:"usernamePassword,omitempty"`
+	// Token contains the access token used with https repository url, e.g. GitHub personal access token
+	Token Token `json:"token,omitempty"`
+	// PollSCM is the Jenkins cron schedule used to poll the repository for changes
+	PollSCM string `json:"pollSCM,omitempty"`
+	// BuildPeriodically is the Jenkins cron schedule of the seed job builds, it reverts manual changes of generated jobs
+	BuildPeriodically string `json:"buildPeriodically,omitempty"`
+	// GitHubPushTrigger builds the seed job when a push to the GitHub repository is received
+	GitHubPushTrigger bool `json:"githubPushTrigger,omitempty"`
+	// ManageWebhook enables registration of the push webhook in the GitHub repository by the operator
+	ManageWebhook bool `json:"manageWebhook,omitempty"`
+	// GitHubAPIToken contains the GitHub API token used to register the push webhook
+	GitHubAPIToken GitHubAPIToken `json:"githubAPIToken,omitempty"`
+	// BitbucketPushTrigger builds the seed job when a push to the Bitbucket repository is received
+	BitbucketPushTrigger bool `json:"bitbucketPushTrigger,omitempty"`
+	// GerritTrigger builds the seed job when a change is merged in the Gerrit project
+	GerritTrigger *GerritTrigger `json:"gerritTrigger,omitempty"`
+	// GCPolicy determines if jobs generated by the seed job are deleted when the seed job is removed from Jenkins CR
+	GCPolicy SeedJobGCPolicy `json:"gcPolicy,omitempty"`
+	// AgentLabel is the label expression restricting where the seed job can run, overrides Jenkins.Spec.SeedJobAgent
+	AgentLabel string `json:"agentLabel,omitempty"`
+	// AdditionalClasspath contains newline separated paths relative to the workspace added to Job DSL classpath, defaults to src
+	AdditionalClasspath string `json:"additionalClasspath,omitempty"`
+	// Sandbox runs Job DSL scripts in the script security sandbox, otherwise they run with full privileges
+	Sandbox bool `json:"sandbox,omitempty"`
+	// ApproveScripts makes operator approve Job DSL scripts pending in script approval when the seed job build failed because of them
+	ApproveScripts bool `json:"approveScripts,omitempty"`
+	// Mode is one of jobDSL, multibranch or organizationFolder, defaults to jobDSL
+	Mode SeedJobMode `json:"mode,omitempty"`
+	// JobDSLSettings contains Job DSL build step settings
+	JobDSLSettings JobDSLSettings `json:"jobDslSettings,omitempty"`
+	// MultibranchSettings contains multibranch pipeline settings used in multibranch mode
+	MultibranchSettings MultibranchSettings `json:"multibranchSettings,omitempty"`
+	// OrganizationFolderSettings contains GitHub organization folder settings used in organizationFolder mode
+	OrganizationFolderSettings OrganizationFolderSettings `json:"organizationFolderSettings,omitempty"`
+	// BuildTimeout is the maximum duration of the seed job build, the build is aborted and retried when exceeded
+	BuildTimeout *metav1.Duration `json:"buildTimeout,omitempty"`
+	// Retries is the maximum amount of retries of the failed seed job build, defaults to 3
+	Retries *int `json:"retries,omitempty"`
+	// RetryBackoff is the delay before the first retry of the failed seed job build, it's doubled for every next retry
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+	// ReRunInterval makes operator build the seed job again when the interval elapsed since the last successful build,
+	// it reverts manual changes of Jenkins made in the meantime
+	ReRunInterval *metav1.Duration `json:"reRunInterval,omitempty"`
+	// BuildRetention is the number of the last builds of the Job DSL seed job kept by Jenkins, defaults to 10
+	BuildRetention *int `json:"buildRetention,omitempty"`
+	// KnownHosts contains known_hosts entries of the git server, added to entries from Jenkins.Spec.Master.SSHKnownHosts
+	KnownHosts string `json:"knownHosts,omitempty"`
+	// InsecureSkipHostKeyVerification disables host key verification of the ssh repository url host
+	InsecureSkipHostKeyVerification bool `json:"insecureSkipHostKeyVerification,omitempty"`
+	// Shallow enables shallow clone of the repository, defaults to true unless the repository branch is a tag or a commit
+	Shallow *bool `json:"shallow,omitempty"`
+	// CloneDepth is the depth of the shallow clone, defaults to 1
+	CloneDepth *int `json:"cloneDepth,omitempty"`
+	// ReferenceRepository is the path to a local repository on the node used as a reference to speed up the clone
+	ReferenceRepository string `json:"referenceRepository,omitempty"`
+	// Submodules contains git submodules settings of the repository checkout
+	Submodules Submodules `json:"submodules,omitempty"`
+	// ValidateConnectivity makes operator check the repository can be read with the seed job credentials
+	// while validating Jenkins CR, the seed job isn't created when the check fails
+	ValidateConnectivity bool `json:"validateConnectivity,omitempty"`
+}
+
+// MultibranchSettings defines the multibranch pipeline created for the seed job
+type MultibranchSettings struct {
+	// JenkinsfilePath is the path to the Jenkinsfile in the repository, defaults to Jenkinsfile
+	JenkinsfilePath string `json:"jenkinsfilePath,omitempty"`
+	// IncludeBranches is the space separated list of wildcard patterns of discovered branches, defaults to *
+	IncludeBranches string `json:"includeBranches,omitempty"`
+	// ExcludeBranches is the space separated list of wildcard patterns of branches which aren't discovered
+	ExcludeBranches string `json:"excludeBranches,omitempty"`
+}
+
+// OrganizationFolderSettings defines the GitHub organization folder created for the seed job
+type OrganizationFolderSettings struct {
+	// Organization is the name of the GitHub organization or user
+	Organization string `json:"organization"`
+	// IncludeRepositories is the space separated list of wildcard patterns of scanned repositories, defaults to *
+	IncludeRepositories string `json:"includeRepositories,omitempty"`
+	// ExcludeRepositories is the space separated list of wildcard patterns of repositories which aren't scanned
+	ExcludeRepositories string `json:"excludeRepositories,omitempty"`
+	// ScanInterval is the interval of the periodic organization scan e.g. 1h or 1d, defaults to 1d
+	ScanInterval string `json:"scanInterval,omitempty"`
+}
+
+// GerritTrigger defines the Gerrit trigger of the seed job
+type GerritTrigger struct {
+	// ServerName is the name of the Gerrit server configured in the gerrit-trigger plugin
+	ServerName string `json:"serverName"`
+	// Project is the name of the Gerrit project
+	Project string `json:"project"`
+	// BranchPattern is the Ant style pattern of branches triggering the seed job, defaults to **
+	BranchPattern string `json:"branchPattern,omitempty"`
+}
+
+// JobDSLSettings defines Job DSL behavior for the seed job
+type JobDSLSettings struct {
+	// IgnoreMissingFiles makes the build pass when no Job DSL script matches the targets
+	IgnoreMissingFiles bool `json:"ignoreMissingFiles,omitempty"`
+	// FailOnMissingPlugin fails the build when a Job DSL script uses a plugin which is not installed
+	FailOnMissingPlugin bool `json:"failOnMissingPlugin,omitempty"`
+	// RemovedJobAction is one of IGNORE, DISABLE or DELETE, defaults to DELETE
+	RemovedJobAction string `json:"removedJobAction,omitempty"`
+	// RemovedViewAction is one of IGNORE or DELETE, defaults to DELETE
+	RemovedViewAction string `json:"removedViewAction,omitempty"`
+}
+
+// Submodules defines how git submodules of the seed job repository are checked out
+type Submodules struct {
+	// Enabled initializes and updates git submodules after the checkout
+	Enabled bool `json:"enabled,omitempty"`
+	// Recursive updates submodules of submodules too
+	Recursive bool `json:"recursive,omitempty"`
+	// ParentCredentials makes submodules use credentials of the seed job repository
+	ParentCredentials bool `json:"parentCredentials,omitempty"`
 }
 
 // PrivateKey contains a private key
 type PrivateKey struct {
 	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef"`
+	// PassphraseSecretKeyRef points at the passphrase of the encrypted private key
+	PassphraseSecretKeyRef *corev1.SecretKeySelector `json:"passphraseSecretKeyRef,omitempty"`
+}
+
+const (
+	// UsernameSecretKey is the key of the username in UsernamePassword Secret
+	UsernameSecretKey = "username"
+	// PasswordSecretKey is the key of the password in UsernamePassword Secret
+	PasswordSecretKey = "password"
+	// PrivateKeySecretKey is the key of the ssh private key in GitConfiguration.CredentialsSecret
+	PrivateKeySecretKey = "privateKey"
+)
+
+// UsernamePassword contains a username and a password
+type UsernamePassword struct {
+	// SecretRef points at the Secret with username and password keys
+	SecretRef *corev1.LocalObjectReference `json:"secretRef,omitempty"`
+}
+
+const (
+	// TokenProviderGitHub uses x-access-token username with the token, it's the default
+	TokenProviderGitHub = "github"
+	// TokenProviderGitLab uses oauth2 username with the token
+	TokenProviderGitLab = "gitlab"
+)
+
+// Token contains an access token of the git provider
+type Token struct {
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef"`
+	// Provider determines the username used with the token, one of github or gitlab, defaults to github
+	Provider string `json:"provider,omitempty"`
+}
+
+// GitHubAPIToken contains a GitHub API token
+type GitHubAPIToken struct {
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef"`
 }
 
 func init() {
@@ -22,9 +22,58 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AppliedScript) DeepCopyInto(out *AppliedScript) {
+	*out = *in
+	if in.LastAppliedTime != nil {
+		in, out := &in.LastAppliedTime, &out.LastAppliedTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AppliedScript.
+func (in *AppliedScript) DeepCopy() *AppliedScript {
+	if in == nil {
+		return nil
+	}
+	out := new(AppliedScript)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Authorization) DeepCopyInto(out *Authorization) {
+	*out = *in
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make([]MatrixPermissions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RoleStrategy != nil {
+		in, out := &in.RoleStrategy, &out.RoleStrategy
+		*out = new(RoleStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Authorization.
+func (in *Authorization) DeepCopy() *Authorization {
+	if in == nil {
+		return nil
+	}
+	out := new(Authorization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Build) DeepCopyInto(out *Build) {
 	*out = *in
@@ -49,6 +98,272 @@ func (in *Build) DeepCopy() *Build {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Configuration) DeepCopyInto(out *Configuration) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.URLs != nil {
+		in, out := &in.URLs, &out.URLs
+		*out = make([]URLConfiguration, len(*in))
+		copy(*out, *in)
+	}
+	if in.BuildRetention != nil {
+		in, out := &in.BuildRetention, &out.BuildRetention
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Configuration.
+func (in *Configuration) DeepCopy() *Configuration {
+	if in == nil {
+		return nil
+	}
+	out := new(Configuration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigurationAsCode) DeepCopyInto(out *ConfigurationAsCode) {
+	*out = *in
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigurationAsCode.
+func (in *ConfigurationAsCode) DeepCopy() *ConfigurationAsCode {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigurationAsCode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Credentials) DeepCopyInto(out *Credentials) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Credentials.
+func (in *Credentials) DeepCopy() *Credentials {
+	if in == nil {
+		return nil
+	}
+	out := new(Credentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsAutoDiscovery) DeepCopyInto(out *CredentialsAutoDiscovery) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsAutoDiscovery.
+func (in *CredentialsAutoDiscovery) DeepCopy() *CredentialsAutoDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsAutoDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CredentialsStatus) DeepCopyInto(out *CredentialsStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CredentialsStatus.
+func (in *CredentialsStatus) DeepCopy() *CredentialsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CredentialsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraFile) DeepCopyInto(out *ExtraFile) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraFile.
+func (in *ExtraFile) DeepCopy() *ExtraFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GerritTrigger) DeepCopyInto(out *GerritTrigger) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GerritTrigger.
+func (in *GerritTrigger) DeepCopy() *GerritTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(GerritTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitConfiguration) DeepCopyInto(out *GitConfiguration) {
+	*out = *in
+	if in.PollInterval != nil {
+		in, out := &in.PollInterval, &out.PollInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitConfiguration.
+func (in *GitConfiguration) DeepCopy() *GitConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(GitConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitConfigurationStatus) DeepCopyInto(out *GitConfigurationStatus) {
+	*out = *in
+	if in.LastFetchTime != nil {
+		in, out := &in.LastFetchTime, &out.LastFetchTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitConfigurationStatus.
+func (in *GitConfigurationStatus) DeepCopy() *GitConfigurationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitConfigurationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubAPIToken) DeepCopyInto(out *GitHubAPIToken) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubAPIToken.
+func (in *GitHubAPIToken) DeepCopy() *GitHubAPIToken {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubAPIToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InitScripts) DeepCopyInto(out *InitScripts) {
+	*out = *in
+	if in.ConfigMaps != nil {
+		in, out := &in.ConfigMaps, &out.ConfigMaps
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitScripts.
+func (in *InitScripts) DeepCopy() *InitScripts {
+	if in == nil {
+		return nil
+	}
+	out := new(InitScripts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstalledPlugin) DeepCopyInto(out *InstalledPlugin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstalledPlugin.
+func (in *InstalledPlugin) DeepCopy() *InstalledPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(InstalledPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Jenkins) DeepCopyInto(out *Jenkins) {
 	*out = *in
@@ -77,6 +392,44 @@ func (in *Jenkins) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsAgent) DeepCopyInto(out *JenkinsAgent) {
+	*out = *in
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsAgent.
+func (in *JenkinsAgent) DeepCopy() *JenkinsAgent {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsAgent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JenkinsCondition) DeepCopyInto(out *JenkinsCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsCondition.
+func (in *JenkinsCondition) DeepCopy() *JenkinsCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(JenkinsCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JenkinsList) DeepCopyInto(out *JenkinsList) {
 	*out = *in
@@ -121,6 +474,11 @@ func (in *JenkinsMaster) DeepCopyInto(out *JenkinsMaster) {
 		}
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.AdminCredentialsSecretRef != nil {
+		in, out := &in.AdminCredentialsSecretRef, &out.AdminCredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	if in.OperatorPlugins != nil {
 		in, out := &in.OperatorPlugins, &out.OperatorPlugins
 		*out = make(map[string][]string, len(*in))
@@ -136,6 +494,13 @@ func (in *JenkinsMaster) DeepCopyInto(out *JenkinsMaster) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.BasePluginsOverride != nil {
+		in, out := &in.BasePluginsOverride, &out.BasePluginsOverride
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.Plugins != nil {
 		in, out := &in.Plugins, &out.Plugins
 		*out = make(map[string][]string, len(*in))
@@ -151,6 +516,65 @@ func (in *JenkinsMaster) DeepCopyInto(out *JenkinsMaster) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.PluginsConfigMapRef != nil {
+		in, out := &in.PluginsConfigMapRef, &out.PluginsConfigMapRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.PluginDownloadRetries != nil {
+		in, out := &in.PluginDownloadRetries, &out.PluginDownloadRetries
+		*out = new(PluginDownloadRetries)
+		**out = **in
+	}
+	if in.SSHKnownHosts != nil {
+		in, out := &in.SSHKnownHosts, &out.SSHKnownHosts
+		*out = new(SSHKnownHosts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalCACerts != nil {
+		in, out := &in.AdditionalCACerts, &out.AdditionalCACerts
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BaseConfigurationOverrides != nil {
+		in, out := &in.BaseConfigurationOverrides, &out.BaseConfigurationOverrides
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ExtraFiles != nil {
+		in, out := &in.ExtraFiles, &out.ExtraFiles
+		*out = make([]ExtraFile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitScripts != nil {
+		in, out := &in.InitScripts, &out.InitScripts
+		*out = new(InitScripts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalEnv != nil {
+		in, out := &in.GlobalEnv, &out.GlobalEnv
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NumExecutors != nil {
+		in, out := &in.NumExecutors, &out.NumExecutors
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DisableUsageStats != nil {
+		in, out := &in.DisableUsageStats, &out.DisableUsageStats
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisabledAdministrativeMonitors != nil {
+		in, out := &in.DisabledAdministrativeMonitors, &out.DisabledAdministrativeMonitors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -175,7 +599,64 @@ func (in *JenkinsSpec) DeepCopyInto(out *JenkinsSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	return
+	in.SeedAgent.DeepCopyInto(&out.SeedAgent)
+	in.Agent.DeepCopyInto(&out.Agent)
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(Proxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigurationAsCode != nil {
+		in, out := &in.ConfigurationAsCode, &out.ConfigurationAsCode
+		*out = new(ConfigurationAsCode)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]Credentials, len(*in))
+		copy(*out, *in)
+	}
+	if in.CredentialsAutoDiscovery != nil {
+		in, out := &in.CredentialsAutoDiscovery, &out.CredentialsAutoDiscovery
+		*out = new(CredentialsAutoDiscovery)
+		**out = **in
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(Vault)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.Security != nil {
+		in, out := &in.Security, &out.Security
+		*out = new(Security)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(Notifications)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SharedLibraries != nil {
+		in, out := &in.SharedLibraries, &out.SharedLibraries
+		*out = make([]SharedLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tools != nil {
+		in, out := &in.Tools, &out.Tools
+		*out = new(Tools)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Views != nil {
+		in, out := &in.Views, &out.Views
+		*out = make([]View, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JenkinsSpec.
@@ -210,6 +691,126 @@ func (in *JenkinsStatus) DeepCopyInto(out *JenkinsStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.SeedJobs != nil {
+		in, out := &in.SeedJobs, &out.SeedJobs
+		*out = make([]SeedJobStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedConfiguration != nil {
+		in, out := &in.AppliedConfiguration, &out.AppliedConfiguration
+		*out = make([]AppliedScript, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AppliedConfigurationAsCode != nil {
+		in, out := &in.AppliedConfigurationAsCode, &out.AppliedConfigurationAsCode
+		*out = make([]AppliedScript, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GitConfiguration != nil {
+		in, out := &in.GitConfiguration, &out.GitConfiguration
+		*out = new(GitConfigurationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(OperatorJobs)
+		**out = **in
+	}
+	if in.InstalledPlugins != nil {
+		in, out := &in.InstalledPlugins, &out.InstalledPlugins
+		*out = make([]InstalledPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginsInSync != nil {
+		in, out := &in.PluginsInSync, &out.PluginsInSync
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PluginVersionsDrift != nil {
+		in, out := &in.PluginVersionsDrift, &out.PluginVersionsDrift
+		*out = make([]PluginVersionDrift, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginsHealthy != nil {
+		in, out := &in.PluginsHealthy, &out.PluginsHealthy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FailedPlugins != nil {
+		in, out := &in.FailedPlugins, &out.FailedPlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginChecksums != nil {
+		in, out := &in.PluginChecksums, &out.PluginChecksums
+		*out = make([]PluginChecksum, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResolvedPlugins != nil {
+		in, out := &in.ResolvedPlugins, &out.ResolvedPlugins
+		*out = make([]ResolvedPlugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginSecurityWarnings != nil {
+		in, out := &in.PluginSecurityWarnings, &out.PluginSecurityWarnings
+		*out = make([]PluginSecurityWarning, len(*in))
+		copy(*out, *in)
+	}
+	if in.PluginDependencies != nil {
+		in, out := &in.PluginDependencies, &out.PluginDependencies
+		*out = make([]PluginDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ValidationErrors != nil {
+		in, out := &in.ValidationErrors, &out.ValidationErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LDAPConnected != nil {
+		in, out := &in.LDAPConnected, &out.LDAPConnected
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LDAPConnectionErrors != nil {
+		in, out := &in.LDAPConnectionErrors, &out.LDAPConnectionErrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = make([]CredentialsStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedLibraries != nil {
+		in, out := &in.SharedLibraries, &out.SharedLibraries
+		*out = make([]SharedLibraryStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Views != nil {
+		in, out := &in.Views, &out.Views
+		*out = make([]ViewStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisabledAdministrativeMonitors != nil {
+		in, out := &in.DisabledAdministrativeMonitors, &out.DisabledAdministrativeMonitors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]JenkinsCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -223,6 +824,284 @@ func (in *JenkinsStatus) DeepCopy() *JenkinsStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobDSLSettings) DeepCopyInto(out *JobDSLSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobDSLSettings.
+func (in *JobDSLSettings) DeepCopy() *JobDSLSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(JobDSLSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAP) DeepCopyInto(out *LDAP) {
+	*out = *in
+	if in.ManagerPasswordSecretRef != nil {
+		in, out := &in.ManagerPasswordSecretRef, &out.ManagerPasswordSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectTimeout != nil {
+		in, out := &in.ConnectTimeout, &out.ConnectTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ReadTimeout != nil {
+		in, out := &in.ReadTimeout, &out.ReadTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAP.
+func (in *LDAP) DeepCopy() *LDAP {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatrixPermissions) DeepCopyInto(out *MatrixPermissions) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatrixPermissions.
+func (in *MatrixPermissions) DeepCopy() *MatrixPermissions {
+	if in == nil {
+		return nil
+	}
+	out := new(MatrixPermissions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MultibranchSettings) DeepCopyInto(out *MultibranchSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultibranchSettings.
+func (in *MultibranchSettings) DeepCopy() *MultibranchSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(MultibranchSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notifications) DeepCopyInto(out *Notifications) {
+	*out = *in
+	if in.SMTP != nil {
+		in, out := &in.SMTP, &out.SMTP
+		*out = new(SMTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(Slack)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]Webhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifications.
+func (in *Notifications) DeepCopy() *Notifications {
+	if in == nil {
+		return nil
+	}
+	out := new(Notifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDC) DeepCopyInto(out *OIDC) {
+	*out = *in
+	in.ClientSecretRef.DeepCopyInto(&out.ClientSecretRef)
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EscapeHatch != nil {
+		in, out := &in.EscapeHatch, &out.EscapeHatch
+		*out = new(OIDCEscapeHatch)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDC.
+func (in *OIDC) DeepCopy() *OIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCEscapeHatch) DeepCopyInto(out *OIDCEscapeHatch) {
+	*out = *in
+	out.CredentialsSecretRef = in.CredentialsSecretRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCEscapeHatch.
+func (in *OIDCEscapeHatch) DeepCopy() *OIDCEscapeHatch {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCEscapeHatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorJobs) DeepCopyInto(out *OperatorJobs) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorJobs.
+func (in *OperatorJobs) DeepCopy() *OperatorJobs {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorJobs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrganizationFolderSettings) DeepCopyInto(out *OrganizationFolderSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrganizationFolderSettings.
+func (in *OrganizationFolderSettings) DeepCopy() *OrganizationFolderSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OrganizationFolderSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginChecksum) DeepCopyInto(out *PluginChecksum) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginChecksum.
+func (in *PluginChecksum) DeepCopy() *PluginChecksum {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginChecksum)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginDependency) DeepCopyInto(out *PluginDependency) {
+	*out = *in
+	if in.RequiredBy != nil {
+		in, out := &in.RequiredBy, &out.RequiredBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginDependency.
+func (in *PluginDependency) DeepCopy() *PluginDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginDownloadRetries) DeepCopyInto(out *PluginDownloadRetries) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginDownloadRetries.
+func (in *PluginDownloadRetries) DeepCopy() *PluginDownloadRetries {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginDownloadRetries)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginSecurityWarning) DeepCopyInto(out *PluginSecurityWarning) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginSecurityWarning.
+func (in *PluginSecurityWarning) DeepCopy() *PluginSecurityWarning {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginSecurityWarning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PluginVersionDrift) DeepCopyInto(out *PluginVersionDrift) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginVersionDrift.
+func (in *PluginVersionDrift) DeepCopy() *PluginVersionDrift {
+	if in == nil {
+		return nil
+	}
+	out := new(PluginVersionDrift)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrivateKey) DeepCopyInto(out *PrivateKey) {
 	*out = *in
@@ -231,6 +1110,11 @@ func (in *PrivateKey) DeepCopyInto(out *PrivateKey) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PassphraseSecretKeyRef != nil {
+		in, out := &in.PassphraseSecretKeyRef, &out.PassphraseSecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -245,14 +1129,253 @@ func (in *PrivateKey) DeepCopy() *PrivateKey {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SeedJob) DeepCopyInto(out *SeedJob) {
+func (in *Proxy) DeepCopyInto(out *Proxy) {
 	*out = *in
-	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedJob.
-func (in *SeedJob) DeepCopy() *SeedJob {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Proxy.
+func (in *Proxy) DeepCopy() *Proxy {
+	if in == nil {
+		return nil
+	}
+	out := new(Proxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResolvedPlugin) DeepCopyInto(out *ResolvedPlugin) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResolvedPlugin.
+func (in *ResolvedPlugin) DeepCopy() *ResolvedPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(ResolvedPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Role) DeepCopyInto(out *Role) {
+	*out = *in
+	if in.Permissions != nil {
+		in, out := &in.Permissions, &out.Permissions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Assignments != nil {
+		in, out := &in.Assignments, &out.Assignments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Role.
+func (in *Role) DeepCopy() *Role {
+	if in == nil {
+		return nil
+	}
+	out := new(Role)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleStrategy) DeepCopyInto(out *RoleStrategy) {
+	*out = *in
+	if in.GlobalRoles != nil {
+		in, out := &in.GlobalRoles, &out.GlobalRoles
+		*out = make([]Role, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ItemRoles != nil {
+		in, out := &in.ItemRoles, &out.ItemRoles
+		*out = make([]Role, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleStrategy.
+func (in *RoleStrategy) DeepCopy() *RoleStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMTP) DeepCopyInto(out *SMTP) {
+	*out = *in
+	if in.CredentialsSecretRef != nil {
+		in, out := &in.CredentialsSecretRef, &out.CredentialsSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMTP.
+func (in *SMTP) DeepCopy() *SMTP {
+	if in == nil {
+		return nil
+	}
+	out := new(SMTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHKnownHosts) DeepCopyInto(out *SSHKnownHosts) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHKnownHosts.
+func (in *SSHKnownHosts) DeepCopy() *SSHKnownHosts {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHKnownHosts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Security) DeepCopyInto(out *Security) {
+	*out = *in
+	if in.TokenRotationInterval != nil {
+		in, out := &in.TokenRotationInterval, &out.TokenRotationInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(LDAP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authorization != nil {
+		in, out := &in.Authorization, &out.Authorization
+		*out = new(Authorization)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Security.
+func (in *Security) DeepCopy() *Security {
+	if in == nil {
+		return nil
+	}
+	out := new(Security)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedAgent) DeepCopyInto(out *SeedAgent) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedAgent.
+func (in *SeedAgent) DeepCopy() *SeedAgent {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedAgent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedJob) DeepCopyInto(out *SeedJob) {
+	*out = *in
+	in.PrivateKey.DeepCopyInto(&out.PrivateKey)
+	in.UsernamePassword.DeepCopyInto(&out.UsernamePassword)
+	in.Token.DeepCopyInto(&out.Token)
+	in.GitHubAPIToken.DeepCopyInto(&out.GitHubAPIToken)
+	if in.GerritTrigger != nil {
+		in, out := &in.GerritTrigger, &out.GerritTrigger
+		*out = new(GerritTrigger)
+		**out = **in
+	}
+	out.JobDSLSettings = in.JobDSLSettings
+	out.MultibranchSettings = in.MultibranchSettings
+	out.OrganizationFolderSettings = in.OrganizationFolderSettings
+	if in.BuildTimeout != nil {
+		in, out := &in.BuildTimeout, &out.BuildTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ReRunInterval != nil {
+		in, out := &in.ReRunInterval, &out.ReRunInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.BuildRetention != nil {
+		in, out := &in.BuildRetention, &out.BuildRetention
+		*out = new(int)
+		**out = **in
+	}
+	if in.Shallow != nil {
+		in, out := &in.Shallow, &out.Shallow
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CloneDepth != nil {
+		in, out := &in.CloneDepth, &out.CloneDepth
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedJob.
+func (in *SeedJob) DeepCopy() *SeedJob {
 	if in == nil {
 		return nil
 	}
@@ -260,3 +1383,394 @@ func (in *SeedJob) DeepCopy() *SeedJob {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedJobStatus) DeepCopyInto(out *SeedJobStatus) {
+	*out = *in
+	if in.LastBuildTime != nil {
+		in, out := &in.LastBuildTime, &out.LastBuildTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulBuildTime != nil {
+		in, out := &in.LastSuccessfulBuildTime, &out.LastSuccessfulBuildTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedJobStatus.
+func (in *SeedJobStatus) DeepCopy() *SeedJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLibrary) DeepCopyInto(out *SharedLibrary) {
+	*out = *in
+	in.Retrieval.DeepCopyInto(&out.Retrieval)
+	if in.AllowVersionOverride != nil {
+		in, out := &in.AllowVersionOverride, &out.AllowVersionOverride
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLibrary.
+func (in *SharedLibrary) DeepCopy() *SharedLibrary {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLibrary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLibraryGitRetrieval) DeepCopyInto(out *SharedLibraryGitRetrieval) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLibraryGitRetrieval.
+func (in *SharedLibraryGitRetrieval) DeepCopy() *SharedLibraryGitRetrieval {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLibraryGitRetrieval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLibraryRetrieval) DeepCopyInto(out *SharedLibraryRetrieval) {
+	*out = *in
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(SharedLibraryGitRetrieval)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLibraryRetrieval.
+func (in *SharedLibraryRetrieval) DeepCopy() *SharedLibraryRetrieval {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLibraryRetrieval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedLibraryStatus) DeepCopyInto(out *SharedLibraryStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedLibraryStatus.
+func (in *SharedLibraryStatus) DeepCopy() *SharedLibraryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedLibraryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Slack) DeepCopyInto(out *Slack) {
+	*out = *in
+	in.WebhookURLSecretKeyRef.DeepCopyInto(&out.WebhookURLSecretKeyRef)
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Slack.
+func (in *Slack) DeepCopy() *Slack {
+	if in == nil {
+		return nil
+	}
+	out := new(Slack)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Submodules) DeepCopyInto(out *Submodules) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Submodules.
+func (in *Submodules) DeepCopy() *Submodules {
+	if in == nil {
+		return nil
+	}
+	out := new(Submodules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Token) DeepCopyInto(out *Token) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Token.
+func (in *Token) DeepCopy() *Token {
+	if in == nil {
+		return nil
+	}
+	out := new(Token)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolInstallation) DeepCopyInto(out *ToolInstallation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolInstallation.
+func (in *ToolInstallation) DeepCopy() *ToolInstallation {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolInstallation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tools) DeepCopyInto(out *Tools) {
+	*out = *in
+	if in.JDK != nil {
+		in, out := &in.JDK, &out.JDK
+		*out = make([]ToolInstallation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Maven != nil {
+		in, out := &in.Maven, &out.Maven
+		*out = make([]ToolInstallation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Gradle != nil {
+		in, out := &in.Gradle, &out.Gradle
+		*out = make([]ToolInstallation, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeJS != nil {
+		in, out := &in.NodeJS, &out.NodeJS
+		*out = make([]ToolInstallation, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tools.
+func (in *Tools) DeepCopy() *Tools {
+	if in == nil {
+		return nil
+	}
+	out := new(Tools)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *URLConfiguration) DeepCopyInto(out *URLConfiguration) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new URLConfiguration.
+func (in *URLConfiguration) DeepCopy() *URLConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(URLConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UsernamePassword) DeepCopyInto(out *UsernamePassword) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UsernamePassword.
+func (in *UsernamePassword) DeepCopy() *UsernamePassword {
+	if in == nil {
+		return nil
+	}
+	out := new(UsernamePassword)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Vault) DeepCopyInto(out *Vault) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(VaultTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Vault.
+func (in *Vault) DeepCopy() *Vault {
+	if in == nil {
+		return nil
+	}
+	out := new(Vault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuth) DeepCopyInto(out *VaultAuth) {
+	*out = *in
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VaultKubernetesAuth)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuth.
+func (in *VaultAuth) DeepCopy() *VaultAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultKubernetesAuth) DeepCopyInto(out *VaultKubernetesAuth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultKubernetesAuth.
+func (in *VaultKubernetesAuth) DeepCopy() *VaultKubernetesAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultKubernetesAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultTLS) DeepCopyInto(out *VaultTLS) {
+	*out = *in
+	if in.CACertSecretRef != nil {
+		in, out := &in.CACertSecretRef, &out.CACertSecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultTLS.
+func (in *VaultTLS) DeepCopy() *VaultTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *View) DeepCopyInto(out *View) {
+	*out = *in
+	if in.JobNames != nil {
+		in, out := &in.JobNames, &out.JobNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new View.
+func (in *View) DeepCopy() *View {
+	if in == nil {
+		return nil
+	}
+	out := new(View)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ViewStatus) DeepCopyInto(out *ViewStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ViewStatus.
+func (in *ViewStatus) DeepCopy() *ViewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ViewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Webhook) DeepCopyInto(out *Webhook) {
+	*out = *in
+	in.URLSecretKeyRef.DeepCopyInto(&out.URLSecretKeyRef)
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Webhook.
+func (in *Webhook) DeepCopy() *Webhook {
+	if in == nil {
+		return nil
+	}
+	out := new(Webhook)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/bndr/gojenkins"
+	"github.com/pkg/errors"
+)
+
+// ReloadConfigurationAsCode makes the configuration-as-code plugin apply JCasC YAML files again
+func (jenkins *jenkins) ReloadConfigurationAsCode() error {
+	output := ""
+	ar := gojenkins.NewAPIRequest("POST", "/configuration-as-code/reload", nil)
+	if err := jenkins.Requester.SetCrumb(ar); err != nil {
+		return errors.Wrap(err, "couldn't reload configuration as code")
+	}
+	ar.Suffix = ""
+
+	r, err := jenkins.Requester.Do(ar, &output)
+	if err != nil {
+		return errors.Wrap(err, "couldn't reload configuration as code")
+	}
+	// the plugin redirects to the configuration page after reload
+	if r.StatusCode != http.StatusOK && r.StatusCode != http.StatusFound {
+		return errors.Errorf("couldn't reload configuration as code: %d", r.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bndr/gojenkins"
+	"github.com/pkg/errors"
+)
+
+// GetBuildConsoleOutput returns the console output of the build
+func (jenkins *jenkins) GetBuildConsoleOutput(jobName string, number int64) (string, error) {
+	output := ""
+	ar := gojenkins.NewAPIRequest("GET", fmt.Sprintf("/job/%s/%d/consoleText", url.PathEscape(jobName), number), nil)
+	ar.Suffix = ""
+
+	r, err := jenkins.Requester.Do(ar, &output)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't get build console output")
+	}
+	if r.StatusCode != http.StatusOK {
+		return "", errors.Errorf("couldn't get build console output: %d", r.StatusCode)
+	}
+
+	return output, nil
+}
@@ -16,6 +16,7 @@ var errorNotFound = errors.New("404")
 // Jenkins defines Jenkins API
 type Jenkins interface {
 	GenerateToken(userName, tokenName string) (*UserToken, error)
+	RevokeToken(userName, tokenUUID string) error
 	Info() (*gojenkins.ExecutorResponse, error)
 	SafeRestart() error
 	CreateNode(name string, numExecutors int, description string, remoteFS string, label string, options ...interface{}) (*gojenkins.Node, error)
@@ -51,12 +52,21 @@ type Jenkins interface {
 	GetAllViews() ([]*gojenkins.View, error)
 	CreateView(name string, viewType string) (*gojenkins.View, error)
 	Poll() (int, error)
+	ExecuteScript(script string) (string, error)
+	GetBuildConsoleOutput(jobName string, number int64) (string, error)
+	ReloadConfigurationAsCode() error
+	GetVersion() string
 }
 
 type jenkins struct {
 	gojenkins.Jenkins
 }
 
+// GetVersion returns the version of Jenkins read from the response headers when the client has been created
+func (jenkins *jenkins) GetVersion() string {
+	return jenkins.Version
+}
+
 // CreateOrUpdateJob creates or updates a job from config
 func (jenkins *jenkins) CreateOrUpdateJob(config, jobName string) (job *gojenkins.Job, created bool, err error) {
 	// create or update
@@ -48,6 +48,20 @@ func (mr *MockJenkinsMockRecorder) GenerateToken(userName, tokenName interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateToken", reflect.TypeOf((*MockJenkins)(nil).GenerateToken), userName, tokenName)
 }
 
+// RevokeToken mocks base method
+func (m *MockJenkins) RevokeToken(userName, tokenUUID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeToken", userName, tokenUUID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken
+func (mr *MockJenkinsMockRecorder) RevokeToken(userName, tokenUUID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockJenkins)(nil).RevokeToken), userName, tokenUUID)
+}
+
 // Info mocks base method
 func (m *MockJenkins) Info() (*gojenkins.ExecutorResponse, error) {
 	m.ctrl.T.Helper()
@@ -603,3 +617,61 @@ func (mr *MockJenkinsMockRecorder) Poll() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poll", reflect.TypeOf((*MockJenkins)(nil).Poll))
 }
+
+// ExecuteScript mocks base method
+func (m *MockJenkins) ExecuteScript(script string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteScript", script)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteScript indicates an expected call of ExecuteScript
+func (mr *MockJenkinsMockRecorder) ExecuteScript(script interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteScript", reflect.TypeOf((*MockJenkins)(nil).ExecuteScript), script)
+}
+
+// GetBuildConsoleOutput mocks base method
+func (m *MockJenkins) GetBuildConsoleOutput(jobName string, number int64) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBuildConsoleOutput", jobName, number)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBuildConsoleOutput indicates an expected call of GetBuildConsoleOutput
+func (mr *MockJenkinsMockRecorder) GetBuildConsoleOutput(jobName, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuildConsoleOutput", reflect.TypeOf((*MockJenkins)(nil).GetBuildConsoleOutput), jobName, number)
+}
+
+// GetVersion mocks base method
+func (m *MockJenkins) GetVersion() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetVersion indicates an expected call of GetVersion
+func (mr *MockJenkinsMockRecorder) GetVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockJenkins)(nil).GetVersion))
+}
+
+// ReloadConfigurationAsCode mocks base method
+func (m *MockJenkins) ReloadConfigurationAsCode() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReloadConfigurationAsCode")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReloadConfigurationAsCode indicates an expected call of ReloadConfigurationAsCode
+func (mr *MockJenkinsMockRecorder) ReloadConfigurationAsCode() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadConfigurationAsCode", reflect.TypeOf((*MockJenkins)(nil).ReloadConfigurationAsCode))
+}
@@ -0,0 +1,34 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+
+	"github.com/bndr/gojenkins"
+	"github.com/pkg/errors"
+)
+
+// ExecuteScript runs the groovy script in Jenkins script console and returns its output
+func (jenkins *jenkins) ExecuteScript(script string) (string, error) {
+	output := ""
+	data := url.Values{}
+	data.Set("script", script)
+
+	ar := gojenkins.NewAPIRequest("POST", "/scriptText", bytes.NewBufferString(data.Encode()))
+	if err := jenkins.Requester.SetCrumb(ar); err != nil {
+		return "", errors.Wrap(err, "couldn't execute groovy script")
+	}
+	ar.SetHeader("Content-Type", "application/x-www-form-urlencoded")
+	ar.Suffix = ""
+
+	r, err := jenkins.Requester.Do(ar, &output)
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't execute groovy script")
+	}
+	if r.StatusCode != http.StatusOK {
+		return "", errors.Errorf("couldn't execute groovy script: %d", r.StatusCode)
+	}
+
+	return output, nil
+}
@@ -29,6 +29,11 @@ func (token *UserToken) GetToken() string {
 	return token.raw.Data.Value
 }
 
+// GetUUID returns the UUID of user token used to revoke it
+func (token *UserToken) GetUUID() string {
+	return token.raw.Data.UUID
+}
+
 func (jenkins *jenkins) GenerateToken(userName, tokenName string) (*UserToken, error) {
 	token := &UserToken{raw: new(userTokenResponse),
 		base: fmt.Sprintf("/user/%s/descriptorByName/jenkins.security.ApiTokenProperty/generateNewToken", userName)}
@@ -50,3 +55,17 @@ func (jenkins *jenkins) GenerateToken(userName, tokenName string) (*UserToken, e
 
 	return nil, errors.Errorf("couldn't generate API token: %d", r.StatusCode)
 }
+
+func (jenkins *jenkins) RevokeToken(userName, tokenUUID string) error {
+	endpoint := fmt.Sprintf("/user/%s/descriptorByName/jenkins.security.ApiTokenProperty/revoke", userName)
+	data := map[string]string{"tokenUuid": tokenUUID}
+	r, err := jenkins.Requester.Post(endpoint, nil, nil, data)
+	if err != nil {
+		return errors.Wrap(err, "couldn't revoke API token")
+	}
+
+	if r.StatusCode != http.StatusOK {
+		return errors.Errorf("couldn't revoke API token: %d", r.StatusCode)
+	}
+	return nil
+}
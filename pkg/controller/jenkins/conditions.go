@@ -0,0 +1,166 @@
+package jenkins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// conditionReasonValidationFailed informs Jenkins CR is invalid, the message contains validation errors
+	conditionReasonValidationFailed = "ValidationFailed"
+	// conditionReasonReconcileFailed informs the reconciliation failed, it's retried
+	conditionReasonReconcileFailed = "ReconcileFailed"
+	// conditionReasonInProgress informs the configuration phase hasn't been completed yet
+	conditionReasonInProgress = "InProgress"
+	// conditionReasonCompleted informs the configuration phase has been completed
+	conditionReasonCompleted = "Completed"
+	// conditionReasonDisabled informs the user configuration phase is disabled
+	conditionReasonDisabled = "Disabled"
+	// conditionReasonVaultUnavailable informs secret references can't be resolved because Vault is unavailable
+	conditionReasonVaultUnavailable = "VaultUnavailable"
+	// conditionReasonReachable informs operator has connected to Jenkins API
+	conditionReasonReachable = "Reachable"
+	// conditionReasonPodNotReady informs Jenkins master pod is created, recreated or hasn't started yet
+	conditionReasonPodNotReady = "JenkinsMasterPodNotReady"
+	// conditionReasonJenkinsReady informs Jenkins is reachable and configured
+	conditionReasonJenkinsReady = "JenkinsReady"
+)
+
+// readyConditionSources contains conditions which make the Ready condition, the first one which isn't true
+// gives the reason why Jenkins isn't ready
+var readyConditionSources = []v1alpha1.JenkinsConditionType{
+	v1alpha1.JenkinsConditionJenkinsAPIReachable,
+	v1alpha1.JenkinsConditionBaseConfigurationReady,
+	v1alpha1.JenkinsConditionUserConfigurationReady,
+}
+
+func newCondition(conditionType v1alpha1.JenkinsConditionType, status corev1.ConditionStatus, reason, message string) v1alpha1.JenkinsCondition {
+	return v1alpha1.JenkinsCondition{
+		Type:    conditionType,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
+// getCondition returns the condition of the given type from Jenkins.Status.Conditions or nil when it isn't set
+func getCondition(jenkins *v1alpha1.Jenkins, conditionType v1alpha1.JenkinsConditionType) *v1alpha1.JenkinsCondition {
+	for i := range jenkins.Status.Conditions {
+		if jenkins.Status.Conditions[i].Type == conditionType {
+			return &jenkins.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition sets the condition in Jenkins.Status.Conditions, the last transition time changes only when the status
+// changes, it returns true when the condition has been changed
+func setCondition(jenkins *v1alpha1.Jenkins, condition v1alpha1.JenkinsCondition, now metav1.Time) bool {
+	current := getCondition(jenkins, condition.Type)
+	if current == nil {
+		condition.LastTransitionTime = now
+		jenkins.Status.Conditions = append(jenkins.Status.Conditions, condition)
+		return true
+	}
+	if current.Status == condition.Status && current.Reason == condition.Reason && current.Message == condition.Message {
+		return false
+	}
+	if current.Status != condition.Status {
+		current.LastTransitionTime = now
+	}
+	current.Status = condition.Status
+	current.Reason = condition.Reason
+	current.Message = condition.Message
+	return true
+}
+
+// newReadyCondition builds the Ready condition from conditions listed in readyConditionSources
+func newReadyCondition(jenkins *v1alpha1.Jenkins) v1alpha1.JenkinsCondition {
+	for _, conditionType := range readyConditionSources {
+		condition := getCondition(jenkins, conditionType)
+		if condition == nil {
+			return newCondition(v1alpha1.JenkinsConditionReady, corev1.ConditionFalse, conditionReasonInProgress,
+				fmt.Sprintf("%s isn't known yet", conditionType))
+		}
+		if condition.Status != corev1.ConditionTrue {
+			return newCondition(v1alpha1.JenkinsConditionReady, corev1.ConditionFalse, condition.Reason,
+				fmt.Sprintf("%s: %s", conditionType, condition.Message))
+		}
+	}
+	return newCondition(v1alpha1.JenkinsConditionReady, corev1.ConditionTrue, conditionReasonJenkinsReady, "Jenkins is ready")
+}
+
+// getPhase summarizes conditions, the validation failure and the failed reconciliation of any configuration phase take
+// precedence over the progress of provisioning
+func getPhase(jenkins *v1alpha1.Jenkins) v1alpha1.JenkinsPhase {
+	failed := false
+	for _, conditionType := range readyConditionSources {
+		condition := getCondition(jenkins, conditionType)
+		if condition == nil || condition.Status == corev1.ConditionTrue {
+			continue
+		}
+		if condition.Reason == conditionReasonValidationFailed {
+			return v1alpha1.JenkinsPhaseValidationFailed
+		}
+		if condition.Reason == conditionReasonReconcileFailed || condition.Reason == conditionReasonVaultUnavailable {
+			failed = true
+		}
+	}
+
+	switch {
+	case failed:
+		return v1alpha1.JenkinsPhaseError
+	case isConditionTrue(jenkins, v1alpha1.JenkinsConditionReady):
+		return v1alpha1.JenkinsPhaseReady
+	case isConditionTrue(jenkins, v1alpha1.JenkinsConditionBaseConfigurationReady):
+		return v1alpha1.JenkinsPhaseBaseConfigured
+	default:
+		return v1alpha1.JenkinsPhaseProvisioning
+	}
+}
+
+func isConditionTrue(jenkins *v1alpha1.Jenkins, conditionType v1alpha1.JenkinsConditionType) bool {
+	condition := getCondition(jenkins, conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// updateConditions sets conditions and the Ready condition derived from them in Jenkins.Status.Conditions
+// and Jenkins.Status.Phase, the status is updated only when conditions or the phase have changed
+func (r *ReconcileJenkins) updateConditions(jenkins *v1alpha1.Jenkins, conditions ...v1alpha1.JenkinsCondition) error {
+	now := metav1.Now()
+	changed := false
+	for _, condition := range conditions {
+		// messages can contain errors with values of secrets
+		condition.Message = log.MaskSecrets(condition.Message)
+		changed = setCondition(jenkins, condition, now) || changed
+	}
+	changed = setCondition(jenkins, newReadyCondition(jenkins), now) || changed
+	if phase := getPhase(jenkins); phase != jenkins.Status.Phase {
+		jenkins.Status.Phase = phase
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return r.client.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in Reconcile
+}
+
+// reportFailure sets conditions describing the failure of the reconciliation and returns err, conditions aren't updated
+// when Jenkins CR is outdated, the failure is returned even when conditions can't be updated
+func (r *ReconcileJenkins) reportFailure(jenkins *v1alpha1.Jenkins, logger logr.Logger, err error, conditions ...v1alpha1.JenkinsCondition) error {
+	if apierrors.IsConflict(err) {
+		return err
+	}
+	if updateErr := r.updateConditions(jenkins, conditions...); updateErr != nil {
+		logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't update conditions of Jenkins CR: %s", updateErr))
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetCondition(t *testing.T) {
+	jenkins := &v1alpha1.Jenkins{}
+	created := metav1.NewTime(time.Now().Add(-time.Hour))
+	now := metav1.Now()
+
+	assert.True(t, setCondition(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionFalse, conditionReasonInProgress, "Base configuration is in progress"), created))
+	assert.False(t, setCondition(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionFalse, conditionReasonInProgress, "Base configuration is in progress"), now))
+
+	// the last transition time doesn't change with the reason
+	assert.True(t, setCondition(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionFalse, conditionReasonReconcileFailed, "connection refused"), now))
+	condition := getCondition(jenkins, v1alpha1.JenkinsConditionBaseConfigurationReady)
+	assert.Equal(t, conditionReasonReconcileFailed, condition.Reason)
+	assert.Equal(t, created, condition.LastTransitionTime)
+
+	assert.True(t, setCondition(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionTrue, conditionReasonCompleted, "Base configuration has been applied"), now))
+	condition = getCondition(jenkins, v1alpha1.JenkinsConditionBaseConfigurationReady)
+	assert.Equal(t, corev1.ConditionTrue, condition.Status)
+	assert.Equal(t, now, condition.LastTransitionTime)
+	assert.Len(t, jenkins.Status.Conditions, 1)
+}
+
+func TestUpdateConditions(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	reconciler := &ReconcileJenkins{client: fakeClient}
+	getReady := func() v1alpha1.JenkinsCondition {
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		condition := getCondition(stored, v1alpha1.JenkinsConditionReady)
+		if !assert.NotNil(t, condition) {
+			return v1alpha1.JenkinsCondition{}
+		}
+		return *condition
+	}
+
+	// the validation failure makes Jenkins not ready
+	assert.NoError(t, reconciler.updateConditions(jenkins,
+		newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionTrue, conditionReasonReachable, "Jenkins API is reachable"),
+		newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionFalse, conditionReasonValidationFailed,
+			"Base CR validation failed: Invalid timezone 'Europe/Atlantis'")))
+	ready := getReady()
+	assert.Equal(t, corev1.ConditionFalse, ready.Status)
+	assert.Equal(t, conditionReasonValidationFailed, ready.Reason)
+	assert.Equal(t, "BaseConfigurationReady: Base CR validation failed: Invalid timezone 'Europe/Atlantis'", ready.Message)
+	assert.Equal(t, v1alpha1.JenkinsPhaseValidationFailed, jenkins.Status.Phase)
+
+	// the user configuration isn't known yet
+	assert.NoError(t, reconciler.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionTrue, conditionReasonCompleted, "Base configuration has been applied")))
+	ready = getReady()
+	assert.Equal(t, corev1.ConditionFalse, ready.Status)
+	assert.Equal(t, conditionReasonInProgress, ready.Reason)
+
+	// all conditions are true
+	assert.NoError(t, reconciler.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+		corev1.ConditionTrue, conditionReasonCompleted, "User configuration has been applied")))
+	ready = getReady()
+	assert.Equal(t, corev1.ConditionTrue, ready.Status)
+	assert.Equal(t, conditionReasonJenkinsReady, ready.Reason)
+	assert.Len(t, jenkins.Status.Conditions, 4)
+	assert.Equal(t, v1alpha1.JenkinsPhaseReady, jenkins.Status.Phase)
+}
+
+func TestGetPhase(t *testing.T) {
+	apiReachable := newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionTrue, conditionReasonReachable, "")
+	podNotReady := newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionFalse, conditionReasonPodNotReady, "")
+	baseInProgress := newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionFalse, conditionReasonInProgress, "")
+	baseCompleted := newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionTrue, conditionReasonCompleted, "")
+	baseInvalid := newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionFalse, conditionReasonValidationFailed, "")
+	baseFailed := newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionFalse, conditionReasonReconcileFailed, "")
+	userInProgress := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionFalse, conditionReasonInProgress, "")
+	userCompleted := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionTrue, conditionReasonCompleted, "")
+	userDisabled := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionTrue, conditionReasonDisabled, "")
+	userInvalid := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionFalse, conditionReasonValidationFailed, "")
+	userFailed := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionFalse, conditionReasonReconcileFailed, "")
+	vaultUnavailable := newCondition(v1alpha1.JenkinsConditionUserConfigurationReady, corev1.ConditionFalse, conditionReasonVaultUnavailable, "")
+
+	tests := []struct {
+		name       string
+		conditions []v1alpha1.JenkinsCondition
+		phase      v1alpha1.JenkinsPhase
+	}{
+		{name: "new Jenkins CR", phase: v1alpha1.JenkinsPhaseProvisioning},
+		{name: "pod isn't ready", conditions: []v1alpha1.JenkinsCondition{podNotReady, baseInProgress}, phase: v1alpha1.JenkinsPhaseProvisioning},
+		{name: "base configuration is in progress", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseInProgress}, phase: v1alpha1.JenkinsPhaseProvisioning},
+		{name: "base configuration applied", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, userInProgress}, phase: v1alpha1.JenkinsPhaseBaseConfigured},
+		{name: "ready", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, userCompleted}, phase: v1alpha1.JenkinsPhaseReady},
+		{name: "ready without user configuration", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, userDisabled}, phase: v1alpha1.JenkinsPhaseReady},
+		{name: "invalid base configuration of new Jenkins CR", conditions: []v1alpha1.JenkinsCondition{baseInvalid}, phase: v1alpha1.JenkinsPhaseValidationFailed},
+		{name: "invalid base configuration of ready Jenkins", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseInvalid, userCompleted}, phase: v1alpha1.JenkinsPhaseValidationFailed},
+		{name: "invalid user configuration", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, userInvalid}, phase: v1alpha1.JenkinsPhaseValidationFailed},
+		{name: "validation failure takes precedence", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseFailed, userInvalid}, phase: v1alpha1.JenkinsPhaseValidationFailed},
+		{name: "base configuration failed", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseFailed}, phase: v1alpha1.JenkinsPhaseError},
+		{name: "user configuration failed", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, userFailed}, phase: v1alpha1.JenkinsPhaseError},
+		{name: "Vault is unavailable", conditions: []v1alpha1.JenkinsCondition{apiReachable, baseCompleted, vaultUnavailable}, phase: v1alpha1.JenkinsPhaseError},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jenkins := &v1alpha1.Jenkins{}
+			now := metav1.Now()
+			for _, condition := range test.conditions {
+				setCondition(jenkins, condition, now)
+			}
+			setCondition(jenkins, newReadyCondition(jenkins), now)
+			assert.Equal(t, test.phase, getPhase(jenkins))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// reasonAdminCredentialsRotated is the event which informs the Jenkins user of operator has been updated
+	// with credentials from Jenkins.Spec.Master.AdminCredentialsSecretRef
+	reasonAdminCredentialsRotated event.Reason = "AdminCredentialsRotated"
+
+	// minAdminPasswordLength is the minimal length of the password from Jenkins.Spec.Master.AdminCredentialsSecretRef
+	minAdminPasswordLength = 8
+)
+
+// updateOperatorUserScriptFmt creates the Jenkins user or sets the password of the existing one and deletes
+// the previous user when it has been renamed, user names and the password are base64 encoded to avoid escaping
+const updateOperatorUserScriptFmt = `
+import hudson.model.User
+import hudson.security.HudsonPrivateSecurityRealm
+import jenkins.model.Jenkins
+
+def realm = Jenkins.getInstance().getSecurityRealm()
+if (!(realm instanceof HudsonPrivateSecurityRealm)) {
+    throw new IllegalStateException('Jenkins own user database is required to update credentials of operator')
+}
+def userName = new String('%s'.decodeBase64(), 'UTF-8')
+realm.createAccount(userName, new String('%s'.decodeBase64(), 'UTF-8'))
+def previousUserName = new String('%s'.decodeBase64(), 'UTF-8')
+if (previousUserName && previousUserName != userName) {
+    def previousUser = User.getById(previousUserName, false)
+    if (previousUser != null) {
+        previousUser.delete()
+    }
+}
+Jenkins.getInstance().save()
+`
+
+// getAdminCredentialsSecret returns the Secret referenced by Jenkins.Spec.Master.AdminCredentialsSecretRef
+func (r *ReconcileJenkinsBaseConfiguration) getAdminCredentialsSecret() (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	name := r.jenkins.Spec.Master.AdminCredentialsSecretRef.Name
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: name}, secret)
+	return secret, err
+}
+
+// validateAdminCredentials checks the Secret referenced by Jenkins.Spec.Master.AdminCredentialsSecretRef contains
+// the user name and the password at least minAdminPasswordLength characters long
+func (r *ReconcileJenkinsBaseConfiguration) validateAdminCredentials(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.Master.AdminCredentialsSecretRef == nil {
+		return true, nil
+	}
+
+	name := jenkins.Spec.Master.AdminCredentialsSecretRef.Name
+	secret, err := r.getAdminCredentialsSecret()
+	if err != nil && apierrors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with admin credentials not found", name))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+	for _, key := range []string{resources.OperatorCredentialsSecretUserNameKey, resources.OperatorCredentialsSecretPasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with admin credentials doesn't contain '%s' key", name, key))
+			return false, nil
+		}
+	}
+	if len(secret.Data[resources.OperatorCredentialsSecretPasswordKey]) < minAdminPasswordLength {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Admin password in secret '%s' has to be at least %d characters long", name, minAdminPasswordLength))
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// ensureAdminCredentials updates the Jenkins user of operator when credentials in the Secret referenced
+// by Jenkins.Spec.Master.AdminCredentialsSecretRef differ from the operator credentials secret, the new credentials
+// are saved without the API token so the Jenkins client is reconnected with the new token in the next reconciliation loop
+func (r *ReconcileJenkinsBaseConfiguration) ensureAdminCredentials(jenkinsClient jenkinsclient.Jenkins) (bool, error) {
+	if r.jenkins.Spec.Master.AdminCredentialsSecretRef == nil {
+		return false, nil
+	}
+
+	adminCredentials, err := r.getAdminCredentialsSecret()
+	if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+	credentialsSecret := &corev1.Secret{}
+	err = r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: resources.GetOperatorCredentialsSecretName(r.jenkins), Namespace: r.jenkins.Namespace}, credentialsSecret)
+	if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
+	userName := adminCredentials.Data[resources.OperatorCredentialsSecretUserNameKey]
+	password := adminCredentials.Data[resources.OperatorCredentialsSecretPasswordKey]
+	if bytes.Equal(userName, credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey]) &&
+		bytes.Equal(password, credentialsSecret.Data[resources.OperatorCredentialsSecretPasswordKey]) {
+		return false, nil
+	}
+
+	// the admin user isn't present in Jenkins, the credentials are used when it's restored
+	if len(credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUserNameKey]) > 0 {
+		credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey] = userName
+		credentialsSecret.Data[resources.OperatorCredentialsSecretPasswordKey] = password
+		if err := r.updateResource(credentialsSecret); err != nil {
+			return false, stackerr.WithStack(err)
+		}
+		r.logger.Info(fmt.Sprintf("Credentials from secret '%s' have been saved, the admin user is disabled",
+			r.jenkins.Spec.Master.AdminCredentialsSecretRef.Name))
+		return false, nil
+	}
+
+	// the previous admin user is deleted when the user name changes, otherwise it would keep its access
+	previousUserName := credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey]
+	_, err = jenkinsClient.ExecuteScript(fmt.Sprintf(updateOperatorUserScriptFmt, base64.StdEncoding.EncodeToString(userName),
+		base64.StdEncoding.EncodeToString(password), base64.StdEncoding.EncodeToString(previousUserName)))
+	if err != nil {
+		return false, err
+	}
+	if len(previousUserName) > 0 && !bytes.Equal(userName, previousUserName) {
+		r.logger.Info(fmt.Sprintf("Jenkins user '%s' has been deleted, it's been renamed to '%s'", previousUserName, userName))
+	}
+
+	credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey] = userName
+	credentialsSecret.Data[resources.OperatorCredentialsSecretPasswordKey] = password
+	delete(credentialsSecret.Data, resources.OperatorCredentialsSecretTokenKey)
+	delete(credentialsSecret.Data, resources.OperatorCredentialsSecretTokenCreationKey)
+	delete(credentialsSecret.Data, resources.OperatorCredentialsSecretTokenUUIDKey)
+	if err := r.updateResource(credentialsSecret); err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
+	r.logger.Info(fmt.Sprintf("Jenkins user '%s' has been updated with credentials from secret '%s'",
+		userName, r.jenkins.Spec.Master.AdminCredentialsSecretRef.Name))
+	r.events.Emitf(r.jenkins, event.TypeNormal, reasonAdminCredentialsRotated,
+		"Jenkins user '%s' has been updated with credentials from secret '%s'", userName, r.jenkins.Spec.Master.AdminCredentialsSecretRef.Name)
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const (
+	// reasonUsageStatsReapplied is the event which informs submitting usage stats has been changed in Jenkins
+	// and operator applied Jenkins.Spec.Master.DisableUsageStats again
+	reasonUsageStatsReapplied event.Reason = "UsageStatsReapplied"
+	// reasonAdministrativeMonitorReapplied is the event which informs the administrative monitor disabled by operator
+	// has been enabled in Jenkins and operator disabled it again
+	reasonAdministrativeMonitorReapplied event.Reason = "AdministrativeMonitorReapplied"
+	// reasonAdministrativeMonitorUnknown is the event which informs the administrative monitor listed
+	// in Jenkins.Spec.Master.DisabledAdministrativeMonitors isn't known by Jenkins
+	reasonAdministrativeMonitorUnknown event.Reason = "AdministrativeMonitorUnknown"
+
+	// usageStatsChangedMarker is printed by administrativeSettingsScriptFmt when submitting usage stats has been changed
+	usageStatsChangedMarker = "usage stats changed"
+	// monitorDisabledPrefix is printed by administrativeSettingsScriptFmt with the ID of the disabled monitor
+	monitorDisabledPrefix = "monitor disabled\t"
+	// monitorEnabledPrefix is printed by administrativeSettingsScriptFmt with the ID of the enabled monitor
+	monitorEnabledPrefix = "monitor enabled\t"
+	// monitorUnknownPrefix is printed by administrativeSettingsScriptFmt with the ID of the monitor unknown to Jenkins
+	monitorUnknownPrefix = "monitor unknown\t"
+	// administrativeSettingsVerifiedMarker is printed by administrativeSettingsScriptFmt when all settings have been verified
+	administrativeSettingsVerifiedMarker = "administrative settings verified"
+)
+
+// administrativeSettingsScriptFmt verifies submitting usage stats and administrative monitors, the settings are passed
+// as the base64 encoded JSON built by administrativeSettings, only settings which differ are changed
+const administrativeSettingsScriptFmt = `
+import hudson.model.AdministrativeMonitor
+import jenkins.model.Jenkins
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def jenkins = Jenkins.getInstance()
+
+try {
+    if (jenkins.isUsageStatisticsCollected() == settings.usageStatsDisabled) {
+        jenkins.setNoUsageStatistics(settings.usageStatsDisabled)
+        jenkins.save()
+        println '` + usageStatsChangedMarker + `'
+    }
+
+    def known = [] as Set
+    AdministrativeMonitor.all().each { monitor ->
+        known << monitor.id
+        if (settings.disable.contains(monitor.id) && monitor.isEnabled()) {
+            monitor.disable(true)
+            println '` + monitorDisabledPrefix + `' + monitor.id
+        } else if (settings.enable.contains(monitor.id) && !monitor.isEnabled()) {
+            monitor.disable(false)
+            println '` + monitorEnabledPrefix + `' + monitor.id
+        }
+    }
+    settings.disable.findAll { id -> !known.contains(id) }.each { id ->
+        println '` + monitorUnknownPrefix + `' + id
+    }
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + administrativeSettingsVerifiedMarker + `'
+`
+
+// administrativeSettings defines settings passed to administrativeSettingsScriptFmt
+type administrativeSettings struct {
+	UsageStatsDisabled bool     `json:"usageStatsDisabled"`
+	Disable            []string `json:"disable"`
+	Enable             []string `json:"enable"`
+}
+
+// verifiedAdministrativeSettings keeps the state of administrative settings of Jenkins CRs keyed by the namespace
+// and the name of Jenkins CR, usageStatsDisabled contains the last value verified by operator, unknownMonitors contains
+// IDs of administrative monitors which have been reported as unknown, they are reported again when they show up again
+var verifiedAdministrativeSettings = struct {
+	sync.Mutex
+	usageStatsDisabled map[string]bool
+	unknownMonitors    map[string]map[string]bool
+}{usageStatsDisabled: map[string]bool{}, unknownMonitors: map[string]map[string]bool{}}
+
+// ensureAdministrativeSettings verifies submitting usage stats and administrative monitors once the base configuration
+// has been applied, settings changed in Jenkins, e.g. in the UI, are applied again, monitors removed
+// from Jenkins.Spec.Master.DisabledAdministrativeMonitors are enabled again and unknown monitors are reported
+func (r *ReconcileJenkinsBaseConfiguration) ensureAdministrativeSettings(jenkinsClient jenkinsclient.Jenkins) error {
+	if r.jenkins.Status.BaseConfigurationCompletedTime == nil {
+		return nil
+	}
+
+	disabled := map[string]bool{}
+	settings := administrativeSettings{
+		UsageStatsDisabled: resources.IsUsageStatsDisabled(r.jenkins),
+		Disable:            []string{},
+		Enable:             []string{},
+	}
+	for _, id := range r.jenkins.Spec.Master.DisabledAdministrativeMonitors {
+		disabled[id] = true
+		settings.Disable = append(settings.Disable, id)
+	}
+	applied := map[string]bool{}
+	for _, id := range r.jenkins.Status.DisabledAdministrativeMonitors {
+		applied[id] = true
+		if !disabled[id] {
+			settings.Enable = append(settings.Enable, id)
+		}
+	}
+
+	script := groovy.SettingsScript{Format: administrativeSettingsScriptFmt, DoneMarker: administrativeSettingsVerifiedMarker}
+	lines, err := script.Execute(jenkinsClient, settings)
+	if err != nil {
+		return stackerr.Wrap(err, "couldn't verify administrative settings")
+	}
+
+	verifiedAdministrativeSettings.Lock()
+	key := fmt.Sprintf("%s/%s", r.jenkins.Namespace, r.jenkins.Name)
+	// only the value changed since the last verification of the same expected value is reported, other changes
+	// are made by operator when Jenkins.Spec.Master.DisableUsageStats changes
+	previous, verified := verifiedAdministrativeSettings.usageStatsDisabled[key]
+	usageStatsReapplied := verified && previous == settings.UsageStatsDisabled
+	verifiedAdministrativeSettings.usageStatsDisabled[key] = settings.UsageStatsDisabled
+	reported := verifiedAdministrativeSettings.unknownMonitors[key]
+	unknown := map[string]bool{}
+	for _, line := range lines {
+		switch {
+		case line == usageStatsChangedMarker:
+			state := "enabled"
+			if settings.UsageStatsDisabled {
+				state = "disabled"
+			}
+			if !usageStatsReapplied {
+				r.logger.Info(fmt.Sprintf("Submitting Jenkins usage stats has been %s", state))
+				continue
+			}
+			message := fmt.Sprintf("Submitting Jenkins usage stats has been changed in Jenkins, it has been %s again", state)
+			r.logger.V(log.VWarn).Info(message)
+			r.events.Emit(r.jenkins, event.TypeWarning, reasonUsageStatsReapplied, message)
+		case strings.HasPrefix(line, monitorDisabledPrefix):
+			id := strings.TrimPrefix(line, monitorDisabledPrefix)
+			if !applied[id] {
+				r.logger.Info(fmt.Sprintf("Administrative monitor '%s' has been disabled", id))
+				continue
+			}
+			message := fmt.Sprintf("Administrative monitor '%s' has been enabled in Jenkins, it has been disabled again", id)
+			r.logger.V(log.VWarn).Info(message)
+			r.events.Emit(r.jenkins, event.TypeWarning, reasonAdministrativeMonitorReapplied, message)
+		case strings.HasPrefix(line, monitorEnabledPrefix):
+			r.logger.Info(fmt.Sprintf("Administrative monitor '%s' has been enabled", strings.TrimPrefix(line, monitorEnabledPrefix)))
+		case strings.HasPrefix(line, monitorUnknownPrefix):
+			id := strings.TrimPrefix(line, monitorUnknownPrefix)
+			unknown[id] = true
+			if reported[id] {
+				continue
+			}
+			message := fmt.Sprintf("Administrative monitor '%s' from spec.master.disabledAdministrativeMonitors isn't known by Jenkins", id)
+			r.logger.V(log.VWarn).Info(message)
+			r.events.Emit(r.jenkins, event.TypeWarning, reasonAdministrativeMonitorUnknown, message)
+		}
+	}
+	// every unknown monitor is reported once until it's known by Jenkins, e.g. after its plugin is installed
+	verifiedAdministrativeSettings.unknownMonitors[key] = unknown
+	verifiedAdministrativeSettings.Unlock()
+
+	expected := r.jenkins.Spec.Master.DisabledAdministrativeMonitors
+	if len(expected) == 0 && len(r.jenkins.Status.DisabledAdministrativeMonitors) == 0 ||
+		reflect.DeepEqual(expected, r.jenkins.Status.DisabledAdministrativeMonitors) {
+		return nil
+	}
+	r.jenkins.Status.DisabledAdministrativeMonitors = append([]string{}, expected...)
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
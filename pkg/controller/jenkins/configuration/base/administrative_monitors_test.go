@@ -0,0 +1,75 @@
+package base
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureAdministrativeSettings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	reverseProxyMonitor := "hudson.diagnosis.ReverseProxySetupMonitor"
+	completedTime := metav1.Now()
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "administrative-settings", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{DisabledAdministrativeMonitors: []string{reverseProxyMonitor, "unknown"}},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fakeClient, nil, logf.ZapLogger(false), jenkins, false, false, events)
+
+	// settings aren't verified until the base configuration is applied
+	assert.NoError(t, baseReconcileLoop.ensureAdministrativeSettings(jenkinsClient))
+
+	// monitors are disabled by operator, the unknown monitor is reported
+	jenkins.Status.BaseConfigurationCompletedTime = &completedTime
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Equal(t, `{"usageStatsDisabled":true,"disable":["hudson.diagnosis.ReverseProxySetupMonitor","unknown"],"enable":[]}`,
+			groovytest.DecodeSettings(t, script))
+		return monitorDisabledPrefix + reverseProxyMonitor + "\n" + monitorUnknownPrefix + "unknown\n" + administrativeSettingsVerifiedMarker, nil
+	})
+	assert.NoError(t, baseReconcileLoop.ensureAdministrativeSettings(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonAdministrativeMonitorUnknown}, events.reasons)
+
+	stored := &v1alpha1.Jenkins{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+	assert.Equal(t, []string{reverseProxyMonitor, "unknown"}, stored.Status.DisabledAdministrativeMonitors)
+
+	// settings changed in Jenkins are applied again, the unknown monitor is reported once
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(usageStatsChangedMarker+"\n"+monitorDisabledPrefix+reverseProxyMonitor+"\n"+
+		monitorUnknownPrefix+"unknown\n"+administrativeSettingsVerifiedMarker, nil)
+	assert.NoError(t, baseReconcileLoop.ensureAdministrativeSettings(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonAdministrativeMonitorUnknown, reasonUsageStatsReapplied, reasonAdministrativeMonitorReapplied},
+		events.reasons)
+
+	// the monitor removed from Jenkins CR is enabled, changed usage stats in Jenkins CR aren't reported as the change made in Jenkins
+	disableUsageStats := false
+	jenkins.Spec.Master.DisableUsageStats = &disableUsageStats
+	jenkins.Spec.Master.DisabledAdministrativeMonitors = nil
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Equal(t, `{"usageStatsDisabled":false,"disable":[],"enable":["hudson.diagnosis.ReverseProxySetupMonitor","unknown"]}`,
+			groovytest.DecodeSettings(t, script))
+		return usageStatsChangedMarker + "\n" + monitorEnabledPrefix + reverseProxyMonitor + "\n" + administrativeSettingsVerifiedMarker, nil
+	})
+	assert.NoError(t, baseReconcileLoop.ensureAdministrativeSettings(jenkinsClient))
+	assert.Len(t, events.reasons, 3)
+	assert.Empty(t, jenkins.Status.DisabledAdministrativeMonitors)
+}
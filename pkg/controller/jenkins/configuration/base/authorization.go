@@ -0,0 +1,103 @@
+package base
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+// validateAuthorization checks the matrix or the role-based authorization strategy of Jenkins.Spec.Security.Authorization,
+// unknown permissions are reported by ValidationErrors
+func (r *ReconcileJenkinsBaseConfiguration) validateAuthorization(jenkins *v1alpha1.Jenkins) bool {
+	if jenkins.Spec.Security == nil || jenkins.Spec.Security.Authorization == nil {
+		return true
+	}
+	authorization := jenkins.Spec.Security.Authorization
+
+	if len(authorization.Matrix) > 0 && authorization.RoleStrategy != nil {
+		r.logger.V(log.VWarn).Info("spec.security.authorization.matrix and spec.security.authorization.roleStrategy can't be set together")
+		return false
+	}
+	if len(authorization.Matrix) == 0 && authorization.RoleStrategy == nil {
+		r.logger.V(log.VWarn).Info("spec.security.authorization requires matrix or roleStrategy")
+		return false
+	}
+
+	var permissions []string
+	for _, entry := range authorization.Matrix {
+		if len(entry.Name) == 0 {
+			r.logger.V(log.VWarn).Info("Name of spec.security.authorization.matrix entry is empty")
+			return false
+		}
+		permissions = append(permissions, entry.Permissions...)
+	}
+	if authorization.RoleStrategy != nil {
+		if !r.validateRoles("spec.security.authorization.roleStrategy.globalRoles", authorization.RoleStrategy.GlobalRoles, false) ||
+			!r.validateRoles("spec.security.authorization.roleStrategy.itemRoles", authorization.RoleStrategy.ItemRoles, true) {
+			return false
+		}
+		for _, role := range authorization.RoleStrategy.GlobalRoles {
+			permissions = append(permissions, role.Permissions...)
+		}
+		for _, role := range authorization.RoleStrategy.ItemRoles {
+			permissions = append(permissions, role.Permissions...)
+		}
+	}
+
+	unknownPermissions := map[string]struct{}{}
+	for _, permission := range permissions {
+		if _, ok := resources.KnownPermissions[permission]; !ok {
+			unknownPermissions[permission] = struct{}{}
+		}
+	}
+	if len(unknownPermissions) == 0 {
+		return true
+	}
+	var names []string
+	for permission := range unknownPermissions {
+		names = append(names, permission)
+	}
+	sort.Strings(names)
+	message := fmt.Sprintf("Unknown permissions in spec.security.authorization: %s", strings.Join(names, ", "))
+	r.logger.V(log.VWarn).Info(message)
+	r.validationErrors = append(r.validationErrors, message)
+	return false
+}
+
+// validateRoles checks names of roles are unique and patterns of item roles are valid regular expressions
+func (r *ReconcileJenkinsBaseConfiguration) validateRoles(field string, roles []v1alpha1.Role, itemRoles bool) bool {
+	names := map[string]struct{}{}
+	for _, role := range roles {
+		if len(role.Name) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Name of %s role is empty", field))
+			return false
+		}
+		if _, ok := names[role.Name]; ok {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Role '%s' is defined more than once in %s", role.Name, field))
+			return false
+		}
+		names[role.Name] = struct{}{}
+
+		if !itemRoles {
+			if role.Name == resources.OperatorRoleName {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Role name '%s' in %s is reserved for operator", role.Name, field))
+				return false
+			}
+			continue
+		}
+		if len(role.Pattern) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Pattern of role '%s' in %s is empty", role.Name, field))
+			return false
+		}
+		if _, err := regexp.Compile(role.Pattern); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Pattern of role '%s' in %s is invalid: %s", role.Name, field, err))
+			return false
+		}
+	}
+	return true
+}
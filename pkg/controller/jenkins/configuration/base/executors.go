@@ -0,0 +1,147 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const (
+	// reasonMasterExecutorsReapplied is the event which informs the number of Jenkins master executors has been changed
+	// in Jenkins and operator applied Jenkins.Spec.Master.NumExecutors again
+	reasonMasterExecutorsReapplied event.Reason = "MasterExecutorsReapplied"
+	// reasonNoBuildExecutors is the event which informs Jenkins has neither master executors nor agents to run builds on
+	reasonNoBuildExecutors event.Reason = "NoBuildExecutors"
+
+	// executorsChangedPrefix is printed by masterExecutorsScriptFmt with the previous and the applied number of executors
+	executorsChangedPrefix = "changed\t"
+	// operatorJobsQueuedPrefix is printed by masterExecutorsScriptFmt with the applied number of executors when
+	// the executor has been added for queued operator jobs
+	operatorJobsQueuedPrefix = "operator jobs queued\t"
+	// noAgentsMarker is printed by masterExecutorsScriptFmt when neither agents nor clouds with pod templates are configured
+	noAgentsMarker = "no agents"
+)
+
+// masterExecutorsScriptFmt sets the number of Jenkins master executors when it differs from the expected one,
+// operator jobs which wait for a master executor while there are none get one executor, the base configuration
+// script sets the expected number again when the base configuration job runs
+const masterExecutorsScriptFmt = `
+import jenkins.model.Jenkins
+
+def expected = %d
+def operatorJobs = new groovy.json.JsonSlurper().parseText('%s')
+def seedJobSuffix = '-` + constants.SeedJobSuffix + `'
+def jenkins = Jenkins.getInstance()
+
+def queued = jenkins.getQueue().getItems().any { item ->
+    def name = item.task.getOwnerTask().getFullName()
+    def label = item.getAssignedLabel()
+    return (operatorJobs.contains(name) || name.endsWith(seedJobSuffix)) && (label == null || label.contains(jenkins))
+}
+def operator = expected == 0 && queued
+if (operator) {
+    expected = 1
+}
+
+def previous = jenkins.getNumExecutors()
+if (previous != expected) {
+    jenkins.setNumExecutors(expected)
+    jenkins.save()
+    println operator ? '` + operatorJobsQueuedPrefix + `' + expected : '` + executorsChangedPrefix + `' + previous + '\t' + expected
+}
+
+def agents = jenkins.getNodes().size()
+jenkins.clouds.each { cloud ->
+    agents += cloud.metaClass.respondsTo(cloud, 'getTemplates') ? cloud.getTemplates().size() : 1
+}
+if (agents == 0) {
+    println '` + noAgentsMarker + `'
+}
+`
+
+// masterExecutors keeps the state of master executors of Jenkins CRs keyed by the namespace and the name of Jenkins CR,
+// applied contains the last number of executors verified by operator, raised contains Jenkins CRs which got
+// the executor for queued operator jobs, reported contains Jenkins CRs which have been reported to have no executors
+// for builds, they are reported again after executors or agents are configured
+var masterExecutors = struct {
+	sync.Mutex
+	applied  map[string]int
+	raised   map[string]bool
+	reported map[string]bool
+}{applied: map[string]int{}, raised: map[string]bool{}, reported: map[string]bool{}}
+
+// ensureMasterExecutors verifies the number of Jenkins master executors once the base configuration has been applied
+// and applies Jenkins.Spec.Master.NumExecutors again when it has been changed in Jenkins, e.g. in the UI
+func (r *ReconcileJenkinsBaseConfiguration) ensureMasterExecutors(jenkinsClient jenkinsclient.Jenkins) error {
+	if r.jenkins.Status.BaseConfigurationCompletedTime == nil {
+		return nil
+	}
+
+	expected := resources.GetMasterExecutors(r.jenkins)
+	operatorJobs := resources.NewOperatorJobs(r.jenkins)
+	names, err := json.Marshal([]string{operatorJobs.BaseConfiguration, operatorJobs.UserConfiguration,
+		operatorJobs.ConfigureSeedJobs, operatorJobs.DeleteSeedJobs})
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	output, err := jenkinsClient.ExecuteScript(fmt.Sprintf(masterExecutorsScriptFmt, expected, names))
+	if err != nil {
+		return err
+	}
+
+	masterExecutors.Lock()
+	defer masterExecutors.Unlock()
+
+	key := fmt.Sprintf("%s/%s", r.jenkins.Namespace, r.jenkins.Name)
+	// only the number changed since the last verification of the same expected number is reported, other changes
+	// are made by operator, e.g. when Jenkins.Spec.Master.NumExecutors changes or the executor for operator jobs is removed
+	applied, verified := masterExecutors.applied[key]
+	reapplied := verified && applied == expected && !masterExecutors.raised[key]
+	masterExecutors.applied[key] = expected
+	delete(masterExecutors.raised, key)
+	noAgents := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, operatorJobsQueuedPrefix):
+			masterExecutors.raised[key] = true
+			r.logger.Info("Jenkins master executor has been added for queued operator jobs")
+		case strings.HasPrefix(line, executorsChangedPrefix):
+			fields := strings.Split(strings.TrimPrefix(line, executorsChangedPrefix), "\t")
+			if len(fields) != 2 {
+				continue
+			}
+			if !reapplied {
+				r.logger.Info(fmt.Sprintf("Number of Jenkins master executors has been set to %s", fields[1]))
+				continue
+			}
+			message := fmt.Sprintf("Number of Jenkins master executors has been changed to %s in Jenkins, %s applied again", fields[0], fields[1])
+			r.logger.V(log.VWarn).Info(message)
+			r.events.Emit(r.jenkins, event.TypeWarning, reasonMasterExecutorsReapplied, message)
+		case line == noAgentsMarker:
+			noAgents = true
+		}
+	}
+
+	// builds can't run anywhere, it's reported once until executors or agents are configured
+	if expected > 0 || !noAgents {
+		delete(masterExecutors.reported, key)
+		return nil
+	}
+	if !masterExecutors.reported[key] {
+		masterExecutors.reported[key] = true
+		message := "Jenkins master has 0 executors and no agents or clouds with pod templates are configured, builds won't run anywhere"
+		r.logger.Info(message)
+		r.events.Emit(r.jenkins, event.TypeNormal, reasonNoBuildExecutors, message)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureMasterExecutors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	numExecutors := int32(0)
+	completedTime := metav1.Now()
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "executors", Namespace: "default"},
+		Spec:       v1alpha1.JenkinsSpec{Master: v1alpha1.JenkinsMaster{NumExecutors: &numExecutors}},
+	}
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+
+	// executors aren't verified until the base configuration is applied
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+
+	// the number is set by operator, builds can't run anywhere
+	jenkins.Status.BaseConfigurationCompletedTime = &completedTime
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Contains(t, script, "def expected = 0\n")
+		assert.Contains(t, script, `["jenkins-operator-base-configuration","jenkins-operator-user-configuration",`)
+		return executorsChangedPrefix + "3\t0\n" + noAgentsMarker + "\n", nil
+	})
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonNoBuildExecutors}, events.reasons)
+
+	// the executor added for operator jobs is removed, builds can't run anywhere is reported once
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(operatorJobsQueuedPrefix+"1\n"+noAgentsMarker, nil)
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(executorsChangedPrefix+"1\t0\n"+noAgentsMarker, nil)
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonNoBuildExecutors}, events.reasons)
+
+	// the number changed in Jenkins is applied again
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(executorsChangedPrefix+"2\t0\n"+noAgentsMarker, nil)
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonNoBuildExecutors, reasonMasterExecutorsReapplied}, events.reasons)
+
+	// the changed number in Jenkins CR isn't reported as the change made in Jenkins
+	numExecutors = 2
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Contains(t, script, "def expected = 2\n")
+		return executorsChangedPrefix + "0\t2\n" + noAgentsMarker, nil
+	})
+	assert.NoError(t, baseReconcileLoop.ensureMasterExecutors(jenkinsClient))
+	assert.Equal(t, []event.Reason{reasonNoBuildExecutors, reasonMasterExecutorsReapplied}, events.reasons)
+}
@@ -0,0 +1,129 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+// reasonLDAPConnectionFailure is the event which informs Jenkins can't connect to the LDAP server of the security realm
+const reasonLDAPConnectionFailure event.Reason = "LDAPConnectionFailure"
+
+// ldapConnectionTestScript binds to every LDAP server of the LDAP security realm with the manager DN and prints
+// failed connections in the server<TAB>exception format, one server per line
+const ldapConnectionTestScript = `
+import javax.naming.Context
+import javax.naming.directory.InitialDirContext
+import jenkins.model.Jenkins
+
+def realm = Jenkins.getInstance().getSecurityRealm()
+if (realm.getClass().getName() != 'hudson.security.LDAPSecurityRealm') {
+    return
+}
+
+realm.getConfigurations().each { configuration ->
+    def environment = new Hashtable()
+    environment.put(Context.INITIAL_CONTEXT_FACTORY, 'com.sun.jndi.ldap.LdapCtxFactory')
+    environment.put(Context.PROVIDER_URL, configuration.getLDAPURL())
+    configuration.getExtraEnvVars()?.each { name, value -> environment.put(name, value) }
+    if (configuration.getManagerDN()) {
+        environment.put(Context.SECURITY_AUTHENTICATION, 'simple')
+        environment.put(Context.SECURITY_PRINCIPAL, configuration.getManagerDN())
+        environment.put(Context.SECURITY_CREDENTIALS, configuration.getManagerPassword())
+    }
+    try {
+        new InitialDirContext(environment).close()
+    } catch (Exception e) {
+        println configuration.getServer() + '\t' + e.toString().readLines().join(' ')
+    }
+}
+`
+
+// validateLDAP checks the LDAP server URL, the user search filter and the manager password secret of Jenkins.Spec.Security.LDAP
+func (r *ReconcileJenkinsBaseConfiguration) validateLDAP(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.Security == nil || jenkins.Spec.Security.LDAP == nil {
+		return true, nil
+	}
+	ldap := jenkins.Spec.Security.LDAP
+
+	if len(ldap.Server) == 0 {
+		r.logger.V(log.VWarn).Info("spec.security.ldap.server is empty")
+		return false, nil
+	}
+	serverURL, err := url.Parse(resources.GetLDAPServerURL(ldap))
+	if err != nil || (serverURL.Scheme != "ldap" && serverURL.Scheme != "ldaps") || len(serverURL.Host) == 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.security.ldap.server '%s' has to be the host or the ldap:// or ldaps:// URL", ldap.Server))
+		return false, nil
+	}
+	if ldap.TLS && serverURL.Scheme != "ldaps" {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.security.ldap.server '%s' has to use the ldaps:// scheme when TLS is enabled", ldap.Server))
+		return false, nil
+	}
+	if len(ldap.UserSearch) > 0 && !strings.Contains(ldap.UserSearch, "{0}") {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.security.ldap.userSearch '%s' has to contain the {0} user name placeholder", ldap.UserSearch))
+		return false, nil
+	}
+	if ldap.ConnectTimeout != nil && ldap.ConnectTimeout.Duration < 0 || ldap.ReadTimeout != nil && ldap.ReadTimeout.Duration < 0 {
+		r.logger.V(log.VWarn).Info("Timeouts of spec.security.ldap can't be negative")
+		return false, nil
+	}
+
+	if ldap.ManagerPasswordSecretRef == nil {
+		return true, nil
+	}
+	if len(ldap.ManagerDN) == 0 {
+		r.logger.V(log.VWarn).Info("spec.security.ldap.managerPasswordSecretRef requires spec.security.ldap.managerDN")
+		return false, nil
+	}
+	return r.validateSecretKeys(ldap.ManagerPasswordSecretRef.Name, "LDAP manager password", ldap.ManagerPasswordSecretRef.Key)
+}
+
+// verifyLDAPConnection checks Jenkins can connect to LDAP servers of Jenkins.Spec.Security.LDAP after the base configuration
+// has been applied and saves the result in Jenkins.Status section, failed connections are reported as the event
+func (r *ReconcileJenkinsBaseConfiguration) verifyLDAPConnection(jenkinsClient jenkinsclient.Jenkins) error {
+	if r.jenkins.Spec.Security == nil || r.jenkins.Spec.Security.LDAP == nil {
+		if r.jenkins.Status.LDAPConnected == nil && len(r.jenkins.Status.LDAPConnectionErrors) == 0 {
+			return nil
+		}
+		r.jenkins.Status.LDAPConnected = nil
+		r.jenkins.Status.LDAPConnectionErrors = nil
+		return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+	}
+
+	output, err := jenkinsClient.ExecuteScript(ldapConnectionTestScript)
+	if err != nil {
+		return err
+	}
+
+	var connectionErrors []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			connectionErrors = append(connectionErrors, strings.Replace(line, "\t", ": ", 1))
+		}
+	}
+	connected := len(connectionErrors) == 0
+	if r.jenkins.Status.LDAPConnected != nil && *r.jenkins.Status.LDAPConnected == connected &&
+		reflect.DeepEqual(connectionErrors, r.jenkins.Status.LDAPConnectionErrors) {
+		return nil
+	}
+
+	if connected {
+		r.logger.Info("Jenkins has connected to the LDAP server")
+	} else {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Jenkins can't connect to the LDAP server: %s", strings.Join(connectionErrors, "; ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonLDAPConnectionFailure,
+			"Jenkins can't connect to the LDAP server: %s", strings.Join(connectionErrors, "; "))
+	}
+
+	r.jenkins.Status.LDAPConnected = &connected
+	r.jenkins.Status.LDAPConnectionErrors = connectionErrors
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
@@ -0,0 +1,144 @@
+package base
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const (
+	// reasonSMTPTestEmailSent is the event which informs the test email has been sent by the configured SMTP server
+	reasonSMTPTestEmailSent event.Reason = "SMTPTestEmailSent"
+	// reasonSMTPTestEmailFailure is the event which informs the test email couldn't be sent by the configured SMTP server
+	reasonSMTPTestEmailFailure event.Reason = "SMTPTestEmailFailure"
+
+	// testEmailSentMarker is printed by smtpTestEmailScriptFmt when the test email has been sent
+	testEmailSentMarker = "test email sent"
+	// testEmailFailedPrefix is printed by smtpTestEmailScriptFmt with the exception
+	testEmailFailedPrefix = "failed\t"
+)
+
+// smtpTestEmailScriptFmt sends the test email to the base64 encoded address by the SMTP server of the mailer plugin
+const smtpTestEmailScriptFmt = `
+import hudson.tasks.Mailer
+import javax.mail.Message
+import javax.mail.Transport
+import javax.mail.internet.InternetAddress
+import javax.mail.internet.MimeMessage
+import jenkins.model.JenkinsLocationConfiguration
+
+def recipient = new String('%s'.decodeBase64(), 'UTF-8')
+try {
+    def message = new MimeMessage(Mailer.descriptor().createSession())
+    def sender = JenkinsLocationConfiguration.get().getAdminAddress()
+    if (sender) {
+        message.setFrom(new InternetAddress(sender))
+    }
+    def replyTo = Mailer.descriptor().getReplyToAddress()
+    if (replyTo) {
+        message.setReplyTo(InternetAddress.parse(replyTo))
+    }
+    message.setRecipients(Message.RecipientType.TO, InternetAddress.parse(recipient))
+    message.setSubject('Jenkins test email')
+    message.setText('This is the test email sent by jenkins-operator to verify the SMTP configuration of Jenkins.')
+    Transport.send(message)
+} catch (Exception e) {
+    print '` + testEmailFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + testEmailSentMarker + `'
+`
+
+// validateSMTP checks the host, the port, email addresses and the credentials secret of Jenkins.Spec.Notifications.SMTP
+func (r *ReconcileJenkinsBaseConfiguration) validateSMTP(jenkins *v1alpha1.Jenkins) (bool, error) {
+	smtp := resources.GetSMTP(jenkins)
+	if smtp == nil {
+		return true, nil
+	}
+
+	if len(smtp.Host) == 0 || strings.ContainsAny(smtp.Host, ":/ ") {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.notifications.smtp.host '%s' has to be the host name", smtp.Host))
+		return false, nil
+	}
+	if smtp.Port < 0 || smtp.Port > 65535 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.notifications.smtp.port '%d' has to be between 1 and 65535", smtp.Port))
+		return false, nil
+	}
+	if smtp.SSL && smtp.StartTLS {
+		r.logger.V(log.VWarn).Info("Only one of spec.notifications.smtp.ssl and spec.notifications.smtp.startTLS can be enabled")
+		return false, nil
+	}
+	for _, address := range []struct{ field, value string }{
+		{field: "defaultSender", value: smtp.DefaultSender},
+		{field: "replyTo", value: smtp.ReplyTo},
+		{field: "sendTestEmail", value: smtp.SendTestEmail},
+	} {
+		if len(address.value) == 0 {
+			continue
+		}
+		if _, err := mail.ParseAddress(address.value); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.notifications.smtp.%s '%s' isn't valid email address: %s", address.field, address.value, err))
+			return false, nil
+		}
+	}
+
+	if smtp.CredentialsSecretRef == nil {
+		return true, nil
+	}
+	return r.validateSecretKeys(smtp.CredentialsSecretRef.Name, "SMTP credentials", resources.SMTPUsernameKey, resources.SMTPPasswordKey)
+}
+
+// sendSMTPTestEmail sends the test email to Jenkins.Spec.Notifications.SMTP.SendTestEmail after the base configuration
+// has been applied, the email is sent once per SMTP settings and the result is reported as the event
+func (r *ReconcileJenkinsBaseConfiguration) sendSMTPTestEmail(jenkinsClient jenkinsclient.Jenkins) error {
+	smtp := resources.GetSMTP(r.jenkins)
+	if smtp == nil || len(smtp.SendTestEmail) == 0 {
+		return nil
+	}
+	checksum, err := calculateSMTPChecksum(smtp)
+	if err != nil {
+		return err
+	}
+	if r.jenkins.Status.SMTPTestEmailChecksum == checksum {
+		return nil
+	}
+
+	output, err := jenkinsClient.ExecuteScript(fmt.Sprintf(smtpTestEmailScriptFmt, base64.StdEncoding.EncodeToString([]byte(smtp.SendTestEmail))))
+	if err != nil {
+		return err
+	}
+	output = strings.TrimSpace(output)
+	if output == testEmailSentMarker {
+		r.logger.Info(fmt.Sprintf("Test email has been sent to '%s'", smtp.SendTestEmail))
+		r.events.Emitf(r.jenkins, event.TypeNormal, reasonSMTPTestEmailSent, "Test email has been sent to '%s'", smtp.SendTestEmail)
+	} else {
+		reason := strings.TrimPrefix(output, testEmailFailedPrefix)
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Test email couldn't be sent to '%s': %s", smtp.SendTestEmail, reason))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonSMTPTestEmailFailure, "Test email couldn't be sent to '%s': %s", smtp.SendTestEmail, reason)
+	}
+
+	r.jenkins.Status.SMTPTestEmailChecksum = checksum
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// calculateSMTPChecksum returns the checksum of SMTP settings including the test email address
+func calculateSMTPChecksum(smtp *v1alpha1.SMTP) (string, error) {
+	data, err := json.Marshal(smtp)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,67 @@
+package base
+
+import (
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// validateNotifications checks the Slack webhook and webhooks of Jenkins.Spec.Notifications
+func (r *ReconcileJenkinsBaseConfiguration) validateNotifications(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.Notifications == nil {
+		return true, nil
+	}
+
+	if slack := jenkins.Spec.Notifications.Slack; slack != nil {
+		if valid, err := r.validateWebhook("spec.notifications.slack", "webhookURLSecretKeyRef", slack.WebhookURLSecretKeyRef, slack.Reasons); !valid || err != nil {
+			return valid, err
+		}
+	}
+
+	names := map[string]bool{}
+	for i, webhook := range jenkins.Spec.Notifications.Webhooks {
+		field := fmt.Sprintf("spec.notifications.webhooks[%d]", i)
+		if len(webhook.Name) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.name can't be empty", field))
+			return false, nil
+		}
+		if names[webhook.Name] {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.name '%s' is used by another webhook", field, webhook.Name))
+			return false, nil
+		}
+		names[webhook.Name] = true
+		if !event.IsWebhookFormatSupported(webhook) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.format '%s' isn't supported, use '%s', '%s' or '%s'", field, webhook.Format,
+				v1alpha1.WebhookFormatGeneric, v1alpha1.WebhookFormatMSTeams, v1alpha1.WebhookFormatSlack))
+			return false, nil
+		}
+		if valid, err := r.validateWebhook(field, "urlSecretKeyRef", webhook.URLSecretKeyRef, webhook.Reasons); !valid || err != nil {
+			return valid, err
+		}
+	}
+	return true, nil
+}
+
+// validateWebhook checks forwarded event reasons and the URL secret of the webhook
+func (r *ReconcileJenkinsBaseConfiguration) validateWebhook(field, selectorField string, selector corev1.SecretKeySelector, reasons []string) (bool, error) {
+	if len(reasons) == 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.reasons has to contain at least one event reason", field))
+		return false, nil
+	}
+	for _, reason := range reasons {
+		if len(reason) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.reasons can't contain empty event reason", field))
+			return false, nil
+		}
+	}
+
+	if len(selector.Name) == 0 || len(selector.Key) == 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("%s.%s has to define the secret name and the key", field, selectorField))
+		return false, nil
+	}
+	return r.validateSecretKeys(selector.Name, fmt.Sprintf("%s URL", field), selector.Key)
+}
@@ -0,0 +1,94 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+var oidcHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// oidcDiscoveryDocument is the part of the OpenID Connect discovery document checked by operator
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// validateOIDC checks the issuer URL, the client and the secrets of Jenkins.Spec.Security.OIDC, the discovery document
+// of the issuer is downloaded when Jenkins.Spec.Security.OIDC.ValidateIssuer is set
+func (r *ReconcileJenkinsBaseConfiguration) validateOIDC(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.Security == nil || jenkins.Spec.Security.OIDC == nil {
+		return true, nil
+	}
+	oidc := jenkins.Spec.Security.OIDC
+
+	issuerURL, err := url.Parse(oidc.IssuerURL)
+	if err != nil || (issuerURL.Scheme != "https" && issuerURL.Scheme != "http") || len(issuerURL.Host) == 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.security.oidc.issuerURL '%s' has to be the http:// or https:// URL", oidc.IssuerURL))
+		return false, nil
+	}
+	if len(oidc.ClientID) == 0 {
+		r.logger.V(log.VWarn).Info("spec.security.oidc.clientID is empty")
+		return false, nil
+	}
+	if len(oidc.ClientSecretRef.Name) == 0 || len(oidc.ClientSecretRef.Key) == 0 {
+		r.logger.V(log.VWarn).Info("spec.security.oidc.clientSecretRef requires the name and the key")
+		return false, nil
+	}
+	if valid, err := r.validateSecretKeys(oidc.ClientSecretRef.Name, "OpenID Connect client secret", oidc.ClientSecretRef.Key); !valid || err != nil {
+		return valid, err
+	}
+	if oidc.EscapeHatch != nil {
+		valid, err := r.validateSecretKeys(oidc.EscapeHatch.CredentialsSecretRef.Name, "OpenID Connect escape hatch credentials",
+			resources.OIDCEscapeHatchUserNameKey, resources.OIDCEscapeHatchPasswordKey)
+		if !valid || err != nil {
+			return valid, err
+		}
+	}
+
+	if oidc.ValidateIssuer {
+		if err := verifyOIDCIssuer(oidc); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Discovery document of spec.security.oidc.issuerURL '%s' isn't available: %s", oidc.IssuerURL, err))
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// verifyOIDCIssuer downloads the discovery document of the OpenID Connect issuer and checks it describes the issuer
+func verifyOIDCIssuer(oidc *v1alpha1.OIDC) error {
+	discoveryURL := resources.GetOIDCDiscoveryURL(oidc)
+	response, err := oidcHTTPClient.Get(discoveryURL)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return stackerr.Errorf("unexpected status '%s' of '%s'", response.Status, discoveryURL)
+	}
+
+	document := &oidcDiscoveryDocument{}
+	if err := json.NewDecoder(response.Body).Decode(document); err != nil {
+		return stackerr.WithStack(err)
+	}
+	if strings.TrimSuffix(document.Issuer, "/") != strings.TrimSuffix(oidc.IssuerURL, "/") {
+		return stackerr.Errorf("discovery document describes the issuer '%s'", document.Issuer)
+	}
+	if len(document.AuthorizationEndpoint) == 0 || len(document.TokenEndpoint) == 0 {
+		return stackerr.Errorf("discovery document doesn't contain the authorization or the token endpoint")
+	}
+	return nil
+}
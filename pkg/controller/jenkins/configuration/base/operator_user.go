@@ -0,0 +1,201 @@
+package base
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// reasonOperatorUserChanged is the event which informs operator has switched the Jenkins user it uses to access Jenkins API
+	reasonOperatorUserChanged event.Reason = "OperatorUserChanged"
+	// reasonOperatorUserChangeFailed is the event which informs the new Jenkins user of operator couldn't be verified
+	// and operator keeps using the current one
+	reasonOperatorUserChangeFailed event.Reason = "OperatorUserChangeFailed"
+)
+
+// createOperatorUserScriptFmt creates the Jenkins user with the API token and prints the token in the uuid<TAB>value format,
+// the account is created in Jenkins own user database if it's used, with the random password when the password is empty,
+// the user is granted the administer permission if the authorization strategy is the permission matrix,
+// the user name and the password are base64 encoded to avoid escaping
+const createOperatorUserScriptFmt = `
+import hudson.model.User
+import hudson.security.HudsonPrivateSecurityRealm
+import hudson.security.Permission
+import jenkins.model.Jenkins
+import jenkins.security.ApiTokenProperty
+
+def userName = new String('%s'.decodeBase64(), 'UTF-8')
+def password = new String('%s'.decodeBase64(), 'UTF-8')
+def jenkins = Jenkins.getInstance()
+
+def user
+def realm = jenkins.getSecurityRealm()
+if (realm instanceof HudsonPrivateSecurityRealm) {
+    if (password.isEmpty()) {
+        def random = new java.security.SecureRandom()
+        password = (1..32).collect { Integer.toHexString(random.nextInt(16)) }.join()
+    }
+    user = realm.createAccount(userName, password)
+} else {
+    user = User.getById(userName, true)
+}
+
+def strategy = jenkins.getAuthorizationStrategy()
+if (strategy.respondsTo('add', [Permission, String] as Object[])) {
+    strategy.add(Jenkins.ADMINISTER, userName)
+}
+jenkins.save()
+
+def token = user.getProperty(ApiTokenProperty.class).getTokenStore().generateNewToken('token')
+user.save()
+println token.tokenUuid + '\t' + token.plainValue
+`
+
+// deleteOperatorUserScriptFmt deletes the Jenkins user with its API tokens, the user name is base64 encoded to avoid escaping
+const deleteOperatorUserScriptFmt = `
+import hudson.model.User
+
+def user = User.getById(new String('%s'.decodeBase64(), 'UTF-8'), false)
+if (user != null) {
+    user.delete()
+}
+`
+
+// whoAmIScript prints the name of the Jenkins user which runs the script
+const whoAmIScript = `
+import jenkins.model.Jenkins
+
+println Jenkins.getAuthentication().getName()
+`
+
+// parseOperatorUserToken parses the output of createOperatorUserScriptFmt
+func parseOperatorUserToken(output string) (uuid string, token string, err error) {
+	for _, line := range strings.Split(output, "\n") {
+		values := strings.Split(strings.TrimSpace(line), "\t")
+		if len(values) == 2 && len(values[0]) > 0 && len(values[1]) > 0 {
+			return values[0], values[1], nil
+		}
+	}
+	return "", "", stackerr.Errorf("couldn't create Jenkins user of operator: %s", output)
+}
+
+// isLocalAdminDisabled returns true when Jenkins.Spec.Security.DisableLocalAdmin is set
+func isLocalAdminDisabled(jenkins *v1alpha1.Jenkins) bool {
+	return jenkins.Spec.Security != nil && jenkins.Spec.Security.DisableLocalAdmin
+}
+
+// ensureOperatorUser switches the Jenkins API access of operator to the service account user after the user configuration
+// completes when Jenkins.Spec.Security.DisableLocalAdmin is set and back to the admin user when it's turned off,
+// the Jenkins user operator uses is saved in Jenkins.Status section, returns true when the user has been switched
+func (r *ReconcileJenkinsBaseConfiguration) ensureOperatorUser(meta metav1.ObjectMeta, jenkinsClient jenkinsclient.Jenkins) (bool, error) {
+	credentialsSecret := &corev1.Secret{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: resources.GetOperatorCredentialsSecretName(r.jenkins), Namespace: r.jenkins.Namespace}, credentialsSecret)
+	if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
+	usesServiceAccount := len(credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUserNameKey]) > 0
+	switched := false
+	if isLocalAdminDisabled(r.jenkins) && !usesServiceAccount && r.jenkins.Status.UserConfigurationCompletedTime != nil {
+		switched, err = r.switchOperatorUser(meta, jenkinsClient, credentialsSecret, resources.OperatorServiceAccountUserName, "")
+	} else if !isLocalAdminDisabled(r.jenkins) && usesServiceAccount {
+		switched, err = r.switchOperatorUser(meta, jenkinsClient, credentialsSecret,
+			string(credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey]),
+			string(credentialsSecret.Data[resources.OperatorCredentialsSecretPasswordKey]))
+	}
+	if err != nil || switched {
+		return switched, err
+	}
+
+	authentication := v1alpha1.OperatorAuthenticationLocalAdmin
+	if usesServiceAccount {
+		authentication = v1alpha1.OperatorAuthenticationServiceAccount
+	}
+	userName := resources.GetOperatorTokenUserName(credentialsSecret)
+	if r.jenkins.Status.OperatorAuthentication == authentication && r.jenkins.Status.OperatorUserName == userName {
+		return false, nil
+	}
+	r.jenkins.Status.OperatorAuthentication = authentication
+	r.jenkins.Status.OperatorUserName = userName
+	return false, r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// switchOperatorUser creates the new Jenkins user of operator with the API token, verifies operator can access Jenkins API
+// as the new user and saves the token in the operator credentials secret, the previous user is deleted only after
+// the secret has been updated so operator is never locked out of Jenkins, returns false when the new user
+// couldn't be verified and operator keeps using the current one
+func (r *ReconcileJenkinsBaseConfiguration) switchOperatorUser(meta metav1.ObjectMeta, jenkinsClient jenkinsclient.Jenkins, credentialsSecret *corev1.Secret, userName, password string) (bool, error) {
+	jenkinsURL, err := r.getJenkinsAPIURL(meta)
+	if err != nil {
+		return false, err
+	}
+	previousUserName := resources.GetOperatorTokenUserName(credentialsSecret)
+	r.logger.Info(fmt.Sprintf("Switching Jenkins user of operator from '%s' to '%s'", previousUserName, userName))
+
+	output, err := jenkinsClient.ExecuteScript(fmt.Sprintf(createOperatorUserScriptFmt,
+		base64.StdEncoding.EncodeToString([]byte(userName)), base64.StdEncoding.EncodeToString([]byte(password))))
+	if err != nil {
+		return false, err
+	}
+	tokenUUID, token, err := parseOperatorUserToken(output)
+	if err != nil {
+		return false, err
+	}
+
+	newJenkinsClient, err := jenkinsclient.New(jenkinsURL, userName, token)
+	if err == nil {
+		output, err = newJenkinsClient.ExecuteScript(whoAmIScript)
+		if err == nil && strings.TrimSpace(output) != userName {
+			err = stackerr.Errorf("Jenkins API is accessed as '%s'", strings.TrimSpace(output))
+		}
+	}
+	if err != nil {
+		r.deleteOperatorUser(jenkinsClient, userName)
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't verify Jenkins user '%s' of operator, keeping '%s': %s", userName, previousUserName, err))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonOperatorUserChangeFailed,
+			"Couldn't verify Jenkins user '%s' of operator, keeping '%s'", userName, previousUserName)
+		return false, nil
+	}
+
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenKey] = []byte(token)
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUUIDKey] = []byte(tokenUUID)
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenCreationKey], _ = time.Now().UTC().MarshalText()
+	if userName == string(credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey]) {
+		delete(credentialsSecret.Data, resources.OperatorCredentialsSecretTokenUserNameKey)
+	} else {
+		credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUserNameKey] = []byte(userName)
+	}
+	if err := r.updateResource(credentialsSecret); err != nil {
+		r.deleteOperatorUser(jenkinsClient, userName)
+		return false, err // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+	}
+
+	r.deleteOperatorUser(newJenkinsClient, previousUserName)
+
+	r.logger.Info(fmt.Sprintf("Jenkins user of operator switched from '%s' to '%s'", previousUserName, userName))
+	r.events.Emitf(r.jenkins, event.TypeNormal, reasonOperatorUserChanged,
+		"Jenkins user of operator switched from '%s' to '%s'", previousUserName, userName)
+	return true, nil
+}
+
+// deleteOperatorUser deletes the Jenkins user, the failure is only logged because operator doesn't use the user anymore
+func (r *ReconcileJenkinsBaseConfiguration) deleteOperatorUser(jenkinsClient jenkinsclient.Jenkins, userName string) {
+	_, err := jenkinsClient.ExecuteScript(fmt.Sprintf(deleteOperatorUserScriptFmt, base64.StdEncoding.EncodeToString([]byte(userName))))
+	if err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't delete Jenkins user '%s': %s", userName, err))
+	}
+}
@@ -0,0 +1,123 @@
+package base
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/bndr/gojenkins"
+)
+
+// reasonPluginCleanup is the event which informs plugins not declared in Jenkins CR are uninstalled
+const reasonPluginCleanup event.Reason = "PluginCleanup"
+
+// detachedPluginsScript prints names of plugins detached from Jenkins core, Jenkins installs them
+// as implied dependencies of older plugins
+const detachedPluginsScript = `
+import jenkins.model.Jenkins
+
+def detachedPlugins
+try {
+    detachedPlugins = Jenkins.getInstance().getPluginManager().uberClassLoader.loadClass('jenkins.plugins.DetachedPluginsUtil').getDetachedPlugins()
+} catch (ClassNotFoundException ignored) {
+    detachedPlugins = hudson.ClassicPluginStrategy.getDetachedPlugins()
+}
+detachedPlugins.each { plugin ->
+    println plugin.getShortName()
+}
+`
+
+// uninstallPluginsScriptFmt uninstalls plugins and restarts Jenkins when no job is running to unload them
+const uninstallPluginsScriptFmt = `
+import jenkins.model.Jenkins
+
+def pluginManager = Jenkins.getInstance().getPluginManager()
+%s.each { name ->
+    pluginManager.getPlugin(name)?.doDoUninstall()
+}
+Jenkins.getInstance().safeRestart()
+`
+
+// getUndeclaredPlugins returns names of installed plugins which aren't declared and aren't dependencies of declared plugins,
+// bundled plugins are kept, dependencies are taken from installed plugins including optional ones
+func getUndeclaredPlugins(allPluginsInJenkins *gojenkins.Plugins, declaredPlugins map[string]bool) []string {
+	installedPlugins := map[string]gojenkins.Plugin{}
+	for _, jenkinsPlugin := range allPluginsInJenkins.Raw.Plugins {
+		if !jenkinsPlugin.Deleted {
+			installedPlugins[jenkinsPlugin.ShortName] = jenkinsPlugin
+		}
+	}
+
+	required := map[string]bool{}
+	var queue []string
+	for name := range declaredPlugins {
+		required[name] = true
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dependency := range installedPlugins[name].Dependencies {
+			if !required[dependency.ShortName] {
+				required[dependency.ShortName] = true
+				queue = append(queue, dependency.ShortName)
+			}
+		}
+	}
+
+	var undeclaredPlugins []string
+	for name, jenkinsPlugin := range installedPlugins {
+		if !required[name] && !jenkinsPlugin.Bundled {
+			undeclaredPlugins = append(undeclaredPlugins, name)
+		}
+	}
+	sort.Strings(undeclaredPlugins)
+	return undeclaredPlugins
+}
+
+// cleanupPlugins uninstalls plugins which aren't declared in Jenkins CR and aren't needed by declared plugins
+// when Jenkins.Spec.Master.PluginCleanup is set, detached plugins are kept, the plan is reported as the event
+// before the plugins are uninstalled and Jenkins is restarted
+func (r *ReconcileJenkinsBaseConfiguration) cleanupPlugins(jenkinsClient jenkinsclient.Jenkins, allPluginsInJenkins *gojenkins.Plugins,
+	declaredPlugins map[string]bool) error {
+	undeclaredPlugins := getUndeclaredPlugins(allPluginsInJenkins, declaredPlugins)
+	if !r.jenkins.Spec.Master.PluginCleanup || len(undeclaredPlugins) == 0 {
+		return nil
+	}
+
+	output, err := jenkinsClient.ExecuteScript(detachedPluginsScript)
+	if err != nil {
+		return err
+	}
+	detachedPlugins := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		detachedPlugins[strings.TrimSpace(line)] = true
+	}
+	var removedPlugins []string
+	for _, name := range undeclaredPlugins {
+		if !detachedPlugins[name] {
+			removedPlugins = append(removedPlugins, name)
+		}
+	}
+	if len(removedPlugins) == 0 {
+		return nil
+	}
+
+	r.logger.Info(fmt.Sprintf("Plugins not declared in Jenkins CR will be uninstalled and Jenkins restarted: %s", strings.Join(removedPlugins, ", ")))
+	r.events.Emitf(r.jenkins, event.TypeNormal, reasonPluginCleanup,
+		"Plugins not declared in Jenkins CR will be uninstalled and Jenkins restarted: %s", strings.Join(removedPlugins, ", "))
+	_, err = jenkinsClient.ExecuteScript(fmt.Sprintf(uninstallPluginsScriptFmt, toGroovyList(removedPlugins)))
+	return err
+}
+
+// toGroovyList returns the groovy list literal of names, names of plugins are safe to quote
+func toGroovyList(names []string) string {
+	var quoted []string
+	for _, name := range names {
+		quoted = append(quoted, "'"+name+"'")
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,83 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+// reasonPluginsFailedToLoad is the event which informs installed plugins failed to load when Jenkins started
+const reasonPluginsFailedToLoad event.Reason = "PluginsFailedToLoad"
+
+// failedPluginsScript prints plugins which Jenkins plugin manager failed to load with the exception
+// in the name<TAB>exception format, one plugin per line
+const failedPluginsScript = `
+import jenkins.model.Jenkins
+
+Jenkins.getInstance().getPluginManager().getFailedPlugins().each { plugin ->
+    println plugin.name + '\t' + (plugin.cause?.toString() ?: '').readLines().join(' ')
+}
+`
+
+// failedPlugin is the plugin which Jenkins failed to load, e.g. because of the missing dependency or too old Jenkins core
+type failedPlugin struct {
+	name  string
+	cause string
+}
+
+// parseFailedPlugins parses the output of failedPluginsScript, plugins are sorted by the name
+func parseFailedPlugins(output string) []failedPlugin {
+	var failedPlugins []failedPlugin
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		values := strings.SplitN(line, "\t", 2)
+		plugin := failedPlugin{name: values[0]}
+		if len(values) == 2 {
+			plugin.cause = values[1]
+		}
+		failedPlugins = append(failedPlugins, plugin)
+	}
+	sort.Slice(failedPlugins, func(i, j int) bool {
+		return failedPlugins[i].name < failedPlugins[j].name
+	})
+	return failedPlugins
+}
+
+// verifyPluginsHealth checks plugins installed in Jenkins have been loaded when Jenkins started and saves the result
+// in Jenkins.Status section, failed plugins are reported as the event and exceptions are logged at the debug level
+func (r *ReconcileJenkinsBaseConfiguration) verifyPluginsHealth(jenkinsClient jenkinsclient.Jenkins) (bool, error) {
+	output, err := jenkinsClient.ExecuteScript(failedPluginsScript)
+	if err != nil {
+		return false, err
+	}
+
+	var names []string
+	for _, plugin := range parseFailedPlugins(output) {
+		r.logger.V(log.VDebug).Info(fmt.Sprintf("Plugin '%s' failed to load: %s", plugin.name, plugin.cause))
+		names = append(names, plugin.name)
+	}
+	healthy := len(names) == 0
+	if r.jenkins.Status.PluginsHealthy != nil && *r.jenkins.Status.PluginsHealthy == healthy &&
+		reflect.DeepEqual(names, r.jenkins.Status.FailedPlugins) {
+		return healthy, nil
+	}
+
+	if !healthy {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugins failed to load: %s", strings.Join(names, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginsFailedToLoad,
+			"Plugins failed to load: %s", strings.Join(names, ", "))
+	}
+
+	r.jenkins.Status.PluginsHealthy = &healthy
+	r.jenkins.Status.FailedPlugins = names
+	return healthy, r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
@@ -2,8 +2,12 @@ package base
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
@@ -11,7 +15,9 @@ import (
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	"github.com/bndr/gojenkins"
@@ -29,8 +35,38 @@ import (
 
 const (
 	fetchAllPlugins = 1
+
+	// reasonBaseConfigurationOverridden is the event which informs base configuration scripts are overridden by user
+	reasonBaseConfigurationOverridden event.Reason = "BaseConfigurationOverridden"
+	// reasonPluginVersionsDrift is the event which informs installed plugin versions differ from versions declared in Jenkins CR
+	reasonPluginVersionsDrift event.Reason = "PluginVersionsDrift"
+	// reasonPluginChecksumMismatch is the event which informs downloaded plugins don't match checksums declared in Jenkins CR
+	reasonPluginChecksumMismatch event.Reason = "PluginChecksumMismatch"
+	// reasonPluginsFileInvalid is the event which informs plugins.txt from Jenkins.Spec.Master.PluginsConfigMapRef can't be parsed
+	reasonPluginsFileInvalid event.Reason = "PluginsFileInvalid"
+	// reasonPluginDownloadFailed is the event which informs plugins can't be downloaded after all retries
+	reasonPluginDownloadFailed event.Reason = "PluginDownloadFailed"
+	// reasonPluginDowngrade is the event which informs declared plugin versions are older than versions installed in Jenkins
+	reasonPluginDowngrade event.Reason = "PluginDowngrade"
+	// reasonJenkinsPodRecreated is the event which informs Jenkins master pod is recreated to apply changes of Jenkins CR
+	reasonJenkinsPodRecreated event.Reason = "JenkinsPodRecreated"
 )
 
+// pluginChecksumsScript prints plugins installed in Jenkins with SHA-256 checksums of their archives
+// in the name:version@sha256:checksum format
+const pluginChecksumsScript = `
+import java.security.MessageDigest
+import jenkins.model.Jenkins
+
+def pluginManager = Jenkins.getInstance().getPluginManager()
+pluginManager.getPlugins().each { plugin ->
+    def archive = ['jpi', 'hpi'].collect { new File(pluginManager.rootDir, plugin.getShortName() + '.' + it) }.find { it.isFile() }
+    if (archive != null) {
+        println plugin.getShortName() + ':' + plugin.getVersion() + '@sha256:' + MessageDigest.getInstance('SHA-256').digest(archive.bytes).encodeHex()
+    }
+}
+`
+
 // ReconcileJenkinsBaseConfiguration defines values required for Jenkins base configuration
 type ReconcileJenkinsBaseConfiguration struct {
 	k8sClient       client.Client
@@ -38,11 +74,21 @@ type ReconcileJenkinsBaseConfiguration struct {
 	logger          logr.Logger
 	jenkins         *v1alpha1.Jenkins
 	local, minikube bool
+	events          event.Recorder
+	// userPlugins, pluginConstraints, resolvedPlugins, pluginDependencies and pluginDependencyConflicts are cached
+	// for the reconciliation loop by getUserPlugins
+	userPlugins               map[string][]string
+	pluginConstraints         map[string][]string
+	resolvedPlugins           []v1alpha1.ResolvedPlugin
+	pluginDependencies        []v1alpha1.PluginDependency
+	pluginDependencyConflicts []string
+	// validationErrors are messages about invalid configuration for users, they're reported by ValidationErrors
+	validationErrors []string
 }
 
 // New create structure which takes care of base configuration
 func New(client client.Client, scheme *runtime.Scheme, logger logr.Logger,
-	jenkins *v1alpha1.Jenkins, local, minikube bool) *ReconcileJenkinsBaseConfiguration {
+	jenkins *v1alpha1.Jenkins, local, minikube bool, events event.Recorder) *ReconcileJenkinsBaseConfiguration {
 	return &ReconcileJenkinsBaseConfiguration{
 		k8sClient: client,
 		scheme:    scheme,
@@ -50,10 +96,12 @@ func New(client client.Client, scheme *runtime.Scheme, logger logr.Logger,
 		jenkins:   jenkins,
 		local:     local,
 		minikube:  minikube,
+		events:    events,
 	}
 }
 
-// Reconcile takes care of base configuration
+// Reconcile takes care of base configuration, the Jenkins API client is returned as soon as Jenkins API is reachable,
+// also when the base configuration fails or isn't completed yet
 func (r *ReconcileJenkinsBaseConfiguration) Reconcile() (reconcile.Result, jenkinsclient.Jenkins, error) {
 	metaObject := resources.NewResourceObjectMeta(r.jenkins)
 
@@ -62,6 +110,10 @@ func (r *ReconcileJenkinsBaseConfiguration) Reconcile() (reconcile.Result, jenki
 		return reconcile.Result{}, nil, err
 	}
 
+	if err := r.updateResolvedPlugins(); err != nil {
+		return reconcile.Result{}, nil, err
+	}
+
 	result, err := r.ensureJenkinsMasterPod(metaObject)
 	if err != nil {
 		return reconcile.Result{}, nil, err
@@ -86,9 +138,29 @@ func (r *ReconcileJenkinsBaseConfiguration) Reconcile() (reconcile.Result, jenki
 	}
 	r.logger.V(log.VDebug).Info("Jenkins API client set")
 
+	if err := r.ensureJenkinsURL(metaObject); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
+	rotated, err := r.ensureAdminCredentials(jenkinsClient)
+	if err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+	if rotated {
+		return reconcile.Result{Requeue: true}, jenkinsClient, nil
+	}
+
+	switched, err := r.ensureOperatorUser(metaObject, jenkinsClient)
+	if err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+	if switched {
+		return reconcile.Result{Requeue: true}, jenkinsClient, nil
+	}
+
 	ok, err := r.verifyPlugins(jenkinsClient)
 	if err != nil {
-		return reconcile.Result{}, nil, err
+		return reconcile.Result{}, jenkinsClient, err
 	}
 	if !ok {
 		r.logger.V(log.VWarn).Info("Please correct Jenkins CR(spec.master.OperatorPlugins or spec.master.plugins)")
@@ -96,8 +168,47 @@ func (r *ReconcileJenkinsBaseConfiguration) Reconcile() (reconcile.Result, jenki
 		return reconcile.Result{Requeue: true}, nil, r.restartJenkinsMasterPod(metaObject)
 	}
 
+	if err := r.ensurePluginChecksums(jenkinsClient); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
+	healthy, err := r.verifyPluginsHealth(jenkinsClient)
+	if err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+	if !healthy {
+		r.logger.V(log.VWarn).Info("Plugins failed to load, please correct Jenkins CR(spec.master.OperatorPlugins or spec.master.plugins)")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Minute}, jenkinsClient, nil
+	}
+
+	if err := r.ensureOperatorJobs(jenkinsClient); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
+	if err := r.ensureOperatorAuthorities(jenkinsClient); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
+	// executors are verified before the base configuration job which needs one on master
+	if err := r.ensureMasterExecutors(jenkinsClient); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
+	// usage stats and administrative monitors changed in Jenkins are reverted before the base configuration job runs
+	if err := r.ensureAdministrativeSettings(jenkinsClient); err != nil {
+		return reconcile.Result{}, jenkinsClient, err
+	}
+
 	result, err = r.ensureBaseConfiguration(jenkinsClient)
-	return result, jenkinsClient, err
+	if err != nil || result.Requeue {
+		return result, jenkinsClient, err
+	}
+
+	if err := r.verifyLDAPConnection(jenkinsClient); err != nil {
+		return result, jenkinsClient, err
+	}
+
+	return result, jenkinsClient, r.sendSMTPTestEmail(jenkinsClient)
 }
 
 func (r *ReconcileJenkinsBaseConfiguration) ensureResourcesRequiredForJenkinsPod(metaObject metav1.ObjectMeta) error {
@@ -121,10 +232,20 @@ func (r *ReconcileJenkinsBaseConfiguration) ensureResourcesRequiredForJenkinsPod
 	}
 	r.logger.V(log.VDebug).Info("Base configuration config map is present")
 
-	if err := r.createUserConfigurationConfigMap(metaObject); err != nil {
-		return err
+	// ConfigMaps with groovy scripts aren't needed when the user configuration is disabled
+	if resources.IsUserConfigurationEnabled(r.jenkins) {
+		if err := r.createUserConfigurationConfigMap(metaObject); err != nil {
+			return err
+		}
+		r.logger.V(log.VDebug).Info("User configuration config map is present")
+
+		if err := r.createUserConfigurationGitConfigMap(); err != nil {
+			return err
+		}
+		if err := r.createUserConfigurationURLsConfigMap(); err != nil {
+			return err
+		}
 	}
-	r.logger.V(log.VDebug).Info("User configuration config map is present")
 
 	if err := r.createRBAC(metaObject); err != nil {
 		return err
@@ -152,30 +273,31 @@ func (r *ReconcileJenkinsBaseConfiguration) verifyPlugins(jenkinsClient jenkinsc
 		}
 	}
 	r.logger.V(log.VDebug).Info(fmt.Sprintf("Installed plugins '%+v'", installedPlugins))
+	if err := r.updateInstalledPlugins(getInstalledPlugins(allPluginsInJenkins)); err != nil {
+		return false, err
+	}
 
-	userPlugins := map[string][]plugins.Plugin{}
-	for rootPlugin, dependentPluginNames := range r.jenkins.Spec.Master.Plugins {
-		var dependentPlugins []plugins.Plugin
-		for _, pluginNameWithVersion := range dependentPluginNames {
-			plugin, err := plugins.New(pluginNameWithVersion)
-			if err != nil {
-				return false, err
-			}
-			dependentPlugins = append(dependentPlugins, *plugin)
-		}
-		userPlugins[rootPlugin] = dependentPlugins
+	operatorPlugins, err := parsePlugins(plugins.OperatorPlugins(r.jenkins))
+	if err != nil {
+		return false, err
+	}
+	userPluginsWithVersions, err := r.getUserPlugins()
+	if err != nil {
+		return false, err
+	}
+	userPlugins, err := parsePlugins(userPluginsWithVersions)
+	if err != nil {
+		return false, err
 	}
 
 	status := true
-	allRequiredPlugins := []map[string][]plugins.Plugin{plugins.BasePluginsMap, userPlugins}
+	declaredVersions := map[string]string{}
+	allRequiredPlugins := []map[string][]plugins.Plugin{operatorPlugins, userPlugins, plugins.FeaturePluginsMap(r.jenkins)}
 	for _, requiredPlugins := range allRequiredPlugins {
 		for rootPluginName, p := range requiredPlugins {
 			rootPlugin, _ := plugins.New(rootPluginName)
-			if found, ok := isPluginInstalled(allPluginsInJenkins, *rootPlugin); !ok {
-				r.logger.V(log.VWarn).Info(fmt.Sprintf("Missing plugin '%s', actual '%+v'", rootPlugin, found))
-				status = false
-			}
-			for _, requiredPlugin := range p {
+			for _, requiredPlugin := range append([]plugins.Plugin{*rootPlugin}, p...) {
+				declaredVersions[requiredPlugin.Name] = requiredPlugin.Version
 				if found, ok := isPluginInstalled(allPluginsInJenkins, requiredPlugin); !ok {
 					r.logger.V(log.VWarn).Info(fmt.Sprintf("Missing plugin '%s', actual '%+v'", requiredPlugin, found))
 					status = false
@@ -184,9 +306,277 @@ func (r *ReconcileJenkinsBaseConfiguration) verifyPlugins(jenkinsClient jenkinsc
 		}
 	}
 
+	drift := getPluginVersionsDrift(allPluginsInJenkins, declaredVersions)
+	if err := r.updatePluginVersionsDrift(drift); err != nil {
+		return false, err
+	}
+	if len(drift) > 0 && r.jenkins.Spec.Master.EnforcePluginVersions {
+		r.logger.V(log.VWarn).Info("Installed plugin versions drift from Jenkins CR, declared versions will be reinstalled")
+		status = false
+	}
+
+	// plugins are cleaned up only when all declared plugins are installed
+	if status {
+		declaredPlugins := map[string]bool{}
+		for name := range declaredVersions {
+			declaredPlugins[name] = true
+		}
+		if err := r.cleanupPlugins(jenkinsClient, allPluginsInJenkins, declaredPlugins); err != nil {
+			return false, err
+		}
+	}
+
 	return status, nil
 }
 
+// getPluginsFile returns plugins.txt from the ConfigMap referenced by Jenkins.Spec.Master.PluginsConfigMapRef
+func (r *ReconcileJenkinsBaseConfiguration) getPluginsFile() (string, error) {
+	reference := r.jenkins.Spec.Master.PluginsConfigMapRef
+	if reference == nil {
+		return "", nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: reference.Name}, configMap)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	return configMap.Data[resources.PluginsFileKey], nil
+}
+
+// getPluginsFileChecksum calculates the checksum of plugins.txt, plugins are installed only on startup
+// so the pod is restarted when it changes
+func (r *ReconcileJenkinsBaseConfiguration) getPluginsFileChecksum() (string, error) {
+	if r.jenkins.Spec.Master.PluginsConfigMapRef == nil {
+		return "", nil
+	}
+
+	content, err := r.getPluginsFile()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(content))
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
+
+// getUserPlugins returns plugins from Jenkins.Spec.Master.Plugins merged with plugins from plugins.txt
+// with the latest versions and dependencies resolved, they are cached for the reconciliation loop,
+// dependent plugins with version constraints are kept in pluginConstraints for the validation
+func (r *ReconcileJenkinsBaseConfiguration) getUserPlugins() (map[string][]string, error) {
+	if r.userPlugins != nil {
+		return r.userPlugins, nil
+	}
+
+	content, err := r.getPluginsFile()
+	if err != nil {
+		return nil, err
+	}
+	filePlugins, err := plugins.ParseFile(content)
+	if err != nil {
+		return nil, err
+	}
+	userPlugins, err := r.resolveLatestPlugins(mergePlugins(r.jenkins.Spec.Master.Plugins, filePlugins))
+	if err != nil {
+		return nil, err
+	}
+	userPlugins, r.pluginConstraints = plugins.SplitConstraints(userPlugins)
+	userPlugins, err = r.resolvePluginDependencies(userPlugins)
+	if err != nil {
+		return nil, err
+	}
+	if userPlugins == nil {
+		userPlugins = map[string][]string{}
+	}
+	r.userPlugins = userPlugins
+	return userPlugins, nil
+}
+
+// mergePlugins adds plugins from plugins.txt as root plugins without dependencies to plugins declared in Jenkins CR,
+// plugins declared in Jenkins CR in any version win
+func mergePlugins(declaredPlugins map[string][]string, filePlugins []plugins.Plugin) map[string][]string {
+	if len(filePlugins) == 0 {
+		return declaredPlugins
+	}
+
+	merged := map[string][]string{}
+	names := map[string]bool{}
+	for rootPlugin, dependentPlugins := range declaredPlugins {
+		merged[rootPlugin] = dependentPlugins
+		for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+			if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+				names[plugin.Name] = true
+			}
+		}
+	}
+	for _, plugin := range filePlugins {
+		if names[plugin.Name] {
+			continue
+		}
+		names[plugin.Name] = true
+		merged[plugin.StringWithChecksum()] = []string{}
+	}
+	return merged
+}
+
+// parsePlugins parses root plugins and their dependencies in the name:version format
+func parsePlugins(pluginsWithVersions map[string][]string) (map[string][]plugins.Plugin, error) {
+	parsedPlugins := map[string][]plugins.Plugin{}
+	for rootPlugin, dependentPluginNames := range pluginsWithVersions {
+		var dependentPlugins []plugins.Plugin
+		for _, pluginNameWithVersion := range dependentPluginNames {
+			plugin, err := plugins.New(pluginNameWithVersion)
+			if err != nil {
+				return nil, err
+			}
+			dependentPlugins = append(dependentPlugins, *plugin)
+		}
+		parsedPlugins[rootPlugin] = dependentPlugins
+	}
+	return parsedPlugins, nil
+}
+
+// getInstalledPlugins returns plugins installed in Jenkins sorted by the name, deleted plugins are skipped
+func getInstalledPlugins(allPluginsInJenkins *gojenkins.Plugins) []v1alpha1.InstalledPlugin {
+	var installedPlugins []v1alpha1.InstalledPlugin
+	for _, jenkinsPlugin := range allPluginsInJenkins.Raw.Plugins {
+		if jenkinsPlugin.Deleted {
+			continue
+		}
+		installedPlugins = append(installedPlugins, v1alpha1.InstalledPlugin{
+			Name:      jenkinsPlugin.ShortName,
+			Version:   jenkinsPlugin.Version,
+			Enabled:   jenkinsPlugin.Enabled,
+			HasUpdate: jenkinsPlugin.HasUpdate,
+		})
+	}
+	sort.Slice(installedPlugins, func(i, j int) bool {
+		return installedPlugins[i].Name < installedPlugins[j].Name
+	})
+	return installedPlugins
+}
+
+// updateInstalledPlugins saves plugins installed in Jenkins in Jenkins.Status section when they have changed
+func (r *ReconcileJenkinsBaseConfiguration) updateInstalledPlugins(installedPlugins []v1alpha1.InstalledPlugin) error {
+	if reflect.DeepEqual(installedPlugins, r.jenkins.Status.InstalledPlugins) {
+		return nil
+	}
+
+	r.jenkins.Status.InstalledPlugins = installedPlugins
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// getPluginVersionsDrift returns installed plugins which versions differ from the declared ones sorted by the name,
+// missing plugins are reported separately
+func getPluginVersionsDrift(installedPlugins *gojenkins.Plugins, declaredVersions map[string]string) []v1alpha1.PluginVersionDrift {
+	var drift []v1alpha1.PluginVersionDrift
+	for name, version := range declaredVersions {
+		installed := installedPlugins.Contains(name)
+		if installed == nil || installed.Deleted || installed.Version == version {
+			continue
+		}
+		drift = append(drift, v1alpha1.PluginVersionDrift{Name: name, DeclaredVersion: version, InstalledVersion: installed.Version})
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		return drift[i].Name < drift[j].Name
+	})
+	return drift
+}
+
+// updatePluginVersionsDrift saves the plugin versions drift in Jenkins.Status section,
+// the new drift is reported as the warning event
+func (r *ReconcileJenkinsBaseConfiguration) updatePluginVersionsDrift(drift []v1alpha1.PluginVersionDrift) error {
+	inSync := len(drift) == 0
+	if r.jenkins.Status.PluginsInSync != nil && *r.jenkins.Status.PluginsInSync == inSync &&
+		reflect.DeepEqual(drift, r.jenkins.Status.PluginVersionsDrift) {
+		return nil
+	}
+
+	if !inSync {
+		var changes []string
+		for _, plugin := range drift {
+			changes = append(changes, fmt.Sprintf("%s declared %s installed %s", plugin.Name, plugin.DeclaredVersion, plugin.InstalledVersion))
+		}
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Installed plugin versions drift from Jenkins CR: %s", strings.Join(changes, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginVersionsDrift,
+			"Installed plugin versions drift from Jenkins CR: %s", strings.Join(changes, ", "))
+	}
+
+	r.jenkins.Status.PluginsInSync = &inSync
+	r.jenkins.Status.PluginVersionsDrift = drift
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// ensurePluginChecksums saves checksums of installed plugins in Jenkins.Status section,
+// they are computed once after Jenkins master pod has been started
+func (r *ReconcileJenkinsBaseConfiguration) ensurePluginChecksums(jenkinsClient jenkinsclient.Jenkins) error {
+	if r.jenkins.Status.PluginChecksums != nil {
+		return nil
+	}
+
+	output, err := jenkinsClient.ExecuteScript(pluginChecksumsScript)
+	if err != nil {
+		return err
+	}
+	checksums, err := parsePluginChecksums(output)
+	if err != nil {
+		return err
+	}
+
+	r.jenkins.Status.PluginChecksums = checksums
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// parsePluginChecksums parses the output of pluginChecksumsScript, checksums are sorted by the plugin name
+func parsePluginChecksums(output string) ([]v1alpha1.PluginChecksum, error) {
+	checksums := []v1alpha1.PluginChecksum{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		plugin, err := plugins.New(line)
+		if err != nil {
+			return nil, err
+		}
+		checksums = append(checksums, v1alpha1.PluginChecksum{Name: plugin.Name, Version: plugin.Version, SHA256: plugin.SHA256})
+	}
+	sort.Slice(checksums, func(i, j int) bool {
+		return checksums[i].Name < checksums[j].Name
+	})
+	return checksums, nil
+}
+
+// getFailedPlugins returns plugins which install-plugins.sh has reported with the given prefix
+// in the termination message of Jenkins master container
+func getFailedPlugins(pod *corev1.Pod, prefix string) []string {
+	var failedPlugins []string
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.State.Terminated == nil {
+			continue
+		}
+		for _, line := range strings.Split(containerStatus.State.Terminated.Message, "\n") {
+			if strings.HasPrefix(line, prefix) {
+				failedPlugins = append(failedPlugins, strings.TrimSpace(strings.TrimPrefix(line, prefix)))
+			}
+		}
+	}
+	return failedPlugins
+}
+
+// reportFailedPlugins logs and emits events with plugins which failed to install in the failed Jenkins master pod
+func (r *ReconcileJenkinsBaseConfiguration) reportFailedPlugins(pod *corev1.Pod) {
+	if failedPlugins := getFailedPlugins(pod, resources.PluginDownloadFailurePrefix); len(failedPlugins) > 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugins can't be downloaded: %s", strings.Join(failedPlugins, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginDownloadFailed,
+			"Plugins can't be downloaded: %s", strings.Join(failedPlugins, ", "))
+	}
+	if mismatches := getFailedPlugins(pod, resources.PluginChecksumMismatchPrefix); len(mismatches) > 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Downloaded plugins don't match checksums declared in Jenkins CR: %s", strings.Join(mismatches, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginChecksumMismatch,
+			"Downloaded plugins don't match checksums declared in Jenkins CR: %s", strings.Join(mismatches, ", "))
+	}
+}
+
 func isPluginInstalled(plugins *gojenkins.Plugins, requiredPlugin plugins.Plugin) (gojenkins.Plugin, bool) {
 	p := plugins.Contains(requiredPlugin.Name)
 	if p == nil {
@@ -200,22 +590,37 @@ func (r *ReconcileJenkinsBaseConfiguration) createOperatorCredentialsSecret(meta
 	found := &corev1.Secret{}
 	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: resources.GetOperatorCredentialsSecretName(r.jenkins), Namespace: r.jenkins.ObjectMeta.Namespace}, found)
 
-	if err != nil && apierrors.IsNotFound(err) {
-		return stackerr.WithStack(r.createResource(resources.NewOperatorCredentialsSecret(meta, r.jenkins)))
-	} else if err != nil && !apierrors.IsNotFound(err) {
+	if err != nil && !apierrors.IsNotFound(err) {
 		return stackerr.WithStack(err)
 	}
-
-	if found.Data[resources.OperatorCredentialsSecretUserNameKey] != nil &&
+	if err == nil && found.Data[resources.OperatorCredentialsSecretUserNameKey] != nil &&
 		found.Data[resources.OperatorCredentialsSecretPasswordKey] != nil {
 		return nil
 	}
 
-	return stackerr.WithStack(r.updateResource(resources.NewOperatorCredentialsSecret(meta, r.jenkins)))
+	secret := resources.NewOperatorCredentialsSecret(meta, r.jenkins)
+	// credentials provided by user are used when the Jenkins user is created, their changes are applied by ensureAdminCredentials
+	if r.jenkins.Spec.Master.AdminCredentialsSecretRef != nil {
+		adminCredentials, err := r.getAdminCredentialsSecret()
+		if err != nil {
+			return stackerr.WithStack(err)
+		}
+		secret.Data[resources.OperatorCredentialsSecretUserNameKey] = adminCredentials.Data[resources.OperatorCredentialsSecretUserNameKey]
+		secret.Data[resources.OperatorCredentialsSecretPasswordKey] = adminCredentials.Data[resources.OperatorCredentialsSecretPasswordKey]
+	}
+
+	if apierrors.IsNotFound(err) {
+		return stackerr.WithStack(r.createResource(secret))
+	}
+	return stackerr.WithStack(r.updateResource(secret))
 }
 
 func (r *ReconcileJenkinsBaseConfiguration) createScriptsConfigMap(meta metav1.ObjectMeta) error {
-	configMap, err := resources.NewScriptsConfigMap(meta, r.jenkins)
+	userPlugins, err := r.getUserPlugins()
+	if err != nil {
+		return err
+	}
+	configMap, err := resources.NewScriptsConfigMap(meta, r.jenkins, userPlugins)
 	if err != nil {
 		return err
 	}
@@ -231,10 +636,54 @@ func (r *ReconcileJenkinsBaseConfiguration) createInitConfigurationConfigMap(met
 }
 
 func (r *ReconcileJenkinsBaseConfiguration) createBaseConfigurationConfigMap(meta metav1.ObjectMeta) error {
-	configMap := resources.NewBaseConfigurationConfigMap(meta, r.jenkins)
+	configMap, err := resources.NewBaseConfigurationConfigMap(meta, r.jenkins)
+	if err != nil {
+		return err
+	}
+
+	if overrides := r.jenkins.Spec.Master.BaseConfigurationOverrides; overrides != nil {
+		overridesConfigMap := &corev1.ConfigMap{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: overrides.Name}, overridesConfigMap)
+		if err != nil {
+			return stackerr.WithStack(err)
+		}
+		replaced, appended := resources.OverrideBaseConfiguration(configMap, overridesConfigMap.Data)
+		message := fmt.Sprintf("Base configuration scripts are overridden by ConfigMap '%s', replaced: %v, appended: %v",
+			overrides.Name, replaced, appended)
+		r.logger.V(log.VWarn).Info(message)
+		r.events.Emit(r.jenkins, event.TypeWarning, reasonBaseConfigurationOverridden, message)
+	}
+
 	return stackerr.WithStack(r.createOrUpdateResource(configMap))
 }
 
+// createUserConfigurationGitConfigMap creates the empty ConfigMap mounted into the Jenkins master pod before
+// scripts are fetched from the git repository, its content is managed by the user configuration
+func (r *ReconcileJenkinsBaseConfiguration) createUserConfigurationGitConfigMap() error {
+	if r.jenkins.Spec.Configuration.Git == nil {
+		return nil
+	}
+	return r.createConfigMapIfNotExists(resources.NewUserConfigurationGitConfigMap(r.jenkins))
+}
+
+// createUserConfigurationURLsConfigMap creates the empty ConfigMap mounted into the Jenkins master pod before
+// scripts are downloaded from URLs, its content is managed by the user configuration
+func (r *ReconcileJenkinsBaseConfiguration) createUserConfigurationURLsConfigMap() error {
+	if len(r.jenkins.Spec.Configuration.URLs) == 0 {
+		return nil
+	}
+	return r.createConfigMapIfNotExists(resources.NewUserConfigurationURLsConfigMap(r.jenkins))
+}
+
+// createConfigMapIfNotExists creates the ConfigMap owned by Jenkins CR, the content of the existing one isn't changed
+func (r *ReconcileJenkinsBaseConfiguration) createConfigMapIfNotExists(configMap *corev1.ConfigMap) error {
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, &corev1.ConfigMap{})
+	if err != nil && errors.IsNotFound(err) {
+		return stackerr.WithStack(r.createResource(configMap))
+	}
+	return stackerr.WithStack(err)
+}
+
 func (r *ReconcileJenkinsBaseConfiguration) createUserConfigurationConfigMap(meta metav1.ObjectMeta) error {
 	currentConfigMap := &corev1.ConfigMap{}
 	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: resources.GetUserConfigurationConfigMapName(r.jenkins), Namespace: r.jenkins.Namespace}, currentConfigMap)
@@ -294,10 +743,21 @@ func (r *ReconcileJenkinsBaseConfiguration) getJenkinsMasterPod(meta metav1.Obje
 }
 
 func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsMasterPod(meta metav1.ObjectMeta) (reconcile.Result, error) {
+	initScriptsChecksum, err := r.getInitScriptsChecksum()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	pluginsFileChecksum, err := r.getPluginsFileChecksum()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	// Check if this Pod already exists
 	currentJenkinsMasterPod, err := r.getJenkinsMasterPod(meta)
 	if err != nil && errors.IsNotFound(err) {
 		jenkinsMasterPod := resources.NewJenkinsMasterPod(meta, r.jenkins)
+		resources.SetInitScriptsChecksum(jenkinsMasterPod, initScriptsChecksum)
+		resources.SetPluginsFileChecksum(jenkinsMasterPod, pluginsFileChecksum)
 		r.logger.Info(fmt.Sprintf("Creating a new Jenkins Master Pod %s/%s", jenkinsMasterPod.Namespace, jenkinsMasterPod.Name))
 		err = r.createResource(jenkinsMasterPod)
 		if err != nil {
@@ -306,6 +766,12 @@ func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsMasterPod(meta metav1.O
 		now := metav1.Now()
 		r.jenkins.Status = v1alpha1.JenkinsStatus{
 			ProvisionStartTime: &now,
+			// the new pod is provisioned with already resolved plugin versions, they are checked during the validation
+			ResolvedPlugins:        r.jenkins.Status.ResolvedPlugins,
+			PluginSecurityWarnings: r.jenkins.Status.PluginSecurityWarnings,
+			PluginDependencies:     r.jenkins.Status.PluginDependencies,
+			// conditions keep their last transition times, they are updated by the reconciliation of the new pod
+			Conditions: r.jenkins.Status.Conditions,
 		}
 		err = r.updateResource(r.jenkins)
 		if err != nil {
@@ -317,41 +783,293 @@ func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsMasterPod(meta metav1.O
 	}
 
 	// Recreate pod
-	recreatePod := false
+	var recreateReasons []string
+	recreate := func(reason string) {
+		r.logger.Info(fmt.Sprintf("%s, recreating pod", reason))
+		recreateReasons = append(recreateReasons, reason)
+	}
 	if currentJenkinsMasterPod != nil &&
 		(currentJenkinsMasterPod.Status.Phase == corev1.PodFailed ||
 			currentJenkinsMasterPod.Status.Phase == corev1.PodSucceeded ||
 			currentJenkinsMasterPod.Status.Phase == corev1.PodUnknown) {
-		r.logger.Info(fmt.Sprintf("Invalid Jenkins pod phase '%+v', recreating pod", currentJenkinsMasterPod.Status.Phase))
-		recreatePod = true
+		recreate(fmt.Sprintf("Invalid Jenkins pod phase '%+v'", currentJenkinsMasterPod.Status.Phase))
+		r.reportFailedPlugins(currentJenkinsMasterPod)
 	}
 
 	if currentJenkinsMasterPod != nil &&
 		r.jenkins.Spec.Master.Image != currentJenkinsMasterPod.Spec.Containers[0].Image {
-		r.logger.Info(fmt.Sprintf("Jenkins image has changed to '%+v', recreating pod", r.jenkins.Spec.Master.Image))
-		recreatePod = true
+		recreate(fmt.Sprintf("Jenkins image has changed to '%+v'", r.jenkins.Spec.Master.Image))
 	}
 
 	if currentJenkinsMasterPod != nil && len(r.jenkins.Spec.Master.Annotations) > 0 &&
 		!reflect.DeepEqual(r.jenkins.Spec.Master.Annotations, currentJenkinsMasterPod.ObjectMeta.Annotations) {
-		r.logger.Info(fmt.Sprintf("Jenkins pod annotations have changed to '%+v', recreating pod", r.jenkins.Spec.Master.Annotations))
-		recreatePod = true
+		recreate(fmt.Sprintf("Jenkins pod annotations have changed to '%+v'", r.jenkins.Spec.Master.Annotations))
 	}
 
 	if currentJenkinsMasterPod != nil &&
 		!reflect.DeepEqual(r.jenkins.Spec.Master.Resources, currentJenkinsMasterPod.Spec.Containers[0].Resources) {
-		r.logger.Info(fmt.Sprintf("Jenkins pod resources have changed, actual '%+v' required '%+v' - recreating pod",
+		recreate(fmt.Sprintf("Jenkins pod resources have changed, actual '%+v' required '%+v'",
 			currentJenkinsMasterPod.Spec.Containers[0].Resources, r.jenkins.Spec.Master.Resources))
-		recreatePod = true
 	}
 
-	if currentJenkinsMasterPod != nil && recreatePod && currentJenkinsMasterPod.ObjectMeta.DeletionTimestamp == nil {
+	if currentJenkinsMasterPod != nil && isProxyChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins proxy has changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isUpdateCenterChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins update center has changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isSecurityRealmChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins security realm secrets have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isSMTPChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins SMTP credentials secret has changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isTimezoneChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins timezone has changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isAdditionalCACertsChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins additional CA certificates have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isUserConfigurationChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins user configuration ConfigMaps or Secrets have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isConfigurationAsCodeChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins configuration as code ConfigMaps or Secrets have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isExtraFilesChanged(r.jenkins, currentJenkinsMasterPod) {
+		recreate("Jenkins extra files have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && isInitScriptsChanged(r.jenkins, currentJenkinsMasterPod, initScriptsChecksum) {
+		recreate("Jenkins init scripts have changed")
+	}
+
+	if currentJenkinsMasterPod != nil && resources.GetPluginsFileChecksum(currentJenkinsMasterPod) != pluginsFileChecksum {
+		recreate("Jenkins plugins.txt has changed")
+	}
+
+	if currentJenkinsMasterPod != nil && len(recreateReasons) > 0 && currentJenkinsMasterPod.ObjectMeta.DeletionTimestamp == nil {
+		r.events.Emitf(r.jenkins, event.TypeNormal, reasonJenkinsPodRecreated, "Jenkins master pod is recreated: %s", strings.Join(recreateReasons, "; "))
 		return reconcile.Result{Requeue: true}, r.restartJenkinsMasterPod(meta)
 	}
 
 	return reconcile.Result{}, nil
 }
 
+// isProxyChanged compares proxy environment variables of the pod with Jenkins.Spec.Proxy
+func isProxyChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	expected := map[string]corev1.EnvVar{}
+	for _, envVar := range resources.NewProxyEnvVars(jenkins) {
+		expected[envVar.Name] = envVar
+	}
+	current := map[string]corev1.EnvVar{}
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if _, ok := resources.ProxyEnvVarNames[envVar.Name]; ok {
+			current[envVar.Name] = envVar
+		}
+	}
+	return !reflect.DeepEqual(expected, current)
+}
+
+// isUpdateCenterChanged compares update center environment variables of the pod with Jenkins.Spec.Master,
+// the pod is recreated so plugins are installed from the new update center
+func isUpdateCenterChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	expected := map[string]corev1.EnvVar{}
+	for _, envVar := range resources.NewUpdateCenterEnvVars(jenkins) {
+		expected[envVar.Name] = envVar
+	}
+	current := map[string]corev1.EnvVar{}
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if _, ok := resources.UpdateCenterEnvVarNames[envVar.Name]; ok {
+			current[envVar.Name] = envVar
+		}
+	}
+	return !reflect.DeepEqual(expected, current)
+}
+
+// isSecurityRealmChanged compares security realm environment variables of the pod with Jenkins.Spec.Security,
+// the pod is recreated so the base configuration reads the new secrets
+func isSecurityRealmChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	expected := map[string]corev1.EnvVar{}
+	for _, envVar := range resources.NewSecurityRealmEnvVars(jenkins) {
+		expected[envVar.Name] = envVar
+	}
+	current := map[string]corev1.EnvVar{}
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if _, ok := resources.SecurityRealmEnvVarNames[envVar.Name]; ok {
+			current[envVar.Name] = envVar
+		}
+	}
+	return !reflect.DeepEqual(expected, current)
+}
+
+// isSMTPChanged compares SMTP environment variables of the pod with Jenkins.Spec.Notifications,
+// the pod is recreated so the base configuration reads the new credentials
+func isSMTPChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	expected := map[string]corev1.EnvVar{}
+	for _, envVar := range resources.NewSMTPEnvVars(jenkins) {
+		expected[envVar.Name] = envVar
+	}
+	current := map[string]corev1.EnvVar{}
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if _, ok := resources.SMTPEnvVarNames[envVar.Name]; ok {
+			current[envVar.Name] = envVar
+		}
+	}
+	return !reflect.DeepEqual(expected, current)
+}
+
+// isTimezoneChanged compares the time zone environment variable of the pod with Jenkins.Spec.Master.Timezone,
+// the pod is recreated so the JVM starts with the new time zone
+func isTimezoneChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	current := ""
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if envVar.Name == resources.TimezoneEnvName {
+			current = envVar.Value
+		}
+	}
+	return current != jenkins.Spec.Master.Timezone
+}
+
+// isAdditionalCACertsChanged compares the secret mounted in the pod with Jenkins.Spec.Master.AdditionalCACerts
+func isAdditionalCACertsChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	var current *corev1.SecretVolumeSource
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == resources.AdditionalCACertsVolumeName {
+			current = volume.Secret
+		}
+	}
+	if jenkins.Spec.Master.AdditionalCACerts == nil || current == nil {
+		return (jenkins.Spec.Master.AdditionalCACerts == nil) != (current == nil)
+	}
+
+	expected := resources.NewAdditionalCACertsVolume(jenkins).Secret
+	return current.SecretName != expected.SecretName || !reflect.DeepEqual(current.Items, expected.Items)
+}
+
+// isUserConfigurationChanged compares ConfigMaps and Secrets with groovy scripts mounted in the pod with Jenkins.Spec.Configuration
+func isUserConfigurationChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	var current []resources.UserConfigurationSource
+	for _, volume := range pod.Spec.Volumes {
+		if !strings.HasPrefix(volume.Name, resources.JenkinsUserConfigurationVolumeName) {
+			continue
+		}
+		if volume.ConfigMap != nil {
+			current = append(current, resources.UserConfigurationSource{Name: volume.ConfigMap.Name})
+		} else if volume.Secret != nil {
+			current = append(current, resources.UserConfigurationSource{Name: volume.Secret.SecretName, Secret: true})
+		}
+	}
+	return !reflect.DeepEqual(current, resources.GetUserConfigurationSources(jenkins))
+}
+
+// isConfigurationAsCodeChanged compares ConfigMaps and Secrets mounted in the pod with Jenkins.Spec.ConfigurationAsCode,
+// changes of their content are reloaded by the user configuration
+func isConfigurationAsCodeChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	var current *corev1.ProjectedVolumeSource
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == resources.ConfigurationAsCodeVolumeName {
+			current = volume.Projected
+		}
+	}
+	if jenkins.Spec.ConfigurationAsCode == nil || current == nil {
+		return (jenkins.Spec.ConfigurationAsCode == nil) != (current == nil)
+	}
+
+	expected := resources.NewConfigurationAsCodeVolume(jenkins).Projected
+	return !reflect.DeepEqual(current.Sources, expected.Sources)
+}
+
+// isExtraFilesChanged compares ConfigMap and Secret keys mounted in the pod with Jenkins.Spec.Master.ExtraFiles,
+// extra files are copied into Jenkins home only when the pod starts
+func isExtraFilesChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod) bool {
+	var current *corev1.ProjectedVolumeSource
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == resources.ExtraFilesVolumeName {
+			current = volume.Projected
+		}
+	}
+	if len(jenkins.Spec.Master.ExtraFiles) == 0 || current == nil {
+		return (len(jenkins.Spec.Master.ExtraFiles) == 0) != (current == nil)
+	}
+
+	expected := resources.NewExtraFilesVolume(jenkins).Projected
+	return !reflect.DeepEqual(current.Sources, expected.Sources)
+}
+
+// isInitScriptsChanged compares ConfigMaps and Secrets mounted in the pod with Jenkins.Spec.Master.InitScripts
+// and the checksum of init scripts stored in the pod with the current one
+func isInitScriptsChanged(jenkins *v1alpha1.Jenkins, pod *corev1.Pod, checksum string) bool {
+	if resources.GetInitScriptsChecksum(pod) != checksum {
+		return true
+	}
+
+	var current *corev1.ProjectedVolumeSource
+	for _, volume := range pod.Spec.Volumes {
+		if volume.Name == resources.InitScriptsVolumeName {
+			current = volume.Projected
+		}
+	}
+	if jenkins.Spec.Master.InitScripts == nil || current == nil {
+		return (jenkins.Spec.Master.InitScripts == nil) != (current == nil)
+	}
+
+	expected := resources.NewInitScriptsVolume(jenkins).Projected
+	return !reflect.DeepEqual(current.Sources, expected.Sources)
+}
+
+// getInitScriptsChecksum calculates the checksum of init scripts from ConfigMaps and Secrets referenced by
+// Jenkins.Spec.Master.InitScripts, init scripts run only on startup so the pod is restarted when it changes
+func (r *ReconcileJenkinsBaseConfiguration) getInitScriptsChecksum() (string, error) {
+	initScripts := r.jenkins.Spec.Master.InitScripts
+	if initScripts == nil {
+		return "", nil
+	}
+
+	scripts := map[string][]byte{}
+	for _, reference := range initScripts.ConfigMaps {
+		configMap := &corev1.ConfigMap{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: reference.Name}, configMap)
+		if err != nil {
+			return "", stackerr.WithStack(err)
+		}
+		for key, value := range configMap.Data {
+			scripts[key] = []byte(value)
+		}
+	}
+	for _, reference := range initScripts.Secrets {
+		secret := &corev1.Secret{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: reference.Name}, secret)
+		if err != nil {
+			return "", stackerr.WithStack(err)
+		}
+		for key, value := range secret.Data {
+			scripts[key] = value
+		}
+	}
+
+	var names []string
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write(scripts[name])
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
+
 func (r *ReconcileJenkinsBaseConfiguration) restartJenkinsMasterPod(meta metav1.ObjectMeta) error {
 	currentJenkinsMasterPod, err := r.getJenkinsMasterPod(meta)
 	r.logger.Info(fmt.Sprintf("Terminating Jenkins Master Pod %s/%s", currentJenkinsMasterPod.Namespace, currentJenkinsMasterPod.Name))
@@ -387,9 +1105,42 @@ func (r *ReconcileJenkinsBaseConfiguration) waitForJenkins(meta metav1.ObjectMet
 	return reconcile.Result{}, nil
 }
 
+// ensureJenkinsURL saves the Jenkins URL exposed by the load balancer service in Jenkins.Status.URL
+func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsURL(meta metav1.ObjectMeta) error {
+	service := &corev1.Service{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Name: meta.Name, Namespace: meta.Namespace}, service)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+
+	jenkinsURL := ""
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		host := ingress.Hostname
+		if host == "" {
+			host = ingress.IP
+		}
+		if host != "" {
+			jenkinsURL = fmt.Sprintf("http://%s:%d", host, resources.HTTPPortInt)
+			break
+		}
+	}
+
+	if jenkinsURL == "" || jenkinsURL == r.jenkins.Status.URL {
+		return nil
+	}
+
+	r.logger.Info(fmt.Sprintf("Jenkins URL %s", jenkinsURL))
+	r.jenkins.Status.URL = jenkinsURL
+	return stackerr.WithStack(r.k8sClient.Update(context.TODO(), r.jenkins))
+}
+
+// getJenkinsAPIURL returns the URL operator uses to access Jenkins API
+func (r *ReconcileJenkinsBaseConfiguration) getJenkinsAPIURL(meta metav1.ObjectMeta) (string, error) {
+	return jenkinsclient.BuildJenkinsAPIUrl(r.jenkins.ObjectMeta.Namespace, meta.Name, resources.HTTPPortInt, r.local, r.minikube)
+}
+
 func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsClient(meta metav1.ObjectMeta) (jenkinsclient.Jenkins, error) {
-	jenkinsURL, err := jenkinsclient.BuildJenkinsAPIUrl(
-		r.jenkins.ObjectMeta.Namespace, meta.Name, resources.HTTPPortInt, r.local, r.minikube)
+	jenkinsURL, err := r.getJenkinsAPIURL(meta)
 	if err != nil {
 		return nil, err
 	}
@@ -434,24 +1185,88 @@ func (r *ReconcileJenkinsBaseConfiguration) ensureJenkinsClient(meta metav1.Obje
 		}
 
 		credentialsSecret.Data[resources.OperatorCredentialsSecretTokenKey] = []byte(token.GetToken())
-		now, _ := time.Now().UTC().MarshalText()
-		credentialsSecret.Data[resources.OperatorCredentialsSecretTokenCreationKey] = now
+		credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUUIDKey] = []byte(token.GetUUID())
+		delete(credentialsSecret.Data, resources.OperatorCredentialsSecretTokenUserNameKey)
+		now := time.Now().UTC()
+		credentialsSecret.Data[resources.OperatorCredentialsSecretTokenCreationKey], _ = now.MarshalText()
 		err = r.updateResource(credentialsSecret)
 		if err != nil {
 			return nil, stackerr.WithStack(err)
 		}
+		tokenCreationTime = &now
 	}
 
-	return jenkinsclient.New(
+	jenkinsClient, err := jenkinsclient.New(
 		jenkinsURL,
-		string(credentialsSecret.Data[resources.OperatorCredentialsSecretUserNameKey]),
+		resources.GetOperatorTokenUserName(credentialsSecret),
 		string(credentialsSecret.Data[resources.OperatorCredentialsSecretTokenKey]))
+	if err != nil {
+		return nil, err
+	}
+
+	if isTokenRotationRequired(r.jenkins, *tokenCreationTime, time.Now()) {
+		return r.rotateAPIToken(jenkinsURL, jenkinsClient, credentialsSecret)
+	}
+	return jenkinsClient, nil
+}
+
+// ensureOperatorJobs deletes jobs created by operator under names which differ from the current ones because
+// Jenkins.Spec.JobNamePrefix has changed and saves current names in Jenkins.Status.Jobs, jobs are created
+// under new names by the base and the user configuration
+func (r *ReconcileJenkinsBaseConfiguration) ensureOperatorJobs(jenkinsClient jenkinsclient.Jenkins) error {
+	operatorJobs := resources.NewOperatorJobs(r.jenkins)
+	previousJobs := r.jenkins.Status.Jobs
+	if previousJobs == nil && r.jenkins.Status.BaseConfigurationCompletedTime != nil {
+		// jobs of Jenkins configured before names were recorded in status have default names
+		previousJobs = resources.NewOperatorJobs(&v1alpha1.Jenkins{})
+	}
+
+	if previousJobs != nil {
+		for _, names := range [][2]string{
+			{previousJobs.BaseConfiguration, operatorJobs.BaseConfiguration},
+			{previousJobs.UserConfiguration, operatorJobs.UserConfiguration},
+			{previousJobs.ConfigureSeedJobs, operatorJobs.ConfigureSeedJobs},
+			{previousJobs.DeleteSeedJobs, operatorJobs.DeleteSeedJobs},
+		} {
+			previous, current := names[0], names[1]
+			if len(previous) == 0 || previous == current {
+				continue
+			}
+			if err := r.deleteJob(jenkinsClient, previous); err != nil {
+				return err
+			}
+		}
+	}
+
+	if reflect.DeepEqual(operatorJobs, r.jenkins.Status.Jobs) {
+		return nil
+	}
+	r.jenkins.Status.Jobs = operatorJobs
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// deleteJob deletes the job from Jenkins if it exists
+func (r *ReconcileJenkinsBaseConfiguration) deleteJob(jenkinsClient jenkinsclient.Jenkins, name string) error {
+	_, err := jenkinsClient.GetJob(name)
+	if err != nil && err.Error() == jobs.ErrorNotFound.Error() {
+		return nil
+	}
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+
+	if _, err = jenkinsClient.DeleteJob(name); err != nil {
+		return stackerr.WithStack(err)
+	}
+	r.logger.Info(fmt.Sprintf("'%s' job has been deleted", name))
+	return nil
 }
 
 func (r *ReconcileJenkinsBaseConfiguration) ensureBaseConfiguration(jenkinsClient jenkinsclient.Jenkins) (reconcile.Result, error) {
-	groovyClient := groovy.New(jenkinsClient, r.k8sClient, r.logger, fmt.Sprintf("%s-base-configuration", constants.OperatorName), resources.JenkinsBaseConfigurationVolumePath)
+	groovyClient := groovy.New(jenkinsClient, r.k8sClient, r.logger, r.events, resources.GetJobName(r.jenkins, constants.BaseConfigurationJobName),
+		resources.JenkinsBaseConfigurationVolumePath)
 
-	err := groovyClient.ConfigureGroovyJob()
+	err := groovyClient.ConfigureGroovyJob(constants.DefaultBuildRetention)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const configureAdministrativeMonitorsFileName = "14-configure-administrative-monitors.groovy"
+
+// configureAdministrativeMonitorsFmt disables administrative monitors which IDs are passed as the base64 encoded
+// JSON list, monitors unknown to Jenkins are skipped, other monitors are left untouched
+const configureAdministrativeMonitorsFmt = `
+import hudson.model.AdministrativeMonitor
+
+def ids = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+
+AdministrativeMonitor.all().each { monitor ->
+    if (ids.contains(monitor.id) && monitor.isEnabled()) {
+        monitor.disable(true)
+        println("Administrative monitor ${monitor.id} disabled.")
+    }
+}
+`
+
+func buildConfigureAdministrativeMonitorsGroovyScript(jenkins *v1alpha1.Jenkins) (string, error) {
+	ids := append([]string{}, jenkins.Spec.Master.DisabledAdministrativeMonitors...)
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	return fmt.Sprintf(configureAdministrativeMonitorsFmt, base64.StdEncoding.EncodeToString(data)), nil
+}
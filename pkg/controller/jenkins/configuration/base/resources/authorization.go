@@ -0,0 +1,155 @@
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+)
+
+const (
+	configureAuthorizationFileName = "12-configure-authorization.groovy"
+
+	// AdministerPermission is the permission operator users always keep in the authorization strategy
+	AdministerPermission = "Overall/Administer"
+	// OperatorRoleName is the name of the global role of operator users in the role-based authorization strategy
+	OperatorRoleName = "jenkins-operator"
+)
+
+// KnownPermissions contains permissions of Jenkins core and plugins installed by operator which can be granted
+// by Jenkins.Spec.Security.Authorization
+var KnownPermissions = map[string]struct{}{
+	"Overall/Administer":            {},
+	"Overall/ConfigureUpdateCenter": {},
+	"Overall/Read":                  {},
+	"Overall/RunScripts":            {},
+	"Overall/UploadPlugins":         {},
+	"Credentials/Create":            {},
+	"Credentials/Delete":            {},
+	"Credentials/ManageDomains":     {},
+	"Credentials/Update":            {},
+	"Credentials/View":              {},
+	"Agent/Build":                   {},
+	"Agent/Configure":               {},
+	"Agent/Connect":                 {},
+	"Agent/Create":                  {},
+	"Agent/Delete":                  {},
+	"Agent/Disconnect":              {},
+	"Agent/Provision":               {},
+	"Job/Build":                     {},
+	"Job/Cancel":                    {},
+	"Job/Configure":                 {},
+	"Job/Create":                    {},
+	"Job/Delete":                    {},
+	"Job/Discover":                  {},
+	"Job/ExtendedRead":              {},
+	"Job/Move":                      {},
+	"Job/Read":                      {},
+	"Job/Workspace":                 {},
+	"Run/Delete":                    {},
+	"Run/Replay":                    {},
+	"Run/Update":                    {},
+	"View/Configure":                {},
+	"View/Create":                   {},
+	"View/Delete":                   {},
+	"View/Read":                     {},
+	"SCM/Tag":                       {},
+	"Lockable Resources/Reserve":    {},
+	"Lockable Resources/Unlock":     {},
+	"Lockable Resources/View":       {},
+}
+
+// configureAuthorizationFmt configures the matrix or the role-based authorization strategy from the base64 encoded JSON
+// settings built by authorizationSettings, the admin user of operator and the service account user are always granted
+// Overall/Administer, the previous strategy is saved in JENKINS_HOME when the strategy is configured for the first time
+// and restored when the settings are empty
+const configureAuthorizationFmt = `
+import hudson.security.Permission
+import jenkins.model.Jenkins
+
+def jenkins = Jenkins.getInstance()
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def previousStrategyFile = new File(jenkins.getRootDir(), 'jenkins-operator-previous-authorization-strategy.xml')
+
+if (!settings.matrix && !settings.roleStrategy) {
+    if (previousStrategyFile.exists()) {
+        jenkins.setAuthorizationStrategy(Jenkins.XSTREAM2.fromXML(previousStrategyFile))
+        jenkins.save()
+        previousStrategyFile.delete()
+        println('Previous authorization strategy restored.')
+    }
+    return
+}
+
+if (!previousStrategyFile.exists()) {
+    previousStrategyFile.text = Jenkins.XSTREAM2.toXML(jenkins.getAuthorizationStrategy())
+}
+
+def permissions = Permission.getAll().collectEntries { [(it.group.title.toString() + '/' + it.name): it] }
+def operatorUsers = [new File(settings.operatorUserNameFile).text.trim(), settings.operatorServiceAccountUserName]
+
+def classLoader = jenkins.getPluginManager().uberClassLoader
+def strategy
+if (settings.matrix) {
+    strategy = classLoader.loadClass('hudson.security.GlobalMatrixAuthorizationStrategy').newInstance()
+    settings.matrix.each { entry ->
+        entry.permissions.each { strategy.add(permissions[it], entry.name) }
+    }
+    operatorUsers.each { strategy.add(Jenkins.ADMINISTER, it) }
+    println('Matrix authorization strategy configured.')
+} else {
+    def strategyClass = classLoader.loadClass('com.michelin.cio.hudson.plugins.rolestrategy.RoleBasedAuthorizationStrategy')
+    def roleClass = classLoader.loadClass('com.michelin.cio.hudson.plugins.rolestrategy.Role')
+    strategy = strategyClass.newInstance()
+    def addRole = { type, role ->
+        def instance = roleClass.newInstance(role.name, role.pattern ?: '.*', role.permissions.collect { permissions[it] } as Set)
+        strategy.addRole(type, instance)
+        role.assignments.each { strategy.assignRole(type, instance, it) }
+    }
+    settings.roleStrategy.globalRoles.each { addRole(strategyClass.GLOBAL, it) }
+    settings.roleStrategy.itemRoles.each { addRole(strategyClass.PROJECT, it) }
+    addRole(strategyClass.GLOBAL, [name: settings.operatorRoleName, permissions: ['` + AdministerPermission + `'], assignments: operatorUsers])
+    println('Role-based authorization strategy configured.')
+}
+jenkins.setAuthorizationStrategy(strategy)
+jenkins.save()
+`
+
+// authorizationSettings defines Jenkins.Spec.Security.Authorization passed to configureAuthorizationFmt
+type authorizationSettings struct {
+	Matrix                         []v1alpha1.MatrixPermissions `json:"matrix,omitempty"`
+	RoleStrategy                   *roleStrategySettings        `json:"roleStrategy,omitempty"`
+	OperatorUserNameFile           string                       `json:"operatorUserNameFile"`
+	OperatorServiceAccountUserName string                       `json:"operatorServiceAccountUserName"`
+	OperatorRoleName               string                       `json:"operatorRoleName"`
+}
+
+type roleStrategySettings struct {
+	GlobalRoles []v1alpha1.Role `json:"globalRoles"`
+	ItemRoles   []v1alpha1.Role `json:"itemRoles"`
+}
+
+func buildConfigureAuthorizationGroovyScript(jenkins *v1alpha1.Jenkins) (string, error) {
+	settings := authorizationSettings{
+		OperatorUserNameFile:           fmt.Sprintf("%s/%s", jenkinsOperatorCredentialsVolumePath, OperatorCredentialsSecretUserNameKey),
+		OperatorServiceAccountUserName: OperatorServiceAccountUserName,
+		OperatorRoleName:               OperatorRoleName,
+	}
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.Authorization != nil {
+		authorization := jenkins.Spec.Security.Authorization
+		settings.Matrix = authorization.Matrix
+		if authorization.RoleStrategy != nil {
+			settings.RoleStrategy = &roleStrategySettings{
+				GlobalRoles: authorization.RoleStrategy.GlobalRoles,
+				ItemRoles:   authorization.RoleStrategy.ItemRoles,
+			}
+		}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(configureAuthorizationFmt, base64.StdEncoding.EncodeToString(data)), nil
+}
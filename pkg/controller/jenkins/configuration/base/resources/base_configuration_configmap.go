@@ -2,6 +2,9 @@ package resources
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"text/template"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
@@ -10,13 +13,26 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	basicSettingsFileName = "1-basic-settings.groovy"
+	// baseConfigurationExtraScriptPrefix prefixes keys of scripts appended to base configuration scripts by overrides,
+	// the job applies scripts sorted by their names so they are applied after base configuration scripts
+	baseConfigurationExtraScriptPrefix = "99-"
+)
+
+// requiredBaseConfigurationScripts can't be replaced by overrides, operator jobs can't run without them
+var requiredBaseConfigurationScripts = []string{basicSettingsFileName, configureSeedAgentFileName}
+
+// securityRealmRegexp matches groovy statements which replace the security realm with the operator user
+var securityRealmRegexp = regexp.MustCompile(`setSecurityRealm\s*\(|\.securityRealm\s*=`)
+
 const basicSettingsFmt = `
 import jenkins.model.Jenkins
 import jenkins.model.JenkinsLocationConfiguration
 import hudson.model.Node.Mode
 
 def jenkins = Jenkins.instance
-//Number of jobs that run simultaneously on master, currently only SeedJob.
+//Number of jobs that run simultaneously on master, operator jobs and jobs tied to master
 jenkins.setNumExecutors(%d)
 //Jobs must specify that they want to run on master
 jenkins.setMode(Mode.EXCLUSIVE)
@@ -39,17 +55,20 @@ if (jenkins.getCrumbIssuer() == null) {
 }
 `
 
-const disableUsageStats = `
+// configureUsageStatsFmt disables or enables submitting usage stats to the Jenkins project
+// according to Jenkins.Spec.Master.DisableUsageStats
+const configureUsageStatsFmt = `
 import jenkins.model.Jenkins
 
 def jenkins = Jenkins.instance
+def disabled = %t
 
-if (jenkins.isUsageStatisticsCollected()) {
-    jenkins.setNoUsageStatistics(true)
+if (jenkins.isUsageStatisticsCollected() == disabled) {
+    jenkins.setNoUsageStatistics(disabled)
     jenkins.save()
-    println('Jenkins usage stats submitting disabled.')
+    println(disabled ? 'Jenkins usage stats submitting disabled.' : 'Jenkins usage stats submitting enabled.')
 } else {
-    println('Nothing changed.  Usage stats are not submitted to the Jenkins project.')
+    println('Nothing changed.')
 }
 `
 
@@ -157,27 +176,216 @@ if (jenkins.getView(jenkinsViewName) == null) {
 jenkins.save()
 `
 
+const configureSeedAgentFileName = "8-configure-seed-agent.groovy"
+
+var configureSeedAgentGroovyTemplate = template.Must(template.New(configureSeedAgentFileName).Parse(`
+import jenkins.model.Jenkins
+import org.csanchez.jenkins.plugins.kubernetes.ContainerTemplate
+import org.csanchez.jenkins.plugins.kubernetes.KubernetesCloud
+import org.csanchez.jenkins.plugins.kubernetes.PodImagePullSecret
+import org.csanchez.jenkins.plugins.kubernetes.PodTemplate
+import org.csanchez.jenkins.plugins.kubernetes.model.KeyValueEnvVar
+import org.csanchez.jenkins.plugins.kubernetes.model.SecretEnvVar
+
+def seedAgentName = '{{ .Name }}'
+def jenkins = Jenkins.getInstance()
+
+KubernetesCloud kubernetes = jenkins.clouds.getByName("kubernetes")
+kubernetes.getTemplates().removeAll { it.getName() == seedAgentName }
+{{- if .Enabled }}
+
+ContainerTemplate jnlp = new ContainerTemplate("jnlp", '{{ .Image }}')
+jnlp.setResourceRequestCpu('{{ .RequestCPU }}')
+jnlp.setResourceRequestMemory('{{ .RequestMemory }}')
+jnlp.setResourceLimitCpu('{{ .LimitCPU }}')
+jnlp.setResourceLimitMemory('{{ .LimitMemory }}')
+
+PodTemplate seedAgent = new PodTemplate()
+seedAgent.setName(seedAgentName)
+seedAgent.setLabel(seedAgentName)
+seedAgent.setServiceAccount('{{ .ServiceAccountName }}')
+seedAgent.setContainers([jnlp])
+seedAgent.setImagePullSecrets([
+{{- range $index, $secret := .ImagePullSecrets }}
+{{- if $index }},{{ end }}
+        new PodImagePullSecret('{{ $secret }}')
+{{- end }}
+])
+seedAgent.setEnvVars([
+{{- range $index, $env := .EnvVars }}
+{{- if $index }},{{ end }}
+{{- if $env.ValueFrom }}
+        new SecretEnvVar('{{ $env.Name }}', '{{ $env.ValueFrom.SecretKeyRef.Name }}', '{{ $env.ValueFrom.SecretKeyRef.Key }}')
+{{- else }}
+        new KeyValueEnvVar('{{ $env.Name }}', '{{ $env.Value }}')
+{{- end }}
+{{- end }}
+])
+kubernetes.addTemplate(seedAgent)
+{{- end }}
+
+jenkins.save()
+`))
+
+func buildConfigureSeedAgentGroovyScript(jenkins *v1alpha1.Jenkins) (*string, error) {
+	seedAgent := jenkins.Spec.SeedAgent
+	data := struct {
+		Name               string
+		Enabled            bool
+		Image              string
+		ServiceAccountName string
+		ImagePullSecrets   []string
+		EnvVars            []corev1.EnvVar
+		RequestCPU         string
+		RequestMemory      string
+		LimitCPU           string
+		LimitMemory        string
+	}{
+		Name:               constants.SeedAgentLabel,
+		Enabled:            seedAgent.Enabled,
+		Image:              seedAgent.Image,
+		ServiceAccountName: seedAgent.ServiceAccountName,
+		EnvVars:            NewProxyEnvVars(jenkins),
+	}
+	if len(data.Image) == 0 {
+		data.Image = agentImage(jenkins)
+	}
+	for _, secret := range jenkins.Spec.Agent.ImagePullSecrets {
+		data.ImagePullSecrets = append(data.ImagePullSecrets, secret.Name)
+	}
+	if quantity, ok := seedAgent.Resources.Requests[corev1.ResourceCPU]; ok {
+		data.RequestCPU = quantity.String()
+	}
+	if quantity, ok := seedAgent.Resources.Requests[corev1.ResourceMemory]; ok {
+		data.RequestMemory = quantity.String()
+	}
+	if quantity, ok := seedAgent.Resources.Limits[corev1.ResourceCPU]; ok {
+		data.LimitCPU = quantity.String()
+	}
+	if quantity, ok := seedAgent.Resources.Limits[corev1.ResourceMemory]; ok {
+		data.LimitMemory = quantity.String()
+	}
+
+	output, err := render(configureSeedAgentGroovyTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &output, nil
+}
+
+// agentImage returns the JNLP agent docker image used by pod templates configured by operator
+func agentImage(jenkins *v1alpha1.Jenkins) string {
+	if len(jenkins.Spec.Agent.Image) > 0 {
+		return jenkins.Spec.Agent.Image
+	}
+	return constants.DefaultJenkinsAgentImage
+}
+
+// GetMasterExecutors returns the amount of Jenkins master executors, Jenkins.Spec.Master.NumExecutors
+// or the default one when it isn't set
+func GetMasterExecutors(jenkins *v1alpha1.Jenkins) int {
+	executors := constants.DefaultAmountOfExecutors
+	if jenkins.Spec.Master.NumExecutors != nil {
+		executors = int(*jenkins.Spec.Master.NumExecutors)
+	}
+	if jenkins.Spec.SeedAgent.Enabled && jenkins.Spec.SeedAgent.RestrictMasterExecutors && executors > 1 {
+		// operator configuration jobs read groovy scripts mounted in Jenkins master pod so they have to run on master
+		return 1
+	}
+	return executors
+}
+
+// IsUsageStatsDisabled returns whether submitting usage stats to the Jenkins project is disabled, it's disabled
+// unless Jenkins.Spec.Master.DisableUsageStats is false
+func IsUsageStatsDisabled(jenkins *v1alpha1.Jenkins) bool {
+	return jenkins.Spec.Master.DisableUsageStats == nil || *jenkins.Spec.Master.DisableUsageStats
+}
+
 // GetBaseConfigurationConfigMapName returns name of Kubernetes config map used to base configuration
 func GetBaseConfigurationConfigMapName(jenkins *v1alpha1.Jenkins) string {
 	return fmt.Sprintf("%s-base-configuration-%s", constants.OperatorName, jenkins.ObjectMeta.Name)
 }
 
 // NewBaseConfigurationConfigMap builds Kubernetes config map used to base configuration
-func NewBaseConfigurationConfigMap(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) *corev1.ConfigMap {
+func NewBaseConfigurationConfigMap(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) (*corev1.ConfigMap, error) {
 	meta.Name = GetBaseConfigurationConfigMapName(jenkins)
 
+	configureSeedAgent, err := buildConfigureSeedAgentGroovyScript(jenkins)
+	if err != nil {
+		return nil, err
+	}
+	configureSecurityRealm, err := buildConfigureSecurityRealmGroovyScript(jenkins)
+	if err != nil {
+		return nil, err
+	}
+	configureAuthorization, err := buildConfigureAuthorizationGroovyScript(jenkins)
+	if err != nil {
+		return nil, err
+	}
+	configureMailer, err := buildConfigureMailerGroovyScript(jenkins)
+	if err != nil {
+		return nil, err
+	}
+	configureAdministrativeMonitors, err := buildConfigureAdministrativeMonitorsGroovyScript(jenkins)
+	if err != nil {
+		return nil, err
+	}
+
 	return &corev1.ConfigMap{
 		TypeMeta:   buildConfigMapTypeMeta(),
 		ObjectMeta: meta,
 		Data: map[string]string{
-			"1-basic-settings.groovy":               fmt.Sprintf(basicSettingsFmt, constants.DefaultAmountOfExecutors),
+			basicSettingsFileName:                   fmt.Sprintf(basicSettingsFmt, GetMasterExecutors(jenkins)),
 			"2-enable-csrf.groovy":                  enableCSRF,
-			"3-disable-usage-stats.groovy":          disableUsageStats,
+			"3-disable-usage-stats.groovy":          fmt.Sprintf(configureUsageStatsFmt, IsUsageStatsDisabled(jenkins)),
 			"4-enable-master-access-control.groovy": enableMasterAccessControl,
 			"5-disable-insecure-features.groovy":    disableInsecureFeatures,
 			"6-configure-kubernetes-plugin.groovy": fmt.Sprintf(configureKubernetesPluginFmt,
 				jenkins.ObjectMeta.Namespace, GetResourceName(jenkins), HTTPPortInt),
-			"7-configure-views.groovy": configureViews,
+			"7-configure-views.groovy":              configureViews,
+			configureSeedAgentFileName:              *configureSeedAgent,
+			configureProxyFileName:                  configureProxy,
+			configureUpdateCenterFileName:           configureUpdateCenter,
+			configureSecurityRealmFileName:          configureSecurityRealm,
+			configureAuthorizationFileName:          configureAuthorization,
+			configureMailerFileName:                 configureMailer,
+			configureAdministrativeMonitorsFileName: configureAdministrativeMonitors,
 		},
+	}, nil
+}
+
+// ValidateBaseConfigurationOverride returns an error when the override would remove the functional minimum operator
+// requires, scripts required by operator jobs can't be replaced and the security realm with the operator user can't be changed
+func ValidateBaseConfigurationOverride(key, script string) error {
+	for _, required := range requiredBaseConfigurationScripts {
+		if key == required {
+			return fmt.Errorf("script '%s' is required by operator and can't be overridden", key)
+		}
+	}
+	if securityRealmRegexp.MatchString(script) {
+		return fmt.Errorf("script '%s' can't change the security realm, operator authenticates as the user of the Jenkins' own user database", key)
+	}
+	return nil
+}
+
+// OverrideBaseConfiguration replaces base configuration scripts of the ConfigMap with same-named overrides and appends
+// other overrides after base configuration scripts, returns sorted keys of replaced and appended scripts
+func OverrideBaseConfiguration(configMap *corev1.ConfigMap, overrides map[string]string) (replaced, appended []string) {
+	base := map[string]bool{}
+	for key := range configMap.Data {
+		base[key] = true
+	}
+	for key, script := range overrides {
+		if base[key] {
+			replaced = append(replaced, key)
+			configMap.Data[key] = script
+		} else {
+			appended = append(appended, key)
+			configMap.Data[baseConfigurationExtraScriptPrefix+key] = script
+		}
 	}
+	sort.Strings(replaced)
+	sort.Strings(appended)
+	return replaced, appended
 }
@@ -0,0 +1,56 @@
+package resources
+
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ConfigurationAsCodeVolumeName is a name of the volume with JCasC YAML files
+	ConfigurationAsCodeVolumeName = "casc"
+	// ConfigurationAsCodeVolumePath is a path where are JCasC YAML files provided by user
+	ConfigurationAsCodeVolumePath = "/var/jenkins/casc"
+
+	configurationAsCodeEnvName = "CASC_JENKINS_CONFIG"
+)
+
+// NewConfigurationAsCodeVolume builds the volume with all ConfigMaps and Secrets referenced by Jenkins.Spec.ConfigurationAsCode,
+// the projected volume keeps all YAML files in one directory read by the configuration-as-code plugin
+func NewConfigurationAsCodeVolume(jenkins *v1alpha1.Jenkins) corev1.Volume {
+	var sources []corev1.VolumeProjection
+	for _, configMap := range jenkins.Spec.ConfigurationAsCode.ConfigMaps {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: configMap},
+		})
+	}
+	for _, secret := range jenkins.Spec.ConfigurationAsCode.Secrets {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{LocalObjectReference: secret},
+		})
+	}
+
+	return corev1.Volume{
+		Name: ConfigurationAsCodeVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
+}
+
+func addConfigurationAsCode(pod *corev1.Pod, jenkins *v1alpha1.Jenkins) {
+	if jenkins.Spec.ConfigurationAsCode == nil {
+		return
+	}
+
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  configurationAsCodeEnvName,
+		Value: ConfigurationAsCodeVolumePath,
+	})
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      ConfigurationAsCodeVolumeName,
+		MountPath: ConfigurationAsCodeVolumePath,
+		ReadOnly:  true,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, NewConfigurationAsCodeVolume(jenkins))
+}
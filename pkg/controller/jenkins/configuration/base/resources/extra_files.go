@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ExtraFilesVolumeName is a name of the volume with files copied into Jenkins home
+	ExtraFilesVolumeName = "extra-files"
+	extraFilesVolumePath = "/var/jenkins/extra-files"
+)
+
+// NewExtraFilesVolume builds the volume with ConfigMap and Secret keys referenced by Jenkins.Spec.Master.ExtraFiles,
+// every key is projected under its path relative to Jenkins home so the init script copies it to the same path
+func NewExtraFilesVolume(jenkins *v1alpha1.Jenkins) corev1.Volume {
+	var sources []corev1.VolumeProjection
+	for _, extraFile := range jenkins.Spec.Master.ExtraFiles {
+		if extraFile.ConfigMapKeyRef != nil {
+			sources = append(sources, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: extraFile.ConfigMapKeyRef.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: extraFile.ConfigMapKeyRef.Key, Path: extraFile.Path}},
+				},
+			})
+		} else if extraFile.SecretKeyRef != nil {
+			sources = append(sources, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: extraFile.SecretKeyRef.LocalObjectReference,
+					Items:                []corev1.KeyToPath{{Key: extraFile.SecretKeyRef.Key, Path: extraFile.Path}},
+				},
+			})
+		}
+	}
+
+	return corev1.Volume{
+		Name: ExtraFilesVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
+}
+
+// getExtraFilePaths returns paths of files copied into Jenkins home by the init script
+func getExtraFilePaths(jenkins *v1alpha1.Jenkins) []string {
+	var paths []string
+	for _, extraFile := range jenkins.Spec.Master.ExtraFiles {
+		paths = append(paths, extraFile.Path)
+	}
+	return paths
+}
+
+func addExtraFiles(pod *corev1.Pod, jenkins *v1alpha1.Jenkins) {
+	if len(jenkins.Spec.Master.ExtraFiles) == 0 {
+		return
+	}
+
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      ExtraFilesVolumeName,
+		MountPath: extraFilesVolumePath,
+		ReadOnly:  true,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, NewExtraFilesVolume(jenkins))
+}
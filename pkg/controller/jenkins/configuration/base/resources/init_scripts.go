@@ -0,0 +1,75 @@
+package resources
+
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// InitScriptsVolumeName is a name of the volume with init.groovy.d scripts provided by user
+	InitScriptsVolumeName = "init-scripts"
+	initScriptsVolumePath = "/var/jenkins/init-scripts"
+	// userInitScriptPrefix is the prefix of user init scripts copied into init.groovy.d, Jenkins runs init scripts
+	// sorted by name so they run after createOperatorUser.groovy
+	userInitScriptPrefix = "user-"
+
+	initScriptsChecksumEnvName = "JENKINS_OPERATOR_INIT_SCRIPTS_CHECKSUM"
+)
+
+// NewInitScriptsVolume builds the volume with all ConfigMaps and Secrets referenced by Jenkins.Spec.Master.InitScripts,
+// the projected volume keeps all scripts in one directory
+func NewInitScriptsVolume(jenkins *v1alpha1.Jenkins) corev1.Volume {
+	var sources []corev1.VolumeProjection
+	for _, configMap := range jenkins.Spec.Master.InitScripts.ConfigMaps {
+		sources = append(sources, corev1.VolumeProjection{
+			ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: configMap},
+		})
+	}
+	for _, secret := range jenkins.Spec.Master.InitScripts.Secrets {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{LocalObjectReference: secret},
+		})
+	}
+
+	return corev1.Volume{
+		Name: InitScriptsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+		},
+	}
+}
+
+// SetInitScriptsChecksum sets the checksum of init scripts the pod has been started with
+func SetInitScriptsChecksum(pod *corev1.Pod, checksum string) {
+	if len(checksum) == 0 {
+		return
+	}
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  initScriptsChecksumEnvName,
+		Value: checksum,
+	})
+}
+
+// GetInitScriptsChecksum returns the checksum of init scripts the pod has been started with
+func GetInitScriptsChecksum(pod *corev1.Pod) string {
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if envVar.Name == initScriptsChecksumEnvName {
+			return envVar.Value
+		}
+	}
+	return ""
+}
+
+func addInitScripts(pod *corev1.Pod, jenkins *v1alpha1.Jenkins) {
+	if jenkins.Spec.Master.InitScripts == nil {
+		return
+	}
+
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+		Name:      InitScriptsVolumeName,
+		MountPath: initScriptsVolumePath,
+		ReadOnly:  true,
+	})
+	pod.Spec.Volumes = append(pod.Spec.Volumes, NewInitScriptsVolume(jenkins))
+}
@@ -0,0 +1,21 @@
+package resources
+
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+)
+
+// GetJobName returns the name of the job created by operator prefixed with Jenkins.Spec.JobNamePrefix
+func GetJobName(jenkins *v1alpha1.Jenkins, name string) string {
+	return jenkins.Spec.JobNamePrefix + name
+}
+
+// NewOperatorJobs returns names of all jobs created by operator for Jenkins CR
+func NewOperatorJobs(jenkins *v1alpha1.Jenkins) *v1alpha1.OperatorJobs {
+	return &v1alpha1.OperatorJobs{
+		BaseConfiguration: GetJobName(jenkins, constants.BaseConfigurationJobName),
+		UserConfiguration: GetJobName(jenkins, constants.UserConfigurationJobName),
+		ConfigureSeedJobs: GetJobName(jenkins, constants.ConfigureSeedJobsName),
+		DeleteSeedJobs:    GetJobName(jenkins, constants.DeleteSeedJobsName),
+	}
+}
@@ -0,0 +1,124 @@
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SMTPUsernameKey is the key of the username in the SMTP credentials secret
+	SMTPUsernameKey = "username"
+	// SMTPPasswordKey is the key of the password in the SMTP credentials secret
+	SMTPPasswordKey = "password"
+
+	smtpUsernameEnvName = "SMTP_USERNAME"
+	smtpPasswordEnvName = "SMTP_PASSWORD"
+
+	configureMailerFileName = "13-configure-mailer.groovy"
+)
+
+// SMTPEnvVarNames contains names of all environment variables which can be built by NewSMTPEnvVars
+var SMTPEnvVarNames = map[string]struct{}{
+	smtpUsernameEnvName: {},
+	smtpPasswordEnvName: {},
+}
+
+// configureMailerFmt configures the SMTP server of the mailer plugin and the default sender from the base64 encoded
+// JSON settings built by mailerSettings, the mailer configuration is left untouched when the settings are empty,
+// STARTTLS is enabled by the JavaMail system property read by sessions of the mailer plugin
+const configureMailerFmt = `
+import hudson.tasks.Mailer
+import jenkins.model.JenkinsLocationConfiguration
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+if (!settings.smtp) {
+    return
+}
+
+def smtp = settings.smtp
+def descriptor = Mailer.descriptor()
+descriptor.setSmtpHost(smtp.host)
+descriptor.setSmtpPort(smtp.port ? smtp.port.toString() : null)
+descriptor.setUseSsl(smtp.ssl)
+def username = System.getenv('` + smtpUsernameEnvName + `')
+if (username) {
+    descriptor.setSmtpAuth(username, System.getenv('` + smtpPasswordEnvName + `'))
+} else {
+    descriptor.setSmtpAuth(null, null)
+}
+descriptor.setReplyToAddress(smtp.replyTo ?: '')
+descriptor.save()
+
+if (smtp.startTLS) {
+    System.setProperty('mail.smtp.starttls.enable', 'true')
+} else {
+    System.clearProperty('mail.smtp.starttls.enable')
+}
+
+if (smtp.defaultSender) {
+    def location = JenkinsLocationConfiguration.get()
+    location.setAdminAddress(smtp.defaultSender)
+    location.save()
+}
+println('SMTP server configured.')
+`
+
+// mailerSettings defines the SMTP server passed to configureMailerFmt
+type mailerSettings struct {
+	SMTP *smtpSettings `json:"smtp"`
+}
+
+type smtpSettings struct {
+	Host          string `json:"host"`
+	Port          int32  `json:"port,omitempty"`
+	SSL           bool   `json:"ssl"`
+	StartTLS      bool   `json:"startTLS"`
+	DefaultSender string `json:"defaultSender"`
+	ReplyTo       string `json:"replyTo"`
+}
+
+// GetSMTP returns the SMTP server defined in Jenkins.Spec.Notifications, it's nil when the SMTP server isn't configured
+func GetSMTP(jenkins *v1alpha1.Jenkins) *v1alpha1.SMTP {
+	if jenkins.Spec.Notifications == nil {
+		return nil
+	}
+	return jenkins.Spec.Notifications.SMTP
+}
+
+func buildConfigureMailerGroovyScript(jenkins *v1alpha1.Jenkins) (string, error) {
+	settings := mailerSettings{}
+	if smtp := GetSMTP(jenkins); smtp != nil {
+		settings.SMTP = &smtpSettings{
+			Host:          smtp.Host,
+			Port:          smtp.Port,
+			SSL:           smtp.SSL,
+			StartTLS:      smtp.StartTLS,
+			DefaultSender: smtp.DefaultSender,
+			ReplyTo:       smtp.ReplyTo,
+		}
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(configureMailerFmt, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// NewSMTPEnvVars builds environment variables of the SMTP server defined in Jenkins.Spec.Notifications for Jenkins master,
+// SMTP credentials are read from the secret by Kubernetes
+func NewSMTPEnvVars(jenkins *v1alpha1.Jenkins) []corev1.EnvVar {
+	smtp := GetSMTP(jenkins)
+	if smtp == nil || smtp.CredentialsSecretRef == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		newSecretEnvVar(smtpUsernameEnvName, smtp.CredentialsSecretRef.Name, SMTPUsernameKey),
+		newSecretEnvVar(smtpPasswordEnvName, smtp.CredentialsSecretRef.Name, SMTPPasswordKey),
+	}
+}
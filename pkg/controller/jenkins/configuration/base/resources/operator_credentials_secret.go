@@ -21,6 +21,13 @@ const (
 	OperatorCredentialsSecretTokenKey = "token"
 	// OperatorCredentialsSecretTokenCreationKey defines key of token creation time in operator credentials secret
 	OperatorCredentialsSecretTokenCreationKey = "tokenCreationTime"
+	// OperatorCredentialsSecretTokenUUIDKey defines key of token UUID in operator credentials secret, it's used to revoke the token
+	OperatorCredentialsSecretTokenUUIDKey = "tokenUUID"
+	// OperatorCredentialsSecretTokenUserNameKey defines key of the name of the user the token belongs to in operator credentials secret,
+	// it's set only when the token doesn't belong to the admin user, see Jenkins.Spec.Security.DisableLocalAdmin
+	OperatorCredentialsSecretTokenUserNameKey = "tokenUser"
+	// OperatorServiceAccountUserName defines username for Jenkins API calls when the admin user is disabled
+	OperatorServiceAccountUserName = "jenkins-operator-service"
 )
 
 func buildSecretTypeMeta() metav1.TypeMeta {
@@ -49,3 +56,11 @@ func NewOperatorCredentialsSecret(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenk
 		},
 	}
 }
+
+// GetOperatorTokenUserName returns name of the Jenkins user the API token from operator credentials secret belongs to
+func GetOperatorTokenUserName(secret *corev1.Secret) string {
+	if userName := secret.Data[OperatorCredentialsSecretTokenUserNameKey]; len(userName) > 0 {
+		return string(userName)
+	}
+	return string(secret.Data[OperatorCredentialsSecretUserNameKey])
+}
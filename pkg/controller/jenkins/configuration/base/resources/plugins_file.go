@@ -0,0 +1,33 @@
+package resources
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PluginsFileKey is the key of the ConfigMap referenced by Jenkins.Spec.Master.PluginsConfigMapRef with plugins
+	PluginsFileKey = "plugins.txt"
+
+	pluginsFileChecksumEnvName = "JENKINS_OPERATOR_PLUGINS_FILE_CHECKSUM"
+)
+
+// SetPluginsFileChecksum sets the checksum of plugins.txt the pod has been started with
+func SetPluginsFileChecksum(pod *corev1.Pod, checksum string) {
+	if len(checksum) == 0 {
+		return
+	}
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  pluginsFileChecksumEnvName,
+		Value: checksum,
+	})
+}
+
+// GetPluginsFileChecksum returns the checksum of plugins.txt the pod has been started with
+func GetPluginsFileChecksum(pod *corev1.Pod) string {
+	for _, envVar := range pod.Spec.Containers[0].Env {
+		if envVar.Name == pluginsFileChecksumEnvName {
+			return envVar.Value
+		}
+	}
+	return ""
+}
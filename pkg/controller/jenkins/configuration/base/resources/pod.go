@@ -28,7 +28,14 @@ const (
 	// this scripts are provided by jenkins-operator
 	JenkinsBaseConfigurationVolumePath = "/var/jenkins/base-configuration"
 
-	jenkinsUserConfigurationVolumeName = "user-configuration"
+	// AdditionalCACertsVolumeName is a name of the volume with additional CA certificates
+	AdditionalCACertsVolumeName = "additional-ca-certs"
+	additionalCACertsVolumePath = "/var/jenkins/additional-ca-certs"
+	additionalCACertsFileName   = "ca.crt"
+
+	// JenkinsUserConfigurationVolumeName is a name of the volume with groovy scripts provided by user,
+	// it's a prefix of volume names when Jenkins.Spec.Configuration.ConfigMaps is set
+	JenkinsUserConfigurationVolumeName = "user-configuration"
 	// JenkinsUserConfigurationVolumePath is a path where are groovy scripts used to configure Jenkins
 	// this scripts are provided by user
 	JenkinsUserConfigurationVolumePath = "/var/jenkins/user-configuration"
@@ -53,6 +60,48 @@ func buildPodTypeMeta() metav1.TypeMeta {
 
 // NewJenkinsMasterPod builds Jenkins Master Kubernetes Pod resource
 func NewJenkinsMasterPod(objectMeta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) *corev1.Pod {
+	pod := newJenkinsMasterPod(objectMeta, jenkins)
+	volumes, volumeMounts := newUserConfigurationVolumes(jenkins)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, volumes...)
+	pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, volumeMounts...)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, NewProxyEnvVars(jenkins)...)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, NewUpdateCenterEnvVars(jenkins)...)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, NewSecurityRealmEnvVars(jenkins)...)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, NewSMTPEnvVars(jenkins)...)
+	pod.Spec.Containers[0].Env = append(pod.Spec.Containers[0].Env, NewTimezoneEnvVars(jenkins)...)
+	if jenkins.Spec.Master.AdditionalCACerts != nil {
+		pod.Spec.Containers[0].VolumeMounts = append(pod.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      AdditionalCACertsVolumeName,
+			MountPath: additionalCACertsVolumePath,
+			ReadOnly:  true,
+		})
+		pod.Spec.Volumes = append(pod.Spec.Volumes, NewAdditionalCACertsVolume(jenkins))
+	}
+	addConfigurationAsCode(pod, jenkins)
+	addExtraFiles(pod, jenkins)
+	addInitScripts(pod, jenkins)
+	return pod
+}
+
+// NewAdditionalCACertsVolume builds the volume with CA certificates referenced by Jenkins.Spec.Master.AdditionalCACerts
+func NewAdditionalCACertsVolume(jenkins *v1alpha1.Jenkins) corev1.Volume {
+	return corev1.Volume{
+		Name: AdditionalCACertsVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: jenkins.Spec.Master.AdditionalCACerts.Name,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  jenkins.Spec.Master.AdditionalCACerts.Key,
+						Path: additionalCACertsFileName,
+					},
+				},
+			},
+		},
+	}
+}
+
+func newJenkinsMasterPod(objectMeta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) *corev1.Pod {
 	initialDelaySeconds := int32(30)
 	timeoutSeconds := int32(5)
 	failureThreshold := int32(12)
@@ -117,7 +166,7 @@ func NewJenkinsMasterPod(objectMeta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins
 						},
 						{
 							Name:  "JAVA_OPTS",
-							Value: "-XX:+UnlockExperimentalVMOptions -XX:+UseCGroupMemoryLimitForHeap -XX:MaxRAMFraction=1 -Djenkins.install.runSetupWizard=false -Djava.awt.headless=true",
+							Value: newJavaOpts(jenkins),
 						},
 					},
 					Resources: jenkins.Spec.Master.Resources,
@@ -142,11 +191,6 @@ func NewJenkinsMasterPod(objectMeta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins
 							MountPath: JenkinsBaseConfigurationVolumePath,
 							ReadOnly:  true,
 						},
-						{
-							Name:      jenkinsUserConfigurationVolumeName,
-							MountPath: JenkinsUserConfigurationVolumePath,
-							ReadOnly:  true,
-						},
 						{
 							Name:      jenkinsOperatorCredentialsVolumeName,
 							MountPath: jenkinsOperatorCredentialsVolumePath,
@@ -192,16 +236,6 @@ func NewJenkinsMasterPod(objectMeta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins
 						},
 					},
 				},
-				{
-					Name: jenkinsUserConfigurationVolumeName,
-					VolumeSource: corev1.VolumeSource{
-						ConfigMap: &corev1.ConfigMapVolumeSource{
-							LocalObjectReference: corev1.LocalObjectReference{
-								Name: GetUserConfigurationConfigMapName(jenkins),
-							},
-						},
-					},
-				},
 				{
 					Name: jenkinsOperatorCredentialsVolumeName,
 					VolumeSource: corev1.VolumeSource{
@@ -0,0 +1,135 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ProxyUsernameKey is the key of the username in the proxy credentials secret
+	ProxyUsernameKey = "username"
+	// ProxyPasswordKey is the key of the password in the proxy credentials secret
+	ProxyPasswordKey = "password"
+
+	proxyUsernameEnvName = "PROXY_USERNAME"
+	proxyPasswordEnvName = "PROXY_PASSWORD"
+
+	configureProxyFileName = "9-configure-proxy.groovy"
+)
+
+// ProxyEnvVarNames contains names of all environment variables which can be built by NewProxyEnvVars
+var ProxyEnvVarNames = map[string]struct{}{
+	proxyUsernameEnvName: {},
+	proxyPasswordEnvName: {},
+	"HTTP_PROXY":         {},
+	"http_proxy":         {},
+	"HTTPS_PROXY":        {},
+	"https_proxy":        {},
+	"NO_PROXY":           {},
+	"no_proxy":           {},
+}
+
+// configureProxy configures Jenkins proxy used by the plugin manager and the update center from the environment
+// variables of Jenkins master pod, the proxy is removed when Jenkins.Spec.Proxy isn't set
+const configureProxy = `
+import hudson.ProxyConfiguration
+import jenkins.model.Jenkins
+
+def jenkins = Jenkins.getInstance()
+
+def proxyURL = System.getenv('HTTPS_PROXY') ?: System.getenv('HTTP_PROXY')
+if (proxyURL) {
+    def proxy = new URI(proxyURL)
+    def port = proxy.getPort() > 0 ? proxy.getPort() : (proxy.getScheme() == 'https' ? 443 : 80)
+    // Jenkins expects newline separated host patterns
+    def noProxyHosts = (System.getenv('NO_PROXY') ?: '').split(',')
+            .findAll { it }
+            .collect { it.startsWith('.') ? '*' + it : it }
+            .join('\n')
+    jenkins.proxy = new ProxyConfiguration(proxy.getHost(), port,
+            System.getenv('` + proxyUsernameEnvName + `'), System.getenv('` + proxyPasswordEnvName + `'), noProxyHosts)
+    println('Proxy configured.')
+} else {
+    jenkins.proxy = null
+}
+
+jenkins.save()
+`
+
+// NewProxyEnvVars builds environment variables of the proxy defined in Jenkins.Spec.Proxy for Jenkins master and agents,
+// proxy credentials are read from the secret and expanded by Kubernetes in proxy urls
+func NewProxyEnvVars(jenkins *v1alpha1.Jenkins) []corev1.EnvVar {
+	proxy := jenkins.Spec.Proxy
+	if proxy == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	if proxy.CredentialsSecretRef != nil {
+		envVars = append(envVars,
+			newSecretEnvVar(proxyUsernameEnvName, proxy.CredentialsSecretRef.Name, ProxyUsernameKey),
+			newSecretEnvVar(proxyPasswordEnvName, proxy.CredentialsSecretRef.Name, ProxyPasswordKey))
+	}
+	// both upper and lower case variables are set because tools don't agree on one of them
+	add := func(name, value string) {
+		envVars = append(envVars,
+			corev1.EnvVar{Name: name, Value: value},
+			corev1.EnvVar{Name: strings.ToLower(name), Value: value})
+	}
+	if len(proxy.HTTPProxy) > 0 {
+		add("HTTP_PROXY", proxyURLWithCredentials(proxy, proxy.HTTPProxy))
+	}
+	if len(proxy.HTTPSProxy) > 0 {
+		add("HTTPS_PROXY", proxyURLWithCredentials(proxy, proxy.HTTPSProxy))
+	}
+	add("NO_PROXY", strings.Join(noProxyHosts(jenkins), ","))
+
+	return envVars
+}
+
+// noProxyHosts returns hosts which aren't proxied, in-cluster hosts are always included so the traffic
+// between Jenkins master, agents and the Kubernetes API doesn't leave the cluster
+func noProxyHosts(jenkins *v1alpha1.Jenkins) []string {
+	serviceName := GetResourceName(jenkins)
+	hosts := []string{
+		"localhost",
+		"127.0.0.1",
+		serviceName,
+		fmt.Sprintf("%s.%s", serviceName, jenkins.Namespace),
+		".svc",
+		".cluster.local",
+		"kubernetes.default",
+		// service environment variables are expanded by Kubernetes
+		"$(KUBERNETES_SERVICE_HOST)",
+	}
+	for _, host := range strings.Split(jenkins.Spec.Proxy.NoProxy, ",") {
+		if host = strings.TrimSpace(host); len(host) > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// proxyURLWithCredentials adds references to the proxy credentials environment variables to the proxy url
+func proxyURLWithCredentials(proxy *v1alpha1.Proxy, proxyURL string) string {
+	if proxy.CredentialsSecretRef == nil {
+		return proxyURL
+	}
+	return strings.Replace(proxyURL, "://", fmt.Sprintf("://$(%s):$(%s)@", proxyUsernameEnvName, proxyPasswordEnvName), 1)
+}
+
+func newSecretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
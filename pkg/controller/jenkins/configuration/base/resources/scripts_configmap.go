@@ -6,12 +6,21 @@ import (
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const installPluginsCommand = "install-plugins.sh"
+const (
+	installPluginsCommand = "install-plugins.sh"
+	// PluginChecksumMismatchPrefix precedes the name of the plugin which doesn't match the declared checksum
+	// in the termination message of Jenkins master container
+	PluginChecksumMismatchPrefix = "Checksum mismatch: "
+	// PluginDownloadFailurePrefix precedes the name of the plugin which can't be downloaded
+	// in the termination message of Jenkins master container
+	PluginDownloadFailurePrefix = "Not downloaded: "
+)
 
 // bash scripts installs single jenkins plugin with specific version
 const installPluginsBashFmt = `#!/bin/bash -eu
@@ -25,6 +34,11 @@ set -o pipefail
 
 REF_DIR=${REF:-%s/plugins}
 FAILED="$REF_DIR/failed-plugins.txt"
+# plugins which don't match the declared checksum are reported in the termination message of the container
+TERMINATION_LOG="%s"
+# failed downloads are retried with the delay doubled after every attempt
+PLUGIN_DOWNLOAD_ATTEMPTS=%d
+PLUGIN_DOWNLOAD_RETRY_DELAY=%d
 
 . /usr/local/bin/jenkins-support
 
@@ -37,21 +51,23 @@ getArchiveFilename() {
 }
 
 download() {
-    local plugin originalPlugin version lock ignoreLockFile
+    local plugin originalPlugin version lock ignoreLockFile checksum url
     plugin="$1"
     version="${2:-latest}"
     ignoreLockFile="${3:-}"
+    checksum="${4:-}"
+    url="${5:-}"
     lock="$(getLockFile "$plugin")"
 
     if [[ $ignoreLockFile ]] || mkdir "$lock" &>/dev/null; then
-        if ! doDownload "$plugin" "$version"; then
+        if ! doDownload "$plugin" "$version" "$url"; then
             # some plugin don't follow the rules about artifact ID
             # typically: docker-plugin
             originalPlugin="$plugin"
             plugin="${plugin}-plugin"
-            if ! doDownload "$plugin" "$version"; then
+            if [[ $url ]] || ! doDownload "$plugin" "$version"; then
                 echo "Failed to download plugin: $originalPlugin or $plugin" >&2
-                echo "Not downloaded: ${originalPlugin}" >> "$FAILED"
+                echo "` + PluginDownloadFailurePrefix + `${originalPlugin}" >> "$FAILED"
                 return 1
             fi
         fi
@@ -62,6 +78,12 @@ download() {
             return 1
         fi
 
+        if [[ $checksum ]] && ! checkChecksum "$plugin" "$checksum"; then
+            echo "Downloaded file doesn't match the checksum ${checksum}: $(getArchiveFilename "$plugin")" >&2
+            echo "` + PluginChecksumMismatchPrefix + `${originalPlugin:-$plugin}" >> "$FAILED"
+            return 1
+        fi
+
     fi
 }
 
@@ -69,6 +91,7 @@ doDownload() {
     local plugin version url jpi
     plugin="$1"
     version="$2"
+    url="${3:-}"
     jpi="$(getArchiveFilename "$plugin")"
 
     # If plugin already exists and is the same version do not download
@@ -77,7 +100,10 @@ doDownload() {
         return 0
     fi
 
-    if [[ "$version" == "latest" && -n "$JENKINS_UC_LATEST" ]]; then
+    if [[ $url ]]; then
+        # The plugin archive is downloaded from the URL given on the command line
+        :
+    elif [[ "$version" == "latest" && -n "$JENKINS_UC_LATEST" ]]; then
         # If version-specific Update Center is available, which is the case for LTS versions,
         # use it to resolve latest versions.
         url="$JENKINS_UC_LATEST/latest/${plugin}.hpi"
@@ -98,8 +124,20 @@ doDownload() {
     fi
 
     echo "Downloading plugin: $plugin from $url"
-    retry_command curl "${CURL_OPTIONS:--sSfL}" --connect-timeout "${CURL_CONNECTION_TIMEOUT:-20}" --retry "${CURL_RETRY:-5}" --retry-delay "${CURL_RETRY_DELAY:-0}" --retry-max-time "${CURL_RETRY_MAX_TIME:-60}" "$url" -o "$jpi"
-    return $?
+    local attempt delay status
+    attempt=1
+    delay=$PLUGIN_DOWNLOAD_RETRY_DELAY
+    while true; do
+        status="$(curl "${CURL_OPTIONS:--sSfL}" --connect-timeout "${CURL_CONNECTION_TIMEOUT:-20}" -w '%%{http_code}' "$url" -o "$jpi")" && return 0
+        # retries won't help when the plugin doesn't exist
+        if [[ $status == 404 ]] || [[ $attempt -ge $PLUGIN_DOWNLOAD_ATTEMPTS ]]; then
+            return 1
+        fi
+        echo "Failed to download plugin: $plugin from $url (attempt ${attempt}/${PLUGIN_DOWNLOAD_ATTEMPTS}), retrying in ${delay}s" >&2
+        sleep "$delay"
+        attempt=$((attempt + 1))
+        delay=$((delay * 2))
+    done
 }
 
 checkIntegrity() {
@@ -111,6 +149,16 @@ checkIntegrity() {
     return $?
 }
 
+checkChecksum() {
+    local plugin checksum jpi
+    plugin="$1"
+    checksum="$2"
+    jpi="$(getArchiveFilename "$plugin")"
+
+    [[ "$(sha256sum "$jpi" | cut -d ' ' -f 1)" == "$checksum" ]]
+    return $?
+}
+
 bundledPlugins() {
     local JENKINS_WAR=/usr/share/jenkins/jenkins.war
     if [ -f $JENKINS_WAR ]
@@ -165,7 +213,7 @@ jenkinsMajorMinorVersion() {
 }
 
 main() {
-    local plugin pluginVersion jenkinsVersion
+    local plugin pluginVersion pluginChecksum pluginURL jenkinsVersion
     local plugins=()
 
     mkdir -p "$REF_DIR" || exit 1
@@ -189,7 +237,7 @@ main() {
     # Create lockfile manually before first run to make sure any explicit version set is used.
     echo "Creating initial locks..."
     for plugin in "${plugins[@]}"; do
-        mkdir "$(getLockFile "${plugin%%:*}")"
+        mkdir -p "$(getLockFile "${plugin%%%%:*}")"
     done
 
     echo "Analyzing war..."
@@ -210,13 +258,27 @@ main() {
     echo "Downloading plugins..."
     for plugin in "${plugins[@]}"; do
         pluginVersion=""
+        pluginChecksum=""
+        pluginURL=""
+
+        # plugins can be pinned to the checksum of the archive, e.g. git:3.9.1@sha256:<checksum>
+        if [[ $plugin == *@sha256:* ]]; then
+            pluginChecksum="${plugin##*@sha256:}"
+            plugin="${plugin%%@sha256:*}"
+        fi
+
+        # plugins can be downloaded from the given URL, e.g. my-plugin:1.2.3:https://artifacts.example.com/my-plugin-1.2.3.hpi
+        if [[ $plugin =~ ^([^:]+:[^:]+):(.+)$ ]]; then
+            pluginURL="${BASH_REMATCH[2]}"
+            plugin="${BASH_REMATCH[1]}"
+        fi
 
         if [[ $plugin =~ .*:.* ]]; then
             pluginVersion=$(versionFromPlugin "${plugin}")
             plugin="${plugin%%:*}"
         fi
 
-        download "$plugin" "$pluginVersion" "true" &
+        download "$plugin" "$pluginVersion" "true" "$pluginChecksum" "$pluginURL" &
     done
     wait
 
@@ -229,6 +291,7 @@ main() {
 
     if [[ -f $FAILED ]]; then
         echo "Some plugins failed to download!" "$(<"$FAILED")" >&2
+        grep -e "^` + PluginChecksumMismatchPrefix + `" -e "^` + PluginDownloadFailurePrefix + `" "$FAILED" >> "$TERMINATION_LOG" || true
         exit 1
     fi
 
@@ -246,6 +309,12 @@ set -x
 # https://wiki.jenkins.io/display/JENKINS/Post-initialization+script
 mkdir -p {{ .JenkinsHomePath }}/init.groovy.d
 cp -n {{ .InitConfigurationPath }}/*.groovy {{ .JenkinsHomePath }}/init.groovy.d
+{{- if .InitScriptsPath }}
+for script in {{ .InitScriptsPath }}/*.groovy; do
+    [ -e "${script}" ] || continue
+    cp "${script}" "{{ .JenkinsHomePath }}/init.groovy.d/{{ .UserInitScriptPrefix }}$(basename "${script}")"
+done
+{{- end }}
 
 mkdir -p {{ .JenkinsHomePath }}/scripts
 cp {{ .JenkinsScriptsVolumePath }}/*.sh {{ .JenkinsHomePath }}/scripts
@@ -254,20 +323,66 @@ chmod +x {{ .JenkinsHomePath }}/scripts/*.sh
 {{- $jenkinsHomePath := .JenkinsHomePath }}
 {{- $installPluginsCommand := .InstallPluginsCommand }}
 
+# all plugins are installed before the failure is reported, plugins which have been downloaded are kept
+pluginsFailed=""
+
 echo "Installing plugins required by Operator - begin"
 {{- range $rootPluginName, $plugins := .OperatorPlugins }}
 echo "Installing required plugins for '{{ $rootPluginName }}'"
-{{ $jenkinsHomePath }}/scripts/{{ $installPluginsCommand }} {{ $rootPluginName }} {{ range $index, $plugin := $plugins }}{{ . }} {{ end }}
+{{ $jenkinsHomePath }}/scripts/{{ $installPluginsCommand }} {{ $rootPluginName }} {{ range $index, $plugin := $plugins }}{{ . }} {{ end }}|| pluginsFailed=true
 {{- end }}
 echo "Installing plugins required by Operator - end"
 
 echo "Installing plugins required by user - begin"
 {{- range $rootPluginName, $plugins := .UserPlugins }}
 echo "Installing required plugins for '{{ $rootPluginName }}'"
-{{ $jenkinsHomePath }}/scripts/{{ $installPluginsCommand }} {{ $rootPluginName }} {{ range $index, $plugin := $plugins }}{{ . }} {{ end }}
+{{ $jenkinsHomePath }}/scripts/{{ $installPluginsCommand }} {{ $rootPluginName }} {{ range $index, $plugin := $plugins }}{{ . }} {{ end }}|| pluginsFailed=true
 {{- end }}
 echo "Installing plugins required by user - end"
 
+echo "Installing plugins required by enabled features - begin"
+{{- range $rootPluginName, $plugins := .FeaturePlugins }}
+echo "Installing required plugins for '{{ $rootPluginName }}'"
+{{ $jenkinsHomePath }}/scripts/{{ $installPluginsCommand }} {{ $rootPluginName }} {{ range $index, $plugin := $plugins }}{{ . }} {{ end }}|| pluginsFailed=true
+{{- end }}
+echo "Installing plugins required by enabled features - end"
+
+if [[ "${pluginsFailed}" ]]; then
+    echo "Some plugins failed to install" >&2
+    exit 1
+fi
+
+{{- if .AdditionalCACertsPath }}
+
+echo "Configuring additional CA certificates - begin"
+# git trusts the system CA certificates followed by the additional ones
+cat /etc/ssl/certs/ca-certificates.crt {{ .AdditionalCACertsPath }} > {{ $jenkinsHomePath }}/ca-certificates.crt
+git config --global http.sslCAInfo {{ $jenkinsHomePath }}/ca-certificates.crt
+
+# the JVM truststore is a copy of the default one with the additional CA certificates imported
+cp "$(find -L "${JAVA_HOME}" -name cacerts -type f | head -n 1)" {{ $jenkinsHomePath }}/cacerts
+chmod u+w {{ $jenkinsHomePath }}/cacerts
+rm -rf /tmp/additional-ca-certs && mkdir -p /tmp/additional-ca-certs
+awk '/-----BEGIN CERTIFICATE-----/ { n++ } n > 0 { print > ("/tmp/additional-ca-certs/" n ".crt") }' {{ .AdditionalCACertsPath }}
+for certificate in /tmp/additional-ca-certs/*.crt; do
+    keytool -importcert -noprompt -keystore {{ $jenkinsHomePath }}/cacerts -storepass changeit \
+        -alias "additional-ca-$(basename "${certificate}" .crt)" -file "${certificate}"
+done
+export JAVA_OPTS="${JAVA_OPTS} -Djavax.net.ssl.trustStore={{ $jenkinsHomePath }}/cacerts -Djavax.net.ssl.trustStorePassword=changeit"
+echo "Configuring additional CA certificates - end"
+{{- end }}
+
+{{- if .ExtraFilePaths }}
+{{- $extraFilesPath := .ExtraFilesPath }}
+
+echo "Copying extra files - begin"
+{{- range .ExtraFilePaths }}
+mkdir -p "$(dirname "{{ $jenkinsHomePath }}/{{ . }}")"
+cp "{{ $extraFilesPath }}/{{ . }}" "{{ $jenkinsHomePath }}/{{ . }}"
+{{- end }}
+echo "Copying extra files - end"
+{{- end }}
+
 /sbin/tini -s -- /usr/local/bin/jenkins.sh
 `))
 
@@ -278,7 +393,7 @@ func buildConfigMapTypeMeta() metav1.TypeMeta {
 	}
 }
 
-func buildInitBashScript(jenkins *v1alpha1.Jenkins) (*string, error) {
+func buildInitBashScript(jenkins *v1alpha1.Jenkins, userPlugins map[string][]string) (*string, error) {
 	data := struct {
 		JenkinsHomePath          string
 		InitConfigurationPath    string
@@ -286,13 +401,29 @@ func buildInitBashScript(jenkins *v1alpha1.Jenkins) (*string, error) {
 		JenkinsScriptsVolumePath string
 		OperatorPlugins          map[string][]string
 		UserPlugins              map[string][]string
+		FeaturePlugins           map[string][]string
+		AdditionalCACertsPath    string
+		ExtraFilesPath           string
+		ExtraFilePaths           []string
+		InitScriptsPath          string
+		UserInitScriptPrefix     string
 	}{
 		JenkinsHomePath:          jenkinsHomePath,
 		InitConfigurationPath:    jenkinsInitConfigurationVolumePath,
-		OperatorPlugins:          jenkins.Spec.Master.OperatorPlugins,
-		UserPlugins:              jenkins.Spec.Master.Plugins,
+		OperatorPlugins:          plugins.OperatorPlugins(jenkins),
+		UserPlugins:              userPlugins,
+		FeaturePlugins:           plugins.FeaturePlugins(jenkins),
 		InstallPluginsCommand:    installPluginsCommand,
 		JenkinsScriptsVolumePath: jenkinsScriptsVolumePath,
+		ExtraFilesPath:           extraFilesVolumePath,
+		ExtraFilePaths:           getExtraFilePaths(jenkins),
+		UserInitScriptPrefix:     userInitScriptPrefix,
+	}
+	if jenkins.Spec.Master.InitScripts != nil {
+		data.InitScriptsPath = initScriptsVolumePath
+	}
+	if jenkins.Spec.Master.AdditionalCACerts != nil {
+		data.AdditionalCACertsPath = fmt.Sprintf("%s/%s", additionalCACertsVolumePath, additionalCACertsFileName)
 	}
 
 	output, err := render(initBashTemplate, data)
@@ -303,15 +434,30 @@ func buildInitBashScript(jenkins *v1alpha1.Jenkins) (*string, error) {
 	return &output, nil
 }
 
+func buildInstallPluginsBashScript(jenkins *v1alpha1.Jenkins) string {
+	attempts := constants.DefaultPluginDownloadAttempts
+	delaySeconds := constants.DefaultPluginDownloadDelaySeconds
+	if retries := jenkins.Spec.Master.PluginDownloadRetries; retries != nil {
+		if retries.Attempts > 0 {
+			attempts = retries.Attempts
+		}
+		if retries.DelaySeconds > 0 {
+			delaySeconds = retries.DelaySeconds
+		}
+	}
+	return fmt.Sprintf(installPluginsBashFmt, jenkinsHomePath, corev1.TerminationMessagePathDefault, attempts, delaySeconds)
+}
+
 func getScriptsConfigMapName(jenkins *v1alpha1.Jenkins) string {
 	return fmt.Sprintf("%s-scripts-%s", constants.OperatorName, jenkins.ObjectMeta.Name)
 }
 
-// NewScriptsConfigMap builds Kubernetes config map used to store scripts
-func NewScriptsConfigMap(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) (*corev1.ConfigMap, error) {
+// NewScriptsConfigMap builds Kubernetes config map used to store scripts, userPlugins are plugins
+// from Jenkins.Spec.Master.Plugins merged with plugins from Jenkins.Spec.Master.PluginsConfigMapRef
+func NewScriptsConfigMap(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins, userPlugins map[string][]string) (*corev1.ConfigMap, error) {
 	meta.Name = getScriptsConfigMapName(jenkins)
 
-	initBashScript, err := buildInitBashScript(jenkins)
+	initBashScript, err := buildInitBashScript(jenkins, userPlugins)
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +467,7 @@ func NewScriptsConfigMap(meta metav1.ObjectMeta, jenkins *v1alpha1.Jenkins) (*co
 		ObjectMeta: meta,
 		Data: map[string]string{
 			initScriptName:        *initBashScript,
-			installPluginsCommand: fmt.Sprintf(installPluginsBashFmt, jenkinsHomePath),
+			installPluginsCommand: buildInstallPluginsBashScript(jenkins),
 		},
 	}, nil
 }
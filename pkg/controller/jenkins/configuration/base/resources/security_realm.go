@@ -0,0 +1,242 @@
+package resources
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ldapManagerPasswordEnvName is the environment variable with the password of the LDAP manager DN
+	ldapManagerPasswordEnvName = "LDAP_MANAGER_PASSWORD"
+	// oidcClientSecretEnvName is the environment variable with the OpenID Connect client secret
+	oidcClientSecretEnvName = "OIDC_CLIENT_SECRET"
+	// oidcEscapeHatchUserNameEnvName is the environment variable with the user name of the OpenID Connect escape hatch admin
+	oidcEscapeHatchUserNameEnvName = "OIDC_ESCAPE_HATCH_USER"
+	// oidcEscapeHatchPasswordEnvName is the environment variable with the password of the OpenID Connect escape hatch admin
+	oidcEscapeHatchPasswordEnvName = "OIDC_ESCAPE_HATCH_PASSWORD"
+
+	configureSecurityRealmFileName = "11-configure-security-realm.groovy"
+
+	// DefaultLDAPUserSearch is the filter users are searched with when Jenkins.Spec.Security.LDAP.UserSearch is empty
+	DefaultLDAPUserSearch = "uid={0}"
+	// OIDCEscapeHatchUserNameKey is the key of the user name in the OpenID Connect escape hatch secret
+	OIDCEscapeHatchUserNameKey = "user"
+	// OIDCEscapeHatchPasswordKey is the key of the password in the OpenID Connect escape hatch secret
+	OIDCEscapeHatchPasswordKey = "password"
+	// OIDCDiscoveryPath is the path of the OpenID Connect discovery document relative to the issuer URL
+	OIDCDiscoveryPath = "/.well-known/openid-configuration"
+)
+
+// DefaultOIDCScopes are requested when Jenkins.Spec.Security.OIDC.Scopes is empty
+var DefaultOIDCScopes = []string{"openid", "email", "profile"}
+
+// SecurityRealmEnvVarNames contains names of all environment variables which can be built by NewSecurityRealmEnvVars
+var SecurityRealmEnvVarNames = map[string]struct{}{
+	ldapManagerPasswordEnvName:     {},
+	oidcClientSecretEnvName:        {},
+	oidcEscapeHatchUserNameEnvName: {},
+	oidcEscapeHatchPasswordEnvName: {},
+}
+
+// configureSecurityRealmFmt configures the LDAP or the OpenID Connect security realm from the base64 encoded JSON settings
+// built by securityRealmSettings, the previous security realm is saved in JENKINS_HOME when the realm is configured
+// for the first time and restored when the settings are empty, plugin classes are loaded dynamically so the script works
+// without the plugins
+const configureSecurityRealmFmt = `
+import hudson.util.Secret
+import jenkins.model.Jenkins
+import org.jenkinsci.plugins.structs.describable.DescribableModel
+
+def jenkins = Jenkins.getInstance()
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def previousRealmFile = new File(jenkins.getRootDir(), 'jenkins-operator-previous-security-realm.xml')
+
+if (!settings.ldap && !settings.oidc) {
+    if (previousRealmFile.exists()) {
+        jenkins.setSecurityRealm(Jenkins.XSTREAM2.fromXML(previousRealmFile))
+        jenkins.save()
+        previousRealmFile.delete()
+        println('Previous security realm restored.')
+    }
+    return
+}
+
+if (!previousRealmFile.exists()) {
+    previousRealmFile.text = Jenkins.XSTREAM2.toXML(jenkins.getSecurityRealm())
+}
+
+def classLoader = jenkins.getPluginManager().uberClassLoader
+def realm
+if (settings.ldap) {
+    def ldap = settings.ldap
+    def configuration = classLoader.loadClass('jenkins.security.plugins.ldap.LDAPConfiguration').newInstance(
+            ldap.server, ldap.rootDN, ldap.inhibitInferRootDN, ldap.managerDN ?: null,
+            Secret.fromString(System.getenv('` + ldapManagerPasswordEnvName + `') ?: ''))
+    configuration.userSearchBase = ldap.userSearchBase
+    configuration.userSearch = ldap.userSearch
+    configuration.groupSearchBase = ldap.groupSearchBase
+    configuration.groupSearchFilter = ldap.groupSearchFilter
+    def environmentPropertyClass = classLoader.loadClass('hudson.security.LDAPSecurityRealm$EnvironmentProperty')
+    def environmentProperties = java.lang.reflect.Array.newInstance(environmentPropertyClass, ldap.environmentProperties.size())
+    ldap.environmentProperties.eachWithIndex { name, value, i ->
+        environmentProperties[i] = environmentPropertyClass.newInstance(name, value)
+    }
+    configuration.environmentProperties = environmentProperties
+    realm = classLoader.loadClass('hudson.security.LDAPSecurityRealm').newInstance([configuration], false, null, null, null)
+    println('LDAP security realm configured.')
+} else {
+    def oidc = settings.oidc
+    realm = DescribableModel.of(classLoader.loadClass('org.jenkinsci.plugins.oic.OicSecurityRealm')).instantiate([
+            clientId                       : oidc.clientID,
+            clientSecret                   : System.getenv('` + oidcClientSecretEnvName + `') ?: '',
+            wellKnownOpenIDConfigurationUrl: oidc.discoveryURL,
+            automanualconfigure            : 'auto',
+            scopes                         : oidc.scopes,
+            userNameField                  : oidc.userNameClaim,
+            groupsFieldName                : oidc.groupsClaim,
+            escapeHatchEnabled             : oidc.escapeHatch,
+            escapeHatchUsername            : System.getenv('` + oidcEscapeHatchUserNameEnvName + `') ?: '',
+            escapeHatchSecret              : System.getenv('` + oidcEscapeHatchPasswordEnvName + `') ?: '',
+            escapeHatchGroup               : oidc.escapeHatchGroup,
+    ])
+    println('OpenID Connect security realm configured.')
+}
+jenkins.setSecurityRealm(realm)
+jenkins.save()
+`
+
+// securityRealmSettings defines Jenkins.Spec.Security.LDAP and Jenkins.Spec.Security.OIDC passed to configureSecurityRealmFmt
+type securityRealmSettings struct {
+	LDAP *ldapSettings `json:"ldap,omitempty"`
+	OIDC *oidcSettings `json:"oidc,omitempty"`
+}
+
+type ldapSettings struct {
+	Server                string            `json:"server"`
+	RootDN                string            `json:"rootDN"`
+	InhibitInferRootDN    bool              `json:"inhibitInferRootDN"`
+	UserSearchBase        string            `json:"userSearchBase"`
+	UserSearch            string            `json:"userSearch"`
+	GroupSearchBase       string            `json:"groupSearchBase"`
+	GroupSearchFilter     string            `json:"groupSearchFilter"`
+	ManagerDN             string            `json:"managerDN"`
+	EnvironmentProperties map[string]string `json:"environmentProperties"`
+}
+
+type oidcSettings struct {
+	ClientID         string `json:"clientID"`
+	DiscoveryURL     string `json:"discoveryURL"`
+	Scopes           string `json:"scopes"`
+	UserNameClaim    string `json:"userNameClaim"`
+	GroupsClaim      string `json:"groupsClaim"`
+	EscapeHatch      bool   `json:"escapeHatch"`
+	EscapeHatchGroup string `json:"escapeHatchGroup"`
+}
+
+// GetLDAPServerURL returns the URL of the LDAP server defined in Jenkins.Spec.Security.LDAP, the ldaps:// scheme is used
+// when TLS is enabled and the server is the host
+func GetLDAPServerURL(ldap *v1alpha1.LDAP) string {
+	if strings.Contains(ldap.Server, "://") {
+		return ldap.Server
+	}
+	if ldap.TLS {
+		return "ldaps://" + ldap.Server
+	}
+	return "ldap://" + ldap.Server
+}
+
+// GetOIDCDiscoveryURL returns the URL of the discovery document of the OpenID Connect issuer defined in Jenkins.Spec.Security.OIDC
+func GetOIDCDiscoveryURL(oidc *v1alpha1.OIDC) string {
+	return strings.TrimSuffix(oidc.IssuerURL, "/") + OIDCDiscoveryPath
+}
+
+func newLDAPSettings(ldap *v1alpha1.LDAP) *ldapSettings {
+	settings := &ldapSettings{
+		Server:                GetLDAPServerURL(ldap),
+		RootDN:                ldap.RootDN,
+		InhibitInferRootDN:    ldap.InhibitInferRootDN,
+		UserSearchBase:        ldap.UserSearchBase,
+		UserSearch:            ldap.UserSearch,
+		GroupSearchBase:       ldap.GroupSearchBase,
+		GroupSearchFilter:     ldap.GroupSearchFilter,
+		ManagerDN:             ldap.ManagerDN,
+		EnvironmentProperties: map[string]string{},
+	}
+	if len(settings.UserSearch) == 0 {
+		settings.UserSearch = DefaultLDAPUserSearch
+	}
+	if ldap.ConnectTimeout != nil {
+		settings.EnvironmentProperties["com.sun.jndi.ldap.connect.timeout"] = fmt.Sprint(int64(ldap.ConnectTimeout.Duration / time.Millisecond))
+	}
+	if ldap.ReadTimeout != nil {
+		settings.EnvironmentProperties["com.sun.jndi.ldap.read.timeout"] = fmt.Sprint(int64(ldap.ReadTimeout.Duration / time.Millisecond))
+	}
+	return settings
+}
+
+func newOIDCSettings(oidc *v1alpha1.OIDC) *oidcSettings {
+	scopes := oidc.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultOIDCScopes
+	}
+	settings := &oidcSettings{
+		ClientID:      oidc.ClientID,
+		DiscoveryURL:  GetOIDCDiscoveryURL(oidc),
+		Scopes:        strings.Join(scopes, " "),
+		UserNameClaim: oidc.UserNameClaim,
+		GroupsClaim:   oidc.GroupsClaim,
+	}
+	if len(settings.UserNameClaim) == 0 {
+		settings.UserNameClaim = "sub"
+	}
+	if oidc.EscapeHatch != nil {
+		settings.EscapeHatch = true
+		settings.EscapeHatchGroup = oidc.EscapeHatch.Group
+	}
+	return settings
+}
+
+func buildConfigureSecurityRealmGroovyScript(jenkins *v1alpha1.Jenkins) (string, error) {
+	settings := securityRealmSettings{}
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.LDAP != nil {
+		settings.LDAP = newLDAPSettings(jenkins.Spec.Security.LDAP)
+	}
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.OIDC != nil {
+		settings.OIDC = newOIDCSettings(jenkins.Spec.Security.OIDC)
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(configureSecurityRealmFmt, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+// NewSecurityRealmEnvVars builds environment variables of the security realm defined in Jenkins.Spec.Security for Jenkins master,
+// the LDAP manager password, the OpenID Connect client secret and the escape hatch credentials are read from secrets by Kubernetes
+func NewSecurityRealmEnvVars(jenkins *v1alpha1.Jenkins) []corev1.EnvVar {
+	if jenkins.Spec.Security == nil {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	if ldap := jenkins.Spec.Security.LDAP; ldap != nil && ldap.ManagerPasswordSecretRef != nil {
+		envVars = append(envVars, newSecretEnvVar(ldapManagerPasswordEnvName, ldap.ManagerPasswordSecretRef.Name, ldap.ManagerPasswordSecretRef.Key))
+	}
+	if oidc := jenkins.Spec.Security.OIDC; oidc != nil {
+		envVars = append(envVars, newSecretEnvVar(oidcClientSecretEnvName, oidc.ClientSecretRef.Name, oidc.ClientSecretRef.Key))
+		if oidc.EscapeHatch != nil {
+			envVars = append(envVars,
+				newSecretEnvVar(oidcEscapeHatchUserNameEnvName, oidc.EscapeHatch.CredentialsSecretRef.Name, OIDCEscapeHatchUserNameKey),
+				newSecretEnvVar(oidcEscapeHatchPasswordEnvName, oidc.EscapeHatch.CredentialsSecretRef.Name, OIDCEscapeHatchPasswordKey))
+		}
+	}
+	return envVars
+}
@@ -0,0 +1,41 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// TimezoneEnvName is the environment variable with the time zone of Jenkins master container
+	TimezoneEnvName = "TZ"
+
+	// defaultJavaOpts are JVM options of Jenkins master
+	defaultJavaOpts = "-XX:+UnlockExperimentalVMOptions -XX:+UseCGroupMemoryLimitForHeap -XX:MaxRAMFraction=1 -Djenkins.install.runSetupWizard=false -Djava.awt.headless=true"
+)
+
+// NewTimezoneEnvVars builds the environment variable with the time zone defined in Jenkins.Spec.Master for Jenkins master,
+// processes started in the container, e.g. shell steps of builds, use it
+func NewTimezoneEnvVars(jenkins *v1alpha1.Jenkins) []corev1.EnvVar {
+	if len(jenkins.Spec.Master.Timezone) == 0 {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name:  TimezoneEnvName,
+			Value: jenkins.Spec.Master.Timezone,
+		},
+	}
+}
+
+// newJavaOpts returns JVM options of Jenkins master, the time zone defined in Jenkins.Spec.Master is used by the JVM
+// and by Jelly views which format times of builds in the UI
+func newJavaOpts(jenkins *v1alpha1.Jenkins) string {
+	if len(jenkins.Spec.Master.Timezone) == 0 {
+		return defaultJavaOpts
+	}
+	return fmt.Sprintf("%s -Duser.timezone=%s -Dorg.apache.commons.jelly.tags.fmt.timeZone=%s",
+		defaultJavaOpts, jenkins.Spec.Master.Timezone, jenkins.Spec.Master.Timezone)
+}
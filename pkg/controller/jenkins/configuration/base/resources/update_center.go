@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// updateCenterEnvName is the environment variable with the update center URL read by install-plugins.sh,
+	// Jenkins docker image sets it to the official update center
+	updateCenterEnvName = "JENKINS_UC"
+	// updateCenterSignatureCheckEnvName is set to false when the signature check of the update center is disabled
+	updateCenterSignatureCheckEnvName = "JENKINS_OPERATOR_UPDATE_CENTER_SIGNATURE_CHECK"
+
+	configureUpdateCenterFileName = "10-configure-update-center.groovy"
+
+	// DefaultUpdateCenterURL is the URL of the update center used by Jenkins docker image
+	DefaultUpdateCenterURL = "https://updates.jenkins.io"
+)
+
+// UpdateCenterEnvVarNames contains names of all environment variables which can be built by NewUpdateCenterEnvVars
+var UpdateCenterEnvVarNames = map[string]struct{}{
+	updateCenterEnvName:               {},
+	updateCenterSignatureCheckEnvName: {},
+}
+
+// configureUpdateCenter configures the default update site of Jenkins from the environment variables of Jenkins master pod,
+// the update center of Jenkins docker image is used when Jenkins.Spec.Master.UpdateCenterURL isn't set
+const configureUpdateCenter = `
+import hudson.model.DownloadService
+import hudson.model.UpdateCenter
+import hudson.model.UpdateSite
+import jenkins.model.Jenkins
+
+def jenkins = Jenkins.getInstance()
+
+DownloadService.signatureCheck = System.getenv('` + updateCenterSignatureCheckEnvName + `') != 'false'
+
+def updateCenterURL = System.getenv('` + updateCenterEnvName + `') ?: '` + DefaultUpdateCenterURL + `'
+def updateSiteURL = updateCenterURL + '/update-center.json'
+def updateCenter = jenkins.getUpdateCenter()
+def updateSite = updateCenter.getById(UpdateCenter.ID_DEFAULT)
+if (updateSite == null || updateSite.getUrl() != updateSiteURL) {
+    if (updateSite != null) {
+        updateCenter.getSites().remove(updateSite)
+    }
+    updateCenter.getSites().add(new UpdateSite(UpdateCenter.ID_DEFAULT, updateSiteURL))
+    updateCenter.save()
+    println('Update center configured.')
+}
+`
+
+// GetUpdateCenterURL returns the URL of the update center used to install plugins without the trailing slash
+func GetUpdateCenterURL(jenkins *v1alpha1.Jenkins) string {
+	if len(jenkins.Spec.Master.UpdateCenterURL) == 0 {
+		return DefaultUpdateCenterURL
+	}
+	return strings.TrimSuffix(jenkins.Spec.Master.UpdateCenterURL, "/")
+}
+
+// NewUpdateCenterEnvVars builds environment variables of the update center defined in Jenkins.Spec.Master for Jenkins master,
+// the update center URL is used by install-plugins.sh in the init script and by the base configuration
+func NewUpdateCenterEnvVars(jenkins *v1alpha1.Jenkins) []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	if len(jenkins.Spec.Master.UpdateCenterURL) > 0 {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  updateCenterEnvName,
+			Value: strings.TrimSuffix(jenkins.Spec.Master.UpdateCenterURL, "/"),
+		})
+	}
+	if jenkins.Spec.Master.SkipUpdateCenterSignatureCheck {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  updateCenterSignatureCheckEnvName,
+			Value: "false",
+		})
+	}
+	return envVars
+}
@@ -2,6 +2,8 @@ package resources
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
@@ -10,6 +12,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// UserConfigurationPriorityAnnotation is the annotation of ConfigMaps and Secrets with groovy scripts which overrides
+// the order they are applied in, scripts from sources with lower priority are applied first
+const UserConfigurationPriorityAnnotation = "jenkins.io/priority"
+
 const configureTheme = `
 import jenkins.*
 import jenkins.model.*
@@ -53,3 +59,139 @@ func NewUserConfigurationConfigMap(jenkins *v1alpha1.Jenkins) *corev1.ConfigMap
 		},
 	}
 }
+
+// GetUserConfigurationGitConfigMapName returns name of Kubernetes config map with groovy scripts fetched from the git repository
+func GetUserConfigurationGitConfigMapName(jenkins *v1alpha1.Jenkins) string {
+	return fmt.Sprintf("%s-user-configuration-git-%s", constants.OperatorName, jenkins.ObjectMeta.Name)
+}
+
+// NewUserConfigurationGitConfigMap builds Kubernetes config map with groovy scripts fetched from the git repository,
+// it's empty until the repository is fetched
+func NewUserConfigurationGitConfigMap(jenkins *v1alpha1.Jenkins) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: buildConfigMapTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetUserConfigurationGitConfigMapName(jenkins),
+			Namespace: jenkins.ObjectMeta.Namespace,
+			Labels:    BuildResourceLabels(jenkins),
+		},
+	}
+}
+
+// GetUserConfigurationURLsConfigMapName returns name of Kubernetes config map with groovy scripts downloaded from URLs
+func GetUserConfigurationURLsConfigMapName(jenkins *v1alpha1.Jenkins) string {
+	return fmt.Sprintf("%s-user-configuration-urls-%s", constants.OperatorName, jenkins.ObjectMeta.Name)
+}
+
+// NewUserConfigurationURLsConfigMap builds Kubernetes config map with groovy scripts downloaded from URLs,
+// it's empty until the scripts are downloaded
+func NewUserConfigurationURLsConfigMap(jenkins *v1alpha1.Jenkins) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: buildConfigMapTypeMeta(),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetUserConfigurationURLsConfigMapName(jenkins),
+			Namespace: jenkins.ObjectMeta.Namespace,
+			Labels:    BuildResourceLabels(jenkins),
+		},
+	}
+}
+
+// UserConfigurationSource is a ConfigMap or a Secret with groovy scripts provided by user
+type UserConfigurationSource struct {
+	Name   string
+	Secret bool
+}
+
+// String returns the kind and the name of the source, e.g. configmap/name or secret/name
+func (source UserConfigurationSource) String() string {
+	if source.Secret {
+		return "secret/" + source.Name
+	}
+	return "configmap/" + source.Name
+}
+
+// IsUserConfigurationEnabled returns false when the user configuration phase is disabled by Jenkins.Spec.Configuration.Enabled
+func IsUserConfigurationEnabled(jenkins *v1alpha1.Jenkins) bool {
+	return jenkins.Spec.Configuration.Enabled == nil || *jenkins.Spec.Configuration.Enabled
+}
+
+// GetUserConfigurationSources returns ConfigMaps and Secrets with groovy scripts provided by user in the order they are applied,
+// scripts from ConfigMaps are applied before scripts from Secrets, scripts fetched from the git repository
+// and scripts downloaded from URLs are the last, there are no sources when the user configuration is disabled
+func GetUserConfigurationSources(jenkins *v1alpha1.Jenkins) []UserConfigurationSource {
+	var sources []UserConfigurationSource
+	if !IsUserConfigurationEnabled(jenkins) {
+		return sources
+	}
+	configMaps := jenkins.Spec.Configuration.ConfigMaps
+	if len(configMaps) == 0 {
+		configMaps = []string{GetUserConfigurationConfigMapName(jenkins)}
+	}
+	for _, name := range configMaps {
+		sources = append(sources, UserConfigurationSource{Name: name})
+	}
+	for _, name := range jenkins.Spec.Configuration.Secrets {
+		sources = append(sources, UserConfigurationSource{Name: name, Secret: true})
+	}
+	if jenkins.Spec.Configuration.Git != nil {
+		sources = append(sources, UserConfigurationSource{Name: GetUserConfigurationGitConfigMapName(jenkins)})
+	}
+	if len(jenkins.Spec.Configuration.URLs) > 0 {
+		sources = append(sources, UserConfigurationSource{Name: GetUserConfigurationURLsConfigMapName(jenkins)})
+	}
+	return sources
+}
+
+// GetUserConfigurationDirectory returns the directory relative to JenkinsUserConfigurationVolumePath with groovy scripts
+// of the source at the given index of GetUserConfigurationSources, directories are sorted in the order of sources,
+// scripts of the default ConfigMap are placed directly in JenkinsUserConfigurationVolumePath
+func GetUserConfigurationDirectory(jenkins *v1alpha1.Jenkins, index int) string {
+	if isDefaultUserConfiguration(jenkins) {
+		return ""
+	}
+	return fmt.Sprintf("%03d-%s", index, GetUserConfigurationSources(jenkins)[index].Name)
+}
+
+// GetUserConfigurationPriority returns the priority of the source with given annotations, it defaults to 0
+func GetUserConfigurationPriority(annotations map[string]string) (int, error) {
+	value, ok := annotations[UserConfigurationPriorityAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '%s' annotation '%s', it has to be an integer", UserConfigurationPriorityAnnotation, value)
+	}
+	return priority, nil
+}
+
+func isDefaultUserConfiguration(jenkins *v1alpha1.Jenkins) bool {
+	return len(jenkins.Spec.Configuration.ConfigMaps) == 0 && len(jenkins.Spec.Configuration.Secrets) == 0 &&
+		jenkins.Spec.Configuration.Git == nil && len(jenkins.Spec.Configuration.URLs) == 0
+}
+
+func newUserConfigurationVolumes(jenkins *v1alpha1.Jenkins) ([]corev1.Volume, []corev1.VolumeMount) {
+	var volumes []corev1.Volume
+	var volumeMounts []corev1.VolumeMount
+	for index, source := range GetUserConfigurationSources(jenkins) {
+		volumeName := JenkinsUserConfigurationVolumeName
+		if !isDefaultUserConfiguration(jenkins) {
+			volumeName = fmt.Sprintf("%s-%d", JenkinsUserConfigurationVolumeName, index)
+		}
+		volume := corev1.Volume{Name: volumeName}
+		if source.Secret {
+			volume.Secret = &corev1.SecretVolumeSource{SecretName: source.Name}
+		} else {
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.Name},
+			}
+		}
+		volumes = append(volumes, volume)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: path.Join(JenkinsUserConfigurationVolumePath, GetUserConfigurationDirectory(jenkins, index)),
+			ReadOnly:  true,
+		})
+	}
+	return volumes, volumeMounts
+}
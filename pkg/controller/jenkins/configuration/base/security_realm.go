@@ -0,0 +1,83 @@
+package base
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// saveOperatorAuthoritiesScript saves authorities of the Jenkins user which runs the script, external security realms can't
+// load the operator user so Jenkins uses the saved authorities when operator authenticates with the API token
+const saveOperatorAuthoritiesScript = `
+import hudson.model.User
+import jenkins.model.Jenkins
+import jenkins.security.LastGrantedAuthoritiesProperty
+
+def user = User.current()
+if (user.getProperty(LastGrantedAuthoritiesProperty.class) == null) {
+    def property = new LastGrantedAuthoritiesProperty()
+    user.addProperty(property)
+    property.update(Jenkins.getAuthentication())
+    println('Authorities of ' + user.getId() + ' saved.')
+}
+`
+
+// isExternalSecurityRealmEnabled returns true when the security realm is configured by Jenkins.Spec.Security
+func isExternalSecurityRealmEnabled(jenkins *v1alpha1.Jenkins) bool {
+	return jenkins.Spec.Security != nil && (jenkins.Spec.Security.LDAP != nil || jenkins.Spec.Security.OIDC != nil)
+}
+
+// validateSecurityRealm checks only one security realm is configured by Jenkins.Spec.Security and validates it
+func (r *ReconcileJenkinsBaseConfiguration) validateSecurityRealm(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.LDAP != nil && jenkins.Spec.Security.OIDC != nil {
+		r.logger.V(log.VWarn).Info("spec.security.ldap and spec.security.oidc can't be set together")
+		return false, nil
+	}
+
+	if valid, err := r.validateLDAP(jenkins); !valid || err != nil {
+		return valid, err
+	}
+	return r.validateOIDC(jenkins)
+}
+
+// validateSecretKeys checks the secret exists and contains non-empty keys
+func (r *ReconcileJenkinsBaseConfiguration) validateSecretKeys(name, description string, keys ...string) (bool, error) {
+	secret := &corev1.Secret{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: r.jenkins.Namespace, Name: name}, secret)
+	if err != nil && apierrors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with %s not found", name, description))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+	for _, key := range keys {
+		if len(secret.Data[key]) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with %s doesn't contain '%s' key", name, description, key))
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ensureOperatorAuthorities saves authorities of the Jenkins user of operator before the base configuration switches
+// to the security realm of Jenkins.Spec.Security, so operator keeps the API access with its token
+func (r *ReconcileJenkinsBaseConfiguration) ensureOperatorAuthorities(jenkinsClient jenkinsclient.Jenkins) error {
+	if !isExternalSecurityRealmEnabled(r.jenkins) {
+		return nil
+	}
+
+	output, err := jenkinsClient.ExecuteScript(saveOperatorAuthoritiesScript)
+	if err != nil {
+		return err
+	}
+	r.logger.V(log.VDebug).Info(output)
+	return nil
+}
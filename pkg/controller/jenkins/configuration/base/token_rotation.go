@@ -0,0 +1,94 @@
+package base
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// reasonAPITokenRotated is the event which informs the Jenkins API token of operator has been rotated
+	reasonAPITokenRotated event.Reason = "APITokenRotated"
+
+	// minTokenRotationInterval is the minimal value of Jenkins.Spec.Security.TokenRotationInterval
+	minTokenRotationInterval = time.Minute
+)
+
+// validateTokenRotationInterval checks Jenkins.Spec.Security.TokenRotationInterval isn't shorter than minTokenRotationInterval
+func (r *ReconcileJenkinsBaseConfiguration) validateTokenRotationInterval(jenkins *v1alpha1.Jenkins) bool {
+	if jenkins.Spec.Security == nil || jenkins.Spec.Security.TokenRotationInterval == nil {
+		return true
+	}
+
+	if jenkins.Spec.Security.TokenRotationInterval.Duration < minTokenRotationInterval {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("spec.security.tokenRotationInterval '%s' has to be at least %s",
+			jenkins.Spec.Security.TokenRotationInterval.Duration, minTokenRotationInterval))
+		return false
+	}
+
+	return true
+}
+
+// isTokenRotationRequired returns true when the Jenkins API token of operator created at creationTime is older
+// than Jenkins.Spec.Security.TokenRotationInterval
+func isTokenRotationRequired(jenkins *v1alpha1.Jenkins, creationTime, now time.Time) bool {
+	if jenkins.Spec.Security == nil || jenkins.Spec.Security.TokenRotationInterval == nil {
+		return false
+	}
+
+	return now.Sub(creationTime) >= jenkins.Spec.Security.TokenRotationInterval.Duration
+}
+
+// rotateAPIToken generates the new Jenkins API token of operator, verifies it and saves it in the operator credentials
+// secret, the previous token is revoked only after the secret has been updated so a conflicting update leaves
+// the previous token working and the new one is revoked instead
+func (r *ReconcileJenkinsBaseConfiguration) rotateAPIToken(jenkinsURL string, jenkinsClient jenkinsclient.Jenkins, credentialsSecret *corev1.Secret) (jenkinsclient.Jenkins, error) {
+	userName := resources.GetOperatorTokenUserName(credentialsSecret)
+	r.logger.Info("Rotating Jenkins API token of operator")
+
+	token, err := jenkinsClient.GenerateToken(userName, "token")
+	if err != nil {
+		return nil, err
+	}
+
+	newJenkinsClient, err := jenkinsclient.New(jenkinsURL, userName, token.GetToken())
+	if err != nil {
+		r.revokeAPIToken(jenkinsClient, userName, token.GetUUID())
+		return nil, err
+	}
+
+	previousTokenUUID := string(credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUUIDKey])
+	now := time.Now().UTC()
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenKey] = []byte(token.GetToken())
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenUUIDKey] = []byte(token.GetUUID())
+	credentialsSecret.Data[resources.OperatorCredentialsSecretTokenCreationKey], _ = now.MarshalText()
+	if err := r.updateResource(credentialsSecret); err != nil {
+		r.revokeAPIToken(newJenkinsClient, userName, token.GetUUID())
+		return nil, err // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+	}
+
+	if len(previousTokenUUID) == 0 {
+		r.logger.V(log.VWarn).Info("UUID of the previous Jenkins API token of operator is unknown, please revoke it manually")
+	} else {
+		r.revokeAPIToken(newJenkinsClient, userName, previousTokenUUID)
+	}
+
+	rotationTime := now.Format(time.RFC3339)
+	r.logger.Info(fmt.Sprintf("Jenkins API token of operator rotated at %s", rotationTime))
+	r.events.Emitf(r.jenkins, event.TypeNormal, reasonAPITokenRotated, "Jenkins API token of operator rotated at %s", rotationTime)
+	return newJenkinsClient, nil
+}
+
+// revokeAPIToken revokes the Jenkins API token, the failure is only logged because the token can't be used by operator anymore
+func (r *ReconcileJenkinsBaseConfiguration) revokeAPIToken(jenkinsClient jenkinsclient.Jenkins, userName, tokenUUID string) {
+	if err := jenkinsClient.RevokeToken(userName, tokenUUID); err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't revoke Jenkins API token '%s' of user '%s': %s", tokenUUID, userName, err))
+	}
+}
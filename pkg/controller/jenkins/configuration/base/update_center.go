@@ -0,0 +1,600 @@
+package base
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const (
+	// reasonPluginResolutionFailed is the event which informs latest plugin versions can't be resolved against the update center
+	reasonPluginResolutionFailed event.Reason = "PluginResolutionFailed"
+	// reasonPluginSecurityWarnings is the event which informs declared plugin versions are affected by security warnings
+	reasonPluginSecurityWarnings event.Reason = "PluginSecurityWarnings"
+	// reasonPluginDependenciesResolved is the event which informs dependencies of plugins have been resolved against the update center
+	reasonPluginDependenciesResolved event.Reason = "PluginDependenciesResolved"
+	// reasonPluginDependencyConflict is the event which informs dependencies of plugins can't be satisfied by declared plugin versions
+	reasonPluginDependencyConflict event.Reason = "PluginDependencyConflict"
+	// reasonPluginIncompatible is the event which informs declared plugin versions require newer Jenkins core than the image has
+	reasonPluginIncompatible event.Reason = "PluginIncompatible"
+
+	// updateCenterMetadataPath is the path of the update center metadata without the JSONP wrapper
+	updateCenterMetadataPath = "/update-center.actual.json"
+	// updateCenterMetadataTTL is the duration the downloaded update center metadata is reused for
+	updateCenterMetadataTTL = 15 * time.Minute
+	// securityWarningPluginType is the type of security warnings which affect plugins
+	securityWarningPluginType = "plugin"
+)
+
+var updateCenterHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// jenkinsCoreVersionRegexp matches the Jenkins core version at the beginning of the image tag, e.g. 2.150.1 in 2.150.1-alpine
+var jenkinsCoreVersionRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)+`)
+
+// updateCenterMetadataCache keeps the update center metadata by the update center URL, the metadata is big
+// and it's needed in every reconciliation loop
+var updateCenterMetadataCache = map[string]cachedUpdateCenterMetadata{}
+var updateCenterMetadataCacheMutex sync.Mutex
+
+type cachedUpdateCenterMetadata struct {
+	metadata     *updateCenterMetadata
+	downloadTime time.Time
+}
+
+// updateCenterMetadata is the part of the update center metadata with versions and dependencies of plugins and security warnings
+type updateCenterMetadata struct {
+	Plugins map[string]struct {
+		Version      string `json:"version"`
+		RequiredCore string `json:"requiredCore"`
+		Dependencies []struct {
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			Optional bool   `json:"optional"`
+		} `json:"dependencies"`
+	} `json:"plugins"`
+	Warnings []struct {
+		Type     string `json:"type"`
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Versions []struct {
+			Pattern string `json:"pattern"`
+		} `json:"versions"`
+	} `json:"warnings"`
+}
+
+// getUpdateCenterMetadata returns the update center metadata downloaded in the last updateCenterMetadataTTL
+// or downloads it
+func getUpdateCenterMetadata(updateCenterURL string) (*updateCenterMetadata, error) {
+	updateCenterMetadataCacheMutex.Lock()
+	defer updateCenterMetadataCacheMutex.Unlock()
+
+	if cached, ok := updateCenterMetadataCache[updateCenterURL]; ok && time.Since(cached.downloadTime) < updateCenterMetadataTTL {
+		return cached.metadata, nil
+	}
+
+	response, err := updateCenterHTTPClient.Get(updateCenterURL + updateCenterMetadataPath)
+	if err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, stackerr.Errorf("unexpected status '%s' of '%s'", response.Status, updateCenterURL+updateCenterMetadataPath)
+	}
+
+	metadata := &updateCenterMetadata{}
+	if err := json.NewDecoder(response.Body).Decode(metadata); err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	updateCenterMetadataCache[updateCenterURL] = cachedUpdateCenterMetadata{metadata: metadata, downloadTime: time.Now()}
+	return metadata, nil
+}
+
+// getLatestPluginVersions returns the latest version of every plugin in the update center
+func getLatestPluginVersions(updateCenterURL string) (map[string]string, error) {
+	metadata, err := getUpdateCenterMetadata(updateCenterURL)
+	if err != nil {
+		return nil, err
+	}
+	versions := map[string]string{}
+	for name, plugin := range metadata.Plugins {
+		versions[name] = plugin.Version
+	}
+	return versions, nil
+}
+
+// getPluginSecurityWarnings returns security warnings of the update center which affect versions of plugins
+// sorted by the plugin name and the warning ID
+func getPluginSecurityWarnings(metadata *updateCenterMetadata, pluginVersions map[string]string) []v1alpha1.PluginSecurityWarning {
+	var warnings []v1alpha1.PluginSecurityWarning
+	for _, warning := range metadata.Warnings {
+		version, ok := pluginVersions[warning.Name]
+		if warning.Type != securityWarningPluginType || !ok {
+			continue
+		}
+		for _, affectedVersions := range warning.Versions {
+			pattern, err := regexp.Compile("^(?:" + affectedVersions.Pattern + ")$")
+			if err != nil || !pattern.MatchString(version) {
+				continue
+			}
+			warnings = append(warnings, v1alpha1.PluginSecurityWarning{Name: warning.Name, Version: version, ID: warning.ID, URL: warning.URL})
+			break
+		}
+	}
+	sort.Slice(warnings, func(i, j int) bool {
+		if warnings[i].Name != warnings[j].Name {
+			return warnings[i].Name < warnings[j].Name
+		}
+		return warnings[i].ID < warnings[j].ID
+	})
+	return warnings
+}
+
+// validatePluginSecurityWarnings checks declared plugin versions against security warnings of the update center,
+// new warnings are reported as the warning event and saved in Jenkins.Status section, the validation fails
+// only when Jenkins.Spec.Master.FailOnSecurityWarnings is set
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginSecurityWarnings(pluginsWithVersions ...map[string][]string) (bool, error) {
+	pluginVersions := getDeclaredPluginVersions(pluginsWithVersions...)
+
+	updateCenterURL := resources.GetUpdateCenterURL(r.jenkins)
+	metadata, err := getUpdateCenterMetadata(updateCenterURL)
+	if err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Update center '%s' can't be reached, plugin security warnings aren't checked: %s", updateCenterURL, err))
+		return true, nil
+	}
+
+	warnings := getPluginSecurityWarnings(metadata, pluginVersions)
+	if !reflect.DeepEqual(warnings, r.jenkins.Status.PluginSecurityWarnings) {
+		if len(warnings) > 0 {
+			var messages []string
+			for _, warning := range warnings {
+				messages = append(messages, fmt.Sprintf("%s:%s %s", warning.Name, warning.Version, warning.ID))
+			}
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugins are affected by security warnings: %s", strings.Join(messages, ", ")))
+			r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginSecurityWarnings,
+				"Plugins are affected by security warnings: %s", strings.Join(messages, ", "))
+		}
+		r.jenkins.Status.PluginSecurityWarnings = warnings
+		if err := r.k8sClient.Update(context.TODO(), r.jenkins); err != nil {
+			return false, err // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+		}
+	}
+
+	if len(warnings) > 0 && r.jenkins.Spec.Master.FailOnSecurityWarnings {
+		r.logger.V(log.VWarn).Info("Plugins affected by security warnings aren't allowed")
+		return false, nil
+	}
+	return true, nil
+}
+
+// getDeclaredPluginVersions returns versions of root and dependent plugins by the plugin name
+func getDeclaredPluginVersions(pluginsWithVersions ...map[string][]string) map[string]string {
+	pluginVersions := map[string]string{}
+	for _, declaredPlugins := range pluginsWithVersions {
+		for rootPlugin, dependentPlugins := range declaredPlugins {
+			for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+				if plugin, err := plugins.New(pluginNameWithVersion); err == nil && len(plugin.Constraint) == 0 {
+					pluginVersions[plugin.Name] = plugin.Version
+				}
+			}
+		}
+	}
+	return pluginVersions
+}
+
+// getJenkinsCoreVersion returns the Jenkins core version from the tag of Jenkins master image, e.g. 2.150.1
+// for jenkins/jenkins:2.150.1-alpine, tags without the version like lts can't be resolved
+func getJenkinsCoreVersion(image string) (string, bool) {
+	if index := strings.Index(image, "@"); index >= 0 {
+		image = image[:index]
+	}
+	index := strings.LastIndex(image, ":")
+	if index < 0 || strings.Contains(image[index:], "/") {
+		return "", false
+	}
+	version := jenkinsCoreVersionRegexp.FindString(image[index+1:])
+	return version, len(version) > 0
+}
+
+// getIncompatiblePlugins returns declared plugins which require newer Jenkins core than the given one sorted by the name,
+// the update center metadata describes only the latest version of every plugin so other versions aren't checked
+func getIncompatiblePlugins(metadata *updateCenterMetadata, pluginVersions map[string]string, coreVersion string) []string {
+	var incompatiblePlugins []string
+	for name, version := range pluginVersions {
+		plugin, ok := metadata.Plugins[name]
+		if !ok || plugin.Version != version || len(plugin.RequiredCore) == 0 {
+			continue
+		}
+		if plugins.CompareVersions(plugin.RequiredCore, coreVersion) > 0 {
+			incompatiblePlugins = append(incompatiblePlugins, fmt.Sprintf("%s:%s requires Jenkins %s", name, version, plugin.RequiredCore))
+		}
+	}
+	sort.Strings(incompatiblePlugins)
+	return incompatiblePlugins
+}
+
+// validatePluginCompatibility checks declared plugin versions don't require newer Jenkins core than the version from the tag
+// of Jenkins master image, the check is skipped when Jenkins.Spec.Master.SkipPluginCompatibilityCheck is set, the core version
+// can't be resolved from the tag or the update center can't be reached
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginCompatibility(pluginsWithVersions ...map[string][]string) bool {
+	if r.jenkins.Spec.Master.SkipPluginCompatibilityCheck {
+		return true
+	}
+	coreVersion, ok := getJenkinsCoreVersion(r.jenkins.Spec.Master.Image)
+	if !ok {
+		r.logger.V(log.VDebug).Info(fmt.Sprintf("Jenkins core version can't be resolved from image '%s', plugin compatibility isn't checked",
+			r.jenkins.Spec.Master.Image))
+		return true
+	}
+
+	updateCenterURL := resources.GetUpdateCenterURL(r.jenkins)
+	metadata, err := getUpdateCenterMetadata(updateCenterURL)
+	if err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Update center '%s' can't be reached, plugin compatibility isn't checked: %s", updateCenterURL, err))
+		return true
+	}
+
+	incompatiblePlugins := getIncompatiblePlugins(metadata, getDeclaredPluginVersions(pluginsWithVersions...), coreVersion)
+	if len(incompatiblePlugins) == 0 {
+		return true
+	}
+
+	message := fmt.Sprintf("Plugins aren't compatible with Jenkins %s: %s", coreVersion, strings.Join(incompatiblePlugins, ", "))
+	r.logger.V(log.VWarn).Info(message)
+	r.events.Emit(r.jenkins, event.TypeWarning, reasonPluginIncompatible, message)
+	r.validationErrors = append(r.validationErrors, message)
+	return false
+}
+
+// resolveLatestPlugins replaces the latest version of plugins with the version from the update center,
+// previously resolved versions from Jenkins.Status.ResolvedPlugins are used when the update center can't be reached,
+// the resolved versions are kept for the reconciliation loop and saved by updateResolvedPlugins
+func (r *ReconcileJenkinsBaseConfiguration) resolveLatestPlugins(pluginsWithVersions map[string][]string) (map[string][]string, error) {
+	latest := map[string]bool{}
+	for rootPlugin, dependentPlugins := range pluginsWithVersions {
+		for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+			if plugin, err := plugins.New(pluginNameWithVersion); err == nil && plugin.Version == plugins.LatestVersion {
+				latest[plugin.Name] = true
+			}
+		}
+	}
+	r.resolvedPlugins = nil
+	if len(latest) == 0 {
+		return pluginsWithVersions, nil
+	}
+
+	updateCenterURL := resources.GetUpdateCenterURL(r.jenkins)
+	versions, err := getLatestPluginVersions(updateCenterURL)
+	if err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Update center '%s' can't be reached, previously resolved plugin versions are used: %s", updateCenterURL, err))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginResolutionFailed,
+			"Update center '%s' can't be reached, previously resolved plugin versions are used: %s", updateCenterURL, err)
+		versions = map[string]string{}
+		for _, resolvedPlugin := range r.jenkins.Status.ResolvedPlugins {
+			versions[resolvedPlugin.Name] = resolvedPlugin.Version
+		}
+	}
+
+	var resolvedPlugins []v1alpha1.ResolvedPlugin
+	for name := range latest {
+		version, ok := versions[name]
+		if !ok {
+			return nil, stackerr.Errorf("latest version of plugin '%s' can't be resolved against update center '%s'", name, updateCenterURL)
+		}
+		resolvedPlugins = append(resolvedPlugins, v1alpha1.ResolvedPlugin{Name: name, Version: version})
+	}
+	sort.Slice(resolvedPlugins, func(i, j int) bool {
+		return resolvedPlugins[i].Name < resolvedPlugins[j].Name
+	})
+	r.resolvedPlugins = resolvedPlugins
+
+	resolve := func(pluginNameWithVersion string) string {
+		plugin, err := plugins.New(pluginNameWithVersion)
+		if err != nil || plugin.Version != plugins.LatestVersion {
+			return pluginNameWithVersion
+		}
+		plugin.Version = versions[plugin.Name]
+		return plugin.StringWithChecksum()
+	}
+	resolved := map[string][]string{}
+	for rootPlugin, dependentPlugins := range pluginsWithVersions {
+		resolvedDependentPlugins := []string{}
+		for _, dependentPlugin := range dependentPlugins {
+			resolvedDependentPlugins = append(resolvedDependentPlugins, resolve(dependentPlugin))
+		}
+		resolved[resolve(rootPlugin)] = resolvedDependentPlugins
+	}
+	return resolved, nil
+}
+
+// resolvePluginDependencies adds missing dependencies of plugins in the latest version from the update center metadata
+// to root plugins which require them when Jenkins.Spec.Master.ResolvePluginDependencies is set, declared plugins older
+// than required are kept for validatePluginDependencies, previously resolved dependencies from Jenkins.Status.PluginDependencies
+// are used when the update center can't be reached, the plan is kept for the reconciliation loop and saved by updateResolvedPlugins
+func (r *ReconcileJenkinsBaseConfiguration) resolvePluginDependencies(pluginsWithVersions map[string][]string) (map[string][]string, error) {
+	r.pluginDependencies = nil
+	r.pluginDependencyConflicts = nil
+	if !r.jenkins.Spec.Master.ResolvePluginDependencies {
+		return pluginsWithVersions, nil
+	}
+
+	updateCenterURL := resources.GetUpdateCenterURL(r.jenkins)
+	metadata, err := getUpdateCenterMetadata(updateCenterURL)
+	if err != nil {
+		if len(r.jenkins.Status.PluginDependencies) == 0 {
+			return nil, stackerr.Wrapf(err, "dependencies of plugins can't be resolved against update center '%s'", updateCenterURL)
+		}
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Update center '%s' can't be reached, previously resolved plugin dependencies are used: %s", updateCenterURL, err))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginResolutionFailed,
+			"Update center '%s' can't be reached, previously resolved plugin dependencies are used: %s", updateCenterURL, err)
+		r.pluginDependencies = r.jenkins.Status.PluginDependencies
+		return addPluginDependencies(pluginsWithVersions, r.pluginDependencies), nil
+	}
+
+	declaredVersions := map[string]string{}
+	var userPluginNames []string
+	for _, declaredPlugins := range []map[string][]string{plugins.OperatorPlugins(r.jenkins), plugins.FeaturePlugins(r.jenkins), pluginsWithVersions} {
+		for rootPlugin, dependentPlugins := range declaredPlugins {
+			for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+				if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+					declaredVersions[plugin.Name] = plugin.Version
+				}
+			}
+		}
+	}
+	for rootPlugin, dependentPlugins := range pluginsWithVersions {
+		for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+			if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+				userPluginNames = append(userPluginNames, plugin.Name)
+			}
+		}
+	}
+	sort.Strings(userPluginNames)
+
+	dependencies := map[string]*v1alpha1.PluginDependency{}
+	conflicts := map[string]bool{}
+	queue := userPluginNames
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		version, declared := declaredVersions[name]
+		if !declared {
+			version = dependencies[name].Version
+		}
+		requiredBy := plugins.Plugin{Name: name, Version: version}.String()
+		for _, dependency := range metadata.Plugins[name].Dependencies {
+			if dependency.Optional {
+				continue
+			}
+			required := plugins.Plugin{Name: dependency.Name, Version: dependency.Version}.String()
+			if declaredVersion, ok := declaredVersions[dependency.Name]; ok {
+				if plugins.CompareVersions(declaredVersion, dependency.Version) < 0 {
+					conflicts[fmt.Sprintf("plugin '%s' requires '%s' or newer but '%s:%s' is declared",
+						requiredBy, required, dependency.Name, declaredVersion)] = true
+				}
+				continue
+			}
+			available, ok := metadata.Plugins[dependency.Name]
+			if !ok {
+				conflicts[fmt.Sprintf("plugin '%s' requires '%s' which isn't available in the update center", requiredBy, required)] = true
+				continue
+			}
+			if plugins.CompareVersions(available.Version, dependency.Version) < 0 {
+				conflicts[fmt.Sprintf("plugin '%s' requires '%s' or newer but the update center offers '%s:%s'",
+					requiredBy, required, dependency.Name, available.Version)] = true
+				continue
+			}
+			if resolved, ok := dependencies[dependency.Name]; ok {
+				resolved.RequiredBy = append(resolved.RequiredBy, name)
+				continue
+			}
+			dependencies[dependency.Name] = &v1alpha1.PluginDependency{Name: dependency.Name, Version: available.Version, RequiredBy: []string{name}}
+			queue = append(queue, dependency.Name)
+		}
+	}
+
+	for _, dependency := range dependencies {
+		sort.Strings(dependency.RequiredBy)
+		r.pluginDependencies = append(r.pluginDependencies, *dependency)
+	}
+	sort.Slice(r.pluginDependencies, func(i, j int) bool {
+		return r.pluginDependencies[i].Name < r.pluginDependencies[j].Name
+	})
+	for conflict := range conflicts {
+		r.pluginDependencyConflicts = append(r.pluginDependencyConflicts, conflict)
+	}
+	sort.Strings(r.pluginDependencyConflicts)
+	return addPluginDependencies(pluginsWithVersions, r.pluginDependencies), nil
+}
+
+// addPluginDependencies adds resolved dependencies to every root plugin which requires them directly or transitively
+func addPluginDependencies(pluginsWithVersions map[string][]string, dependencies []v1alpha1.PluginDependency) map[string][]string {
+	if len(dependencies) == 0 {
+		return pluginsWithVersions
+	}
+
+	// key - plugin name, value - names of resolved dependencies required by the plugin
+	requiredDependencies := map[string][]string{}
+	for _, dependency := range dependencies {
+		for _, name := range dependency.RequiredBy {
+			requiredDependencies[name] = append(requiredDependencies[name], dependency.Name)
+		}
+	}
+	versions := map[string]string{}
+	for _, dependency := range dependencies {
+		versions[dependency.Name] = dependency.Version
+	}
+
+	resolved := map[string][]string{}
+	for rootPlugin, dependentPlugins := range pluginsWithVersions {
+		var queue []string
+		for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+			if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+				queue = append(queue, plugin.Name)
+			}
+		}
+		added := map[string]bool{}
+		var addedNames []string
+		for len(queue) > 0 {
+			name := queue[0]
+			queue = queue[1:]
+			for _, dependency := range requiredDependencies[name] {
+				if !added[dependency] {
+					added[dependency] = true
+					addedNames = append(addedNames, dependency)
+					queue = append(queue, dependency)
+				}
+			}
+		}
+		sort.Strings(addedNames)
+
+		resolvedDependentPlugins := append([]string{}, dependentPlugins...)
+		for _, name := range addedNames {
+			resolvedDependentPlugins = append(resolvedDependentPlugins, plugins.Plugin{Name: name, Version: versions[name]}.String())
+		}
+		resolved[rootPlugin] = resolvedDependentPlugins
+	}
+	return resolved
+}
+
+// validatePluginDependencies reports dependencies of plugins which can't be satisfied by declared plugin versions
+// or the update center, they fail the validation
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginDependencies() bool {
+	if len(r.pluginDependencyConflicts) == 0 {
+		return true
+	}
+
+	for _, conflict := range r.pluginDependencyConflicts {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugin dependency conflict: %s", conflict))
+		r.validationErrors = append(r.validationErrors, fmt.Sprintf("Plugin dependency conflict: %s", conflict))
+	}
+	r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginDependencyConflict,
+		"Plugin dependencies can't be resolved: %s", strings.Join(r.pluginDependencyConflicts, ", "))
+	return false
+}
+
+// validateBasePluginsOverride checks plugins in Jenkins.Spec.Master.BasePluginsOverride are required by operator
+// and the overridden versions satisfy dependencies from the update center metadata, the update center metadata contains
+// dependencies of the latest version of every plugin so only plugins declared in the latest version are checked
+func (r *ReconcileJenkinsBaseConfiguration) validateBasePluginsOverride(userPlugins map[string][]string) bool {
+	overrides := r.jenkins.Spec.Master.BasePluginsOverride
+	if len(overrides) == 0 {
+		return true
+	}
+
+	basePlugins := map[string]bool{}
+	for rootPlugin, dependentPlugins := range r.jenkins.Spec.Master.OperatorPlugins {
+		for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+			if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+				basePlugins[plugin.Name] = true
+			}
+		}
+	}
+	valid := true
+	for name, version := range overrides {
+		if !basePlugins[name] {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugin '%s' in basePluginsOverride isn't required by operator, declare it in spec.master.plugins", name))
+			valid = false
+		} else if _, err := plugins.New(name + ":" + version); err != nil || len(version) == 0 || strings.ContainsAny(version, ":@") {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid version '%s' of plugin '%s' in basePluginsOverride", version, name))
+			valid = false
+		}
+	}
+	if !valid {
+		return false
+	}
+
+	declaredVersions := map[string]string{}
+	for _, declaredPlugins := range []map[string][]string{plugins.OperatorPlugins(r.jenkins), plugins.FeaturePlugins(r.jenkins), userPlugins} {
+		for rootPlugin, dependentPlugins := range declaredPlugins {
+			for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+				if plugin, err := plugins.New(pluginNameWithVersion); err == nil {
+					declaredVersions[plugin.Name] = plugin.Version
+				}
+			}
+		}
+	}
+
+	updateCenterURL := resources.GetUpdateCenterURL(r.jenkins)
+	metadata, err := getUpdateCenterMetadata(updateCenterURL)
+	if err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Update center '%s' can't be reached, dependencies of overridden base plugins aren't checked: %s", updateCenterURL, err))
+		return true
+	}
+
+	var conflicts []string
+	for name, version := range declaredVersions {
+		if metadata.Plugins[name].Version != version {
+			continue
+		}
+		for _, dependency := range metadata.Plugins[name].Dependencies {
+			declaredVersion, ok := declaredVersions[dependency.Name]
+			_, overriddenPlugin := overrides[name]
+			_, overriddenDependency := overrides[dependency.Name]
+			if dependency.Optional || !ok || (!overriddenPlugin && !overriddenDependency) {
+				continue
+			}
+			if plugins.CompareVersions(declaredVersion, dependency.Version) < 0 {
+				conflicts = append(conflicts, fmt.Sprintf("plugin '%s:%s' requires '%s:%s' or newer but '%s:%s' is declared",
+					name, version, dependency.Name, dependency.Version, dependency.Name, declaredVersion))
+			}
+		}
+	}
+	if len(conflicts) == 0 {
+		return true
+	}
+
+	sort.Strings(conflicts)
+	r.logger.V(log.VWarn).Info(fmt.Sprintf("Overridden base plugins don't satisfy dependencies: %s", strings.Join(conflicts, ", ")))
+	r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginDependencyConflict,
+		"Overridden base plugins don't satisfy dependencies: %s", strings.Join(conflicts, ", "))
+	return false
+}
+
+// updateResolvedPlugins saves versions of plugins resolved by resolveLatestPlugins and dependencies of plugins
+// resolved by resolvePluginDependencies in Jenkins.Status section
+func (r *ReconcileJenkinsBaseConfiguration) updateResolvedPlugins() error {
+	if reflect.DeepEqual(r.resolvedPlugins, r.jenkins.Status.ResolvedPlugins) &&
+		reflect.DeepEqual(r.pluginDependencies, r.jenkins.Status.PluginDependencies) {
+		return nil
+	}
+
+	if len(r.pluginDependencies) > 0 && !reflect.DeepEqual(r.pluginDependencies, r.jenkins.Status.PluginDependencies) {
+		var dependencies []string
+		for _, dependency := range r.pluginDependencies {
+			dependencies = append(dependencies, plugins.Plugin{Name: dependency.Name, Version: dependency.Version}.String())
+		}
+		r.logger.Info(fmt.Sprintf("Plugin dependencies have been resolved: %s", strings.Join(dependencies, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeNormal, reasonPluginDependenciesResolved,
+			"Plugin dependencies have been resolved: %s", strings.Join(dependencies, ", "))
+	}
+
+	if len(r.resolvedPlugins) > 0 && !reflect.DeepEqual(r.resolvedPlugins, r.jenkins.Status.ResolvedPlugins) {
+		var changes []string
+		for _, plugin := range r.resolvedPlugins {
+			changes = append(changes, plugins.Plugin{Name: plugin.Name, Version: plugin.Version}.String())
+		}
+		r.logger.Info(fmt.Sprintf("Latest plugin versions have been resolved: %s", strings.Join(changes, ", ")))
+	}
+
+	r.jenkins.Status.ResolvedPlugins = r.resolvedPlugins
+	r.jenkins.Status.PluginDependencies = r.pluginDependencies
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
@@ -0,0 +1,274 @@
+package base
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestResolveLatestPlugins(t *testing.T) {
+	available := true
+	updateCenter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available || r.URL.Path != updateCenterMetadataPath {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plugins": {"git": {"version": "3.10.0"}, "job-dsl": {"version": "1.72"}}}`))
+	}))
+	defer updateCenter.Close()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{
+				UpdateCenterURL: updateCenter.URL + "/",
+				Plugins:         map[string][]string{"git:latest": {"job-dsl:latest", "scm-api:2.3.0"}},
+			},
+		},
+	}
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	expected := map[string][]string{"git:3.10.0": {"job-dsl:1.72", "scm-api:2.3.0"}}
+
+	userPlugins, err := baseReconcileLoop.getUserPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, userPlugins)
+	assert.NoError(t, baseReconcileLoop.updateResolvedPlugins())
+	assert.Equal(t, []v1alpha1.ResolvedPlugin{{Name: "git", Version: "3.10.0"}, {Name: "job-dsl", Version: "1.72"}}, jenkins.Status.ResolvedPlugins)
+
+	// previously resolved versions are used when the update center can't be reached
+	available = false
+	updateCenterMetadataCache = map[string]cachedUpdateCenterMetadata{}
+	baseReconcileLoop = New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	userPlugins, err = baseReconcileLoop.getUserPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, userPlugins)
+	assert.Equal(t, []event.Reason{reasonPluginResolutionFailed}, events.reasons)
+
+	// plugins which have never been resolved can't be installed
+	jenkins.Spec.Master.Plugins["workflow-job:latest"] = nil
+	baseReconcileLoop = New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	_, err = baseReconcileLoop.getUserPlugins()
+	assert.Error(t, err)
+}
+
+func TestValidatePluginSecurityWarnings(t *testing.T) {
+	updateCenter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"warnings": [
+			{"type": "plugin", "id": "SECURITY-2", "name": "git", "url": "https://jenkins.io/security/advisory/2",
+				"versions": [{"lastVersion": "3.9.1", "pattern": "3[.][0-8](|[.-].*)|3[.]9[.][01]"}]},
+			{"type": "plugin", "id": "SECURITY-1", "name": "git", "url": "https://jenkins.io/security/advisory/1",
+				"versions": [{"lastVersion": "3.9.1", "pattern": "3[.]9[.]1"}]},
+			{"type": "plugin", "id": "SECURITY-3", "name": "job-dsl", "url": "https://jenkins.io/security/advisory/3",
+				"versions": [{"lastVersion": "1.70", "pattern": "1[.]([0-6][0-9]|70)"}]},
+			{"type": "core", "id": "SECURITY-4", "name": "core", "url": "https://jenkins.io/security/advisory/4",
+				"versions": [{"lastVersion": "2.150", "pattern": ".*"}]}
+		]}`))
+	}))
+	defer updateCenter.Close()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{UpdateCenterURL: updateCenter.URL},
+		},
+	}
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	userPlugins := map[string][]string{"git:3.9.1": {"job-dsl:1.71"}}
+
+	// warnings are reported once
+	valid, err := baseReconcileLoop.validatePluginSecurityWarnings(userPlugins)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = baseReconcileLoop.validatePluginSecurityWarnings(userPlugins)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []event.Reason{reasonPluginSecurityWarnings}, events.reasons)
+	assert.Equal(t, []v1alpha1.PluginSecurityWarning{
+		{Name: "git", Version: "3.9.1", ID: "SECURITY-1", URL: "https://jenkins.io/security/advisory/1"},
+		{Name: "git", Version: "3.9.1", ID: "SECURITY-2", URL: "https://jenkins.io/security/advisory/2"},
+	}, jenkins.Status.PluginSecurityWarnings)
+
+	jenkins.Spec.Master.FailOnSecurityWarnings = true
+	valid, err = baseReconcileLoop.validatePluginSecurityWarnings(userPlugins)
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = baseReconcileLoop.validatePluginSecurityWarnings(map[string][]string{"git:3.10.0": {"job-dsl:1.71"}})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	assert.Empty(t, jenkins.Status.PluginSecurityWarnings)
+}
+
+func TestValidatePluginCompatibility(t *testing.T) {
+	updateCenter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plugins": {
+			"git": {"version": "4.11.0", "requiredCore": "2.263.1"},
+			"job-dsl": {"version": "1.77", "requiredCore": "2.150.1"},
+			"workflow-job": {"version": "2.40", "requiredCore": "2.248"}
+		}}`))
+	}))
+	defer updateCenter.Close()
+
+	for image, version := range map[string]string{
+		"jenkins/jenkins:2.150.1":                                 "2.150.1",
+		"jenkins/jenkins:2.150.1-alpine":                          "2.150.1",
+		"registry.example.com:5000/jenkins:2.263.1":               "2.263.1",
+		"jenkins/jenkins:2.277@sha256:" + strings.Repeat("a", 64): "2.277",
+	} {
+		resolved, ok := getJenkinsCoreVersion(image)
+		assert.True(t, ok, image)
+		assert.Equal(t, version, resolved, image)
+	}
+	for _, image := range []string{"jenkins/jenkins:lts", "jenkins/jenkins", "registry.example.com:5000/jenkins"} {
+		_, ok := getJenkinsCoreVersion(image)
+		assert.False(t, ok, image)
+	}
+
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{Image: "jenkins/jenkins:2.150.1", UpdateCenterURL: updateCenter.URL},
+		},
+	}
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+
+	// versions other than the latest one aren't described by the update center
+	userPlugins := map[string][]string{"git:4.11.0": {"job-dsl:1.77", "workflow-job:2.32"}}
+	assert.False(t, baseReconcileLoop.validatePluginCompatibility(userPlugins))
+	assert.Equal(t, []event.Reason{reasonPluginIncompatible}, events.reasons)
+	assert.Equal(t, []string{"Plugins aren't compatible with Jenkins 2.150.1: git:4.11.0 requires Jenkins 2.263.1"},
+		baseReconcileLoop.ValidationErrors())
+
+	jenkins.Spec.Master.Image = "jenkins/jenkins:2.263.1-alpine"
+	assert.True(t, baseReconcileLoop.validatePluginCompatibility(userPlugins))
+	jenkins.Spec.Master.Image = "jenkins/jenkins:lts"
+	assert.True(t, baseReconcileLoop.validatePluginCompatibility(userPlugins))
+	jenkins.Spec.Master.Image = "jenkins/jenkins:2.150.1"
+	jenkins.Spec.Master.SkipPluginCompatibilityCheck = true
+	assert.True(t, baseReconcileLoop.validatePluginCompatibility(userPlugins))
+	assert.Equal(t, []event.Reason{reasonPluginIncompatible}, events.reasons)
+}
+
+func TestResolvePluginDependencies(t *testing.T) {
+	available := true
+	updateCenter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"plugins": {
+			"git": {"version": "3.10.0", "dependencies": [
+				{"name": "git-client", "version": "2.7.6", "optional": false},
+				{"name": "scm-api", "version": "2.3.0", "optional": false},
+				{"name": "promoted-builds", "version": "2.27", "optional": true}]},
+			"git-client": {"version": "2.7.7", "dependencies": [{"name": "ssh-credentials", "version": "1.13", "optional": false}]},
+			"scm-api": {"version": "2.4.1", "dependencies": [{"name": "structs", "version": "1.17", "optional": false}]},
+			"ssh-credentials": {"version": "1.16"},
+			"structs": {"version": "1.19"},
+			"promoted-builds": {"version": "3.2"}
+		}}`))
+	}))
+	defer updateCenter.Close()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{
+				UpdateCenterURL:           updateCenter.URL,
+				ResolvePluginDependencies: true,
+				Plugins:                   map[string][]string{"git:3.10.0": {"scm-api:2.3.0"}},
+			},
+		},
+	}
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	expected := map[string][]string{"git:3.10.0": {"scm-api:2.3.0", "git-client:2.7.7", "ssh-credentials:1.16", "structs:1.19"}}
+
+	// missing dependencies are installed in the latest version, declared ones are kept
+	userPlugins, err := baseReconcileLoop.getUserPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, userPlugins)
+	assert.True(t, baseReconcileLoop.validatePluginDependencies())
+	assert.NoError(t, baseReconcileLoop.updateResolvedPlugins())
+	assert.Equal(t, []v1alpha1.PluginDependency{
+		{Name: "git-client", Version: "2.7.7", RequiredBy: []string{"git"}},
+		{Name: "ssh-credentials", Version: "1.16", RequiredBy: []string{"git-client"}},
+		{Name: "structs", Version: "1.19", RequiredBy: []string{"scm-api"}},
+	}, jenkins.Status.PluginDependencies)
+	assert.Equal(t, []event.Reason{reasonPluginDependenciesResolved}, events.reasons)
+
+	// previously resolved dependencies are used when the update center can't be reached
+	available = false
+	updateCenterMetadataCache = map[string]cachedUpdateCenterMetadata{}
+	baseReconcileLoop = New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	userPlugins, err = baseReconcileLoop.getUserPlugins()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, userPlugins)
+	assert.NoError(t, baseReconcileLoop.updateResolvedPlugins())
+	assert.Equal(t, []event.Reason{reasonPluginDependenciesResolved, reasonPluginResolutionFailed}, events.reasons)
+
+	// declared plugins older than required fail the validation
+	available = true
+	updateCenterMetadataCache = map[string]cachedUpdateCenterMetadata{}
+	jenkins.Spec.Master.Plugins = map[string][]string{"git:3.10.0": {"git-client:2.7.0"}}
+	baseReconcileLoop = New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+	_, err = baseReconcileLoop.getUserPlugins()
+	assert.NoError(t, err)
+	assert.False(t, baseReconcileLoop.validatePluginDependencies())
+	assert.Equal(t, []string{"plugin 'git:3.10.0' requires 'git-client:2.7.6' or newer but 'git-client:2.7.0' is declared"},
+		baseReconcileLoop.pluginDependencyConflicts)
+}
+
+func TestValidateBasePluginsOverride(t *testing.T) {
+	updateCenter := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"plugins": {
+			"kubernetes": {"version": "1.14.0", "dependencies": [{"name": "credentials", "version": "2.1.18", "optional": false}]},
+			"git": {"version": "3.10.0", "dependencies": [{"name": "credentials", "version": "2.1.19", "optional": false}]}
+		}}`))
+	}))
+	defer updateCenter.Close()
+
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{
+				UpdateCenterURL:     updateCenter.URL,
+				OperatorPlugins:     map[string][]string{"kubernetes:1.13.8": {"credentials:2.1.18"}},
+				BasePluginsOverride: map[string]string{"kubernetes": "1.14.0"},
+			},
+		},
+	}
+	events := &fakeRecorder{}
+	baseReconcileLoop := New(fake.NewFakeClient(jenkins), nil, logf.ZapLogger(false), jenkins, false, false, events)
+
+	assert.True(t, baseReconcileLoop.validateBasePluginsOverride(map[string][]string{}))
+
+	// the overridden plugin is required by the user plugin in a newer version
+	jenkins.Spec.Master.BasePluginsOverride = map[string]string{"credentials": "2.1.18"}
+	assert.True(t, baseReconcileLoop.validateBasePluginsOverride(map[string][]string{}))
+	assert.False(t, baseReconcileLoop.validateBasePluginsOverride(map[string][]string{"git:3.10.0": {}}))
+	assert.Equal(t, []event.Reason{reasonPluginDependencyConflict}, events.reasons)
+
+	jenkins.Spec.Master.BasePluginsOverride = map[string]string{"git": "3.10.0"}
+	assert.False(t, baseReconcileLoop.validateBasePluginsOverride(map[string][]string{}))
+
+	jenkins.Spec.Master.BasePluginsOverride = map[string]string{"kubernetes": "1.14.0@sha256:invalid"}
+	assert.False(t, baseReconcileLoop.validateBasePluginsOverride(map[string][]string{}))
+}
@@ -1,22 +1,53 @@
 package base
 
 import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"net/url"
+	"path"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	docker "github.com/docker/distribution/reference"
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 var (
 	dockerImageRegexp = regexp.MustCompile(`^` + docker.TagRegexp.String() + `$`)
+	// noProxyHostRegexp matches hosts, domain suffixes, IP addresses and CIDRs excluded from proxying
+	noProxyHostRegexp = regexp.MustCompile(`^$|^[*.]?[\w.:/-]+$`)
+	// extraFilePathRegexp matches paths of extra files which are safe to use in the init script
+	extraFilePathRegexp = regexp.MustCompile(`^[-._a-zA-Z0-9/]+$`)
+	// reservedExtraFileDirectories contains directories of Jenkins home managed by operator
+	reservedExtraFileDirectories = []string{"init.groovy.d", "scripts"}
+	// jobNamePrefixRegexp matches prefixes which make valid Jenkins job names
+	jobNamePrefixRegexp = regexp.MustCompile(`^[-_a-zA-Z0-9][-._a-zA-Z0-9]*$`)
 )
 
+// ValidationErrors returns messages about conflicting plugins and unknown permissions found by Validate, other failures are only logged
+func (r *ReconcileJenkinsBaseConfiguration) ValidationErrors() []string {
+	return r.validationErrors
+}
+
 // Validate validates Jenkins CR Spec.master section
 func (r *ReconcileJenkinsBaseConfiguration) Validate(jenkins *v1alpha1.Jenkins) (bool, error) {
+	r.validationErrors = nil
 	if jenkins.Spec.Master.Image == "" {
 		r.logger.V(log.VWarn).Info("Image not set")
 		return false, nil
@@ -28,23 +59,750 @@ func (r *ReconcileJenkinsBaseConfiguration) Validate(jenkins *v1alpha1.Jenkins)
 
 	}
 
-	if !r.validatePlugins(jenkins.Spec.Master.OperatorPlugins, jenkins.Spec.Master.Plugins) {
+	if err := validateResourceRequirements(jenkins.Spec.Master.Resources); err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid master resources: %s", err))
+		return false, nil
+	}
+
+	if len(jenkins.Spec.JobNamePrefix) > 0 && !jobNamePrefixRegexp.MatchString(jenkins.Spec.JobNamePrefix) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid job name prefix '%s'", jenkins.Spec.JobNamePrefix))
+		return false, nil
+	}
+
+	if jenkins.Spec.Master.NumExecutors != nil && *jenkins.Spec.Master.NumExecutors < 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid number of master executors %d, it can't be negative", *jenkins.Spec.Master.NumExecutors))
+		return false, nil
+	}
+
+	if timezone := jenkins.Spec.Master.Timezone; len(timezone) > 0 {
+		if err := validateTimezone(timezone); err != nil {
+			message := fmt.Sprintf("Invalid timezone '%s' in spec.master.timezone: %s", timezone, err)
+			r.logger.V(log.VWarn).Info(message)
+			r.validationErrors = append(r.validationErrors, message)
+			return false, nil
+		}
+	}
+
+	for _, id := range jenkins.Spec.Master.DisabledAdministrativeMonitors {
+		if len(strings.TrimSpace(id)) == 0 {
+			r.logger.V(log.VWarn).Info("Administrative monitor ID in spec.master.disabledAdministrativeMonitors can't be empty")
+			return false, nil
+		}
+	}
+
+	if valid, err := r.validateAdminCredentials(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if !r.validateTokenRotationInterval(jenkins) {
+		return false, nil
+	}
+
+	if valid, err := r.validateSecurityRealm(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if !r.validateAuthorization(jenkins) {
+		return false, nil
+	}
+
+	if valid, err := r.validateSMTP(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validateNotifications(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validatePluginsFile(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	userPlugins, err := r.getUserPlugins()
+	if err != nil {
+		return false, err
+	}
+	if !r.validatePluginDependencies() {
+		return false, nil
+	}
+	if !r.validateBasePluginsOverride(userPlugins) {
+		return false, nil
+	}
+	if _, constraints := plugins.SplitConstraints(jenkins.Spec.Master.OperatorPlugins); len(constraints) > 0 {
+		r.logger.V(log.VWarn).Info("Version constraints of plugins are allowed only in spec.master.plugins")
+		return false, nil
+	}
+	if !r.validatePluginSources(
+		pluginSource{name: "spec.master.basePlugins", plugins: plugins.OperatorPlugins(jenkins)},
+		pluginSource{name: "spec.master.plugins", plugins: userPlugins},
+		pluginSource{name: "spec.master.plugins", plugins: r.pluginConstraints},
+		pluginSource{name: "plugins required by enabled features", plugins: plugins.FeaturePlugins(jenkins)},
+	) {
+		return false, nil
+	}
+	if !r.validatePluginDowngrades(plugins.OperatorPlugins(jenkins), userPlugins, plugins.FeaturePlugins(jenkins)) {
+		return false, nil
+	}
+
+	if valid, err := r.validatePluginSecurityWarnings(plugins.OperatorPlugins(jenkins), userPlugins, plugins.FeaturePlugins(jenkins)); !valid || err != nil {
+		return valid, err
+	}
+	if !r.validatePluginCompatibility(plugins.OperatorPlugins(jenkins), userPlugins, plugins.FeaturePlugins(jenkins)) {
+		return false, nil
+	}
+
+	if retries := jenkins.Spec.Master.PluginDownloadRetries; retries != nil && (retries.Attempts < 0 || retries.DelaySeconds < 0) {
+		r.logger.V(log.VWarn).Info("Plugin download attempts and delay can't be negative")
+		return false, nil
+	}
+
+	if !r.validateAgent(jenkins.Spec.Agent) {
+		return false, nil
+	}
+
+	if !r.validateSeedAgent(jenkins.Spec.SeedAgent) {
+		return false, nil
+	}
+
+	if valid, err := r.validateProxy(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if updateCenterURL := jenkins.Spec.Master.UpdateCenterURL; len(updateCenterURL) > 0 {
+		if err := validateUpdateCenterURL(updateCenterURL); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid update center url '%s': %s", updateCenterURL, err))
+			return false, nil
+		}
+	}
+
+	if valid, err := r.validateAdditionalCACerts(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validateExtraFiles(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validateInitScripts(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validateBaseConfigurationOverrides(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	if valid, err := r.validateUserConfiguration(jenkins); !valid || err != nil {
+		return valid, err
+	}
+
+	return r.validateConfigurationAsCode(jenkins)
+}
+
+func (r *ReconcileJenkinsBaseConfiguration) validateAgent(agent v1alpha1.JenkinsAgent) bool {
+	if len(agent.Image) > 0 && !dockerImageRegexp.MatchString(agent.Image) && !docker.ReferenceRegexp.MatchString(agent.Image) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid agent image '%s'", agent.Image))
+		return false
+	}
+
+	for _, secret := range agent.ImagePullSecrets {
+		if errs := validation.IsDNS1123Subdomain(secret.Name); len(errs) > 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid agent image pull secret name '%s': %s", secret.Name, strings.Join(errs, ", ")))
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *ReconcileJenkinsBaseConfiguration) validateSeedAgent(seedAgent v1alpha1.SeedAgent) bool {
+	if len(seedAgent.Image) > 0 && !dockerImageRegexp.MatchString(seedAgent.Image) && !docker.ReferenceRegexp.MatchString(seedAgent.Image) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid seed agent image '%s'", seedAgent.Image))
+		return false
+	}
+
+	if err := validateResourceRequirements(seedAgent.Resources); err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid seed agent resources: %s", err))
+		return false
+	}
+	// the kubernetes plugin configures only CPU and memory of the agent container
+	for _, resourceList := range []corev1.ResourceList{seedAgent.Resources.Requests, seedAgent.Resources.Limits} {
+		for name := range resourceList {
+			if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid seed agent resources: unsupported resource '%s'", name))
+				return false
+			}
+		}
+	}
+
+	if len(seedAgent.ServiceAccountName) > 0 {
+		if errs := validation.IsDNS1123Subdomain(seedAgent.ServiceAccountName); len(errs) > 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid seed agent service account name '%s': %s", seedAgent.ServiceAccountName, strings.Join(errs, ", ")))
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *ReconcileJenkinsBaseConfiguration) validateProxy(jenkins *v1alpha1.Jenkins) (bool, error) {
+	proxy := jenkins.Spec.Proxy
+	if proxy == nil {
+		return true, nil
+	}
+
+	if len(proxy.HTTPProxy) == 0 && len(proxy.HTTPSProxy) == 0 {
+		r.logger.V(log.VWarn).Info("Invalid proxy: one of httpProxy and httpsProxy has to be set")
+		return false, nil
+	}
+	for _, proxyURL := range []string{proxy.HTTPProxy, proxy.HTTPSProxy} {
+		if len(proxyURL) == 0 {
+			continue
+		}
+		if err := validateProxyURL(proxyURL); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid proxy url '%s': %s", proxyURL, err))
+			return false, nil
+		}
+	}
+	for _, host := range strings.Split(proxy.NoProxy, ",") {
+		if !noProxyHostRegexp.MatchString(strings.TrimSpace(host)) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid proxy noProxy host '%s'", host))
+			return false, nil
+		}
+	}
+
+	if proxy.CredentialsSecretRef == nil {
+		return true, nil
+	}
+	secret := &corev1.Secret{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: proxy.CredentialsSecretRef.Name}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with proxy credentials not found", proxy.CredentialsSecretRef.Name))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+	for _, key := range []string{resources.ProxyUsernameKey, resources.ProxyPasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with proxy credentials doesn't contain '%s' key", proxy.CredentialsSecretRef.Name, key))
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// validateProxyURL checks the proxy url is an absolute http or https url without credentials, they are provided by the secret
+func validateProxyURL(proxyURL string) error {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return stackerr.Errorf("unsupported scheme '%s', allowed values are 'http' and 'https'", parsed.Scheme)
+	}
+	if len(parsed.Hostname()) == 0 {
+		return stackerr.New("host can't be empty")
+	}
+	if parsed.User != nil {
+		return stackerr.New("credentials have to be provided by credentialsSecretRef")
+	}
+	return nil
+}
+
+// validateUpdateCenterURL checks the update center url is an absolute http or https url without query and fragment,
+// paths of update center files are appended to it
+func validateUpdateCenterURL(updateCenterURL string) error {
+	parsed, err := url.Parse(updateCenterURL)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return stackerr.Errorf("unsupported scheme '%s', allowed values are 'http' and 'https'", parsed.Scheme)
+	}
+	if len(parsed.Hostname()) == 0 {
+		return stackerr.New("host can't be empty")
+	}
+	if len(parsed.RawQuery) > 0 || len(parsed.Fragment) > 0 {
+		return stackerr.New("query and fragment aren't allowed")
+	}
+	return nil
+}
+
+// validateTimezone checks the time zone is known by the tz database, Local isn't allowed because it's the time zone
+// of the operator
+func validateTimezone(timezone string) error {
+	if timezone == "Local" {
+		return stackerr.New("the IANA time zone name is required")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return stackerr.New("unknown time zone, the IANA time zone name is required, e.g. Europe/Warsaw")
+	}
+	return nil
+}
+
+// validateResourceRequirements checks that the requests don't exceed the limits
+func validateResourceRequirements(resources corev1.ResourceRequirements) error {
+	for name, request := range resources.Requests {
+		limit, ok := resources.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			return stackerr.Errorf("%s request '%s' is greater than limit '%s'", name, request.String(), limit.String())
+		}
+	}
+	return nil
+}
+
+func (r *ReconcileJenkinsBaseConfiguration) validateAdditionalCACerts(jenkins *v1alpha1.Jenkins) (bool, error) {
+	selector := jenkins.Spec.Master.AdditionalCACerts
+	if selector == nil {
+		return true, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: selector.Name}, secret)
+	if err != nil && errors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with additional CA certificates not found", selector.Name))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
+	if err := validateCACertificates(secret.Data[selector.Key]); err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid additional CA certificates in secret '%s': %s", selector.Name, err))
 		return false, nil
 	}
 
 	return true, nil
 }
 
-func (r *ReconcileJenkinsBaseConfiguration) validatePlugins(pluginsWithVersionSlice ...map[string][]string) bool {
+// validateExtraFiles checks paths of extra files stay in Jenkins home and referenced ConfigMap and Secret keys exist
+func (r *ReconcileJenkinsBaseConfiguration) validateExtraFiles(jenkins *v1alpha1.Jenkins) (bool, error) {
+	paths := map[string]bool{}
+	for _, extraFile := range jenkins.Spec.Master.ExtraFiles {
+		if err := validateExtraFilePath(extraFile.Path); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid extra file '%s': %s", extraFile.Path, err))
+			return false, nil
+		}
+		if paths[extraFile.Path] {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Duplicate extra file '%s'", extraFile.Path))
+			return false, nil
+		}
+		paths[extraFile.Path] = true
+
+		if (extraFile.ConfigMapKeyRef == nil) == (extraFile.SecretKeyRef == nil) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid extra file '%s': exactly one of configMapKeyRef and secretKeyRef has to be set", extraFile.Path))
+			return false, nil
+		}
+
+		if reference := extraFile.ConfigMapKeyRef; reference != nil {
+			configMap := &corev1.ConfigMap{}
+			err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, configMap)
+			if err != nil && errors.IsNotFound(err) {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' with extra file '%s' not found", reference.Name, extraFile.Path))
+				return false, nil
+			} else if err != nil {
+				return false, stackerr.WithStack(err)
+			}
+			if _, ok := configMap.Data[reference.Key]; !ok {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Key '%s' of extra file '%s' not found in ConfigMap '%s'", reference.Key, extraFile.Path, reference.Name))
+				return false, nil
+			}
+		} else {
+			reference := extraFile.SecretKeyRef
+			secret := &corev1.Secret{}
+			err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, secret)
+			if err != nil && errors.IsNotFound(err) {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with extra file '%s' not found", reference.Name, extraFile.Path))
+				return false, nil
+			} else if err != nil {
+				return false, stackerr.WithStack(err)
+			}
+			if _, ok := secret.Data[reference.Key]; !ok {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Key '%s' of extra file '%s' not found in Secret '%s'", reference.Key, extraFile.Path, reference.Name))
+				return false, nil
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// validateExtraFilePath checks the path is relative to Jenkins home and doesn't point outside of it
+// or into directories managed by operator
+func validateExtraFilePath(extraFilePath string) error {
+	if !extraFilePathRegexp.MatchString(extraFilePath) {
+		return stackerr.New("path may contain only letters, digits, '-', '_', '.' and '/'")
+	}
+	if path.IsAbs(extraFilePath) {
+		return stackerr.New("path has to be relative to Jenkins home")
+	}
+	if path.Clean(extraFilePath) != extraFilePath || extraFilePath == "." {
+		return stackerr.New("path has to be clean, without trailing '/' and '.' or empty segments")
+	}
+	if extraFilePath == ".." || strings.HasPrefix(extraFilePath, "../") {
+		return stackerr.New("path points outside of Jenkins home")
+	}
+	for _, directory := range reservedExtraFileDirectories {
+		if extraFilePath == directory || strings.HasPrefix(extraFilePath, directory+"/") {
+			return stackerr.Errorf("directory '%s' is managed by operator", directory)
+		}
+	}
+	return nil
+}
+
+// validatePluginDowngrades compares declared plugin versions with versions in Jenkins.Status.InstalledPlugins,
+// declared versions older than installed ones are reported as the warning event and fail the validation
+// unless Jenkins.Spec.Master.AllowPluginDowngrades is set
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginDowngrades(pluginsWithVersions ...map[string][]string) bool {
+	installedVersions := map[string]string{}
+	for _, installedPlugin := range r.jenkins.Status.InstalledPlugins {
+		installedVersions[installedPlugin.Name] = installedPlugin.Version
+	}
+
+	downgrades := map[string]bool{}
+	for _, declaredPlugins := range pluginsWithVersions {
+		for rootPlugin, dependentPlugins := range declaredPlugins {
+			for _, pluginNameWithVersion := range append([]string{rootPlugin}, dependentPlugins...) {
+				plugin, err := plugins.New(pluginNameWithVersion)
+				if err != nil {
+					continue
+				}
+				if installedVersion, ok := installedVersions[plugin.Name]; ok && plugins.CompareVersions(plugin.Version, installedVersion) < 0 {
+					downgrades[fmt.Sprintf("%s installed %s declared %s", plugin.Name, installedVersion, plugin.Version)] = true
+				}
+			}
+		}
+	}
+	if len(downgrades) == 0 {
+		return true
+	}
+
+	var messages []string
+	for message := range downgrades {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	if r.jenkins.Spec.Master.AllowPluginDowngrades {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugins will be downgraded, back up Jenkins home first: %s", strings.Join(messages, ", ")))
+		r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginDowngrade,
+			"Plugins will be downgraded, back up Jenkins home first: %s", strings.Join(messages, ", "))
+		return true
+	}
+	r.logger.V(log.VWarn).Info(fmt.Sprintf("Plugin downgrades aren't allowed: %s", strings.Join(messages, ", ")))
+	r.events.Emitf(r.jenkins, event.TypeWarning, reasonPluginDowngrade,
+		"Plugin downgrades aren't allowed, set spec.master.allowPluginDowngrades to install them: %s", strings.Join(messages, ", "))
+	return false
+}
+
+// validatePluginsFile checks the ConfigMap referenced by Jenkins.Spec.Master.PluginsConfigMapRef exists
+// and contains the valid plugins.txt, the parse error with the line number is reported as the warning event
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginsFile(jenkins *v1alpha1.Jenkins) (bool, error) {
+	reference := jenkins.Spec.Master.PluginsConfigMapRef
+	if reference == nil {
+		return true, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' with plugins not found", reference.Name))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
+	content, ok := configMap.Data[resources.PluginsFileKey]
+	if !ok {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' doesn't contain the '%s' key", reference.Name, resources.PluginsFileKey))
+		return false, nil
+	}
+	if _, err := plugins.ParseFile(content); err != nil {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid %s in ConfigMap '%s': %s", resources.PluginsFileKey, reference.Name, err))
+		r.events.Emitf(jenkins, event.TypeWarning, reasonPluginsFileInvalid,
+			"Invalid %s in ConfigMap '%s': %s", resources.PluginsFileKey, reference.Name, err)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// validateInitScripts checks referenced ConfigMaps and Secrets exist and contain only groovy scripts,
+// script names have to be unique because all of them are projected into one directory
+func (r *ReconcileJenkinsBaseConfiguration) validateInitScripts(jenkins *v1alpha1.Jenkins) (bool, error) {
+	initScripts := jenkins.Spec.Master.InitScripts
+	if initScripts == nil {
+		return true, nil
+	}
+
+	if len(initScripts.ConfigMaps) == 0 && len(initScripts.Secrets) == 0 {
+		r.logger.V(log.VWarn).Info("Invalid init scripts: one of configMaps and secrets has to be set")
+		return false, nil
+	}
+
+	scripts := map[string]string{}
+	validateScripts := func(kind, name string, keys []string) bool {
+		for _, key := range keys {
+			if !strings.HasSuffix(key, ".groovy") {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid init script '%s' in %s '%s', it has to be a .groovy file", key, kind, name))
+				return false
+			}
+			if source, ok := scripts[key]; ok {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Duplicate init script '%s' in %s '%s' and %s", key, kind, name, source))
+				return false
+			}
+			scripts[key] = fmt.Sprintf("%s '%s'", kind, name)
+		}
+		return true
+	}
+
+	for _, reference := range initScripts.ConfigMaps {
+		configMap := &corev1.ConfigMap{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, configMap)
+		if err != nil && errors.IsNotFound(err) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' with init scripts not found", reference.Name))
+			return false, nil
+		} else if err != nil {
+			return false, stackerr.WithStack(err)
+		}
+		var keys []string
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		if !validateScripts("ConfigMap", reference.Name, keys) {
+			return false, nil
+		}
+	}
+	for _, reference := range initScripts.Secrets {
+		secret := &corev1.Secret{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, secret)
+		if err != nil && errors.IsNotFound(err) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with init scripts not found", reference.Name))
+			return false, nil
+		} else if err != nil {
+			return false, stackerr.WithStack(err)
+		}
+		var keys []string
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		if !validateScripts("Secret", reference.Name, keys) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// validateBaseConfigurationOverrides checks the ConfigMap with overrides exists and overrides keep the functional minimum
+// required by operator
+func (r *ReconcileJenkinsBaseConfiguration) validateBaseConfigurationOverrides(jenkins *v1alpha1.Jenkins) (bool, error) {
+	overrides := jenkins.Spec.Master.BaseConfigurationOverrides
+	if overrides == nil {
+		return true, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: overrides.Name}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' with base configuration overrides not found", overrides.Name))
+		return false, nil
+	} else if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+
 	valid := true
-	allPlugins := map[plugins.Plugin][]plugins.Plugin{}
+	for key, script := range configMap.Data {
+		if !strings.HasSuffix(key, ".groovy") {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid base configuration override '%s', it has to be a .groovy file", key))
+			valid = false
+		} else if err := resources.ValidateBaseConfigurationOverride(key, script); err != nil {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid base configuration override: %s", err))
+			valid = false
+		}
+	}
+	return valid, nil
+}
+
+// validateUserConfiguration checks ConfigMaps and Secrets with groovy scripts listed in Jenkins.Spec.Configuration exist
+func (r *ReconcileJenkinsBaseConfiguration) validateUserConfiguration(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if !resources.IsUserConfigurationEnabled(jenkins) {
+		return true, nil
+	}
+
+	validateNames := func(kind string, names []string, emptyObject runtime.Object) (bool, error) {
+		unique := map[string]bool{}
+		for _, name := range names {
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid user configuration %s name '%s': %s", kind, name, strings.Join(errs, ", ")))
+				return false, nil
+			}
+			if unique[name] {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Duplicate user configuration %s '%s'", kind, name))
+				return false, nil
+			}
+			unique[name] = true
+
+			// a new object for every name, so annotations of the previous one aren't kept
+			object := emptyObject.DeepCopyObject()
+			err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: name}, object)
+			if err != nil && errors.IsNotFound(err) {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("User configuration %s '%s' not found", kind, name))
+				return false, nil
+			} else if err != nil {
+				return false, stackerr.WithStack(err)
+			}
+
+			objectMeta, err := meta.Accessor(object)
+			if err != nil {
+				return false, stackerr.WithStack(err)
+			}
+			if _, err := resources.GetUserConfigurationPriority(objectMeta.GetAnnotations()); err != nil {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid user configuration %s '%s': %s", kind, name, err))
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	switch jenkins.Spec.Configuration.ExecutionMode {
+	case "", v1alpha1.ExecutionModeJob, v1alpha1.ExecutionModeScriptConsole:
+	default:
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid user configuration execution mode '%s', allowed values are '%s' and '%s'",
+			jenkins.Spec.Configuration.ExecutionMode, v1alpha1.ExecutionModeJob, v1alpha1.ExecutionModeScriptConsole))
+		return false, nil
+	}
+
+	if buildRetention := jenkins.Spec.Configuration.BuildRetention; buildRetention != nil && *buildRetention <= 0 {
+		r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid user configuration build retention '%d', it has to be positive", *buildRetention))
+		return false, nil
+	}
+
+	if valid, err := validateNames("ConfigMap", jenkins.Spec.Configuration.ConfigMaps, &corev1.ConfigMap{}); !valid || err != nil {
+		return valid, err
+	}
+	return validateNames("Secret", jenkins.Spec.Configuration.Secrets, &corev1.Secret{})
+}
+
+// validateConfigurationAsCode checks referenced ConfigMaps and Secrets exist and contain only YAML files,
+// file names have to be unique because all of them are projected into one directory
+func (r *ReconcileJenkinsBaseConfiguration) validateConfigurationAsCode(jenkins *v1alpha1.Jenkins) (bool, error) {
+	casc := jenkins.Spec.ConfigurationAsCode
+	if casc == nil {
+		return true, nil
+	}
+
+	if len(casc.ConfigMaps) == 0 && len(casc.Secrets) == 0 {
+		r.logger.V(log.VWarn).Info("Invalid configuration as code: one of configMaps and secrets has to be set")
+		return false, nil
+	}
 
+	files := map[string]string{}
+	validateFiles := func(kind, name string, keys []string) bool {
+		for _, key := range keys {
+			if !strings.HasSuffix(key, ".yaml") && !strings.HasSuffix(key, ".yml") {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid configuration as code file '%s' in %s '%s', it has to be a .yaml or .yml file", key, kind, name))
+				return false
+			}
+			if source, ok := files[key]; ok {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Duplicate configuration as code file '%s' in %s '%s' and %s", key, kind, name, source))
+				return false
+			}
+			files[key] = fmt.Sprintf("%s '%s'", kind, name)
+		}
+		return true
+	}
+
+	for _, reference := range casc.ConfigMaps {
+		configMap := &corev1.ConfigMap{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, configMap)
+		if err != nil && errors.IsNotFound(err) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("ConfigMap '%s' with configuration as code not found", reference.Name))
+			return false, nil
+		} else if err != nil {
+			return false, stackerr.WithStack(err)
+		}
+		var keys []string
+		for key := range configMap.Data {
+			keys = append(keys, key)
+		}
+		if !validateFiles("ConfigMap", reference.Name, keys) {
+			return false, nil
+		}
+	}
+	for _, reference := range casc.Secrets {
+		secret := &corev1.Secret{}
+		err := r.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, secret)
+		if err != nil && errors.IsNotFound(err) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("Secret '%s' with configuration as code not found", reference.Name))
+			return false, nil
+		} else if err != nil {
+			return false, stackerr.WithStack(err)
+		}
+		var keys []string
+		for key := range secret.Data {
+			keys = append(keys, key)
+		}
+		if !validateFiles("Secret", reference.Name, keys) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// validateCACertificates checks data contains at least one PEM encoded certificate and all of them can be parsed
+func validateCACertificates(data []byte) error {
+	certificates := 0
+	for {
+		block, rest := pem.Decode(data)
+		if block == nil {
+			break
+		}
+		data = rest
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return stackerr.WithStack(err)
+		}
+		certificates++
+	}
+
+	if certificates == 0 {
+		return stackerr.New("no PEM encoded certificate found")
+	}
+	return nil
+}
+
+func (r *ReconcileJenkinsBaseConfiguration) validatePlugins(pluginsWithVersionSlice ...map[string][]string) bool {
+	var sources []pluginSource
 	for _, pluginsWithVersions := range pluginsWithVersionSlice {
-		for rootPluginName, dependentPluginNames := range pluginsWithVersions {
+		sources = append(sources, pluginSource{plugins: pluginsWithVersions})
+	}
+	return r.validatePluginSources(sources...)
+}
+
+// pluginSource contains plugins declared in one place, e.g. spec.master.plugins
+type pluginSource struct {
+	name    string
+	plugins map[string][]string
+}
+
+// validatePluginSources checks the format of plugins and verifies their dependencies, the same plugin in the same version
+// can be declared in many sources, conflicting versions are reported with the sources which declare them
+func (r *ReconcileJenkinsBaseConfiguration) validatePluginSources(sources ...pluginSource) bool {
+	valid := true
+	var declaredPlugins []plugins.DeclaredPlugins
+
+	for _, source := range sources {
+		allPlugins := map[plugins.Plugin][]plugins.Plugin{}
+		for rootPluginName, dependentPluginNames := range source.plugins {
 			rootPlugin, err := plugins.New(rootPluginName)
 			if err != nil {
 				r.logger.V(log.VWarn).Info(fmt.Sprintf("Invalid root plugin name '%s'", rootPluginName))
 				valid = false
+			} else if len(rootPlugin.Constraint) > 0 {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("Root plugin '%s' can't have the version constraint", rootPluginName))
+				valid = false
 			}
 
 			var dependentPlugins []plugins.Plugin
@@ -58,14 +816,20 @@ func (r *ReconcileJenkinsBaseConfiguration) validatePlugins(pluginsWithVersionSl
 			}
 
 			if rootPlugin != nil {
-				allPlugins[*rootPlugin] = dependentPlugins
+				allPlugins[*rootPlugin] = append(allPlugins[*rootPlugin], dependentPlugins...)
 			}
 		}
+		declaredPlugins = append(declaredPlugins, plugins.DeclaredPlugins{Source: source.name, Plugins: allPlugins})
 	}
 
-	if valid {
-		return plugins.VerifyDependencies(allPlugins)
+	if !valid {
+		return false
 	}
 
-	return valid
+	conflicts := plugins.VerifyDeclaredDependencies(declaredPlugins...)
+	for _, conflict := range conflicts {
+		r.logger.V(log.VWarn).Info(conflict.String())
+		r.validationErrors = append(r.validationErrors, conflict.String())
+	}
+	return len(conflicts) == 0
 }
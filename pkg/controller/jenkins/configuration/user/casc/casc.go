@@ -0,0 +1,136 @@
+package casc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonReloaded is the event which informs JCasC YAML files have been applied again
+	reasonReloaded event.Reason = "ConfigurationAsCodeReloaded"
+)
+
+// mountedChecksumScript calculates the checksum of JCasC YAML files mounted in Jenkins master pod
+// the same way checksum calculates it from ConfigMaps and Secrets, hidden entries are created by Kubernetes
+const mountedChecksumScript = `
+import java.security.MessageDigest
+
+def digest = MessageDigest.getInstance('SHA-256')
+def files = new File('%s').listFiles().findAll { it.isFile() && !it.name.startsWith('.') }.sort { it.name }
+files.each {
+    digest.update(it.name.getBytes('UTF-8'))
+    digest.update(it.bytes)
+}
+print Base64.getEncoder().encodeToString(digest.digest())
+`
+
+// ConfigurationAsCode defines API client for Jenkins Configuration as Code
+type ConfigurationAsCode struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates ConfigurationAsCode object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *ConfigurationAsCode {
+	return &ConfigurationAsCode{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// EnsureConfigurationAsCode reloads JCasC YAML files when content of ConfigMaps and Secrets referenced by
+// Jenkins.Spec.ConfigurationAsCode changed, returns false when Kubernetes hasn't updated mounted files yet
+func (c *ConfigurationAsCode) EnsureConfigurationAsCode(jenkins *v1alpha1.Jenkins) (bool, error) {
+	if jenkins.Spec.ConfigurationAsCode == nil {
+		if len(jenkins.Status.ConfigurationAsCodeChecksum) == 0 {
+			return true, nil
+		}
+		jenkins.Status.ConfigurationAsCodeChecksum = ""
+		return true, c.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+	}
+
+	checksum, err := c.checksum(jenkins)
+	if err != nil {
+		return false, err
+	}
+	if checksum == jenkins.Status.ConfigurationAsCodeChecksum {
+		return true, nil
+	}
+
+	// kubelet updates mounted ConfigMaps and Secrets with a delay, reloading before would apply the old files
+	output, err := c.jenkinsClient.ExecuteScript(fmt.Sprintf(mountedChecksumScript, resources.ConfigurationAsCodeVolumePath))
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(output) != checksum {
+		c.logger.V(log.VDebug).Info(fmt.Sprintf("Waiting for configuration as code files, expected checksum '%s', actual '%s'", checksum, output))
+		return false, nil
+	}
+
+	if err := c.jenkinsClient.ReloadConfigurationAsCode(); err != nil {
+		return false, err
+	}
+	c.logger.Info("Configuration as code has been reloaded")
+	c.events.Emit(jenkins, event.TypeNormal, reasonReloaded, "Configuration as code has been reloaded")
+
+	jenkins.Status.ConfigurationAsCodeChecksum = checksum
+	return true, c.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// checksum calculates the checksum of JCasC YAML files from ConfigMaps and Secrets referenced by Jenkins.Spec.ConfigurationAsCode
+func (c *ConfigurationAsCode) checksum(jenkins *v1alpha1.Jenkins) (string, error) {
+	files := map[string][]byte{}
+	for _, reference := range jenkins.Spec.ConfigurationAsCode.ConfigMaps {
+		configMap := &corev1.ConfigMap{}
+		err := c.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, configMap)
+		if err != nil {
+			return "", stackerr.WithStack(err)
+		}
+		for key, value := range configMap.Data {
+			files[key] = []byte(value)
+		}
+	}
+	for _, reference := range jenkins.Spec.ConfigurationAsCode.Secrets {
+		secret := &corev1.Secret{}
+		err := c.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: reference.Name}, secret)
+		if err != nil {
+			return "", stackerr.WithStack(err)
+		}
+		for key, value := range secret.Data {
+			files[key] = value
+		}
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write(files[name])
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
+}
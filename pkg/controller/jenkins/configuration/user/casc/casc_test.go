@@ -0,0 +1,117 @@
+package casc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureConfigurationAsCode(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "casc", Namespace: "default"},
+		Data: map[string]string{
+			"jenkins.yaml": "jenkins:\n  systemMessage: Configured by JCasC\n",
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "casc-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"credentials.yaml": []byte("credentials: {}\n"),
+		},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			ConfigurationAsCode: &v1alpha1.ConfigurationAsCode{
+				ConfigMaps: []corev1.LocalObjectReference{{Name: "casc"}},
+				Secrets:    []corev1.LocalObjectReference{{Name: "casc-credentials"}},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(configMap, secret, jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	configurationAsCode := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+	checksum, err := configurationAsCode.checksum(jenkins)
+	assert.NoError(t, err)
+
+	// mounted files aren't updated yet
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("outdated", nil)
+
+	done, err := configurationAsCode.EnsureConfigurationAsCode(jenkins)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Empty(t, events.reasons)
+
+	// mounted files are updated
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(checksum+"\n", nil)
+	jenkinsClient.EXPECT().ReloadConfigurationAsCode().Return(nil)
+
+	done, err = configurationAsCode.EnsureConfigurationAsCode(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, []event.Reason{reasonReloaded}, events.reasons)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, checksum, jenkins.Status.ConfigurationAsCodeChecksum)
+
+	// nothing changed
+	done, err = configurationAsCode.EnsureConfigurationAsCode(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// the config map changed
+	configMap.Data["jenkins.yaml"] = "jenkins:\n  systemMessage: Reconfigured by JCasC\n"
+	err = fakeClient.Update(ctx, configMap)
+	assert.NoError(t, err)
+	changedChecksum, err := configurationAsCode.checksum(jenkins)
+	assert.NoError(t, err)
+	assert.NotEqual(t, checksum, changedChecksum)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(changedChecksum, nil)
+	jenkinsClient.EXPECT().ReloadConfigurationAsCode().Return(nil)
+
+	done, err = configurationAsCode.EnsureConfigurationAsCode(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, changedChecksum, jenkins.Status.ConfigurationAsCodeChecksum)
+
+	// configuration as code removed from Jenkins CR
+	jenkins.Spec.ConfigurationAsCode = nil
+	done, err = configurationAsCode.EnsureConfigurationAsCode(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Empty(t, jenkins.Status.ConfigurationAsCodeChecksum)
+}
+
+type fakeRecorder struct {
+	reasons []event.Reason
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.reasons = append(r.reasons, reason)
+}
@@ -0,0 +1,2 @@
+// Package casc implements reload of Jenkins Configuration as Code (JCasC) YAML files
+package casc
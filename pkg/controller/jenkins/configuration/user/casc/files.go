@@ -0,0 +1,154 @@
+package casc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// reasonFileFailed is the event which informs the JCasC YAML file from the user configuration can't be applied
+	reasonFileFailed event.Reason = "ConfigurationAsCodeFileFailed"
+
+	// fileFailureMarker precedes the exception of the file which can't be applied in the script console output
+	fileFailureMarker = "jenkins-operator:casc-failure:"
+	// fileNotSynchronizedMarker is printed when the mounted file doesn't match the checksum of the file
+	fileNotSynchronizedMarker = "jenkins-operator:casc-not-synchronized"
+	// fileFailureMessageSize is the maximum size of the failure message passed to the event
+	fileFailureMessageSize = 1024
+)
+
+// applyFileScript applies the JCasC YAML file mounted in Jenkins master pod by the configuration-as-code plugin
+// when its checksum matches the expected one, kubelet updates mounted ConfigMaps and Secrets with a delay
+const applyFileScript = `
+import io.jenkins.plugins.casc.ConfigurationAsCode
+import java.security.MessageDigest
+
+def file = new File('%s')
+if (!file.isFile() || Base64.getEncoder().encodeToString(MessageDigest.getInstance('SHA-256').digest(file.bytes)) != '%s') {
+    print '` + fileNotSynchronizedMarker + `'
+    return
+}
+try {
+    ConfigurationAsCode.get().configure(file.path)
+} catch (Throwable e) {
+    print '` + fileFailureMarker + `' + e
+}
+`
+
+// IsFile checks whether the user configuration key is the JCasC YAML file
+func IsFile(key string) bool {
+	return strings.HasSuffix(key, ".yaml") || strings.HasSuffix(key, ".yml")
+}
+
+// File is the JCasC YAML file from the user configuration source
+type File struct {
+	// Source is the kind and the name of the object with the file, e.g. configmap/name or secret/name
+	Source string
+	// Key is the key of the file in the source
+	Key string
+	// Content is the file itself
+	Content string
+	// Priority overrides the order of files, files with lower priority are applied first
+	Priority int
+}
+
+// EnsureConfigurationAsCodeFiles applies new JCasC YAML files and files which checksum differs from the applied one,
+// files are keyed by their path relative to the user configuration directory and applied sorted by the priority
+// and the path, the file which can't be applied is reported as the warning event and applied again when it changes,
+// returns false when Kubernetes hasn't updated mounted files yet, the state of applied files is kept
+// in Jenkins.Status.AppliedConfigurationAsCode
+func (c *ConfigurationAsCode) EnsureConfigurationAsCodeFiles(files map[string]File, jenkins *v1alpha1.Jenkins) (bool, error) {
+	var paths []string
+	for filePath := range files {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+	sort.SliceStable(paths, func(i, j int) bool {
+		return files[paths[i]].Priority < files[paths[j]].Priority
+	})
+
+	// removed files are dropped from the status
+	var appliedFiles []v1alpha1.AppliedScript
+	var changed []int
+	checksums := map[string]string{}
+	for _, filePath := range paths {
+		file := files[filePath]
+		hash := sha256.Sum256([]byte(file.Content))
+		checksums[filePath] = base64.StdEncoding.EncodeToString(hash[:])
+		appliedFile, found := getAppliedFile(jenkins, file.Source, file.Key)
+		if !found {
+			appliedFile = v1alpha1.AppliedScript{Source: file.Source, Key: file.Key}
+		}
+		if appliedFile.Checksum != checksums[filePath] {
+			changed = append(changed, len(appliedFiles))
+		}
+		appliedFiles = append(appliedFiles, appliedFile)
+	}
+
+	done := true
+	for _, index := range changed {
+		filePath := paths[index]
+		output, err := c.jenkinsClient.ExecuteScript(fmt.Sprintf(applyFileScript,
+			path.Join(resources.JenkinsUserConfigurationVolumePath, filePath), checksums[filePath]))
+		if err != nil {
+			return false, err
+		}
+		if strings.Contains(output, fileNotSynchronizedMarker) {
+			c.logger.V(log.VDebug).Info(fmt.Sprintf("Waiting for configuration as code file '%s'", filePath))
+			done = false
+			break
+		}
+
+		now := metav1.Now()
+		appliedFiles[index].Checksum = checksums[filePath]
+		appliedFiles[index].LastAppliedTime = &now
+		appliedFiles[index].Result = v1alpha1.AppliedScriptSuccessResult
+		appliedFiles[index].Failures = 0
+		// the output and the exception can contain values of secrets
+		output = log.MaskSecrets(output)
+		if marker := strings.Index(output, fileFailureMarker); marker >= 0 {
+			c.reportFileFailure(filePath, strings.TrimSpace(output[marker+len(fileFailureMarker):]), jenkins)
+			appliedFiles[index].Result = v1alpha1.AppliedScriptFailureResult
+			appliedFiles[index].Failures = 1
+			continue
+		}
+		c.logger.Info(fmt.Sprintf("Configuration as code file '%s' has been applied", filePath))
+	}
+
+	if reflect.DeepEqual(appliedFiles, jenkins.Status.AppliedConfigurationAsCode) {
+		return done, nil
+	}
+	jenkins.Status.AppliedConfigurationAsCode = appliedFiles
+	return done, c.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// reportFileFailure logs the failure of the file and emits the event
+func (c *ConfigurationAsCode) reportFileFailure(filePath, message string, jenkins *v1alpha1.Jenkins) {
+	if len(message) > fileFailureMessageSize {
+		message = message[:fileFailureMessageSize]
+	}
+	c.logger.V(log.VWarn).Info(fmt.Sprintf("Configuration as code file '%s' can't be applied: %s", filePath, message))
+	c.events.Emitf(jenkins, event.TypeWarning, reasonFileFailed, "Configuration as code file '%s' can't be applied: %s", filePath, message)
+}
+
+func getAppliedFile(jenkins *v1alpha1.Jenkins, source, key string) (v1alpha1.AppliedScript, bool) {
+	for _, appliedFile := range jenkins.Status.AppliedConfigurationAsCode {
+		if appliedFile.Source == source && appliedFile.Key == key {
+			return appliedFile, true
+		}
+	}
+	return v1alpha1.AppliedScript{}, false
+}
@@ -0,0 +1,88 @@
+package casc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureConfigurationAsCodeFiles(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	configurationAsCode := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+	files := map[string]File{
+		"0/jenkins.yaml": {Source: "configmap/user", Key: "jenkins.yaml", Content: "jenkins:\n  systemMessage: user\n"},
+		"0/broken.yml":   {Source: "configmap/user", Key: "broken.yml", Content: "jenkins: [", Priority: 1},
+	}
+	getStatus := func() []v1alpha1.AppliedScript {
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		return stored.Status.AppliedConfigurationAsCode
+	}
+
+	// mounted files aren't updated yet
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(fileNotSynchronizedMarker, nil)
+
+	done, err := configurationAsCode.EnsureConfigurationAsCodeFiles(files, jenkins)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Empty(t, events.reasons)
+	assert.Len(t, getStatus(), 2)
+	assert.Empty(t, getStatus()[0].Result)
+
+	// files are applied in the order of the priority, the broken file is reported
+	gomock.InOrder(
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("", nil),
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(fileFailureMarker+"ConfiguratorException: invalid", nil),
+	)
+
+	done, err = configurationAsCode.EnsureConfigurationAsCodeFiles(files, jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, []event.Reason{reasonFileFailed}, events.reasons)
+	status := getStatus()
+	assert.Equal(t, "jenkins.yaml", status[0].Key)
+	assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, status[0].Result)
+	assert.Equal(t, "broken.yml", status[1].Key)
+	assert.Equal(t, v1alpha1.AppliedScriptFailureResult, status[1].Result)
+
+	// unchanged files aren't applied again, the broken one until it changes
+	done, err = configurationAsCode.EnsureConfigurationAsCodeFiles(files, jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// removed files are dropped from the status
+	delete(files, "0/broken.yml")
+	done, err = configurationAsCode.EnsureConfigurationAsCodeFiles(files, jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Len(t, getStatus(), 1)
+}
+
+func TestIsFile(t *testing.T) {
+	assert.True(t, IsFile("jenkins.yaml"))
+	assert.True(t, IsFile("jenkins.yml"))
+	assert.False(t, IsFile("1-configure.groovy"))
+	assert.False(t, IsFile("README.md"))
+}
@@ -0,0 +1,217 @@
+package user
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	stackerr "github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// connectivityCheckTimeout is the maximum duration of the seed job repository connectivity check
+const connectivityCheckTimeout = 10 * time.Second
+
+// connectivityCheckInterval is the duration after which the repository is checked again when neither
+// the repository url nor the credentials changed
+const connectivityCheckInterval = 5 * time.Minute
+
+// repositoryCredentials contains seed job credentials read from secrets during validation
+type repositoryCredentials struct {
+	privateKey string
+	passphrase string
+	username   string
+	password   string
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the credentials
+func (c repositoryCredentials) checksum() string {
+	hash := sha256.New()
+	for _, value := range []string{c.privateKey, c.passphrase, c.username, c.password} {
+		_, _ = hash.Write([]byte(value))
+		_, _ = hash.Write([]byte{0})
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+// connectivityCheck is the last connectivity check of the repository with the credentials
+type connectivityCheck struct {
+	credentialsChecksum string
+	time                time.Time
+	err                 error
+}
+
+// connectivityChecks are shared by all reconciliation loops, they are keyed by the namespace and the name of Jenkins CR
+// and the repository url
+var connectivityChecks = struct {
+	sync.Mutex
+	entries map[string]*connectivityCheck
+}{entries: map[string]*connectivityCheck{}}
+
+// repositoryConnectivity returns the result of the last connectivity check of the repository, the repository is checked
+// again when the credentials changed or the check interval elapsed
+func repositoryConnectivity(jenkins *v1alpha1.Jenkins, repositoryURL string, credentials repositoryCredentials, now time.Time,
+	check func(repositoryURL string, credentials repositoryCredentials, timeout time.Duration) error) error {
+	connectivityChecks.Lock()
+	defer connectivityChecks.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s", jenkins.Namespace, jenkins.Name, repositoryURL)
+	checksum := credentials.checksum()
+	checked, ok := connectivityChecks.entries[key]
+	if ok && checked.credentialsChecksum == checksum && now.Sub(checked.time) < connectivityCheckInterval {
+		return checked.err
+	}
+	checked = &connectivityCheck{credentialsChecksum: checksum, time: now}
+	checked.err = check(repositoryURL, credentials, connectivityCheckTimeout)
+	connectivityChecks.entries[key] = checked
+	return checked.err
+}
+
+// checkRepositoryConnectivity checks the repository refs can be listed with the credentials the same way
+// git ls-remote does, ssh repository urls are checked with the private key and https ones with username and password
+func checkRepositoryConnectivity(repositoryURL string, credentials repositoryCredentials, timeout time.Duration) error {
+	if scpRepositoryURLRegexp.MatchString(repositoryURL) {
+		// the path of git@host:path is relative to the home directory of the user
+		userHost := strings.SplitN(repositoryURL, ":", 2)
+		parsedURL, err := url.Parse("ssh://" + userHost[0])
+		if err != nil {
+			return stackerr.WithStack(err)
+		}
+		return checkSSHRepositoryConnectivity(parsedURL, userHost[1], credentials, timeout)
+	}
+
+	parsedURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if parsedURL.Scheme == "ssh" {
+		repositoryPath := parsedURL.Path
+		if strings.HasPrefix(repositoryPath, "/~") {
+			// ssh://host/~user/path is relative to the home directory of the user like in git
+			repositoryPath = repositoryPath[1:]
+		}
+		return checkSSHRepositoryConnectivity(parsedURL, repositoryPath, credentials, timeout)
+	}
+	return checkHTTPRepositoryConnectivity(parsedURL, credentials, timeout)
+}
+
+func checkHTTPRepositoryConnectivity(repositoryURL *url.URL, credentials repositoryCredentials, timeout time.Duration) error {
+	refsURL := *repositoryURL
+	refsURL.Path = strings.TrimSuffix(refsURL.Path, "/") + "/info/refs"
+	refsURL.RawQuery = "service=git-upload-pack"
+
+	request, err := http.NewRequest(http.MethodGet, refsURL.String(), nil)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if len(credentials.username) > 0 || len(credentials.password) > 0 {
+		request.SetBasicAuth(credentials.username, credentials.password)
+	}
+
+	httpClient := &http.Client{Timeout: timeout}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return stackerr.Errorf("authentication failed, server responded with '%s'", response.Status)
+	case http.StatusNotFound:
+		return stackerr.Errorf("repository not found, server responded with '%s'", response.Status)
+	default:
+		return stackerr.Errorf("server responded with '%s'", response.Status)
+	}
+}
+
+func checkSSHRepositoryConnectivity(repositoryURL *url.URL, repositoryPath string, credentials repositoryCredentials, timeout time.Duration) error {
+	var signer ssh.Signer
+	var err error
+	if len(credentials.passphrase) > 0 {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(credentials.privateKey), []byte(credentials.passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(credentials.privateKey))
+	}
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+
+	user := "git"
+	if repositoryURL.User != nil && len(repositoryURL.User.Username()) > 0 {
+		user = repositoryURL.User.Username()
+	}
+	port := repositoryURL.Port()
+	if len(port) == 0 {
+		port = "22"
+	}
+	config := &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		// the check verifies only the credentials, public key authentication doesn't expose the private key
+		// to the server and the host key is verified by Jenkins while cloning the repository
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         timeout,
+	}
+
+	address := net.JoinHostPort(repositoryURL.Hostname(), port)
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return stackerr.WithStack(err)
+	}
+
+	clientConn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	client := ssh.NewClient(clientConn, channels, requests)
+	defer func() {
+		_ = client.Close()
+	}()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = session.Close()
+	}()
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	stderr := &bytes.Buffer{}
+	session.Stderr = stderr
+
+	// git-upload-pack advertises the repository refs as pkt-lines, the first 4 bytes are the length of the first line
+	if err = session.Start(fmt.Sprintf("git-upload-pack '%s'", strings.Replace(repositoryPath, "'", `'\''`, -1))); err != nil {
+		return stackerr.WithStack(err)
+	}
+	pktLength := make([]byte, 4)
+	if _, err = io.ReadFull(stdout, pktLength); err != nil {
+		if message := strings.TrimSpace(stderr.String()); len(message) > 0 {
+			return stackerr.New(message)
+		}
+		return stackerr.WithStack(err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package user
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	stackerr "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckHTTPRepositoryConnectivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/org/repo.git/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("001e# service=git-upload-pack\n0000"))
+	}))
+	defer server.Close()
+
+	credentials := repositoryCredentials{username: "user", password: "secret"}
+	t.Run("happy", func(t *testing.T) {
+		err := checkRepositoryConnectivity(server.URL+"/org/repo.git", credentials, time.Second)
+		assert.NoError(t, err)
+	})
+	t.Run("fail, wrong password", func(t *testing.T) {
+		err := checkRepositoryConnectivity(server.URL+"/org/repo.git", repositoryCredentials{username: "user", password: "wrong"}, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "authentication failed")
+	})
+	t.Run("fail, repository not found", func(t *testing.T) {
+		err := checkRepositoryConnectivity(server.URL+"/org/missing.git", credentials, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "repository not found")
+	})
+	t.Run("fail, server unreachable", func(t *testing.T) {
+		err := checkRepositoryConnectivity("http://127.0.0.1:1/org/repo.git", credentials, time.Second)
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckSSHRepositoryConnectivity(t *testing.T) {
+	authorizedKey, err := ssh.ParsePrivateKey([]byte(fakePrivateKey))
+	assert.NoError(t, err)
+	address, commands := startFakeGitSSHServer(t, authorizedKey)
+
+	t.Run("happy, relative path", func(t *testing.T) {
+		// scp-like urls don't support custom ports, they are checked with the relative path of ssh url
+		repositoryURL, err := url.Parse(fmt.Sprintf("ssh://git@%s", address))
+		assert.NoError(t, err)
+		err = checkSSHRepositoryConnectivity(repositoryURL, "org/repo.git", repositoryCredentials{privateKey: fakePrivateKey}, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "git-upload-pack 'org/repo.git'", <-commands)
+	})
+	t.Run("happy, ssh url", func(t *testing.T) {
+		err := checkRepositoryConnectivity(fmt.Sprintf("ssh://git@%s/org/repo.git", address),
+			repositoryCredentials{privateKey: fakePrivateKey}, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "git-upload-pack '/org/repo.git'", <-commands)
+	})
+	t.Run("happy, encrypted private key", func(t *testing.T) {
+		encryptedKey, err := ssh.ParsePrivateKeyWithPassphrase([]byte(fakeEncryptedPEMPrivateKey), []byte("secret"))
+		assert.NoError(t, err)
+		encryptedAddress, encryptedCommands := startFakeGitSSHServer(t, encryptedKey)
+		err = checkRepositoryConnectivity(fmt.Sprintf("ssh://git@%s/org/repo.git", encryptedAddress),
+			repositoryCredentials{privateKey: fakeEncryptedPEMPrivateKey, passphrase: "secret"}, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "git-upload-pack '/org/repo.git'", <-encryptedCommands)
+	})
+	t.Run("fail, key not authorized", func(t *testing.T) {
+		err := checkRepositoryConnectivity(fmt.Sprintf("ssh://git@%s/org/repo.git", address),
+			repositoryCredentials{privateKey: fakeOpenSSHRSAPrivateKey}, time.Second)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to authenticate")
+	})
+	t.Run("fail, invalid private key", func(t *testing.T) {
+		err := checkRepositoryConnectivity(fmt.Sprintf("ssh://git@%s/org/repo.git", address),
+			repositoryCredentials{privateKey: fakeInvalidPrivateKey}, time.Second)
+		assert.Error(t, err)
+	})
+}
+
+// startFakeGitSSHServer starts ssh server accepting only the given key, it responds to every command
+// with the flush-pkt and sends received commands to the returned channel
+func startFakeGitSSHServer(t *testing.T, authorizedKey ssh.Signer) (string, <-chan string) {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "git" && bytes.Equal(key.Marshal(), authorizedKey.PublicKey().Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown public key for %s", conn.User())
+		},
+	}
+	config.AddHostKey(authorizedKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeGitSSHConn(conn, config, commands)
+		}
+	}()
+	return listener.Addr().String(), commands
+}
+
+func serveFakeGitSSHConn(conn net.Conn, config *ssh.ServerConfig, commands chan<- string) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	_, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	for newChannel := range channels {
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		for request := range channelRequests {
+			if request.Type != "exec" {
+				_ = request.Reply(false, nil)
+				continue
+			}
+			var exec struct{ Command string }
+			_ = ssh.Unmarshal(request.Payload, &exec)
+			commands <- exec.Command
+			_ = request.Reply(true, nil)
+			_, _ = channel.Write([]byte("0000"))
+			_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			_ = channel.Close()
+		}
+	}
+}
+
+func TestRepositoryConnectivity(t *testing.T) {
+	jenkins := &v1alpha1.Jenkins{ObjectMeta: metav1.ObjectMeta{Name: "connectivity", Namespace: "default"}}
+	repositoryURL := "https://github.com/org/repo.git"
+	credentials := repositoryCredentials{username: "user", password: "secret"}
+	checks := 0
+	check := func(string, repositoryCredentials, time.Duration) error {
+		checks++
+		return stackerr.New("unreachable")
+	}
+	now := time.Now()
+
+	err := repositoryConnectivity(jenkins, repositoryURL, credentials, now, check)
+	assert.EqualError(t, err, "unreachable")
+	assert.Equal(t, 1, checks)
+
+	// the result is cached until the check interval elapses
+	err = repositoryConnectivity(jenkins, repositoryURL, credentials, now.Add(time.Minute), check)
+	assert.EqualError(t, err, "unreachable")
+	assert.Equal(t, 1, checks)
+
+	// the repository is checked again when the credentials changed
+	credentials.password = "changed"
+	_ = repositoryConnectivity(jenkins, repositoryURL, credentials, now.Add(time.Minute), check)
+	assert.Equal(t, 2, checks)
+
+	// the repository is checked again when the url changed
+	_ = repositoryConnectivity(jenkins, "https://github.com/org/other.git", credentials, now.Add(time.Minute), check)
+	assert.Equal(t, 3, checks)
+
+	// the repository is checked again when the check interval elapsed
+	_ = repositoryConnectivity(jenkins, repositoryURL, credentials, now.Add(time.Minute+connectivityCheckInterval), check)
+	assert.Equal(t, 4, checks)
+}
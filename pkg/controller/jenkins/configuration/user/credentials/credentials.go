@@ -0,0 +1,301 @@
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// UsernameKey is the key of the username in the Secret of usernamePassword and basicSSHUserPrivateKey credentials
+	UsernameKey = "username"
+	// PasswordKey is the key of the password in the Secret of usernamePassword and certificate credentials
+	PasswordKey = "password"
+	// SecretKey is the key of the secret text in the Secret of secretText credentials
+	SecretKey = "secret"
+	// PrivateKeyKey is the key of the private key in the Secret of basicSSHUserPrivateKey credentials
+	PrivateKeyKey = "privateKey"
+	// PassphraseKey is the key of the private key passphrase in the Secret of basicSSHUserPrivateKey credentials
+	PassphraseKey = "passphrase"
+	// CertificateKey is the key of the PKCS#12 keystore in the Secret of certificate credentials
+	CertificateKey = "certificate"
+
+	// reasonCredentialsUpdated is the event which informs credentials have been created, updated or deleted in Jenkins
+	reasonCredentialsUpdated event.Reason = "CredentialsUpdated"
+
+	// syncedMarker is printed by syncCredentialsFmt when all credentials have been synchronized
+	syncedMarker = "credentials synchronized"
+)
+
+// secretKeys contains required and optional keys of the Secret keyed by the credentials type
+var secretKeys = map[v1alpha1.CredentialsType]struct{ required, optional []string }{
+	v1alpha1.CredentialsTypeUsernamePassword:       {required: []string{UsernameKey, PasswordKey}},
+	v1alpha1.CredentialsTypeSecretText:             {required: []string{SecretKey}},
+	v1alpha1.CredentialsTypeBasicSSHUserPrivateKey: {required: []string{UsernameKey, PrivateKeyKey}, optional: []string{PassphraseKey}},
+	v1alpha1.CredentialsTypeCertificate:            {required: []string{CertificateKey}, optional: []string{PasswordKey}},
+}
+
+// syncCredentialsFmt creates or updates credentials and deletes removed credentials in the global domain of
+// the system credentials store, credentials are passed as the base64 encoded JSON built by syncSettings
+const syncCredentialsFmt = `
+import com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey
+import com.cloudbees.plugins.credentials.CredentialsScope
+import com.cloudbees.plugins.credentials.SystemCredentialsProvider
+import com.cloudbees.plugins.credentials.domains.Domain
+import com.cloudbees.plugins.credentials.impl.CertificateCredentialsImpl
+import com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl
+import hudson.util.Secret
+import org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def store = SystemCredentialsProvider.getInstance().getStore()
+def domain = Domain.global()
+def find = { id -> store.getCredentials(domain).find { it.id == id } }
+
+try {
+    settings.remove.each { id ->
+        def credentials = find(id)
+        if (credentials) {
+            store.removeCredentials(domain, credentials)
+        }
+    }
+    settings.credentials.each { entry ->
+        def values = entry.values
+        def credentials
+        switch (entry.type) {
+            case '` + string(v1alpha1.CredentialsTypeUsernamePassword) + `':
+                credentials = new UsernamePasswordCredentialsImpl(CredentialsScope.GLOBAL, entry.id, entry.description,
+                        values.` + UsernameKey + `, values.` + PasswordKey + `)
+                break
+            case '` + string(v1alpha1.CredentialsTypeSecretText) + `':
+                credentials = new StringCredentialsImpl(CredentialsScope.GLOBAL, entry.id, entry.description,
+                        Secret.fromString(values.` + SecretKey + `))
+                break
+            case '` + string(v1alpha1.CredentialsTypeBasicSSHUserPrivateKey) + `':
+                credentials = new BasicSSHUserPrivateKey(CredentialsScope.GLOBAL, entry.id, values.` + UsernameKey + `,
+                        new BasicSSHUserPrivateKey.DirectEntryPrivateKeySource(values.` + PrivateKeyKey + `),
+                        values.` + PassphraseKey + ` ?: '', entry.description)
+                break
+            case '` + string(v1alpha1.CredentialsTypeCertificate) + `':
+                credentials = new CertificateCredentialsImpl(CredentialsScope.GLOBAL, entry.id, entry.description,
+                        values.` + PasswordKey + ` ?: '', new CertificateCredentialsImpl.UploadedKeyStoreSource(values.` + CertificateKey + `))
+                break
+        }
+        def previous = find(entry.id)
+        if (previous) {
+            store.updateCredentials(domain, previous, credentials)
+        } else {
+            store.addCredentials(domain, credentials)
+        }
+    }
+} catch (Exception e) {
+    // only the exception class is printed, the exception message can contain values
+    print '` + groovy.SettingsFailedPrefix + `' + e.getClass().getName()
+    return
+}
+print '` + syncedMarker + `'
+`
+
+// syncSettings defines credentials passed to syncCredentialsFmt
+type syncSettings struct {
+	Credentials []credentialsSettings `json:"credentials"`
+	Remove      []string              `json:"remove"`
+}
+
+type credentialsSettings struct {
+	ID          string            `json:"id"`
+	Description string            `json:"description"`
+	Type        string            `json:"type"`
+	Values      map[string]string `json:"values"`
+}
+
+// Credentials synchronizes Jenkins credentials with Secrets referenced by Jenkins.Spec.Credentials and discovered by the label
+type Credentials struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates Credentials object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *Credentials {
+	return &Credentials{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// RequiredKeys returns keys the Secret of the credentials type has to contain, it returns false for unknown type
+func RequiredKeys(credentialsType v1alpha1.CredentialsType) ([]string, bool) {
+	keys, ok := secretKeys[credentialsType]
+	return keys.required, ok
+}
+
+// Types returns supported credentials types
+func Types() []string {
+	var names []string
+	for credentialsType := range secretKeys {
+		names = append(names, string(credentialsType))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnsureCredentials creates or updates declared and discovered credentials which checksum differs from Jenkins.Status.Credentials
+// and deletes credentials which are neither declared nor discovered anymore, values of credentials are never logged
+func (c *Credentials) EnsureCredentials(jenkins *v1alpha1.Jenkins) error {
+	checksums := map[string]string{}
+	for _, status := range jenkins.Status.Credentials {
+		checksums[status.ID] = status.Checksum
+	}
+
+	all, err := c.getCredentials(jenkins)
+	if err != nil {
+		return err
+	}
+
+	settings := syncSettings{}
+	var managed []v1alpha1.CredentialsStatus
+	var updated []string
+	for _, credentials := range all {
+		values, err := c.getValues(jenkins, credentials)
+		if err != nil {
+			return err
+		}
+		checksum := calculateChecksum(credentials, values)
+		managed = append(managed, v1alpha1.CredentialsStatus{ID: credentials.ID, Checksum: checksum})
+		if previous, ok := checksums[credentials.ID]; ok && previous == checksum {
+			continue
+		}
+		settings.Credentials = append(settings.Credentials, credentialsSettings{
+			ID:          credentials.ID,
+			Description: credentials.Description,
+			Type:        string(credentials.Type),
+			Values:      values,
+		})
+		updated = append(updated, credentials.ID)
+	}
+	for _, status := range jenkins.Status.Credentials {
+		if !isManaged(managed, status.ID) {
+			settings.Remove = append(settings.Remove, status.ID)
+			log.UnregisterSecrets(credentialsSecretOwner(jenkins, status.ID))
+		}
+	}
+	if len(settings.Credentials) == 0 && len(settings.Remove) == 0 {
+		return nil
+	}
+
+	script := groovy.SettingsScript{Format: syncCredentialsFmt, DoneMarker: syncedMarker, HideOutput: true}
+	if _, err := script.Execute(c.jenkinsClient, settings); err != nil {
+		return stackerr.Wrapf(err, "couldn't synchronize Jenkins credentials %v", append(updated, settings.Remove...))
+	}
+
+	var messages []string
+	if len(updated) > 0 {
+		messages = append(messages, fmt.Sprintf("created or updated: %s", strings.Join(updated, ", ")))
+	}
+	if len(settings.Remove) > 0 {
+		messages = append(messages, fmt.Sprintf("deleted: %s", strings.Join(settings.Remove, ", ")))
+	}
+	c.logger.Info(fmt.Sprintf("Jenkins credentials %s", strings.Join(messages, "; ")))
+	c.events.Emitf(jenkins, event.TypeNormal, reasonCredentialsUpdated, "Jenkins credentials %s", strings.Join(messages, "; "))
+
+	jenkins.Status.Credentials = managed
+	return c.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// getValues reads values of the credentials from the Secret or the Vault secret, the certificate keystore is base64 encoded,
+// it's expected to be already base64 encoded in Vault
+func (c *Credentials) getValues(jenkins *v1alpha1.Jenkins, credentials v1alpha1.Credentials) (map[string]string, error) {
+	keys := secretKeys[credentials.Type]
+	values := map[string]string{}
+	if vault.IsReference(credentials.SecretRef.Name) {
+		reference, err := vault.ParseReference(credentials.SecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := vault.New(c.k8sClient, jenkins).Read(reference.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range append(append([]string{}, keys.required...), keys.optional...) {
+			if value, ok := data[key]; ok {
+				values[key] = value
+			}
+		}
+		return values, nil
+	}
+
+	secret := &corev1.Secret{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: credentials.SecretRef.Name}
+	if err := c.k8sClient.Get(context.TODO(), namespaceName, secret); err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	for _, key := range append(append([]string{}, keys.required...), keys.optional...) {
+		value, ok := secret.Data[key]
+		if !ok {
+			continue
+		}
+		if key == CertificateKey {
+			values[key] = base64.StdEncoding.EncodeToString(value)
+		} else {
+			values[key] = string(value)
+		}
+	}
+	var registered []string
+	for _, value := range values {
+		registered = append(registered, value)
+	}
+	log.ReplaceSecrets(credentialsSecretOwner(jenkins, credentials.ID), registered...)
+	return values, nil
+}
+
+// credentialsSecretOwner returns the owner of values of the credentials registered to be masked
+func credentialsSecretOwner(jenkins *v1alpha1.Jenkins, id string) log.SecretOwner {
+	return log.SecretOwner{Namespace: jenkins.Namespace, Name: jenkins.Name, Source: "credentials " + id}
+}
+
+// calculateChecksum returns the checksum of the type, the description and values of the credentials
+func calculateChecksum(credentials v1alpha1.Credentials, values map[string]string) string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	hash.Write([]byte(credentials.Type))
+	hash.Write([]byte(credentials.Description))
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte(values[key]))
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+func isManaged(managed []v1alpha1.CredentialsStatus, id string) bool {
+	for _, status := range managed {
+		if status.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureCredentials(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: "default"},
+		Data:       map[string][]byte{UsernameKey: []byte("deploy"), PasswordKey: []byte("first-password")},
+	}
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "token", Namespace: "default"},
+		Data:       map[string][]byte{SecretKey: []byte("token-value")},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Credentials: []v1alpha1.Credentials{
+				{ID: "deploy", Type: v1alpha1.CredentialsTypeUsernamePassword, SecretRef: corev1.LocalObjectReference{Name: "deploy"}},
+				{ID: "token", Type: v1alpha1.CredentialsTypeSecretText, SecretRef: corev1.LocalObjectReference{Name: "token"}},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(secret, token, jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	credentials := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// credentials are created
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `"id":"deploy"`)
+		assert.Contains(t, settings, `"id":"token"`)
+		assert.Contains(t, settings, "first-password")
+		return syncedMarker + "\n", nil
+	})
+
+	err = credentials.EnsureCredentials(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonCredentialsUpdated}, events.reasons)
+	assert.False(t, strings.Contains(events.messages[0], "first-password"))
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.Len(t, updatedJenkins.Status.Credentials, 2)
+	assert.Equal(t, "deploy", updatedJenkins.Status.Credentials[0].ID)
+	assert.Equal(t, "token", updatedJenkins.Status.Credentials[1].ID)
+
+	// nothing changed
+	err = credentials.EnsureCredentials(jenkins)
+	assert.NoError(t, err)
+
+	// the secret changed
+	secret.Data[PasswordKey] = []byte("second-password")
+	err = fakeClient.Update(ctx, secret)
+	assert.NoError(t, err)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, "second-password")
+		assert.NotContains(t, settings, `"id":"token"`)
+		return syncedMarker, nil
+	})
+
+	err = credentials.EnsureCredentials(jenkins)
+	assert.NoError(t, err)
+
+	// the credentials removed from Jenkins CR
+	jenkins.Spec.Credentials = jenkins.Spec.Credentials[:1]
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Contains(t, groovytest.DecodeSettings(t, script), `"credentials":null,"remove":["token"]`)
+		return syncedMarker, nil
+	})
+
+	err = credentials.EnsureCredentials(jenkins)
+	assert.NoError(t, err)
+	assert.Len(t, jenkins.Status.Credentials, 1)
+	assert.Equal(t, "deploy", jenkins.Status.Credentials[0].ID)
+
+	// the script failed
+	secret.Data[PasswordKey] = []byte("third-password")
+	err = fakeClient.Update(ctx, secret)
+	assert.NoError(t, err)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.lang.IllegalArgumentException", nil)
+
+	err = credentials.EnsureCredentials(jenkins)
+	assert.EqualError(t, err, "couldn't synchronize Jenkins credentials [deploy]: java.lang.IllegalArgumentException")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
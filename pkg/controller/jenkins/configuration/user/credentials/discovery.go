@@ -0,0 +1,133 @@
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultTypeLabel is the key of the label with the credentials type of discovered Secrets
+	DefaultTypeLabel = "jenkins.io/credentials-type"
+	// IDAnnotation is the annotation of the discovered Secret with the credentials ID, the name of the Secret is used without it
+	IDAnnotation = "jenkins.io/credentials-id"
+	// DescriptionAnnotation is the annotation of the discovered Secret with the credentials description
+	DescriptionAnnotation = "jenkins.io/credentials-description"
+
+	// reasonDiscoveredCredentialsSkipped is the event which informs the discovered Secret isn't mirrored into Jenkins credentials
+	reasonDiscoveredCredentialsSkipped event.Reason = "DiscoveredCredentialsSkipped"
+)
+
+// reportedSkippedSecrets contains skipped discovered Secrets which have been reported, keyed by the namespace and the name
+// of Jenkins CR, the Secret and the reason, every Secret is reported once
+var reportedSkippedSecrets = struct {
+	sync.Mutex
+	entries map[string]bool
+}{entries: map[string]bool{}}
+
+// GetTypeLabel returns the key of the label with the credentials type of Secrets discovered for Jenkins CR,
+// it's empty when the auto discovery is disabled
+func GetTypeLabel(jenkins *v1alpha1.Jenkins) string {
+	if jenkins.Spec.CredentialsAutoDiscovery == nil {
+		return ""
+	}
+	if len(jenkins.Spec.CredentialsAutoDiscovery.LabelSelector) > 0 {
+		return jenkins.Spec.CredentialsAutoDiscovery.LabelSelector
+	}
+	return DefaultTypeLabel
+}
+
+// getCredentials returns credentials declared in Jenkins.Spec.Credentials followed by credentials discovered from labeled
+// Secrets, discovered credentials with IDs of declared ones, unknown types or missing keys are skipped and reported
+func (c *Credentials) getCredentials(jenkins *v1alpha1.Jenkins) ([]v1alpha1.Credentials, error) {
+	typeLabel := GetTypeLabel(jenkins)
+	if len(typeLabel) == 0 {
+		return jenkins.Spec.Credentials, nil
+	}
+
+	// the kind is required only by the fake client, labels are checked below because it ignores the selector
+	listOptions := &k8s.ListOptions{
+		Namespace: jenkins.Namespace,
+		Raw:       &metav1.ListOptions{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}},
+	}
+	if err := listOptions.SetLabelSelector(typeLabel); err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	secrets := &corev1.SecretList{}
+	if err := c.k8sClient.List(context.TODO(), listOptions, secrets); err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	sort.Slice(secrets.Items, func(i, j int) bool {
+		return secrets.Items[i].Name < secrets.Items[j].Name
+	})
+
+	all := append([]v1alpha1.Credentials{}, jenkins.Spec.Credentials...)
+	sources := map[string]string{}
+	for _, credentials := range jenkins.Spec.Credentials {
+		sources[credentials.ID] = "spec.credentials"
+	}
+	for _, secret := range secrets.Items {
+		credentialsType, ok := secret.Labels[typeLabel]
+		if !ok {
+			continue
+		}
+		credentials := v1alpha1.Credentials{
+			ID:          secret.Name,
+			Description: secret.Annotations[DescriptionAnnotation],
+			Type:        v1alpha1.CredentialsType(credentialsType),
+			SecretRef:   corev1.LocalObjectReference{Name: secret.Name},
+		}
+		if id := secret.Annotations[IDAnnotation]; len(id) > 0 {
+			credentials.ID = id
+		}
+
+		if source, ok := sources[credentials.ID]; ok {
+			c.reportSkippedSecret(jenkins, secret.Name, fmt.Sprintf("credentials ID '%s' is already used by %s", credentials.ID, source))
+			continue
+		}
+		requiredKeys, ok := RequiredKeys(credentials.Type)
+		if !ok {
+			c.reportSkippedSecret(jenkins, secret.Name, fmt.Sprintf("unsupported credentials type '%s'", credentialsType))
+			continue
+		}
+		var missingKeys []string
+		for _, key := range requiredKeys {
+			if len(secret.Data[key]) == 0 {
+				missingKeys = append(missingKeys, key)
+			}
+		}
+		if len(missingKeys) > 0 {
+			c.reportSkippedSecret(jenkins, secret.Name, fmt.Sprintf("missing %v keys", missingKeys))
+			continue
+		}
+
+		sources[credentials.ID] = fmt.Sprintf("secret '%s'", secret.Name)
+		all = append(all, credentials)
+	}
+	return all, nil
+}
+
+// reportSkippedSecret reports the discovered Secret which isn't mirrored into Jenkins credentials once
+func (c *Credentials) reportSkippedSecret(jenkins *v1alpha1.Jenkins, secretName, reason string) {
+	key := fmt.Sprintf("%s/%s/%s/%s", jenkins.Namespace, jenkins.Name, secretName, reason)
+	reportedSkippedSecrets.Lock()
+	defer reportedSkippedSecrets.Unlock()
+	if reportedSkippedSecrets.entries[key] {
+		return
+	}
+	reportedSkippedSecrets.entries[key] = true
+
+	message := fmt.Sprintf("Secret '%s' isn't mirrored into Jenkins credentials: %s", secretName, reason)
+	c.logger.V(log.VWarn).Info(message)
+	c.events.Emit(jenkins, event.TypeWarning, reasonDiscoveredCredentialsSkipped, message)
+}
@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestGetCredentials(t *testing.T) {
+	newSecret := func(name string, labels, annotations map[string]string, data map[string][]byte) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels, Annotations: annotations},
+			Data:       data,
+		}
+	}
+	secrets := []*corev1.Secret{
+		newSecret("nexus", map[string]string{DefaultTypeLabel: "usernamePassword"},
+			map[string]string{IDAnnotation: "nexus-deploy", DescriptionAnnotation: "Nexus"},
+			map[string][]byte{UsernameKey: []byte("deploy"), PasswordKey: []byte("password")}),
+		newSecret("slack", map[string]string{DefaultTypeLabel: "secretText"}, nil,
+			map[string][]byte{SecretKey: []byte("token")}),
+		newSecret("declared", map[string]string{DefaultTypeLabel: "secretText"}, nil,
+			map[string][]byte{SecretKey: []byte("token")}),
+		newSecret("unknown-type", map[string]string{DefaultTypeLabel: "token"}, nil,
+			map[string][]byte{SecretKey: []byte("token")}),
+		newSecret("missing-keys", map[string]string{DefaultTypeLabel: "usernamePassword"}, nil,
+			map[string][]byte{UsernameKey: []byte("deploy")}),
+		newSecret("custom-label", map[string]string{"example.com/credentials": "secretText"}, nil,
+			map[string][]byte{SecretKey: []byte("token")}),
+		newSecret("not-labeled", nil, nil, map[string][]byte{SecretKey: []byte("token")}),
+	}
+	fakeClient := fake.NewFakeClient()
+	for _, secret := range secrets {
+		assert.NoError(t, fakeClient.Create(context.TODO(), secret))
+	}
+	declared := v1alpha1.Credentials{ID: "declared", Type: v1alpha1.CredentialsTypeSecretText,
+		SecretRef: corev1.LocalObjectReference{Name: "slack"}}
+
+	t.Run("auto discovery disabled", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+			Spec:       v1alpha1.JenkinsSpec{Credentials: []v1alpha1.Credentials{declared}},
+		}
+		credentials, err := New(nil, fakeClient, logf.ZapLogger(false), &fakeRecorder{}).getCredentials(jenkins)
+		assert.NoError(t, err)
+		assert.Equal(t, []v1alpha1.Credentials{declared}, credentials)
+	})
+	t.Run("default label", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins-default-label", Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				Credentials:              []v1alpha1.Credentials{declared},
+				CredentialsAutoDiscovery: &v1alpha1.CredentialsAutoDiscovery{},
+			},
+		}
+		events := &fakeRecorder{}
+		credentials, err := New(nil, fakeClient, logf.ZapLogger(false), events).getCredentials(jenkins)
+		assert.NoError(t, err)
+		assert.Equal(t, []v1alpha1.Credentials{
+			declared,
+			{ID: "nexus-deploy", Description: "Nexus", Type: v1alpha1.CredentialsTypeUsernamePassword,
+				SecretRef: corev1.LocalObjectReference{Name: "nexus"}},
+			{ID: "slack", Type: v1alpha1.CredentialsTypeSecretText, SecretRef: corev1.LocalObjectReference{Name: "slack"}},
+		}, credentials)
+		assert.Equal(t, []event.Reason{reasonDiscoveredCredentialsSkipped, reasonDiscoveredCredentialsSkipped,
+			reasonDiscoveredCredentialsSkipped}, events.reasons)
+
+		// skipped secrets are reported once
+		_, err = New(nil, fakeClient, logf.ZapLogger(false), events).getCredentials(jenkins)
+		assert.NoError(t, err)
+		assert.Len(t, events.reasons, 3)
+	})
+	t.Run("custom label", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins-custom-label", Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				CredentialsAutoDiscovery: &v1alpha1.CredentialsAutoDiscovery{LabelSelector: "example.com/credentials"},
+			},
+		}
+		credentials, err := New(nil, fakeClient, logf.ZapLogger(false), &fakeRecorder{}).getCredentials(jenkins)
+		assert.NoError(t, err)
+		assert.Equal(t, []v1alpha1.Credentials{
+			{ID: "custom-label", Type: v1alpha1.CredentialsTypeSecretText, SecretRef: corev1.LocalObjectReference{Name: "custom-label"}},
+		}, credentials)
+	})
+}
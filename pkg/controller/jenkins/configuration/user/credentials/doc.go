@@ -0,0 +1,2 @@
+// Package credentials implements synchronization of Kubernetes Secrets into Jenkins credentials
+package credentials
@@ -0,0 +1,127 @@
+package user
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+var cronAliases = map[string]bool{
+	"@yearly":   true,
+	"@annually": true,
+	"@monthly":  true,
+	"@weekly":   true,
+	"@daily":    true,
+	"@midnight": true,
+	"@hourly":   true,
+}
+
+// validateCronSpec validates Jenkins cron syntax including the 'H' symbol, every line is a separate schedule,
+// see https://jenkins.io/doc/book/pipeline/syntax/#cron-syntax
+func validateCronSpec(spec string) error {
+	schedules := 0
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "TZ=") {
+			continue
+		}
+		schedules++
+
+		if cronAliases[line] {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != len(cronFields) {
+			return errors.Errorf("expected %d fields but got %d in '%s'", len(cronFields), len(fields), line)
+		}
+		for i, field := range fields {
+			if err := validateCronField(field, cronFields[i].min, cronFields[i].max); err != nil {
+				return errors.Wrapf(err, "invalid %s field '%s'", cronFields[i].name, field)
+			}
+		}
+	}
+
+	if schedules == 0 {
+		return errors.New("cron expression is empty")
+	}
+
+	return nil
+}
+
+func validateCronField(field string, min, max int) error {
+	for _, item := range strings.Split(field, ",") {
+		value, step := item, ""
+		if index := strings.Index(item, "/"); index >= 0 {
+			value, step = item[:index], item[index+1:]
+			if number, err := strconv.Atoi(step); err != nil || number <= 0 {
+				return errors.Errorf("invalid step '%s'", step)
+			}
+		}
+
+		switch {
+		case value == "*" || value == "H":
+		case strings.HasPrefix(value, "H(") && strings.HasSuffix(value, ")"):
+			if err := validateCronRange(value[2:len(value)-1], min, max); err != nil {
+				return err
+			}
+		case strings.Contains(value, "-"):
+			if err := validateCronRange(value, min, max); err != nil {
+				return err
+			}
+		default:
+			if len(step) > 0 {
+				return errors.Errorf("step is allowed only for '*', 'H' or range, got '%s'", item)
+			}
+			if err := validateCronValue(value, min, max); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateCronRange(value string, min, max int) error {
+	bounds := strings.SplitN(value, "-", 2)
+	if len(bounds) != 2 {
+		return errors.Errorf("invalid range '%s'", value)
+	}
+	if err := validateCronValue(bounds[0], min, max); err != nil {
+		return err
+	}
+	if err := validateCronValue(bounds[1], min, max); err != nil {
+		return err
+	}
+	from, _ := strconv.Atoi(bounds[0])
+	to, _ := strconv.Atoi(bounds[1])
+	if from > to {
+		return errors.Errorf("invalid range '%s', %d is greater than %d", value, from, to)
+	}
+
+	return nil
+}
+
+func validateCronValue(value string, min, max int) error {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return errors.Errorf("'%s' is not a number", value)
+	}
+	if number < min || number > max {
+		return errors.Errorf("%d is out of range %d-%d", number, min, max)
+	}
+
+	return nil
+}
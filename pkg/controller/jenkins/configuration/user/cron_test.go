@@ -0,0 +1,44 @@
+package user
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCronSpec(t *testing.T) {
+	data := []struct {
+		spec  string
+		valid bool
+	}{
+		{spec: "* * * * *", valid: true},
+		{spec: "H/15 * * * *", valid: true},
+		{spec: "H(0-29)/10 * * * 1-5", valid: true},
+		{spec: "0 2 * * 0,6", valid: true},
+		{spec: "@daily", valid: true},
+		{spec: "# nightly\nH 2 * * *\nH 14 * * *", valid: true},
+		{spec: "TZ=Europe/Warsaw\nH 2 * * *", valid: true},
+		{spec: "", valid: false},
+		{spec: "* * * *", valid: false},
+		{spec: "60 * * * *", valid: false},
+		{spec: "* 24 * * *", valid: false},
+		{spec: "* * 0 * *", valid: false},
+		{spec: "* * * 13 *", valid: false},
+		{spec: "5-1 * * * *", valid: false},
+		{spec: "*/0 * * * *", valid: false},
+		{spec: "5/2 * * * *", valid: false},
+		{spec: "@sometimes", valid: false},
+	}
+
+	for _, testingData := range data {
+		t.Run(fmt.Sprintf("Testing '%s'", testingData.spec), func(t *testing.T) {
+			err := validateCronSpec(testingData.spec)
+			if testingData.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
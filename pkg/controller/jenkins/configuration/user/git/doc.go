@@ -0,0 +1,2 @@
+// Package git implements fetching of user configuration groovy scripts from the git repository
+package git
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultRepositoryBranch is the branch fetched when neither the branch nor the revision is set
+	DefaultRepositoryBranch = "master"
+	// DefaultPollInterval is the interval of fetching the repository when the poll interval isn't set
+	DefaultPollInterval = 5 * time.Minute
+
+	// reasonFetched is the event which informs scripts of the new commit have been fetched
+	reasonFetched event.Reason = "GitConfigurationFetched"
+	// reasonFetchFailed is the event which informs the repository can't be fetched, previously fetched scripts are kept
+	reasonFetchFailed event.Reason = "GitConfigurationFetchFailed"
+)
+
+// Git fetches user configuration groovy scripts from the git repository into the ConfigMap created by operator
+type Git struct {
+	k8sClient k8s.Client
+	logger    logr.Logger
+	events    event.Recorder
+	// workDir contains working copies of repositories, one per Jenkins CR
+	workDir string
+}
+
+// New creates Git object
+func New(k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *Git {
+	return &Git{
+		k8sClient: k8sClient,
+		logger:    logger,
+		events:    events,
+		workDir:   filepath.Join(os.TempDir(), "jenkins-operator", "git"),
+	}
+}
+
+// Source returns the repository URL, the branch or the revision and the path scripts are fetched from
+func Source(git v1alpha1.GitConfiguration) string {
+	return fmt.Sprintf("%s@%s:%s", git.RepositoryURL, getRef(git), strings.Trim(git.Path, "/"))
+}
+
+// getRef returns the revision or the branch of the repository which is fetched
+func getRef(git v1alpha1.GitConfiguration) string {
+	if len(git.Revision) > 0 {
+		return git.Revision
+	}
+	if len(git.RepositoryBranch) > 0 {
+		return git.RepositoryBranch
+	}
+	return DefaultRepositoryBranch
+}
+
+// getPollInterval returns the interval of fetching the repository
+func getPollInterval(git v1alpha1.GitConfiguration) time.Duration {
+	if git.PollInterval != nil {
+		return git.PollInterval.Duration
+	}
+	return DefaultPollInterval
+}
+
+// EnsureGitConfiguration fetches the repository referenced by Jenkins.Spec.Configuration.Git when its source changed
+// or the poll interval elapsed and copies its scripts into the ConfigMap created by operator, the repository which
+// can't be fetched is reported as the warning event and previously fetched scripts are kept,
+// returns the duration after which the repository is due to be fetched again, zero means it's not fetched
+func (g *Git) EnsureGitConfiguration(jenkins *v1alpha1.Jenkins, now time.Time) (time.Duration, error) {
+	git := jenkins.Spec.Configuration.Git
+	if git == nil {
+		if jenkins.Status.GitConfiguration == nil {
+			return 0, nil
+		}
+		jenkins.Status.GitConfiguration = nil
+		return 0, g.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+	}
+
+	source := Source(*git)
+	pollInterval := getPollInterval(*git)
+	status := jenkins.Status.GitConfiguration
+	if status != nil && status.Source == source && status.LastFetchTime != nil {
+		if elapsed := now.Sub(status.LastFetchTime.Time); elapsed < pollInterval {
+			return pollInterval - elapsed, nil
+		}
+	}
+	if status == nil || status.Source != source {
+		status = &v1alpha1.GitConfigurationStatus{Source: source}
+	}
+	lastFetchTime := metav1.NewTime(now)
+	status.LastFetchTime = &lastFetchTime
+	jenkins.Status.GitConfiguration = status
+
+	commit, err := g.fetch(jenkins, *git)
+	if err != nil {
+		g.logger.V(log.VWarn).Info(fmt.Sprintf("Git repository '%s' can't be fetched: %s", source, err))
+		g.events.Emitf(jenkins, event.TypeWarning, reasonFetchFailed, "Git repository '%s' can't be fetched: %s", source, err)
+	} else if status.FetchedCommit != commit {
+		g.logger.Info(fmt.Sprintf("User configuration scripts of commit '%s' have been fetched from '%s'", commit, source))
+		g.events.Emitf(jenkins, event.TypeNormal, reasonFetched, "User configuration scripts of commit '%s' have been fetched from '%s'", commit, source)
+		status.FetchedCommit = commit
+	}
+
+	return pollInterval, g.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// fetch fetches the repository, copies its scripts into the ConfigMap and returns SHA of the fetched commit
+func (g *Git) fetch(jenkins *v1alpha1.Jenkins, git v1alpha1.GitConfiguration) (string, error) {
+	repositoryCredentials, err := g.getCredentials(jenkins, git)
+	if err != nil {
+		return "", err
+	}
+
+	directory := filepath.Join(g.workDir, jenkins.Namespace, jenkins.Name)
+	commit, err := fetchRepository(directory, git, repositoryCredentials)
+	if err != nil {
+		return "", err
+	}
+
+	scripts, skipped, err := readScripts(directory, git.Path)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range skipped {
+		g.logger.V(log.VWarn).Info(fmt.Sprintf("File '%s' fetched from git repository is skipped, its name isn't a valid ConfigMap key", name))
+	}
+
+	configMap := &corev1.ConfigMap{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: resources.GetUserConfigurationGitConfigMapName(jenkins)}
+	if err := g.k8sClient.Get(context.TODO(), namespaceName, configMap); err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	if len(configMap.Data) == 0 && len(scripts) == 0 || reflect.DeepEqual(configMap.Data, scripts) {
+		return commit, nil
+	}
+	configMap.Data = scripts
+	if err := g.k8sClient.Update(context.TODO(), configMap); err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	g.logger.V(log.VDebug).Info(fmt.Sprintf("Config map '%s' has been updated with scripts of commit '%s'", configMap.Name, commit))
+	return commit, nil
+}
+
+// getCredentials reads credentials of the repository from Jenkins.Spec.Configuration.Git.CredentialsSecret
+func (g *Git) getCredentials(jenkins *v1alpha1.Jenkins, git v1alpha1.GitConfiguration) (credentials, error) {
+	if len(git.CredentialsSecret) == 0 {
+		return credentials{}, nil
+	}
+
+	secret := &corev1.Secret{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: git.CredentialsSecret}
+	if err := g.k8sClient.Get(context.TODO(), namespaceName, secret); err != nil {
+		return credentials{}, stackerr.WithStack(err)
+	}
+	repositoryCredentials := credentials{
+		username:   string(secret.Data[v1alpha1.UsernameSecretKey]),
+		password:   string(secret.Data[v1alpha1.PasswordSecretKey]),
+		privateKey: string(secret.Data[v1alpha1.PrivateKeySecretKey]),
+	}
+	owner := log.SecretOwner{Namespace: jenkins.Namespace, Name: jenkins.Name, Source: "git credentials " + git.RepositoryURL}
+	log.ReplaceSecrets(owner, repositoryCredentials.password, repositoryCredentials.privateKey)
+	return repositoryCredentials, nil
+}
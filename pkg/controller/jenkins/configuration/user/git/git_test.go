@@ -0,0 +1,160 @@
+package git
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureGitConfiguration(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+
+	// given
+	repository, err := ioutil.TempDir("", "repository")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(repository)
+	}()
+	commit := func(files map[string]string) {
+		for name, content := range files {
+			assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repository, name)), 0700))
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(repository, name), []byte(content), 0600))
+		}
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "test"},
+		} {
+			_, err := runGit(repository, os.Environ(), args...)
+			assert.NoError(t, err)
+		}
+	}
+	_, err = runGit(repository, os.Environ(), "init", "-q")
+	assert.NoError(t, err)
+	_, err = runGit(repository, os.Environ(), "checkout", "-q", "-b", "master")
+	assert.NoError(t, err)
+	commit(map[string]string{
+		"groovy/1-first.groovy":  "println 'first'",
+		"groovy/README.md":       "scripts",
+		"groovy/nested/x.groovy": "println 'nested'",
+		"other.groovy":           "println 'other'",
+	})
+
+	err = v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Configuration: v1alpha1.Configuration{
+				Git: &v1alpha1.GitConfiguration{RepositoryURL: repository, Path: "groovy"},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins, resources.NewUserConfigurationGitConfigMap(jenkins))
+	events := &fakeRecorder{}
+	workDir, err := ioutil.TempDir("", "work")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(workDir)
+	}()
+	git := New(fakeClient, logf.ZapLogger(false), events)
+	git.workDir = workDir
+	now := time.Now()
+	getScripts := func() map[string]string {
+		configMap := &corev1.ConfigMap{}
+		namespaceName := types.NamespacedName{Namespace: "default", Name: resources.GetUserConfigurationGitConfigMapName(jenkins)}
+		assert.NoError(t, fakeClient.Get(context.TODO(), namespaceName, configMap))
+		return configMap.Data
+	}
+
+	// when
+	after, err := git.EnsureGitConfiguration(jenkins, now)
+
+	// then only groovy scripts placed directly in the path are copied
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPollInterval, after)
+	assert.Equal(t, map[string]string{"1-first.groovy": "println 'first'"}, getScripts())
+	status := jenkins.Status.GitConfiguration
+	assert.Len(t, status.FetchedCommit, 40)
+	assert.Equal(t, repository+"@master:groovy", status.Source)
+	assert.Equal(t, []event.Reason{reasonFetched}, events.reasons)
+	fetchedCommit := status.FetchedCommit
+
+	// the repository isn't fetched again until the poll interval elapses
+	commit(map[string]string{"groovy/2-second.groovy": "println 'second'"})
+	after, err = git.EnsureGitConfiguration(jenkins, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPollInterval-time.Minute, after)
+	assert.Equal(t, fetchedCommit, jenkins.Status.GitConfiguration.FetchedCommit)
+
+	after, err = git.EnsureGitConfiguration(jenkins, now.Add(DefaultPollInterval))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPollInterval, after)
+	assert.NotEqual(t, fetchedCommit, jenkins.Status.GitConfiguration.FetchedCommit)
+	assert.Equal(t, map[string]string{"1-first.groovy": "println 'first'", "2-second.groovy": "println 'second'"}, getScripts())
+
+	// the repository which can't be fetched is reported and fetched scripts are kept
+	jenkins.Spec.Configuration.Git.RepositoryBranch = "missing"
+	after, err = git.EnsureGitConfiguration(jenkins, now.Add(DefaultPollInterval))
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPollInterval, after)
+	assert.Empty(t, jenkins.Status.GitConfiguration.FetchedCommit)
+	assert.Equal(t, reasonFetchFailed, events.reasons[len(events.reasons)-1])
+	assert.Len(t, getScripts(), 2)
+
+	// status is removed with the git configuration
+	jenkins.Spec.Configuration.Git = nil
+	after, err = git.EnsureGitConfiguration(jenkins, now)
+	assert.NoError(t, err)
+	assert.Zero(t, after)
+	assert.Nil(t, jenkins.Status.GitConfiguration)
+}
+
+func TestReadScripts(t *testing.T) {
+	directory, err := ioutil.TempDir("", "scripts")
+	assert.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(directory)
+	}()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, "valid.groovy"), []byte("println 'valid'"), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, "in valid.groovy"), []byte("println 'invalid'"), 0600))
+	assert.NoError(t, os.Symlink("/etc/passwd", filepath.Join(directory, "link.groovy")))
+
+	scripts, skipped, err := readScripts(directory, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"valid.groovy": "println 'valid'"}, scripts)
+	assert.Equal(t, []string{"in valid.groovy"}, skipped)
+
+	_, _, err = readScripts(directory, "missing")
+	assert.Error(t, err)
+}
+
+type fakeRecorder struct {
+	reasons []event.Reason
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.reasons = append(r.reasons, reason)
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	stackerr "github.com/pkg/errors"
+)
+
+const (
+	// fetchTimeout is the timeout of every git command
+	fetchTimeout = 2 * time.Minute
+	// maxScriptsSize is the maximal size of scripts copied into the ConfigMap, the object stored in etcd can't exceed 1MiB
+	maxScriptsSize = 1000 * 1024
+	// scriptExtension is the extension of files copied into the ConfigMap
+	scriptExtension = ".groovy"
+)
+
+// askPassScript answers git username and password prompts with values passed in the environment,
+// so credentials are never written into the repository config or the command line
+const askPassScript = `#!/bin/sh
+case "$1" in
+Username*) printf '%s' "$JENKINS_OPERATOR_GIT_USERNAME" ;;
+*) printf '%s' "$JENKINS_OPERATOR_GIT_PASSWORD" ;;
+esac
+`
+
+// configMapKeyRegexp matches names of files which can be ConfigMap keys
+var configMapKeyRegexp = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// credentials contains username and password used with https repository URL or the private key used with ssh one
+type credentials struct {
+	username   string
+	password   string
+	privateKey string
+}
+
+// fetchRepository fetches the branch or the revision of the repository into the working copy in the directory
+// and checks it out, returns SHA of the fetched commit
+func fetchRepository(directory string, git v1alpha1.GitConfiguration, repositoryCredentials credentials) (string, error) {
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return "", stackerr.WithStack(err)
+	}
+
+	// credentials are written into the temporary directory which is removed right after the fetch
+	authDirectory, err := ioutil.TempDir("", "jenkins-operator-git-")
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(authDirectory)
+	}()
+	env, err := buildEnv(authDirectory, git, repositoryCredentials)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filepath.Join(directory, ".git")); os.IsNotExist(err) {
+		if _, err := runGit(directory, env, "init", "-q"); err != nil {
+			return "", err
+		}
+	}
+	if _, err := runGit(directory, env, "fetch", "-q", "--depth", "1", "--force", git.RepositoryURL, getRef(git)); err != nil {
+		return "", err
+	}
+	if _, err := runGit(directory, env, "checkout", "-q", "--force", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	return runGit(directory, env, "rev-parse", "HEAD")
+}
+
+// buildEnv returns environment of git commands, git never prompts for credentials and doesn't read user's config
+func buildEnv(authDirectory string, git v1alpha1.GitConfiguration, repositoryCredentials credentials) ([]string, error) {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_CONFIG_NOSYSTEM=1", "HOME="+authDirectory)
+
+	if len(repositoryCredentials.username) > 0 || len(repositoryCredentials.password) > 0 {
+		askPass := filepath.Join(authDirectory, "askpass.sh")
+		if err := ioutil.WriteFile(askPass, []byte(askPassScript), 0700); err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		env = append(env,
+			"GIT_ASKPASS="+askPass,
+			"JENKINS_OPERATOR_GIT_USERNAME="+repositoryCredentials.username,
+			"JENKINS_OPERATOR_GIT_PASSWORD="+repositoryCredentials.password)
+	}
+
+	sshCommand := []string{"ssh", "-o", "BatchMode=yes"}
+	if len(repositoryCredentials.privateKey) > 0 {
+		privateKey := filepath.Join(authDirectory, "id")
+		// ssh refuses the private key without the trailing new line
+		content := strings.TrimSpace(repositoryCredentials.privateKey) + "\n"
+		if err := ioutil.WriteFile(privateKey, []byte(content), 0600); err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		sshCommand = append(sshCommand, "-i", privateKey, "-o", "IdentitiesOnly=yes")
+	}
+	if git.InsecureSkipHostKeyVerification {
+		sshCommand = append(sshCommand, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null")
+	} else {
+		knownHosts := filepath.Join(authDirectory, "known_hosts")
+		if err := ioutil.WriteFile(knownHosts, []byte(git.KnownHosts), 0600); err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		sshCommand = append(sshCommand, "-o", "StrictHostKeyChecking=yes", "-o", "UserKnownHostsFile="+knownHosts)
+	}
+	return append(env, "GIT_SSH_COMMAND="+strings.Join(sshCommand, " ")), nil
+}
+
+// runGit runs the git command in the directory and returns its trimmed output, the error contains git's error output
+func runGit(directory string, env []string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Dir = directory
+	command.Env = env
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+	if err := command.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", stackerr.Errorf("git %s timed out after %s", args[0], fetchTimeout)
+		}
+		return "", stackerr.Errorf("git %s failed: %s: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// readScripts reads groovy scripts placed directly in the path of the working copy, returns names of skipped
+// scripts which can't be ConfigMap keys
+func readScripts(directory, path string) (map[string]string, []string, error) {
+	scriptsDirectory := filepath.Join(directory, filepath.FromSlash(path))
+	files, err := ioutil.ReadDir(scriptsDirectory)
+	if os.IsNotExist(err) {
+		return nil, nil, stackerr.Errorf("path '%s' doesn't exist in the repository", path)
+	} else if err != nil {
+		return nil, nil, stackerr.WithStack(err)
+	}
+
+	scripts := map[string]string{}
+	var skipped []string
+	size := 0
+	for _, file := range files {
+		// symbolic links could point outside of the repository
+		if !file.Mode().IsRegular() || !strings.HasSuffix(file.Name(), scriptExtension) {
+			continue
+		}
+		if !configMapKeyRegexp.MatchString(file.Name()) {
+			skipped = append(skipped, file.Name())
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(scriptsDirectory, file.Name()))
+		if err != nil {
+			return nil, nil, stackerr.WithStack(err)
+		}
+		size += len(file.Name()) + len(content)
+		scripts[file.Name()] = string(content)
+	}
+	if size > maxScriptsSize {
+		return nil, nil, stackerr.Errorf("scripts in path '%s' exceed %d bytes", path, maxScriptsSize)
+	}
+	return scripts, skipped, nil
+}
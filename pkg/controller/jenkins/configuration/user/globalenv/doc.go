@@ -0,0 +1,2 @@
+// Package globalenv implements global environment variables of Jenkins declared in Jenkins CR
+package globalenv
@@ -0,0 +1,126 @@
+package globalenv
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonGlobalEnvUpdated is the event which informs global environment variables of Jenkins have been changed
+	reasonGlobalEnvUpdated event.Reason = "GlobalEnvUpdated"
+
+	// appliedMarker is printed by ensureGlobalEnvFmt when global environment variables match the settings
+	appliedMarker = "global env applied"
+	// updatedPrefix is printed by ensureGlobalEnvFmt with comma separated names of added, changed and removed variables
+	// when variables have been updated
+	updatedPrefix = "global env updated:"
+
+	// secretReferenceKind names global environment variables in events about secret references which can't be resolved
+	secretReferenceKind = "Global environment variable"
+)
+
+// ensureGlobalEnvFmt replaces environment variables of the global node properties with variables passed
+// as the base64 encoded JSON built by globalEnvSettings, Jenkins is saved only when they differ
+const ensureGlobalEnvFmt = `
+import hudson.slaves.EnvironmentVariablesNodeProperty
+import jenkins.model.Jenkins
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def jenkins = Jenkins.getInstance()
+
+try {
+    def properties = jenkins.getGlobalNodeProperties()
+    def existing = properties.getAll(EnvironmentVariablesNodeProperty.class)
+    def current = [:]
+    existing.each { property -> current.putAll(property.getEnvVars()) }
+
+    def changed = ((current.keySet() + settings.env.keySet()) as TreeSet).findAll { name -> current[name] != settings.env[name] }
+    if (!changed.isEmpty() || existing.size() > 1) {
+        properties.removeAll(EnvironmentVariablesNodeProperty.class)
+        if (!settings.env.isEmpty()) {
+            properties.add(new EnvironmentVariablesNodeProperty(settings.env.collect { name, value ->
+                new EnvironmentVariablesNodeProperty.Entry(name, value)
+            }))
+        }
+        jenkins.save()
+        println '` + updatedPrefix + `' + changed.join(',')
+    }
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + appliedMarker + `'
+`
+
+// globalEnvSettings defines variables passed to ensureGlobalEnvFmt
+type globalEnvSettings struct {
+	Env map[string]string `json:"env"`
+}
+
+// GlobalEnv applies Jenkins.Spec.Master.GlobalEnv to the global node properties of Jenkins
+type GlobalEnv struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates GlobalEnv object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *GlobalEnv {
+	return &GlobalEnv{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// EnsureGlobalEnv makes global environment variables of Jenkins match Jenkins.Spec.Master.GlobalEnv on every call,
+// variables changed or added in Jenkins otherwise are reverted or removed, secret references in values are resolved
+// like in groovy scripts and variables aren't applied when any of them can't be resolved, variables are left untouched
+// until operator applied the section for the first time
+func (g *GlobalEnv) EnsureGlobalEnv(jenkins *v1alpha1.Jenkins) error {
+	if len(jenkins.Spec.Master.GlobalEnv) == 0 && !jenkins.Status.GlobalEnvManaged {
+		return nil
+	}
+
+	// only secret references are resolved, the groovy job isn't used
+	groovyClient := groovy.New(g.jenkinsClient, g.k8sClient, g.logger, g.events, "", "")
+	env, resolved, err := groovyClient.SubstituteSecretReferences(jenkins.Spec.Master.GlobalEnv, secretReferenceKind, jenkins)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return nil
+	}
+
+	script := groovy.SettingsScript{Format: ensureGlobalEnvFmt, DoneMarker: appliedMarker}
+	lines, err := script.Execute(g.jenkinsClient, globalEnvSettings{Env: env})
+	if err != nil {
+		return stackerr.Wrap(err, "couldn't configure global environment variables")
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, updatedPrefix) {
+			names := strings.Replace(strings.TrimPrefix(line, updatedPrefix), ",", ", ", -1)
+			g.logger.Info(fmt.Sprintf("Global environment variables have been updated: %s", names))
+			g.events.Emitf(jenkins, event.TypeNormal, reasonGlobalEnvUpdated, "Global environment variables updated: %s", names)
+		}
+	}
+
+	managed := len(jenkins.Spec.Master.GlobalEnv) > 0
+	if jenkins.Status.GlobalEnvManaged == managed {
+		return nil
+	}
+	jenkins.Status.GlobalEnvManaged = managed
+	return g.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
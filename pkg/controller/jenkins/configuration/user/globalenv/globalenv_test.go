@@ -0,0 +1,119 @@
+package globalenv
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureGlobalEnv(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "artifactory", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("artifactory-token")},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{
+				GlobalEnv: map[string]string{
+					"ARTIFACTORY_URL":   "https://artifactory.example.com",
+					"ARTIFACTORY_TOKEN": "${secret:artifactory:token}",
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins, secret)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	globalEnv := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// variables are applied with resolved secret references
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Equal(t, `{"env":{"ARTIFACTORY_TOKEN":"artifactory-token","ARTIFACTORY_URL":"https://artifactory.example.com"}}`,
+			groovytest.DecodeSettings(t, script))
+		return updatedPrefix + "ARTIFACTORY_TOKEN,ARTIFACTORY_URL\n" + appliedMarker, nil
+	})
+
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonGlobalEnvUpdated}, events.reasons)
+	assert.Equal(t, "Global environment variables updated: ARTIFACTORY_TOKEN, ARTIFACTORY_URL", events.messages[0])
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.True(t, updatedJenkins.Status.GlobalEnvManaged)
+
+	// variables are checked again on every call
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(appliedMarker, nil)
+
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.NoError(t, err)
+	assert.Len(t, events.reasons, 1)
+
+	// the secret reference can't be resolved, nothing is applied
+	jenkins.Spec.Master.GlobalEnv["ARTIFACTORY_USER"] = "${secret:artifactory:user}"
+
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, event.Reason("SecretReferenceNotResolved"), events.reasons[len(events.reasons)-1])
+
+	// the section removed from Jenkins CR
+	jenkins.Spec.Master.GlobalEnv = nil
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Equal(t, `{"env":{}}`, groovytest.DecodeSettings(t, script))
+		return updatedPrefix + "ARTIFACTORY_TOKEN,ARTIFACTORY_URL\n" + appliedMarker, nil
+	})
+
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.NoError(t, err)
+	assert.False(t, jenkins.Status.GlobalEnvManaged)
+
+	// variables aren't managed anymore
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.NoError(t, err)
+
+	// the script failed, the value of the secret is masked
+	jenkins.Spec.Master.GlobalEnv = map[string]string{"ARTIFACTORY_TOKEN": "${secret:artifactory:token}"}
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.io.IOException: can't save artifactory-token", nil)
+
+	err = globalEnv.EnsureGlobalEnv(jenkins)
+	assert.EqualError(t, err, "couldn't configure global environment variables: java.io.IOException: can't save ****")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,2 @@
+// Package libraries implements registration of global pipeline shared libraries declared in Jenkins CR
+package libraries
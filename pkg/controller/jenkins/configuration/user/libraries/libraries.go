@@ -0,0 +1,176 @@
+package libraries
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonSharedLibrariesUpdated is the event which informs global pipeline shared libraries have been registered,
+	// configured again or unregistered in Jenkins
+	reasonSharedLibrariesUpdated event.Reason = "SharedLibrariesUpdated"
+
+	// syncedMarker is printed by syncLibrariesFmt when all libraries have been synchronized
+	syncedMarker = "shared libraries synchronized"
+)
+
+// syncLibrariesFmt registers or replaces changed libraries and unregisters removed libraries of the
+// workflow-cps-global-lib plugin, libraries are passed as the base64 encoded JSON built by syncSettings,
+// libraries configured manually in Jenkins are kept
+const syncLibrariesFmt = `
+import jenkins.plugins.git.GitSCMSource
+import org.jenkinsci.plugins.workflow.libs.GlobalLibraries
+import org.jenkinsci.plugins.workflow.libs.LibraryConfiguration
+import org.jenkinsci.plugins.workflow.libs.SCMSourceRetriever
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+
+try {
+    def configured = [:]
+    settings.libraries.each { entry ->
+        def source = new GitSCMSource(entry.git.repositoryUrl)
+        source.setCredentialsId(entry.git.credentialsId ?: null)
+        def library = new LibraryConfiguration(entry.name, new SCMSourceRetriever(source))
+        library.setDefaultVersion(entry.defaultVersion ?: null)
+        library.setImplicit(entry.implicit)
+        library.setAllowVersionOverride(entry.allowVersionOverride)
+        configured[entry.name] = library
+    }
+
+    def globalLibraries = GlobalLibraries.get()
+    def libraries = []
+    globalLibraries.getLibraries().each { library ->
+        if (configured.containsKey(library.getName())) {
+            libraries.add(configured.remove(library.getName()))
+        } else if (!settings.remove.contains(library.getName())) {
+            libraries.add(library)
+        }
+    }
+    libraries.addAll(configured.values())
+    globalLibraries.setLibraries(libraries)
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + syncedMarker + `'
+`
+
+// syncSettings defines libraries passed to syncLibrariesFmt
+type syncSettings struct {
+	Libraries []librarySettings `json:"libraries"`
+	Remove    []string          `json:"remove"`
+}
+
+type librarySettings struct {
+	Name                 string                              `json:"name"`
+	DefaultVersion       string                              `json:"defaultVersion"`
+	Git                  *v1alpha1.SharedLibraryGitRetrieval `json:"git"`
+	Implicit             bool                                `json:"implicit"`
+	AllowVersionOverride bool                                `json:"allowVersionOverride"`
+}
+
+// SharedLibraries synchronizes global pipeline shared libraries of Jenkins with Jenkins.Spec.SharedLibraries
+type SharedLibraries struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates SharedLibraries object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *SharedLibraries {
+	return &SharedLibraries{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// AllowVersionOverride returns whether pipelines can select the version of the library, it's allowed by default
+func AllowVersionOverride(library v1alpha1.SharedLibrary) bool {
+	return library.AllowVersionOverride == nil || *library.AllowVersionOverride
+}
+
+// EnsureSharedLibraries registers or configures again libraries which checksum differs from Jenkins.Status.SharedLibraries
+// and unregisters libraries which have been removed from Jenkins.Spec.SharedLibraries
+func (s *SharedLibraries) EnsureSharedLibraries(jenkins *v1alpha1.Jenkins) error {
+	checksums := map[string]string{}
+	for _, status := range jenkins.Status.SharedLibraries {
+		checksums[status.Name] = status.Checksum
+	}
+
+	settings := syncSettings{}
+	var managed []v1alpha1.SharedLibraryStatus
+	var updated []string
+	declared := map[string]bool{}
+	for _, library := range jenkins.Spec.SharedLibraries {
+		entry := librarySettings{
+			Name:                 library.Name,
+			DefaultVersion:       library.DefaultVersion,
+			Git:                  library.Retrieval.Git,
+			Implicit:             library.Implicit,
+			AllowVersionOverride: AllowVersionOverride(library),
+		}
+		checksum, err := calculateChecksum(entry)
+		if err != nil {
+			return err
+		}
+		declared[library.Name] = true
+		managed = append(managed, v1alpha1.SharedLibraryStatus{Name: library.Name, Checksum: checksum})
+		if previous, ok := checksums[library.Name]; ok && previous == checksum {
+			continue
+		}
+		settings.Libraries = append(settings.Libraries, entry)
+		updated = append(updated, library.Name)
+	}
+	for _, status := range jenkins.Status.SharedLibraries {
+		if !declared[status.Name] {
+			settings.Remove = append(settings.Remove, status.Name)
+		}
+	}
+	if len(settings.Libraries) == 0 && len(settings.Remove) == 0 {
+		return nil
+	}
+
+	script := groovy.SettingsScript{Format: syncLibrariesFmt, DoneMarker: syncedMarker}
+	if _, err := script.Execute(s.jenkinsClient, settings); err != nil {
+		return stackerr.Wrapf(err, "couldn't synchronize shared libraries %v", append(updated, settings.Remove...))
+	}
+
+	var messages []string
+	if len(updated) > 0 {
+		messages = append(messages, fmt.Sprintf("registered or updated: %s", strings.Join(updated, ", ")))
+	}
+	if len(settings.Remove) > 0 {
+		messages = append(messages, fmt.Sprintf("unregistered: %s", strings.Join(settings.Remove, ", ")))
+	}
+	s.logger.Info(fmt.Sprintf("Shared libraries %s", strings.Join(messages, "; ")))
+	s.events.Emitf(jenkins, event.TypeNormal, reasonSharedLibrariesUpdated, "Shared libraries %s", strings.Join(messages, "; "))
+
+	jenkins.Status.SharedLibraries = managed
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// calculateChecksum returns the checksum of the library configuration applied by syncLibrariesFmt
+func calculateChecksum(library librarySettings) (string, error) {
+	data, err := json.Marshal(library)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,132 @@
+package libraries
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureSharedLibraries(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	allowVersionOverride := false
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			SharedLibraries: []v1alpha1.SharedLibrary{
+				{
+					Name:           "pipeline-utils",
+					DefaultVersion: "master",
+					Retrieval: v1alpha1.SharedLibraryRetrieval{
+						Git: &v1alpha1.SharedLibraryGitRetrieval{RepositoryURL: "https://github.com/example/pipeline-utils.git"},
+					},
+				},
+				{
+					Name:           "deploy",
+					DefaultVersion: "v1.0.0",
+					Retrieval: v1alpha1.SharedLibraryRetrieval{
+						Git: &v1alpha1.SharedLibraryGitRetrieval{RepositoryURL: "git@github.com:example/deploy.git", CredentialsID: "deploy-key"},
+					},
+					Implicit:             true,
+					AllowVersionOverride: &allowVersionOverride,
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	libraries := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// libraries are registered
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `{"name":"pipeline-utils","defaultVersion":"master",`+
+			`"git":{"repositoryUrl":"https://github.com/example/pipeline-utils.git"},"implicit":false,"allowVersionOverride":true}`)
+		assert.Contains(t, settings, `{"name":"deploy","defaultVersion":"v1.0.0",`+
+			`"git":{"repositoryUrl":"git@github.com:example/deploy.git","credentialsId":"deploy-key"},"implicit":true,"allowVersionOverride":false}`)
+		return syncedMarker + "\n", nil
+	})
+
+	err = libraries.EnsureSharedLibraries(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonSharedLibrariesUpdated}, events.reasons)
+	assert.Equal(t, "Shared libraries registered or updated: pipeline-utils, deploy", events.messages[0])
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.Len(t, updatedJenkins.Status.SharedLibraries, 2)
+	assert.Equal(t, "pipeline-utils", updatedJenkins.Status.SharedLibraries[0].Name)
+	assert.Equal(t, "deploy", updatedJenkins.Status.SharedLibraries[1].Name)
+
+	// nothing changed
+	err = libraries.EnsureSharedLibraries(jenkins)
+	assert.NoError(t, err)
+
+	// only the changed library is configured again
+	jenkins.Spec.SharedLibraries[0].DefaultVersion = "v2"
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `"defaultVersion":"v2"`)
+		assert.NotContains(t, settings, `"name":"deploy"`)
+		return syncedMarker, nil
+	})
+
+	err = libraries.EnsureSharedLibraries(jenkins)
+	assert.NoError(t, err)
+
+	// the library removed from Jenkins CR
+	jenkins.Spec.SharedLibraries = jenkins.Spec.SharedLibraries[:1]
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Contains(t, groovytest.DecodeSettings(t, script), `"libraries":null,"remove":["deploy"]`)
+		return syncedMarker, nil
+	})
+
+	err = libraries.EnsureSharedLibraries(jenkins)
+	assert.NoError(t, err)
+	assert.Len(t, jenkins.Status.SharedLibraries, 1)
+	assert.Equal(t, "pipeline-utils", jenkins.Status.SharedLibraries[0].Name)
+	assert.Equal(t, "Shared libraries unregistered: deploy", events.messages[len(events.messages)-1])
+
+	// the script failed
+	jenkins.Spec.SharedLibraries[0].Implicit = true
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.lang.NoClassDefFoundError: jenkins/plugins/git/GitSCMSource", nil)
+
+	err = libraries.EnsureSharedLibraries(jenkins)
+	assert.EqualError(t, err, "couldn't synchronize shared libraries [pipeline-utils]: java.lang.NoClassDefFoundError: jenkins/plugins/git/GitSCMSource")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
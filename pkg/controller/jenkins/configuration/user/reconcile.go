@@ -2,47 +2,102 @@ package user
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/casc"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/credentials"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/git"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/globalenv"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/libraries"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/seedjobs"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/systemmessage"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/tools"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/urls"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/views"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	// reasonFileSkipped is the event which informs the file from the user configuration source is neither
+	// the groovy script nor the JCasC YAML file
+	reasonFileSkipped event.Reason = "UserConfigurationFileSkipped"
+)
+
 // ReconcileUserConfiguration defines values required for Jenkins user configuration
 type ReconcileUserConfiguration struct {
 	k8sClient     k8s.Client
 	jenkinsClient jenkinsclient.Jenkins
 	logger        logr.Logger
 	jenkins       *v1alpha1.Jenkins
+	events        event.Recorder
 }
 
 // New create structure which takes care of user configuration
 func New(k8sClient k8s.Client, jenkinsClient jenkinsclient.Jenkins, logger logr.Logger,
-	jenkins *v1alpha1.Jenkins) *ReconcileUserConfiguration {
+	jenkins *v1alpha1.Jenkins, events event.Recorder) *ReconcileUserConfiguration {
 	return &ReconcileUserConfiguration{
 		k8sClient:     k8sClient,
 		jenkinsClient: jenkinsClient,
 		logger:        logger,
 		jenkins:       jenkins,
+		events:        events,
 	}
 }
 
 // Reconcile it's a main reconciliation loop for user supplied configuration
 func (r *ReconcileUserConfiguration) Reconcile() (reconcile.Result, error) {
-	// reconcile seed jobs
-	result, err := r.ensureSeedJobs()
+	// credentials from secrets are synchronized first, configuration as code and seed jobs can use them
+	err := credentials.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureCredentials(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// shared libraries can use credentials, they are registered before seed jobs build pipelines loading them
+	err = libraries.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureSharedLibraries(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// tools are configured before seed jobs build pipelines using them
+	err = tools.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureTools(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// global environment variables are kept in sync on every reconciliation, changes made in Jenkins are reverted
+	err = globalenv.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureGlobalEnv(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// the system message is compared with Jenkins CR on every reconciliation, changes made in Jenkins are reverted
+	err = systemmessage.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureSystemMessage(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// reconcile configuration as code, seed jobs can use credentials configured by it
+	result, err := r.ensureConfigurationAsCode()
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -50,6 +105,33 @@ func (r *ReconcileUserConfiguration) Reconcile() (reconcile.Result, error) {
 		return result, nil
 	}
 
+	// reconcile seed jobs, a failed build is retried after the rest of the user configuration is applied
+	seedJobsResult, seedJobsFailed, err := r.ensureSeedJobs()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if seedJobsResult.Requeue && !seedJobsFailed {
+		return seedJobsResult, nil
+	}
+
+	// views show jobs created by seed jobs
+	err = views.New(r.jenkinsClient, r.k8sClient, r.logger, r.events).EnsureViews(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// fetch user configuration scripts from the git repository, they are applied with other scripts
+	now := time.Now()
+	fetchAfter, err := git.New(r.k8sClient, r.logger, r.events).EnsureGitConfiguration(r.jenkins, now)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// download user configuration scripts from URLs, they are applied with other scripts
+	downloadAfter, err := urls.New(r.k8sClient, r.logger, r.events).EnsureURLConfiguration(r.jenkins, now)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	result, err = r.ensureUserConfiguration(r.jenkinsClient)
 	if err != nil {
 		return reconcile.Result{}, err
@@ -57,55 +139,223 @@ func (r *ReconcileUserConfiguration) Reconcile() (reconcile.Result, error) {
 	if result.Requeue {
 		return result, nil
 	}
+	if seedJobsFailed {
+		return seedJobsResult, nil
+	}
 
+	// seed jobs with reRunInterval are built again, the git repository is fetched again, scripts are downloaded
+	// again and secret references are resolved from Vault again by the operator, requeue when the first one is due
+	requeueAfter := seedjobs.ReRunAfter(r.jenkins, now)
+	for _, after := range []time.Duration{fetchAfter, downloadAfter, vault.RefreshInterval(r.jenkins)} {
+		if after > 0 && (requeueAfter == 0 || after < requeueAfter) {
+			requeueAfter = after
+		}
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func (r *ReconcileUserConfiguration) ensureConfigurationAsCode() (reconcile.Result, error) {
+	configurationAsCode := casc.New(r.jenkinsClient, r.k8sClient, r.logger, r.events)
+	done, err := configurationAsCode.EnsureConfigurationAsCode(r.jenkins)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// mounted files not updated yet - requeue reconciliation loop with timeout
+	if !done {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileUserConfiguration) ensureSeedJobs() (reconcile.Result, error) {
-	seedJobs := seedjobs.New(r.jenkinsClient, r.k8sClient, r.logger)
+// ensureSeedJobs returns true when a seed job build failed and is retried, the rest of the user configuration
+// doesn't wait for it
+func (r *ReconcileUserConfiguration) ensureSeedJobs() (reconcile.Result, bool, error) {
+	seedJobs := seedjobs.New(r.jenkinsClient, r.k8sClient, r.logger, r.events)
 	done, err := seedJobs.EnsureSeedJobs(r.jenkins)
 	if err != nil {
 		// build failed and can be recovered - retry build and requeue reconciliation loop with timeout
 		if err == jobs.ErrorBuildFailed {
-			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, true, nil
 		}
 		// build failed and cannot be recovered
 		if err == jobs.ErrorUnrecoverableBuildFailed {
-			return reconcile.Result{}, nil
+			return reconcile.Result{}, false, nil
 		}
 		// unexpected error - requeue reconciliation loop
-		return reconcile.Result{}, errors.WithStack(err)
+		return reconcile.Result{}, false, errors.WithStack(err)
 	}
 	// build not finished yet - requeue reconciliation loop with timeout
 	if !done {
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, false, nil
 	}
-	return reconcile.Result{}, nil
+	return reconcile.Result{}, false, nil
 }
 
 func (r *ReconcileUserConfiguration) ensureUserConfiguration(jenkinsClient jenkinsclient.Jenkins) (reconcile.Result, error) {
-	groovyClient := groovy.New(jenkinsClient, r.k8sClient, r.logger, constants.UserConfigurationJobName, resources.JenkinsUserConfigurationVolumePath)
+	groovyClient := groovy.New(jenkinsClient, r.k8sClient, r.logger, r.events, resources.GetJobName(r.jenkins, constants.UserConfigurationJobName),
+		resources.JenkinsUserConfigurationVolumePath)
 
-	err := groovyClient.ConfigureGroovyJob()
+	// the job isn't needed when scripts are executed via the script console
+	options := groovy.Options{
+		ScriptConsole: r.jenkins.Spec.Configuration.ExecutionMode == v1alpha1.ExecutionModeScriptConsole,
+		FailFast:      r.jenkins.Spec.Configuration.FailFast,
+	}
+	var err error
+	if options.ScriptConsole {
+		err = groovyClient.DeleteGroovyJob()
+	} else {
+		err = groovyClient.ConfigureGroovyJob(jobs.BuildRetention(r.jenkins.Spec.Configuration.BuildRetention))
+	}
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	configuration := &corev1.ConfigMap{}
-	namespaceName := types.NamespacedName{Namespace: r.jenkins.Namespace, Name: resources.GetUserConfigurationConfigMapName(r.jenkins)}
-	err = r.k8sClient.Get(context.TODO(), namespaceName, configuration)
+	// scripts and JCasC YAML files are keyed by their path relative to the scripts directory, they are applied
+	// sorted by the priority of their source and the path, other files are skipped
+	scripts := map[string]groovy.Script{}
+	files := map[string]casc.File{}
+	skippedFiles := map[string]string{}
+	for index, source := range resources.GetUserConfigurationSources(r.jenkins) {
+		data, priority, err := r.getUserConfigurationScripts(source)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		directory := resources.GetUserConfigurationDirectory(r.jenkins, index)
+		var keys []string
+		for key, script := range data {
+			switch {
+			case strings.HasSuffix(key, groovy.ScriptExtension):
+				scripts[path.Join(directory, key)] = groovy.Script{Source: source.String(), Key: key, Content: script, Priority: priority}
+			case casc.IsFile(key):
+				files[path.Join(directory, key)] = casc.File{Source: source.String(), Key: key, Content: script, Priority: priority}
+			default:
+				skippedFiles[fmt.Sprintf("%s/%s", source, key)] = fmt.Sprintf(
+					"File '%s' from '%s' is skipped, it has to be a .groovy script or a .yaml or .yml JCasC file", key, source)
+			}
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		// only key names are logged, scripts from secrets can contain credentials
+		r.logger.V(log.VDebug).Info(fmt.Sprintf("User configuration scripts %v from '%s', checksum '%s'",
+			keys, source, groovy.CalculateHash(data)))
+	}
+	err = r.reportSkippedFiles(skippedFiles)
 	if err != nil {
-		return reconcile.Result{}, errors.WithStack(err)
+		return reconcile.Result{}, err
 	}
 
-	done, err := groovyClient.EnsureGroovyJob(configuration.Data, r.jenkins)
+	// JCasC YAML files are applied first, groovy scripts can use what they configure
+	done, err := casc.New(jenkinsClient, r.k8sClient, r.logger, r.events).EnsureConfigurationAsCodeFiles(files, r.jenkins)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
+	// mounted files not updated yet - requeue reconciliation loop with timeout
+	if !done {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
 
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, r.jenkins, options)
+	// scripts can't be compiled, failed and aren't retried or the build failed and cannot be recovered - they are applied again when they change
+	if err == groovy.ErrorScriptSyntax || err == groovy.ErrorScriptFailed || err == jobs.ErrorUnrecoverableBuildFailed {
+		return reconcile.Result{}, nil
+	}
+	// build failed and can be recovered - retry build and requeue reconciliation loop with timeout
+	if err == jobs.ErrorBuildFailed {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// build not finished yet or failed scripts are retried - requeue reconciliation loop with timeout
 	if !done {
 		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
 	}
 
+	// the first completion is recorded by the Jenkins controller
+	appliedAgain := r.isUserConfigurationAppliedAgain()
+	if appliedAgain {
+		now := metav1.Now()
+		r.jenkins.Status.UserConfigurationCompletedTime = &now
+		r.logger.Info("User configuration has been applied again")
+	}
+	gitStatus := r.jenkins.Status.GitConfiguration
+	gitApplied := gitStatus != nil && gitStatus.AppliedCommit != gitStatus.FetchedCommit
+	if gitApplied {
+		gitStatus.AppliedCommit = gitStatus.FetchedCommit
+		r.logger.Info(fmt.Sprintf("User configuration scripts of commit '%s' have been applied", gitStatus.AppliedCommit))
+	}
+	if appliedAgain || gitApplied {
+		err = r.k8sClient.Update(context.TODO(), r.jenkins)
+		if err != nil {
+			return reconcile.Result{}, err // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+		}
+	}
+
 	return reconcile.Result{}, nil
 }
+
+// isUserConfigurationAppliedAgain checks whether any script or JCasC YAML file has been applied after the user configuration completed
+func (r *ReconcileUserConfiguration) isUserConfigurationAppliedAgain() bool {
+	completedTime := r.jenkins.Status.UserConfigurationCompletedTime
+	if completedTime == nil {
+		return false
+	}
+	for _, appliedScripts := range [][]v1alpha1.AppliedScript{r.jenkins.Status.AppliedConfiguration, r.jenkins.Status.AppliedConfigurationAsCode} {
+		for _, appliedScript := range appliedScripts {
+			if appliedScript.Result == v1alpha1.AppliedScriptSuccessResult && appliedScript.LastAppliedTime != nil &&
+				completedTime.Before(appliedScript.LastAppliedTime) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reportSkippedFiles reports files which are neither groovy scripts nor JCasC YAML files, keyed by the source
+// and the key, when the set of skipped files differs from the one stored in status
+func (r *ReconcileUserConfiguration) reportSkippedFiles(skippedFiles map[string]string) error {
+	checksum := ""
+	if len(skippedFiles) > 0 {
+		checksum = groovy.CalculateHash(skippedFiles)
+	}
+	if checksum == r.jenkins.Status.SkippedUserConfigurationFilesChecksum {
+		return nil
+	}
+
+	var keys []string
+	for key := range skippedFiles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		r.logger.V(log.VWarn).Info(skippedFiles[key])
+		r.events.Emit(r.jenkins, event.TypeWarning, reasonFileSkipped, skippedFiles[key])
+	}
+	r.jenkins.Status.SkippedUserConfigurationFilesChecksum = checksum
+	return r.k8sClient.Update(context.TODO(), r.jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// getUserConfigurationScripts returns scripts of the source and its priority
+func (r *ReconcileUserConfiguration) getUserConfigurationScripts(source resources.UserConfigurationSource) (map[string]string, int, error) {
+	namespaceName := types.NamespacedName{Namespace: r.jenkins.Namespace, Name: source.Name}
+	if !source.Secret {
+		configMap := &corev1.ConfigMap{}
+		if err := r.k8sClient.Get(context.TODO(), namespaceName, configMap); err != nil {
+			return nil, 0, errors.WithStack(err)
+		}
+		priority, err := resources.GetUserConfigurationPriority(configMap.Annotations)
+		return configMap.Data, priority, errors.WithStack(err)
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.k8sClient.Get(context.TODO(), namespaceName, secret); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	data := map[string]string{}
+	for key, value := range secret.Data {
+		data[key] = string(value)
+	}
+	priority, err := resources.GetUserConfigurationPriority(secret.Annotations)
+	return data, priority, errors.WithStack(err)
+}
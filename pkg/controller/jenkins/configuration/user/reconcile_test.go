@@ -0,0 +1,85 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestReportSkippedFiles(t *testing.T) {
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	events := &fakeRecorder{}
+	reconciler := New(fakeClient, nil, logf.ZapLogger(false), jenkins, events)
+	skippedFiles := map[string]string{
+		"configmap jenkins/README.md": "File 'README.md' from 'configmap jenkins' is skipped",
+		"configmap jenkins/notes.txt": "File 'notes.txt' from 'configmap jenkins' is skipped",
+	}
+
+	t.Run("new files are reported", func(t *testing.T) {
+		err := reconciler.reportSkippedFiles(skippedFiles)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"File 'README.md' from 'configmap jenkins' is skipped",
+			"File 'notes.txt' from 'configmap jenkins' is skipped",
+		}, events.messages)
+		stored := &v1alpha1.Jenkins{}
+		err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: "jenkins", Namespace: "default"}, stored)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, stored.Status.SkippedUserConfigurationFilesChecksum)
+	})
+	t.Run("the same files aren't reported again", func(t *testing.T) {
+		events.messages = nil
+
+		err := reconciler.reportSkippedFiles(skippedFiles)
+
+		assert.NoError(t, err)
+		assert.Empty(t, events.messages)
+	})
+	t.Run("changed set of files is reported", func(t *testing.T) {
+		events.messages = nil
+		delete(skippedFiles, "configmap jenkins/notes.txt")
+
+		err := reconciler.reportSkippedFiles(skippedFiles)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"File 'README.md' from 'configmap jenkins' is skipped"}, events.messages)
+	})
+	t.Run("no skipped files clear the checksum", func(t *testing.T) {
+		events.messages = nil
+
+		err := reconciler.reportSkippedFiles(map[string]string{})
+
+		assert.NoError(t, err)
+		assert.Empty(t, events.messages)
+		assert.Empty(t, jenkins.Status.SkippedUserConfigurationFilesChecksum)
+	})
+}
+
+type fakeRecorder struct {
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
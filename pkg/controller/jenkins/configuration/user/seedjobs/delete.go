@@ -0,0 +1,240 @@
+package seedjobs
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+const (
+	seedJobIDParameterName           = "SEED_JOB_ID"
+	deleteGeneratedJobsParameterName = "DELETE_GENERATED_JOBS"
+	deletedSeedJobModeParameterName  = "SEED_JOB_MODE"
+)
+
+// trackSeedJobs saves seed jobs from Jenkins CR in Jenkins.Status.SeedJobs section,
+// entries of removed seed jobs are kept until they are deleted from Jenkins
+func (s *SeedJobs) trackSeedJobs(jenkins *v1alpha1.Jenkins) error {
+	changed := false
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		index := seedJobStatusIndex(jenkins.Status.SeedJobs, seedJob.ID)
+		if index < 0 {
+			jenkins.Status.SeedJobs = append(jenkins.Status.SeedJobs, v1alpha1.SeedJobStatus{ID: seedJob.ID, GCPolicy: seedJob.GCPolicy, Mode: seedJob.Mode})
+			changed = true
+		} else if jenkins.Status.SeedJobs[index].GCPolicy != seedJob.GCPolicy || jenkins.Status.SeedJobs[index].Mode != seedJob.Mode {
+			jenkins.Status.SeedJobs[index].GCPolicy = seedJob.GCPolicy
+			jenkins.Status.SeedJobs[index].Mode = seedJob.Mode
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// deleteRemovedSeedJobs deletes from Jenkins seed jobs which were created by operator and removed from Jenkins CR
+func (s *SeedJobs) deleteRemovedSeedJobs(jenkins *v1alpha1.Jenkins) (done bool, err error) {
+	var removedSeedJobs []v1alpha1.SeedJobStatus
+	for _, seedJobStatus := range jenkins.Status.SeedJobs {
+		if !isSeedJobInSpec(jenkins, seedJobStatus.ID) {
+			removedSeedJobs = append(removedSeedJobs, seedJobStatus)
+		}
+	}
+	if len(removedSeedJobs) == 0 {
+		return true, nil
+	}
+
+	jobName := resources.GetJobName(jenkins, constants.DeleteSeedJobsName)
+	_, created, err := s.jenkinsClient.CreateOrUpdateJob(deleteSeedJobConfigXML, jobName)
+	if err != nil {
+		return false, err
+	}
+	if created {
+		s.logger.Info(fmt.Sprintf("'%s' job has been created", jobName))
+	}
+
+	allDone := true
+	for _, seedJobStatus := range removedSeedJobs {
+		parameters := map[string]string{
+			seedJobIDParameterName:           seedJobStatus.ID,
+			deleteGeneratedJobsParameterName: boolParameter(seedJobStatus.GCPolicy == v1alpha1.SeedJobGCPolicyDelete),
+			deletedSeedJobModeParameterName:  string(seedJobStatus.Mode),
+		}
+
+		hash := sha256.New()
+		hash.Write([]byte(parameters[seedJobIDParameterName]))
+		hash.Write([]byte(parameters[deleteGeneratedJobsParameterName]))
+		hash.Write([]byte(parameters[deletedSeedJobModeParameterName]))
+		encodedHash := base64.URLEncoding.EncodeToString(hash.Sum(nil))
+
+		jobsClient := jobs.New(s.jenkinsClient, s.k8sClient, s.logger, s.events)
+		done, err := jobsClient.EnsureBuildJob(jobName, encodedHash, parameters, jenkins, true, jobs.BuildOptions{})
+		if err != nil {
+			return false, err
+		}
+		if !done {
+			allDone = false
+			continue
+		}
+
+		s.logger.Info(fmt.Sprintf("Seed job '%s' has been deleted", seedJobStatus.ID))
+		err = s.untrackSeedJob(jenkins, seedJobStatus.ID, encodedHash)
+		if err != nil {
+			s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't remove seed job '%s' from status", seedJobStatus.ID))
+			return false, err
+		}
+	}
+	return allDone, nil
+}
+
+// untrackSeedJob removes deleted seed job and its delete build from Jenkins.Status section
+func (s *SeedJobs) untrackSeedJob(jenkins *v1alpha1.Jenkins, id, hash string) error {
+	var seedJobs []v1alpha1.SeedJobStatus
+	for _, seedJobStatus := range jenkins.Status.SeedJobs {
+		if seedJobStatus.ID != id {
+			seedJobs = append(seedJobs, seedJobStatus)
+		}
+	}
+	jenkins.Status.SeedJobs = seedJobs
+
+	var builds []v1alpha1.Build
+	for _, build := range jenkins.Status.Builds {
+		if build.JobName != resources.GetJobName(jenkins, constants.DeleteSeedJobsName) || build.Hash != hash {
+			builds = append(builds, build)
+		}
+	}
+	jenkins.Status.Builds = builds
+
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+func seedJobStatusIndex(seedJobs []v1alpha1.SeedJobStatus, id string) int {
+	for index, seedJobStatus := range seedJobs {
+		if seedJobStatus.ID == id {
+			return index
+		}
+	}
+	return -1
+}
+
+func isSeedJobInSpec(jenkins *v1alpha1.Jenkins, id string) bool {
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		if seedJob.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteSeedJobConfigXML this is the XML representation of the job which deletes seed jobs,
+// only jobs which were created by operator or generated by them are touched
+var deleteSeedJobConfigXML = `
+<flow-definition plugin="workflow-job@2.30">
+  <actions/>
+  <description>Delete Seed Jobs</description>
+  <keepDependencies>false</keepDependencies>
+  <properties>
+    ` + jobs.BuildDiscarderPropertyXML(constants.DefaultBuildRetention) + `
+    <hudson.model.ParametersDefinitionProperty>
+      <parameterDefinitions>
+        <hudson.model.StringParameterDefinition>
+          <name>` + seedJobIDParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + deleteGeneratedJobsParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + deletedSeedJobModeParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+      </parameterDefinitions>
+    </hudson.model.ParametersDefinitionProperty>
+  </properties>
+  <definition class="org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition" plugin="workflow-cps@2.61">
+    <script>import com.cloudbees.plugins.credentials.SystemCredentialsProvider
+import com.cloudbees.plugins.credentials.domains.Domain
+import hudson.model.FreeStyleProject
+import javaposse.jobdsl.plugin.DescriptorImpl
+import javaposse.jobdsl.plugin.ExecuteDslScripts
+import jenkins.model.Jenkins
+
+Jenkins jenkins = Jenkins.instance
+
+def seedJobID = &quot;${params.SEED_JOB_ID}&quot;
+def jobDslSeedName = &quot;${params.SEED_JOB_ID}-` + constants.SeedJobSuffix + `&quot;
+def jobRef = jenkins.getItem(jobDslSeedName)
+
+// only Job DSL seed jobs are deleted, any other job with the same name is left untouched
+if (jobRef instanceof FreeStyleProject &amp;&amp; jobRef.getBuildersList().get(ExecuteDslScripts) != null) {
+        if (&quot;${params.DELETE_GENERATED_JOBS}&quot;) {
+                def generatedJobNames = []
+                for (generatedJob in jenkins.getDescriptorByType(DescriptorImpl).getGeneratedJobMap().entrySet()) {
+                        if (generatedJob.value.seedJobName == jobDslSeedName) {
+                                generatedJobNames.add(generatedJob.key)
+                        }
+                }
+                for (generatedJobName in generatedJobNames) {
+                        def generatedJobRef = jenkins.getItemByFullName(generatedJobName)
+                        if (generatedJobRef != null) {
+                                println(&quot;Deleting generated job &quot; + generatedJobName)
+                                generatedJobRef.delete()
+                        }
+                }
+        }
+        println(&quot;Deleting seed job &quot; + jobDslSeedName)
+        jobRef.delete()
+}
+
+// the multibranch pipeline is deleted together with jobs of its branches
+def multibranchRef = jenkins.getItem(seedJobID)
+if (&quot;${params.SEED_JOB_MODE}&quot; == &quot;` + string(v1alpha1.SeedJobModeMultibranch) + `&quot; &amp;&amp;
+        multibranchRef != null &amp;&amp;
+        multibranchRef.getClass().getName() == &quot;org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject&quot;) {
+        println(&quot;Deleting multibranch pipeline &quot; + seedJobID)
+        multibranchRef.delete()
+}
+
+// the organization folder contains jobs of all organization repositories, it's deleted only when the gc policy allows it
+def organizationFolderRef = jenkins.getItem(seedJobID)
+if (&quot;${params.SEED_JOB_MODE}&quot; == &quot;` + string(v1alpha1.SeedJobModeOrganizationFolder) + `&quot; &amp;&amp;
+        &quot;${params.DELETE_GENERATED_JOBS}&quot; &amp;&amp;
+        organizationFolderRef != null &amp;&amp;
+        organizationFolderRef.getClass().getName() == &quot;jenkins.branch.OrganizationFolder&quot;) {
+        println(&quot;Deleting organization folder &quot; + seedJobID)
+        organizationFolderRef.delete()
+}
+
+def store = SystemCredentialsProvider.getInstance().getStore()
+def deployKeys = []
+for (credentials in store.getCredentials(Domain.global())) {
+        if (credentials.id == seedJobID || credentials.id == seedJobID + &quot;-token&quot;) {
+                deployKeys.add(credentials)
+        }
+}
+for (deployKey in deployKeys) {
+        store.removeCredentials(Domain.global(), deployKey)
+}
+</script>
+    <sandbox>false</sandbox>
+  </definition>
+  <triggers/>
+  <disabled>false</disabled>
+</flow-definition>
+`
@@ -0,0 +1,47 @@
+package seedjobs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+// gerritServerScript prints true when the Gerrit server is configured in the gerrit-trigger plugin
+const gerritServerScript = `
+def pluginImpl = Jenkins.instance.pluginManager.uberClassLoader
+        .loadClass("com.sonyericsson.hudson.plugins.gerrit.trigger.PluginImpl")
+        .getInstance()
+print(pluginImpl != null && pluginImpl.getServer('%s') != null)
+`
+
+// groovyStringReplacer escapes the value of the single quoted groovy string
+var groovyStringReplacer = strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+
+// ensureGerritServer emits the warning event when the Gerrit server used by the seed job isn't configured in Jenkins,
+// the server is configured by user groovy scripts or Configuration as Code so the seed job is created anyway
+func (s *SeedJobs) ensureGerritServer(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) {
+	serverName := seedJob.GerritTrigger.ServerName
+	output, err := s.jenkinsClient.ExecuteScript(fmt.Sprintf(gerritServerScript, groovyStringReplacer.Replace(serverName)))
+	if err != nil {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't check Gerrit server '%s' of seed job '%s': %s", serverName, seedJob.ID, err))
+		return
+	}
+	if strings.TrimSpace(output) != "true" {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Gerrit server '%s' of seed job '%s' isn't configured in Jenkins", serverName, seedJob.ID))
+		s.events.Emitf(jenkins, event.TypeWarning, reasonGerritServerNotConfigured,
+			"Gerrit server '%s' of seed job '%s' isn't configured in Jenkins, the seed job won't be triggered", serverName, seedJob.ID)
+	}
+}
+
+// isBuildScheduled checks if the build configuring the seed job with given hash has been already scheduled
+func isBuildScheduled(jenkins *v1alpha1.Jenkins, hash string) bool {
+	for _, build := range jenkins.Status.Builds {
+		if build.JobName == configureSeedJobsName(jenkins) && build.Hash == hash {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package seedjobs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureGerritServer(t *testing.T) {
+	seedJob := v1alpha1.SeedJob{
+		ID: "jenkins-operator",
+		GerritTrigger: &v1alpha1.GerritTrigger{
+			ServerName: "gerrit's",
+			Project:    "jenkins-operator",
+		},
+	}
+
+	data := []struct {
+		description    string
+		output         string
+		err            error
+		expectedEvents []event.Reason
+	}{
+		{description: "configured server", output: "true", expectedEvents: nil},
+		{description: "not configured server", output: "false", expectedEvents: []event.Reason{reasonGerritServerNotConfigured}},
+		{description: "script failure", err: errors.New("connection refused"), expectedEvents: nil},
+	}
+
+	for _, testingData := range data {
+		t.Run(fmt.Sprintf("Testing '%s'", testingData.description), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			events := &fakeRecorder{}
+			jenkinsClient := client.NewMockJenkins(ctrl)
+			jenkinsClient.
+				EXPECT().
+				ExecuteScript(gomock.Any()).
+				DoAndReturn(func(script string) (string, error) {
+					assert.Contains(t, script, `getServer('gerrit\'s')`)
+					return testingData.output, testingData.err
+				})
+
+			seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), events)
+			seedJobs.ensureGerritServer(&v1alpha1.Jenkins{}, seedJob)
+
+			assert.Equal(t, testingData.expectedEvents, events.reasons)
+		})
+	}
+}
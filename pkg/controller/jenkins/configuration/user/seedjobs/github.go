@@ -0,0 +1,148 @@
+package seedjobs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	githubAPITimeout  = 10 * time.Second
+	githubAPIURL      = "https://api.github.com"
+	githubHost        = "github.com"
+	githubWebhookPath = "/github-webhook/"
+	// githubPerPageQuery requests the maximum page size to limit the number of GitHub API calls
+	githubPerPageQuery = "?per_page=100"
+)
+
+// githubRepositoryRegexp matches https://host/owner/repo(.git), ssh://git@host/owner/repo(.git) and git@host:owner/repo(.git)
+var githubRepositoryRegexp = regexp.MustCompile(`^(?:https?://|ssh://git@|git@)([^/:]+)[/:]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// githubNextLinkRegexp matches the next page entry of the Link header, e.g. <https://api.github.com/...?page=2>; rel="next"
+var githubNextLinkRegexp = regexp.MustCompile(`^<([^>]+)>;.*\brel="next"`)
+
+type githubWebhook struct {
+	ID     int64             `json:"id,omitempty"`
+	Name   string            `json:"name"`
+	Active bool              `json:"active"`
+	Events []string          `json:"events"`
+	Config map[string]string `json:"config"`
+}
+
+// registerGitHubWebhook creates the push webhook pointing at Jenkins in the GitHub repository if it doesn't exist
+func registerGitHubWebhook(httpClient *http.Client, repositoryURL, token, jenkinsURL string) error {
+	apiURL, owner, repository, err := parseGitHubRepositoryURL(repositoryURL)
+	if err != nil {
+		return err
+	}
+
+	hooksURL := fmt.Sprintf("%s/repos/%s/%s/hooks", apiURL, owner, repository)
+	webhookURL := strings.TrimSuffix(jenkinsURL, "/") + githubWebhookPath
+
+	// the webhook can be on any page of repository hooks
+	for pageURL := hooksURL + githubPerPageQuery; len(pageURL) > 0; {
+		var webhooks []githubWebhook
+		header, err := githubRequest(httpClient, http.MethodGet, pageURL, token, nil, &webhooks)
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			if webhook.Config["url"] == webhookURL {
+				return nil
+			}
+		}
+		pageURL = nextPageURL(header)
+	}
+
+	webhook := &githubWebhook{
+		Name:   "web",
+		Active: true,
+		Events: []string{"push"},
+		Config: map[string]string{
+			"url":          webhookURL,
+			"content_type": "json",
+		},
+	}
+	_, err = githubRequest(httpClient, http.MethodPost, hooksURL, token, webhook, nil)
+	return err
+}
+
+// githubWebhookChecksum returns the checksum of the repository URL and Jenkins URL the GitHub webhook is registered with
+func githubWebhookChecksum(repositoryURL, jenkinsURL string) string {
+	hash := sha256.Sum256([]byte(repositoryURL + "\n" + jenkinsURL))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// nextPageURL returns the URL of the next page from the Link header of GitHub API response, it's empty on the last page
+func nextPageURL(header http.Header) string {
+	for _, link := range strings.Split(header.Get("Link"), ",") {
+		matches := githubNextLinkRegexp.FindStringSubmatch(strings.TrimSpace(link))
+		if matches != nil {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// parseGitHubRepositoryURL returns GitHub API URL, owner and name of the repository, GitHub Enterprise hosts are supported
+func parseGitHubRepositoryURL(repositoryURL string) (apiURL, owner, repository string, err error) {
+	matches := githubRepositoryRegexp.FindStringSubmatch(repositoryURL)
+	if matches == nil {
+		return "", "", "", errors.Errorf("invalid GitHub repository URL '%s'", repositoryURL)
+	}
+
+	host := matches[1]
+	if host == githubHost {
+		apiURL = githubAPIURL
+	} else {
+		apiURL = fmt.Sprintf("https://%s/api/v3", host)
+	}
+
+	return apiURL, matches[2], matches[3], nil
+}
+
+func githubRequest(httpClient *http.Client, method, url, token string, body, result interface{}) (http.Header, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, url, payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	request.Header.Set("Accept", "application/vnd.github.v3+json")
+	request.Header.Set("Authorization", "token "+token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, errors.Errorf("GitHub API %s %s returned status code %d", method, url, response.StatusCode)
+	}
+
+	if result != nil {
+		return response.Header, errors.WithStack(json.NewDecoder(response.Body).Decode(result))
+	}
+
+	return response.Header, nil
+}
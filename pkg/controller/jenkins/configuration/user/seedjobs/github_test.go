@@ -0,0 +1,186 @@
+package seedjobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+const (
+	testJenkinsURL    = "http://jenkins.example.com:8080"
+	testRepositoryURL = "https://github.example.com/oldsj/jenkins-operator.git"
+)
+
+// newGitHubServer returns GitHub API server with repository hooks split into pages of one hook, created hooks are
+// appended to the last page
+func newGitHubServer(t *testing.T, webhookURLs ...string) (server *httptest.Server, created *[]string, requests *int) {
+	created, requests = &[]string{}, new(int)
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.Equal(t, "token secret-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/v3/repos/oldsj/jenkins-operator/hooks", r.URL.Path)
+		if r.Method == http.MethodPost {
+			webhook := githubWebhook{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&webhook))
+			*created = append(*created, webhook.Config["url"])
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		page := 0
+		_, _ = fmt.Sscanf(r.URL.Query().Get("page"), "%d", &page)
+		var webhooks []githubWebhook
+		if page < len(webhookURLs) {
+			webhooks = append(webhooks, githubWebhook{ID: int64(page), Config: map[string]string{"url": webhookURLs[page]}})
+		}
+		if page+1 < len(webhookURLs) {
+			w.Header().Set("Link", fmt.Sprintf(`<https://%s%s?per_page=100&page=%d>; rel="next", <https://%s%s?page=%d>; rel="last"`,
+				r.Host, r.URL.Path, page+1, r.Host, r.URL.Path, len(webhookURLs)-1))
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(webhooks))
+	}))
+	return server, created, requests
+}
+
+// serverTransport sends requests for any host to the test server
+type serverTransport struct {
+	server *httptest.Server
+}
+
+func (t *serverTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	serverRequest := *request
+	serverURL := *request.URL
+	serverURL.Host = t.server.Listener.Addr().String()
+	serverRequest.URL = &serverURL
+	return t.server.Client().Transport.RoundTrip(&serverRequest)
+}
+
+func newGitHubClient(server *httptest.Server) *http.Client {
+	return &http.Client{Transport: &serverTransport{server: server}}
+}
+
+func TestRegisterGitHubWebhook(t *testing.T) {
+	webhookURL := testJenkinsURL + githubWebhookPath
+	t.Run("webhook on the last page", func(t *testing.T) {
+		server, created, requests := newGitHubServer(t, "https://ci.example.com/github-webhook/", "https://other.example.com/", webhookURL)
+		defer server.Close()
+
+		err := registerGitHubWebhook(newGitHubClient(server), testRepositoryURL, "secret-token", testJenkinsURL+"/")
+		assert.NoError(t, err)
+		assert.Empty(t, *created)
+		assert.Equal(t, 3, *requests)
+	})
+	t.Run("missing webhook", func(t *testing.T) {
+		server, created, requests := newGitHubServer(t, "https://ci.example.com/github-webhook/", "https://other.example.com/")
+		defer server.Close()
+
+		err := registerGitHubWebhook(newGitHubClient(server), testRepositoryURL, "secret-token", testJenkinsURL)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{webhookURL}, *created)
+		assert.Equal(t, 3, *requests)
+	})
+}
+
+func TestNextPageURL(t *testing.T) {
+	header := http.Header{}
+	assert.Empty(t, nextPageURL(header))
+
+	header.Set("Link", `<https://api.github.com/repositories/1/hooks?page=1>; rel="prev", <https://api.github.com/repositories/1/hooks?page=3>; rel="next", <https://api.github.com/repositories/1/hooks?page=5>; rel="last"`)
+	assert.Equal(t, "https://api.github.com/repositories/1/hooks?page=3", nextPageURL(header))
+
+	header.Set("Link", `<https://api.github.com/repositories/1/hooks?page=1>; rel="first", <https://api.github.com/repositories/1/hooks?page=4>; rel="prev"`)
+	assert.Empty(t, nextPageURL(header))
+}
+
+func TestEnsureGitHubWebhook(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	server, created, requests := newGitHubServer(t)
+	defer server.Close()
+
+	seedJob := v1alpha1.SeedJob{
+		ID:            "jenkins-operator",
+		RepositoryURL: testRepositoryURL,
+		ManageWebhook: true,
+		GitHubAPIToken: v1alpha1.GitHubAPIToken{
+			SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "github-token"}, Key: "token"},
+		},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Status: v1alpha1.JenkinsStatus{
+			URL:      testJenkinsURL,
+			SeedJobs: []v1alpha1.SeedJobStatus{{ID: seedJob.ID}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "github-token", Namespace: "default"},
+		Data:       map[string][]byte{"token": []byte("secret-token")},
+	}
+	fakeClient := fake.NewFakeClient(jenkins, secret)
+	events := &fakeRecorder{}
+	seedJobs := New(nil, fakeClient, logf.ZapLogger(false), events)
+	seedJobs.httpClient = newGitHubClient(server)
+
+	assert.NoError(t, seedJobs.ensureGitHubWebhook(jenkins, seedJob))
+	assert.Equal(t, []string{testJenkinsURL + githubWebhookPath}, *created)
+	assert.Equal(t, 2, *requests)
+	stored := &v1alpha1.Jenkins{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+	assert.NotEmpty(t, stored.Status.SeedJobs[0].GitHubWebhookChecksum)
+
+	// the registered webhook isn't looked up again
+	assert.NoError(t, seedJobs.ensureGitHubWebhook(jenkins, seedJob))
+	assert.Equal(t, 2, *requests)
+
+	// the webhook is registered again when Jenkins URL changes
+	jenkins.Status.URL = "http://jenkins.example.org:8080"
+	assert.NoError(t, seedJobs.ensureGitHubWebhook(jenkins, seedJob))
+	assert.Equal(t, []string{testJenkinsURL + githubWebhookPath, "http://jenkins.example.org:8080" + githubWebhookPath}, *created)
+	assert.Equal(t, 4, *requests)
+	assert.Empty(t, events.reasons)
+}
+
+func TestParseGitHubRepositoryURL(t *testing.T) {
+	data := []struct {
+		repositoryURL      string
+		expectedAPIURL     string
+		expectedOwner      string
+		expectedRepository string
+		expectedError      bool
+	}{
+		{"https://github.com/jenkinsci/kubernetes-operator.git", githubAPIURL, "jenkinsci", "kubernetes-operator", false},
+		{"https://github.com/jenkinsci/kubernetes-operator", githubAPIURL, "jenkinsci", "kubernetes-operator", false},
+		{"git@github.com:jenkinsci/kubernetes-operator.git", githubAPIURL, "jenkinsci", "kubernetes-operator", false},
+		{"ssh://git@github.com/jenkinsci/kubernetes-operator.git", githubAPIURL, "jenkinsci", "kubernetes-operator", false},
+		{"https://github.example.com/team/jobs.git", "https://github.example.com/api/v3", "team", "jobs", false},
+		{"https://github.com/jenkinsci", "", "", "", true},
+		{"file:///tmp/repository", "", "", "", true},
+	}
+
+	for _, testingData := range data {
+		t.Run(testingData.repositoryURL, func(t *testing.T) {
+			apiURL, owner, repository, err := parseGitHubRepositoryURL(testingData.repositoryURL)
+			if testingData.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testingData.expectedAPIURL, apiURL)
+			assert.Equal(t, testingData.expectedOwner, owner)
+			assert.Equal(t, testingData.expectedRepository, repository)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package seedjobs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+)
+
+// organizationScanScript prints true when the first scan of the organization folder has finished
+const organizationScanScript = `
+def organizationFolder = Jenkins.instance.getItem('%s')
+def computation = organizationFolder?.getComputation()
+print(computation != null && !computation.isBuilding() && computation.getResult() != null)
+`
+
+// isOrganizationScanned checks if the organization folder of the seed job has scanned the GitHub organization
+func (s *SeedJobs) isOrganizationScanned(seedJob v1alpha1.SeedJob) (bool, error) {
+	output, err := s.jenkinsClient.ExecuteScript(fmt.Sprintf(organizationScanScript, groovyStringReplacer.Replace(seedJob.ID)))
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(output) == "true", nil
+}
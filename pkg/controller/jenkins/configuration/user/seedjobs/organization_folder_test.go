@@ -0,0 +1,44 @@
+package seedjobs
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestIsOrganizationScanned(t *testing.T) {
+	data := []struct {
+		output          string
+		expectedScanned bool
+	}{
+		{output: "true", expectedScanned: true},
+		{output: "false", expectedScanned: false},
+	}
+
+	for _, testingData := range data {
+		t.Run(fmt.Sprintf("Testing '%s'", testingData.output), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			jenkinsClient := client.NewMockJenkins(ctrl)
+			jenkinsClient.
+				EXPECT().
+				ExecuteScript(gomock.Any()).
+				DoAndReturn(func(script string) (string, error) {
+					assert.Contains(t, script, `getItem('jenkins-operator')`)
+					return testingData.output, nil
+				})
+
+			seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), &fakeRecorder{})
+			scanned, err := seedJobs.isOrganizationScanned(v1alpha1.SeedJob{ID: "jenkins-operator", Mode: v1alpha1.SeedJobModeOrganizationFolder})
+
+			assert.NoError(t, err)
+			assert.Equal(t, testingData.expectedScanned, scanned)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package seedjobs
+
+import (
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+)
+
+// isReRunDue returns true when the re-run interval of the seed job elapsed since its last successful build,
+// the seed job is re-run only when the build of its current configuration succeeded
+func isReRunDue(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob, hash string, now time.Time) bool {
+	if seedJob.ReRunInterval == nil || seedJob.ReRunInterval.Duration <= 0 {
+		return false
+	}
+	build := buildFromStatus(jenkins, hash)
+	if build == nil || build.Status != v1alpha1.BuildSuccessStatus {
+		return false
+	}
+	next, ok := nextReRun(jenkins, seedJob)
+	return ok && !now.Before(next)
+}
+
+// nextReRun returns the time the seed job is due to be re-run
+func nextReRun(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) (time.Time, bool) {
+	if seedJob.ReRunInterval == nil || seedJob.ReRunInterval.Duration <= 0 {
+		return time.Time{}, false
+	}
+	index := seedJobStatusIndex(jenkins.Status.SeedJobs, seedJob.ID)
+	if index < 0 || jenkins.Status.SeedJobs[index].LastSuccessfulBuildTime == nil {
+		return time.Time{}, false
+	}
+	return jenkins.Status.SeedJobs[index].LastSuccessfulBuildTime.Add(seedJob.ReRunInterval.Duration), true
+}
+
+// ReRunAfter returns the duration after which the first seed job is due to be re-run,
+// zero means no seed job is re-run periodically
+func ReRunAfter(jenkins *v1alpha1.Jenkins, now time.Time) time.Duration {
+	var after time.Duration
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		next, ok := nextReRun(jenkins, seedJob)
+		if !ok {
+			continue
+		}
+		due := next.Sub(now)
+		if due <= 0 {
+			// the re-run is overdue, it's triggered in the next reconciliation loop
+			due = time.Second
+		}
+		if after == 0 || due < after {
+			after = due
+		}
+	}
+	return after
+}
+
+// buildFromStatus returns the seed job build with the given hash from Jenkins.Status.Builds section
+func buildFromStatus(jenkins *v1alpha1.Jenkins, hash string) *v1alpha1.Build {
+	for i, build := range jenkins.Status.Builds {
+		if build.JobName == configureSeedJobsName(jenkins) && build.Hash == hash {
+			return &jenkins.Status.Builds[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package seedjobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+
+	"github.com/bndr/gojenkins"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestIsReRunDue(t *testing.T) {
+	now := time.Now()
+	lastSuccessfulBuildTime := metav1.NewTime(now.Add(-2 * time.Hour))
+	seedJob := v1alpha1.SeedJob{ID: "jenkins-operator-e2e", ReRunInterval: &metav1.Duration{Duration: time.Hour}}
+	jenkins := &v1alpha1.Jenkins{
+		Spec: v1alpha1.JenkinsSpec{SeedJobs: []v1alpha1.SeedJob{seedJob}},
+		Status: v1alpha1.JenkinsStatus{
+			Builds: []v1alpha1.Build{
+				{JobName: constants.ConfigureSeedJobsName, Hash: "hash", Status: v1alpha1.BuildSuccessStatus},
+			},
+			SeedJobs: []v1alpha1.SeedJobStatus{
+				{ID: "jenkins-operator-e2e", LastSuccessfulBuildTime: &lastSuccessfulBuildTime},
+			},
+		},
+	}
+
+	assert.True(t, isReRunDue(jenkins, seedJob, "hash", now))
+	assert.False(t, isReRunDue(jenkins, seedJob, "changed", now), "configuration changed, the build isn't finished")
+	assert.False(t, isReRunDue(jenkins, seedJob, "hash", now.Add(-90*time.Minute)))
+	assert.False(t, isReRunDue(jenkins, v1alpha1.SeedJob{ID: "jenkins-operator-e2e"}, "hash", now))
+
+	jenkins.Status.Builds[0].Status = v1alpha1.BuildRunningStatus
+	assert.False(t, isReRunDue(jenkins, seedJob, "hash", now))
+}
+
+func TestReRunAfter(t *testing.T) {
+	now := time.Now()
+	lastSuccessfulBuildTime := metav1.NewTime(now.Add(-30 * time.Minute))
+	jenkins := &v1alpha1.Jenkins{
+		Spec: v1alpha1.JenkinsSpec{
+			SeedJobs: []v1alpha1.SeedJob{
+				{ID: "first", ReRunInterval: &metav1.Duration{Duration: time.Hour}},
+				{ID: "second", ReRunInterval: &metav1.Duration{Duration: 2 * time.Hour}},
+				{ID: "third"},
+			},
+		},
+		Status: v1alpha1.JenkinsStatus{
+			SeedJobs: []v1alpha1.SeedJobStatus{
+				{ID: "first", LastSuccessfulBuildTime: &lastSuccessfulBuildTime},
+				{ID: "second", LastSuccessfulBuildTime: &lastSuccessfulBuildTime},
+				{ID: "third", LastSuccessfulBuildTime: &lastSuccessfulBuildTime},
+			},
+		},
+	}
+
+	assert.Equal(t, 30*time.Minute, ReRunAfter(jenkins, now))
+	assert.Equal(t, time.Second, ReRunAfter(jenkins, now.Add(time.Hour)), "overdue re-run")
+
+	jenkins.Spec.SeedJobs = jenkins.Spec.SeedJobs[2:]
+	assert.Equal(t, time.Duration(0), ReRunAfter(jenkins, now))
+}
+
+func TestEnsureSeedJobsReRun(t *testing.T) {
+	// given
+	logger := logf.ZapLogger(false)
+	events := &fakeRecorder{}
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	jenkins := jenkinsCustomResource()
+	jenkins.Spec.SeedJobs[0].ReRunInterval = &metav1.Duration{Duration: time.Hour}
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+
+	// first run - should create job and schedule build
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, true, nil)
+	jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: 1}}, nil)
+	jenkinsClient.EXPECT().BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).Return(int64(0), nil)
+
+	_, err = New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+
+	// second run - should finish the build and record the successful build time
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+	jenkinsClient.EXPECT().GetBuild(constants.ConfigureSeedJobsName, int64(1)).
+		Return(&gojenkins.Build{Raw: &gojenkins.BuildResponse{Result: string(v1alpha1.BuildSuccessStatus)}}, nil)
+	jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e-"+constants.SeedJobSuffix).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{
+			DisplayName: "Seed Job from jenkins-operator-e2e",
+			Description: "Jenkins Operator e2e tests repository",
+		}}, nil)
+
+	done, err := New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	assert.NotNil(t, jenkins.Status.SeedJobs[0].LastSuccessfulBuildTime)
+	assert.NotContains(t, events.reasons, reasonReRun)
+
+	// the interval elapsed
+	lastSuccessfulBuildTime := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	jenkins.Status.SeedJobs[0].LastSuccessfulBuildTime = &lastSuccessfulBuildTime
+	err = fakeClient.Update(ctx, jenkins)
+	assert.NoError(t, err)
+
+	// third run - should build the seed job again
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+	jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: 2}}, nil)
+	jenkinsClient.EXPECT().BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).Return(int64(0), nil)
+
+	done, err = New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jenkins.Status.Builds))
+	assert.Equal(t, int64(2), jenkins.Status.Builds[0].Number)
+	assert.Equal(t, v1alpha1.BuildRunningStatus, jenkins.Status.Builds[0].Status)
+	assert.Equal(t, int64(2), jenkins.Status.SeedJobs[0].LastBuildNumber)
+	assert.Equal(t, lastSuccessfulBuildTime.Unix(), jenkins.Status.SeedJobs[0].LastSuccessfulBuildTime.Unix())
+	assert.Contains(t, events.reasons, reasonReRun)
+}
@@ -0,0 +1,64 @@
+package seedjobs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+)
+
+// scriptNotApprovedMessage is printed by Job DSL when the script waits in script approval
+const scriptNotApprovedMessage = "script not yet approved for use"
+
+// approvePendingScriptsScript approves scripts of the job pending in script approval and prints their hashes
+const approvePendingScriptsScript = `
+import org.jenkinsci.plugins.scriptsecurity.scripts.ScriptApproval
+
+def jobName = '%s'
+def scriptApproval = ScriptApproval.get()
+scriptApproval.getPendingScripts()
+        .findAll { it.getContext().getItem()?.getFullName() == jobName }
+        .each {
+            scriptApproval.approveScript(it.getHash())
+            println(it.getHash())
+        }
+`
+
+// ensureScriptsApproved approves Job DSL scripts pending in script approval and rebuilds the seed job
+// when its last build failed because of them, the approvals are reported as events
+func (s *SeedJobs) ensureScriptsApproved(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) error {
+	name := seedJobName(seedJob)
+	job, err := s.jenkinsClient.GetJob(name)
+	if err != nil {
+		return err
+	}
+
+	lastCompletedBuild := job.Raw.LastCompletedBuild.Number
+	if lastCompletedBuild == 0 || job.Raw.LastBuild.Number != lastCompletedBuild || job.Raw.LastFailedBuild.Number != lastCompletedBuild {
+		return nil
+	}
+	consoleOutput, err := s.jenkinsClient.GetBuildConsoleOutput(name, lastCompletedBuild)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(consoleOutput, scriptNotApprovedMessage) {
+		return nil
+	}
+
+	output, err := s.jenkinsClient.ExecuteScript(fmt.Sprintf(approvePendingScriptsScript, groovyStringReplacer.Replace(name)))
+	if err != nil {
+		return err
+	}
+	hashes := strings.Fields(output)
+	if len(hashes) == 0 {
+		// nothing to approve, the build is not retried to avoid an endless loop
+		return nil
+	}
+
+	s.logger.Info(fmt.Sprintf("Approved scripts of seed job '%s': %s", seedJob.ID, strings.Join(hashes, ", ")))
+	s.events.Emitf(jenkins, event.TypeNormal, reasonScriptsApproved, "Approved scripts of seed job '%s': %s", seedJob.ID, strings.Join(hashes, ", "))
+
+	_, err = s.jenkinsClient.BuildJob(name)
+	return err
+}
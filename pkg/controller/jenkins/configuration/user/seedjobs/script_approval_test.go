@@ -0,0 +1,86 @@
+package seedjobs
+
+import (
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/bndr/gojenkins"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureScriptsApproved(t *testing.T) {
+	seedJob := v1alpha1.SeedJob{ID: "jenkins-operator", ApproveScripts: true}
+	jobName := "jenkins-operator-" + constants.SeedJobSuffix
+	failedJob := &gojenkins.Job{Raw: &gojenkins.JobResponse{
+		LastBuild:          gojenkins.JobBuild{Number: 2},
+		LastCompletedBuild: gojenkins.JobBuild{Number: 2},
+		LastFailedBuild:    gojenkins.JobBuild{Number: 2},
+	}}
+
+	t.Run("approves pending scripts and rebuilds the seed job", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		events := &fakeRecorder{}
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob(jobName).Return(failedJob, nil)
+		jenkinsClient.EXPECT().GetBuildConsoleOutput(jobName, int64(2)).
+			Return("ERROR: script not yet approved for use\nFinished: FAILURE\n", nil)
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).
+			DoAndReturn(func(script string) (string, error) {
+				assert.Contains(t, script, "def jobName = '"+jobName+"'")
+				return "SHA1:2f3a\n", nil
+			})
+		jenkinsClient.EXPECT().BuildJob(jobName).Return(int64(0), nil)
+
+		seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), events)
+		err := seedJobs.ensureScriptsApproved(&v1alpha1.Jenkins{}, seedJob)
+		assert.NoError(t, err)
+		assert.Equal(t, []event.Reason{reasonScriptsApproved}, events.reasons)
+	})
+	t.Run("nothing pending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		events := &fakeRecorder{}
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob(jobName).Return(failedJob, nil)
+		jenkinsClient.EXPECT().GetBuildConsoleOutput(jobName, int64(2)).
+			Return("ERROR: script not yet approved for use\nFinished: FAILURE\n", nil)
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("", nil)
+
+		seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), events)
+		err := seedJobs.ensureScriptsApproved(&v1alpha1.Jenkins{}, seedJob)
+		assert.NoError(t, err)
+		assert.Empty(t, events.reasons)
+	})
+	t.Run("build failed because of another reason", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob(jobName).Return(failedJob, nil)
+		jenkinsClient.EXPECT().GetBuildConsoleOutput(jobName, int64(2)).Return("ERROR: no Job DSL files found\n", nil)
+
+		seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), &fakeRecorder{})
+		err := seedJobs.ensureScriptsApproved(&v1alpha1.Jenkins{}, seedJob)
+		assert.NoError(t, err)
+	})
+	t.Run("last build succeeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob(jobName).Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{
+			LastBuild:          gojenkins.JobBuild{Number: 3},
+			LastCompletedBuild: gojenkins.JobBuild{Number: 3},
+			LastFailedBuild:    gojenkins.JobBuild{Number: 2},
+		}}, nil)
+
+		seedJobs := New(jenkinsClient, nil, logf.ZapLogger(false), &fakeRecorder{})
+		err := seedJobs.ensureScriptsApproved(&v1alpha1.Jenkins{}, seedJob)
+		assert.NoError(t, err)
+	})
+}
@@ -5,11 +5,21 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	"github.com/go-logr/logr"
@@ -19,114 +29,723 @@ import (
 )
 
 const (
-	// ConfigureSeedJobsName this is the fixed seed job name
-	ConfigureSeedJobsName = constants.OperatorName + "-configure-seed-job"
-
-	deployKeyIDParameterName      = "DEPLOY_KEY_ID"
-	privateKeyParameterName       = "PRIVATE_KEY"
-	repositoryURLParameterName    = "REPOSITORY_URL"
-	repositoryBranchParameterName = "REPOSITORY_BRANCH"
-	targetsParameterName          = "TARGETS"
-	displayNameParameterName      = "SEED_JOB_DISPLAY_NAME"
+	deployKeyIDParameterName          = "DEPLOY_KEY_ID"
+	privateKeyParameterName           = "PRIVATE_KEY"
+	passphraseParameterName           = "PASSPHRASE"
+	usernameParameterName             = "USERNAME"
+	passwordParameterName             = "PASSWORD"
+	tokenCredentialParameterName      = "TOKEN_CREDENTIAL"
+	repositoryURLParameterName        = "REPOSITORY_URL"
+	repositoryBranchParameterName     = "REPOSITORY_BRANCH"
+	pinnedRevisionParameterName       = "PINNED_REVISION"
+	targetsParameterName              = "TARGETS"
+	displayNameParameterName          = "SEED_JOB_DISPLAY_NAME"
+	descriptionParameterName          = "SEED_JOB_DESCRIPTION"
+	pollSCMParameterName              = "POLL_SCM"
+	buildPeriodicallyParameterName    = "BUILD_PERIODICALLY"
+	githubPushTriggerParameterName    = "GITHUB_PUSH_TRIGGER"
+	bitbucketPushTriggerParameterName = "BITBUCKET_PUSH_TRIGGER"
+	gerritServerParameterName         = "GERRIT_SERVER"
+	gerritProjectParameterName        = "GERRIT_PROJECT"
+	gerritBranchPatternParameterName  = "GERRIT_BRANCH_PATTERN"
+	agentLabelParameterName           = "AGENT_LABEL"
+	additionalClasspathParameterName  = "ADDITIONAL_CLASSPATH"
+	ignoreMissingFilesParameterName   = "IGNORE_MISSING_FILES"
+	failOnMissingPluginParameterName  = "FAIL_ON_MISSING_PLUGIN"
+	sandboxParameterName              = "SANDBOX"
+	scriptSecurityParameterName       = "JOB_DSL_SCRIPT_SECURITY"
+	removedJobActionParameterName     = "REMOVED_JOB_ACTION"
+	removedViewActionParameterName    = "REMOVED_VIEW_ACTION"
+	knownHostsParameterName           = "KNOWN_HOSTS"
+	insecureSSHHostParameterName      = "INSECURE_SSH_HOST"
+	shallowCloneParameterName         = "SHALLOW_CLONE"
+	cloneDepthParameterName           = "CLONE_DEPTH"
+	referenceRepositoryParameterName  = "REFERENCE_REPOSITORY"
+	submodulesParameterName           = "SUBMODULES"
+	recursiveSubmodulesParameterName  = "RECURSIVE_SUBMODULES"
+	parentCredentialsParameterName    = "SUBMODULES_PARENT_CREDENTIALS"
+	seedJobModeParameterName          = "SEED_JOB_MODE"
+	jenkinsfilePathParameterName      = "JENKINSFILE_PATH"
+	includeBranchesParameterName      = "INCLUDE_BRANCHES"
+	excludeBranchesParameterName      = "EXCLUDE_BRANCHES"
+	organizationParameterName         = "ORGANIZATION"
+	includeRepositoriesParameterName  = "INCLUDE_REPOSITORIES"
+	excludeRepositoriesParameterName  = "EXCLUDE_REPOSITORIES"
+	scanIntervalParameterName         = "SCAN_INTERVAL"
+	buildRetentionParameterName       = "BUILD_RETENTION"
+
+	// reasonGitHubWebhookFailure is the event which informs the GitHub webhook couldn't be registered
+	reasonGitHubWebhookFailure event.Reason = "GitHubWebhookFailure"
+	// reasonBuildTimeout is the event which informs the seed job build exceeded timeout and has been aborted
+	reasonBuildTimeout event.Reason = "SeedJobBuildTimeout"
+	// reasonGerritServerNotConfigured is the event which informs the Gerrit server used by the seed job isn't configured in Jenkins
+	reasonGerritServerNotConfigured event.Reason = "GerritServerNotConfigured"
+
+	// reasonSandboxDisabled is the event which informs the seed job runs Job DSL scripts with full privileges
+	reasonSandboxDisabled event.Reason = "SeedJobSandboxDisabled"
+	// reasonScriptsApproved is the event which informs the operator approved Job DSL scripts of the seed job
+	reasonScriptsApproved event.Reason = "SeedJobScriptsApproved"
+	// reasonReRun is the event which informs the seed job is built again because its reRunInterval elapsed
+	reasonReRun event.Reason = "SeedJobReRun"
+	// reasonBuildRetry is the event which informs the seed job build failed and will be retried
+	reasonBuildRetry event.Reason = "SeedJobBuildRetry"
+	// reasonBuildFailure is the event which informs the seed job build failed and won't be retried
+	reasonBuildFailure event.Reason = "SeedJobBuildFailure"
 )
 
+// scpRepositoryURLRegexp matches scp-like ssh repository urls e.g. git@github.com:org/repo.git and captures the host
+var scpRepositoryURLRegexp = regexp.MustCompile(`^[\w.-]+@([\w.-]+):`)
+
+// commitSHARegexp matches full commit SHAs, other pinned revisions are tags
+var commitSHARegexp = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// tagRefPrefix is the prefix of git tag refs
+const tagRefPrefix = "refs/tags/"
+
 // SeedJobs defines API for configuring and ensuring Jenkins Seed Jobs and Deploy Keys
 type SeedJobs struct {
 	jenkinsClient jenkinsclient.Jenkins
 	k8sClient     k8s.Client
 	logger        logr.Logger
+	events        event.Recorder
+	httpClient    *http.Client
 }
 
 // New creates SeedJobs object
-func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger) *SeedJobs {
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *SeedJobs {
 	return &SeedJobs{
 		jenkinsClient: jenkinsClient,
 		k8sClient:     k8sClient,
 		logger:        logger,
+		events:        events,
+		httpClient:    &http.Client{Timeout: githubAPITimeout},
 	}
 }
 
 // EnsureSeedJobs configures seed job and runs it for every entry from Jenkins.Spec.SeedJobs
 func (s *SeedJobs) EnsureSeedJobs(jenkins *v1alpha1.Jenkins) (done bool, err error) {
-	err = s.createJob()
+	err = s.createJob(jenkins)
 	if err != nil {
 		s.logger.V(log.VWarn).Info("Couldn't create jenkins seed job")
 		return false, err
 	}
+	err = s.trackSeedJobs(jenkins)
+	if err != nil {
+		s.logger.V(log.VWarn).Info("Couldn't save seed jobs in status")
+		return false, err
+	}
+	deleted, err := s.deleteRemovedSeedJobs(jenkins)
+	if err != nil {
+		s.logger.V(log.VWarn).Info("Couldn't delete removed jenkins seed job")
+		return false, err
+	}
 	done, err = s.buildJobs(jenkins)
 	if err != nil {
 		s.logger.V(log.VWarn).Info("Couldn't build jenkins seed job")
 		return false, err
 	}
-	return done, nil
+	return done && deleted, nil
 }
 
 // createJob is responsible for creating jenkins job which configures jenkins seed jobs and deploy keys
-func (s *SeedJobs) createJob() error {
-	_, created, err := s.jenkinsClient.CreateOrUpdateJob(seedJobConfigXML, ConfigureSeedJobsName)
+func (s *SeedJobs) createJob(jenkins *v1alpha1.Jenkins) error {
+	jobName := configureSeedJobsName(jenkins)
+	_, created, err := s.jenkinsClient.CreateOrUpdateJob(seedJobConfigXML, jobName)
 	if err != nil {
 		return err
 	}
 	if created {
-		s.logger.Info(fmt.Sprintf("'%s' job has been created", ConfigureSeedJobsName))
+		s.logger.Info(fmt.Sprintf("'%s' job has been created", jobName))
 	}
 	return nil
 }
 
-// buildJobs is responsible for running jenkins builds which configures jenkins seed jobs and deploy keys
+// configureSeedJobsName returns the name of the job which configures seed jobs of Jenkins CR
+func configureSeedJobsName(jenkins *v1alpha1.Jenkins) string {
+	return resources.GetJobName(jenkins, constants.ConfigureSeedJobsName)
+}
+
+// buildJobs is responsible for running jenkins builds which configures jenkins seed jobs and deploy keys,
+// builds of all seed jobs are triggered and tracked independently, so a failed build doesn't hold back the other ones
 func (s *SeedJobs) buildJobs(jenkins *v1alpha1.Jenkins) (done bool, err error) {
 	allDone := true
+	failed := false
 	seedJobs := jenkins.Spec.SeedJobs
 	for _, seedJob := range seedJobs {
-		privateKey, err := s.privateKeyFromSecret(jenkins.Namespace, seedJob)
+		privateKey, err := s.privateKeyFromSecret(jenkins, seedJob)
+		if err != nil {
+			return false, err
+		}
+		passphrase, err := s.valueFromSecret(jenkins, seedJob.PrivateKey.PassphraseSecretKeyRef)
 		if err != nil {
 			return false, err
 		}
+		knownHosts, err := s.knownHosts(jenkins, seedJob)
+		if err != nil {
+			return false, err
+		}
+		username, password, err := s.usernamePassword(jenkins, seedJob)
+		if err != nil {
+			return false, err
+		}
+		gerritServer, gerritProject, gerritBranchPattern := gerritTrigger(seedJob)
+		pollSCM, githubPushTrigger, bitbucketPushTrigger := scmTriggers(seedJob)
 		parameters := map[string]string{
-			deployKeyIDParameterName:      seedJob.ID,
-			privateKeyParameterName:       privateKey,
-			repositoryURLParameterName:    seedJob.RepositoryURL,
-			repositoryBranchParameterName: seedJob.RepositoryBranch,
-			targetsParameterName:          seedJob.Targets,
-			displayNameParameterName:      fmt.Sprintf("Seed Job from %s", seedJob.ID),
+			deployKeyIDParameterName:          seedJob.ID,
+			privateKeyParameterName:           privateKey,
+			passphraseParameterName:           passphrase,
+			usernameParameterName:             username,
+			passwordParameterName:             password,
+			tokenCredentialParameterName:      boolParameter(seedJob.Token.SecretKeyRef != nil),
+			repositoryURLParameterName:        seedJob.RepositoryURL,
+			repositoryBranchParameterName:     repositoryBranch(seedJob),
+			pinnedRevisionParameterName:       pinnedRevision(seedJob),
+			targetsParameterName:              seedJob.Targets,
+			displayNameParameterName:          displayName(seedJob),
+			descriptionParameterName:          seedJob.Description,
+			pollSCMParameterName:              pollSCM,
+			buildPeriodicallyParameterName:    seedJob.BuildPeriodically,
+			githubPushTriggerParameterName:    boolParameter(githubPushTrigger),
+			bitbucketPushTriggerParameterName: boolParameter(bitbucketPushTrigger),
+			gerritServerParameterName:         gerritServer,
+			gerritProjectParameterName:        gerritProject,
+			gerritBranchPatternParameterName:  gerritBranchPattern,
+			agentLabelParameterName:           agentLabel(jenkins, seedJob),
+			additionalClasspathParameterName:  seedJob.AdditionalClasspath,
+			ignoreMissingFilesParameterName:   boolParameter(seedJob.JobDSLSettings.IgnoreMissingFiles),
+			failOnMissingPluginParameterName:  boolParameter(seedJob.JobDSLSettings.FailOnMissingPlugin),
+			sandboxParameterName:              boolParameter(seedJob.Sandbox),
+			scriptSecurityParameterName:       boolParameter(scriptSecurity(jenkins)),
+			removedJobActionParameterName:     seedJob.JobDSLSettings.RemovedJobAction,
+			removedViewActionParameterName:    seedJob.JobDSLSettings.RemovedViewAction,
+			knownHostsParameterName:           knownHosts,
+			insecureSSHHostParameterName:      insecureSSHHost(seedJob),
+			shallowCloneParameterName:         shallowClone(seedJob),
+			cloneDepthParameterName:           cloneDepth(seedJob),
+			referenceRepositoryParameterName:  seedJob.ReferenceRepository,
+			submodulesParameterName:           boolParameter(seedJob.Submodules.Enabled),
+			recursiveSubmodulesParameterName:  boolParameter(seedJob.Submodules.Recursive),
+			parentCredentialsParameterName:    boolParameter(seedJob.Submodules.ParentCredentials),
+			seedJobModeParameterName:          string(seedJob.Mode),
+			jenkinsfilePathParameterName:      seedJob.MultibranchSettings.JenkinsfilePath,
+			includeBranchesParameterName:      seedJob.MultibranchSettings.IncludeBranches,
+			excludeBranchesParameterName:      seedJob.MultibranchSettings.ExcludeBranches,
+			organizationParameterName:         seedJob.OrganizationFolderSettings.Organization,
+			includeRepositoriesParameterName:  seedJob.OrganizationFolderSettings.IncludeRepositories,
+			excludeRepositoriesParameterName:  seedJob.OrganizationFolderSettings.ExcludeRepositories,
+			scanIntervalParameterName:         seedJob.OrganizationFolderSettings.ScanInterval,
+			buildRetentionParameterName:       strconv.Itoa(jobs.BuildRetention(seedJob.BuildRetention)),
 		}
 
 		hash := sha256.New()
 		hash.Write([]byte(parameters[deployKeyIDParameterName]))
 		hash.Write([]byte(parameters[privateKeyParameterName]))
+		hash.Write([]byte(parameters[passphraseParameterName]))
+		hash.Write([]byte(parameters[usernameParameterName]))
+		hash.Write([]byte(parameters[passwordParameterName]))
+		hash.Write([]byte(parameters[tokenCredentialParameterName]))
 		hash.Write([]byte(parameters[repositoryURLParameterName]))
 		hash.Write([]byte(parameters[repositoryBranchParameterName]))
+		hash.Write([]byte(parameters[pinnedRevisionParameterName]))
 		hash.Write([]byte(parameters[targetsParameterName]))
 		hash.Write([]byte(parameters[displayNameParameterName]))
+		hash.Write([]byte(parameters[descriptionParameterName]))
+		hash.Write([]byte(parameters[pollSCMParameterName]))
+		hash.Write([]byte(parameters[buildPeriodicallyParameterName]))
+		hash.Write([]byte(parameters[githubPushTriggerParameterName]))
+		hash.Write([]byte(parameters[bitbucketPushTriggerParameterName]))
+		hash.Write([]byte(parameters[gerritServerParameterName]))
+		hash.Write([]byte(parameters[gerritProjectParameterName]))
+		hash.Write([]byte(parameters[gerritBranchPatternParameterName]))
+		hash.Write([]byte(parameters[agentLabelParameterName]))
+		hash.Write([]byte(parameters[additionalClasspathParameterName]))
+		hash.Write([]byte(parameters[ignoreMissingFilesParameterName]))
+		hash.Write([]byte(parameters[failOnMissingPluginParameterName]))
+		hash.Write([]byte(parameters[sandboxParameterName]))
+		hash.Write([]byte(parameters[scriptSecurityParameterName]))
+		hash.Write([]byte(parameters[removedJobActionParameterName]))
+		hash.Write([]byte(parameters[removedViewActionParameterName]))
+		hash.Write([]byte(parameters[knownHostsParameterName]))
+		hash.Write([]byte(parameters[insecureSSHHostParameterName]))
+		hash.Write([]byte(parameters[shallowCloneParameterName]))
+		hash.Write([]byte(parameters[cloneDepthParameterName]))
+		hash.Write([]byte(parameters[referenceRepositoryParameterName]))
+		hash.Write([]byte(parameters[submodulesParameterName]))
+		hash.Write([]byte(parameters[recursiveSubmodulesParameterName]))
+		hash.Write([]byte(parameters[parentCredentialsParameterName]))
+		hash.Write([]byte(parameters[seedJobModeParameterName]))
+		hash.Write([]byte(parameters[jenkinsfilePathParameterName]))
+		hash.Write([]byte(parameters[includeBranchesParameterName]))
+		hash.Write([]byte(parameters[excludeBranchesParameterName]))
+		hash.Write([]byte(parameters[organizationParameterName]))
+		hash.Write([]byte(parameters[includeRepositoriesParameterName]))
+		hash.Write([]byte(parameters[excludeRepositoriesParameterName]))
+		hash.Write([]byte(parameters[scanIntervalParameterName]))
+		hash.Write([]byte(parameters[buildRetentionParameterName]))
+		// a change of the retry policy resets the retries counter
+		if seedJob.Retries != nil {
+			hash.Write([]byte(strconv.Itoa(*seedJob.Retries)))
+		}
+		if seedJob.RetryBackoff != nil {
+			hash.Write([]byte(seedJob.RetryBackoff.Duration.String()))
+		}
 		encodedHash := base64.URLEncoding.EncodeToString(hash.Sum(nil))
 
-		jobsClient := jobs.New(s.jenkinsClient, s.k8sClient, s.logger)
-		done, err := jobsClient.EnsureBuildJob(ConfigureSeedJobsName, encodedHash, parameters, jenkins, true)
+		if len(gerritServer) > 0 && !isBuildScheduled(jenkins, encodedHash) {
+			s.ensureGerritServer(jenkins, seedJob)
+		}
+		if isJobDSL(seedJob) && !seedJob.Sandbox && !isBuildScheduled(jenkins, encodedHash) {
+			s.events.Emitf(jenkins, event.TypeWarning, reasonSandboxDisabled,
+				"Seed job '%s' runs Job DSL scripts outside the sandbox with full privileges", seedJob.ID)
+		}
+
+		jobsClient := jobs.New(s.jenkinsClient, s.k8sClient, s.logger, s.events)
+		options := buildOptions(seedJob)
+		var done bool
+		if isReRunDue(jenkins, seedJob, encodedHash, time.Now()) {
+			s.logger.Info(fmt.Sprintf("Re-running seed job '%s', reRunInterval %s elapsed", seedJob.ID, seedJob.ReRunInterval.Duration))
+			s.events.Emitf(jenkins, event.TypeNormal, reasonReRun,
+				"Seed job '%s' is re-run, reRunInterval %s elapsed since the last successful build", seedJob.ID, seedJob.ReRunInterval.Duration)
+			done, err = jobsClient.RerunBuildJob(configureSeedJobsName(jenkins), encodedHash, parameters, jenkins)
+		} else {
+			done, err = jobsClient.EnsureBuildJob(configureSeedJobsName(jenkins), encodedHash, parameters, jenkins, true, options)
+		}
+		if err == jobs.ErrorBuildTimeout {
+			s.events.Emitf(jenkins, event.TypeWarning, reasonBuildTimeout,
+				"Seed job '%s' build exceeded timeout %s and has been aborted", seedJob.ID, options.Timeout)
+		}
+		if statusErr := s.updateSeedJobStatus(jenkins, seedJob, encodedHash, err); statusErr != nil {
+			s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't update status of seed job '%s'", seedJob.ID))
+			return false, statusErr
+		}
+		if err == jobs.ErrorBuildFailed || err == jobs.ErrorBuildTimeout {
+			// the build is retried in the next reconciliation loop, aborted build is retried like the failed one
+			failed = true
+			continue
+		}
+		if err == jobs.ErrorUnrecoverableBuildFailed {
+			// the failure is reported in status and events, it doesn't block other seed jobs and user configuration
+			continue
+		}
 		if err != nil {
 			return false, err
 		}
 		if !done {
 			allDone = false
+			continue
 		}
+
+		if seedJob.ManageWebhook {
+			if err := s.ensureGitHubWebhook(jenkins, seedJob); err != nil {
+				s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't update GitHub webhook status of seed job '%s'", seedJob.ID))
+				return false, err
+			}
+		}
+		if err := s.ensureDisplayNameAndDescription(seedJob); err != nil {
+			s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't ensure display name and description of seed job '%s'", seedJob.ID))
+			return false, err
+		}
+		if seedJob.ApproveScripts && isJobDSL(seedJob) {
+			if err := s.ensureScriptsApproved(jenkins, seedJob); err != nil {
+				s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't approve scripts of seed job '%s'", seedJob.ID))
+				return false, err
+			}
+		}
+		if seedJob.Mode == v1alpha1.SeedJobModeMultibranch {
+			if err := s.updateDiscoveredBranches(jenkins, seedJob); err != nil {
+				s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't update discovered branches of seed job '%s'", seedJob.ID))
+				return false, err
+			}
+		}
+		if seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+			scanned, err := s.isOrganizationScanned(seedJob)
+			if err != nil {
+				s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't check organization scan of seed job '%s'", seedJob.ID))
+				return false, err
+			}
+			if !scanned {
+				allDone = false
+			}
+		}
+	}
+	if failed {
+		return false, jobs.ErrorBuildFailed
 	}
 	return allDone, nil
 }
 
+// updateSeedJobStatus copies state of the build which configures the seed job to Jenkins.Status.SeedJobs section
+func (s *SeedJobs) updateSeedJobStatus(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob, hash string, buildErr error) error {
+	id := seedJob.ID
+	index := seedJobStatusIndex(jenkins.Status.SeedJobs, id)
+	if index < 0 {
+		return nil
+	}
+
+	var build *v1alpha1.Build
+	for i, existingBuild := range jenkins.Status.Builds {
+		if existingBuild.JobName == configureSeedJobsName(jenkins) && existingBuild.Hash == hash {
+			build = &jenkins.Status.Builds[i]
+		}
+	}
+	if build == nil {
+		return nil
+	}
+
+	options := buildOptions(seedJob)
+	seedJobStatus := jenkins.Status.SeedJobs[index]
+	seedJobStatus.LastBuildNumber = build.Number
+	seedJobStatus.LastBuildURL = build.URL
+	seedJobStatus.LastBuildResult = strings.ToUpper(string(build.Status))
+	seedJobStatus.LastBuildTime = build.LastUpdateTime
+	seedJobStatus.FailureReason = ""
+	if build.Status == v1alpha1.BuildSuccessStatus {
+		seedJobStatus.LastSuccessfulBuildTime = build.LastUpdateTime
+	}
+	switch {
+	case buildErr == jobs.ErrorUnrecoverableBuildFailed:
+		seedJobStatus.FailureReason = fmt.Sprintf("build #%d failed and the retries limit of %d was reached, correct the seed job and update Jenkins CR",
+			build.Number, options.MaxRetries())
+	case buildErr == jobs.ErrorBuildTimeout:
+		seedJobStatus.FailureReason = fmt.Sprintf("build #%d exceeded timeout %s and has been aborted", build.Number, options.Timeout)
+	case buildErr == jobs.ErrorBuildFailed:
+		seedJobStatus.FailureReason = fmt.Sprintf("build #%d failed", build.Number)
+	case build.Retires > 0:
+		seedJobStatus.FailureReason = fmt.Sprintf("previous build failed, retry %d of %d", build.Retires, options.MaxRetries())
+	}
+
+	if reflect.DeepEqual(seedJobStatus, jenkins.Status.SeedJobs[index]) {
+		return nil
+	}
+	if buildErr == jobs.ErrorUnrecoverableBuildFailed {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Seed job '%s': %s", id, seedJobStatus.FailureReason))
+		s.events.Emitf(jenkins, event.TypeWarning, reasonBuildFailure, "Seed job '%s': %s", id, seedJobStatus.FailureReason)
+	}
+	if buildErr == jobs.ErrorBuildFailed {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Seed job '%s': %s", id, seedJobStatus.FailureReason))
+		s.events.Emitf(jenkins, event.TypeWarning, reasonBuildRetry, "Seed job '%s': %s, it will be retried", id, seedJobStatus.FailureReason)
+	}
+	jenkins.Status.SeedJobs[index] = seedJobStatus
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// updateDiscoveredBranches copies the number of branches discovered by the multibranch pipeline to Jenkins.Status.SeedJobs section
+func (s *SeedJobs) updateDiscoveredBranches(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) error {
+	index := seedJobStatusIndex(jenkins.Status.SeedJobs, seedJob.ID)
+	if index < 0 {
+		return nil
+	}
+
+	job, err := s.jenkinsClient.GetJob(seedJob.ID)
+	if err != nil {
+		return err
+	}
+	discoveredBranches := len(job.Raw.Jobs)
+	if jenkins.Status.SeedJobs[index].DiscoveredBranches == discoveredBranches {
+		return nil
+	}
+
+	jenkins.Status.SeedJobs[index].DiscoveredBranches = discoveredBranches
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// ensureDisplayNameAndDescription restores the display name and the description of the seed job changed manually in Jenkins,
+// empty description leaves the description set in Jenkins untouched
+func (s *SeedJobs) ensureDisplayNameAndDescription(seedJob v1alpha1.SeedJob) error {
+	name := seedJobName(seedJob)
+	job, err := s.jenkinsClient.GetJob(name)
+	if err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf("def job = Jenkins.instance.getItem('%s')\n", groovyStringReplacer.Replace(name))
+	changed := false
+	if job.Raw.DisplayName != displayName(seedJob) {
+		script += fmt.Sprintf("job.setDisplayName('%s')\n", groovyStringReplacer.Replace(displayName(seedJob)))
+		changed = true
+	}
+	if len(seedJob.Description) > 0 && job.Raw.Description != seedJob.Description {
+		script += fmt.Sprintf("job.setDescription('%s')\n", groovyStringReplacer.Replace(seedJob.Description))
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	s.logger.Info(fmt.Sprintf("Restoring display name and description of seed job '%s'", seedJob.ID))
+	_, err = s.jenkinsClient.ExecuteScript(script)
+	return err
+}
+
+// ensureGitHubWebhook registers the GitHub push webhook once for the repository URL and Jenkins URL, they are saved as
+// the checksum in Jenkins.Status.SeedJobs section to not call GitHub API in every reconciliation loop, registration
+// failures are reported as events and don't block reconciliation
+func (s *SeedJobs) ensureGitHubWebhook(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) error {
+	if jenkins.Status.URL == "" {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Jenkins URL is unknown, skipping GitHub webhook registration for seed job '%s'", seedJob.ID))
+		s.events.Emitf(jenkins, event.TypeWarning, reasonGitHubWebhookFailure,
+			"GitHub webhook for seed job '%s' not registered: Jenkins URL is unknown", seedJob.ID)
+		return nil
+	}
+
+	index := seedJobStatusIndex(jenkins.Status.SeedJobs, seedJob.ID)
+	if index < 0 {
+		return nil
+	}
+	checksum := githubWebhookChecksum(seedJob.RepositoryURL, jenkins.Status.URL)
+	if jenkins.Status.SeedJobs[index].GitHubWebhookChecksum == checksum {
+		return nil
+	}
+
+	token, err := s.valueFromSecret(jenkins, seedJob.GitHubAPIToken.SecretKeyRef)
+	if err == nil {
+		err = registerGitHubWebhook(s.httpClient, seedJob.RepositoryURL, token, jenkins.Status.URL)
+	}
+	if err != nil {
+		s.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't register GitHub webhook for seed job '%s': %s", seedJob.ID, err))
+		s.events.Emitf(jenkins, event.TypeWarning, reasonGitHubWebhookFailure,
+			"GitHub webhook for seed job '%s' not registered: %s", seedJob.ID, err)
+		return nil
+	}
+
+	s.logger.Info(fmt.Sprintf("GitHub webhook for seed job '%s' has been registered", seedJob.ID))
+	jenkins.Status.SeedJobs[index].GitHubWebhookChecksum = checksum
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
 // privateKeyFromSecret it's utility function which extracts deploy key from the kubernetes secret
-func (s *SeedJobs) privateKeyFromSecret(namespace string, seedJob v1alpha1.SeedJob) (string, error) {
-	if seedJob.PrivateKey.SecretKeyRef != nil {
-		deployKeySecret := &v1.Secret{}
-		namespaceName := types.NamespacedName{Namespace: namespace, Name: seedJob.PrivateKey.SecretKeyRef.Name}
-		err := s.k8sClient.Get(context.TODO(), namespaceName, deployKeySecret)
+func (s *SeedJobs) privateKeyFromSecret(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) (string, error) {
+	return s.valueFromSecret(jenkins, seedJob.PrivateKey.SecretKeyRef)
+}
+
+// usernamePassword extracts username and password of the seed job from the kubernetes secret or the Vault secret,
+// the token is used as the password with the username expected by the token provider
+func (s *SeedJobs) usernamePassword(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) (username, password string, err error) {
+	if seedJob.Token.SecretKeyRef != nil {
+		token, err := s.valueFromSecret(jenkins, seedJob.Token.SecretKeyRef)
+		return TokenUsername(seedJob.Token.Provider), token, err
+	}
+	if seedJob.UsernamePassword.SecretRef == nil {
+		return "", "", nil
+	}
+	if name := seedJob.UsernamePassword.SecretRef.Name; vault.IsReference(name) {
+		reference, err := vault.ParseReference(name)
+		if err != nil {
+			return "", "", err
+		}
+		values, err := vault.New(s.k8sClient, jenkins).Read(reference.Path)
+		if err != nil {
+			return "", "", err
+		}
+		return values[v1alpha1.UsernameSecretKey], values[v1alpha1.PasswordSecretKey], nil
+	}
+	secret := &v1.Secret{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.UsernamePassword.SecretRef.Name}
+	err = s.k8sClient.Get(context.TODO(), namespaceName, secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return string(secret.Data[v1alpha1.UsernameSecretKey]), string(secret.Data[v1alpha1.PasswordSecretKey]), nil
+}
+
+// TokenUsername returns the username used with the access token by the git provider
+func TokenUsername(provider string) string {
+	if provider == v1alpha1.TokenProviderGitLab {
+		return "oauth2"
+	}
+	return "x-access-token"
+}
+
+// knownHosts returns known_hosts entries from Jenkins.Spec.Master.SSHKnownHosts followed by entries of the seed job
+func (s *SeedJobs) knownHosts(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) (string, error) {
+	var knownHosts []string
+	if sshKnownHosts := jenkins.Spec.Master.SSHKnownHosts; sshKnownHosts != nil {
+		value, err := s.valueFromSecret(jenkins, sshKnownHosts.SecretKeyRef)
+		if err != nil {
+			return "", err
+		}
+		knownHosts = append(knownHosts, strings.TrimSpace(value))
+		value, err = s.valueFromConfigMap(jenkins.Namespace, sshKnownHosts.ConfigMapKeyRef)
+		if err != nil {
+			return "", err
+		}
+		knownHosts = append(knownHosts, strings.TrimSpace(value))
+	}
+	knownHosts = append(knownHosts, strings.TrimSpace(seedJob.KnownHosts))
+	return strings.TrimSpace(strings.Join(knownHosts, "\n")), nil
+}
+
+// valueFromConfigMap it's utility function which extracts value referenced by the selector from the kubernetes config map
+func (s *SeedJobs) valueFromConfigMap(namespace string, selector *v1.ConfigMapKeySelector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+	configMap := &v1.ConfigMap{}
+	namespaceName := types.NamespacedName{Namespace: namespace, Name: selector.Name}
+	err := s.k8sClient.Get(context.TODO(), namespaceName, configMap)
+	if err != nil {
+		return "", err
+	}
+
+	return configMap.Data[selector.Key], nil
+}
+
+// valueFromSecret it's utility function which extracts value referenced by the selector from the kubernetes secret,
+// selectors which name uses the vault: scheme are resolved from Vault
+func (s *SeedJobs) valueFromSecret(jenkins *v1alpha1.Jenkins, selector *v1.SecretKeySelector) (string, error) {
+	if selector == nil {
+		return "", nil
+	}
+	if vault.IsReference(selector.Name) {
+		reference, err := vault.SelectorReference(selector)
 		if err != nil {
 			return "", err
 		}
-		return string(deployKeySecret.Data[seedJob.PrivateKey.SecretKeyRef.Key]), nil
+		return vault.New(s.k8sClient, jenkins).Resolve(reference)
+	}
+	secret := &v1.Secret{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: selector.Name}
+	err := s.k8sClient.Get(context.TODO(), namespaceName, secret)
+	if err != nil {
+		return "", err
+	}
+	return string(secret.Data[selector.Key]), nil
+}
+
+// buildOptions returns timeout and retry policy of the seed job build
+func buildOptions(seedJob v1alpha1.SeedJob) jobs.BuildOptions {
+	options := jobs.BuildOptions{
+		Timeout: constants.DefaultSeedJobBuildTimeout,
+		Retries: seedJob.Retries,
+	}
+	if seedJob.BuildTimeout != nil {
+		options.Timeout = seedJob.BuildTimeout.Duration
 	}
-	return "", nil
+	if seedJob.RetryBackoff != nil {
+		options.RetryBackoff = seedJob.RetryBackoff.Duration
+	}
+	return options
 }
 
-// FIXME(antoniaklja) use mask-password plugin for params.PRIVATE_KEY
+// insecureSSHHost returns the host of the ssh repository url when host key verification is disabled for the seed job
+func insecureSSHHost(seedJob v1alpha1.SeedJob) string {
+	if !seedJob.InsecureSkipHostKeyVerification {
+		return ""
+	}
+	if repositoryURL, err := url.Parse(seedJob.RepositoryURL); err == nil && repositoryURL.Scheme == "ssh" {
+		return repositoryURL.Hostname()
+	}
+	if matches := scpRepositoryURLRegexp.FindStringSubmatch(seedJob.RepositoryURL); matches != nil {
+		return matches[1]
+	}
+	return ""
+}
+
+// displayName returns the display name of the seed job in Jenkins
+func displayName(seedJob v1alpha1.SeedJob) string {
+	if len(seedJob.DisplayName) > 0 {
+		return seedJob.DisplayName
+	}
+	return fmt.Sprintf("Seed Job from %s", seedJob.ID)
+}
+
+// isJobDSL returns true when the seed job runs Job DSL scripts
+func isJobDSL(seedJob v1alpha1.SeedJob) bool {
+	return seedJob.Mode != v1alpha1.SeedJobModeMultibranch && seedJob.Mode != v1alpha1.SeedJobModeOrganizationFolder
+}
+
+// scriptSecurity returns true when Job DSL script security has to be enabled, because any seed job runs in the sandbox
+func scriptSecurity(jenkins *v1alpha1.Jenkins) bool {
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		if isJobDSL(seedJob) && seedJob.Sandbox {
+			return true
+		}
+	}
+	return false
+}
+
+// seedJobName returns the name of the job created for the seed job in Jenkins
+func seedJobName(seedJob v1alpha1.SeedJob) string {
+	if seedJob.Mode == v1alpha1.SeedJobModeMultibranch || seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+		return seedJob.ID
+	}
+	return fmt.Sprintf("%s-%s", seedJob.ID, constants.SeedJobSuffix)
+}
+
+// gerritTrigger returns the Gerrit server, project and branch pattern of the seed job, empty values mean no trigger
+func gerritTrigger(seedJob v1alpha1.SeedJob) (server, project, branchPattern string) {
+	if seedJob.GerritTrigger == nil || len(seedJob.Revision) > 0 {
+		return "", "", ""
+	}
+	return seedJob.GerritTrigger.ServerName, seedJob.GerritTrigger.Project, seedJob.GerritTrigger.BranchPattern
+}
+
+// scmTriggers returns SCM polling and push triggers of the seed job, they are disabled when the seed job is pinned to a revision
+func scmTriggers(seedJob v1alpha1.SeedJob) (pollSCM string, githubPushTrigger, bitbucketPushTrigger bool) {
+	if len(seedJob.Revision) > 0 {
+		return "", false, false
+	}
+	return seedJob.PollSCM, seedJob.GitHubPushTrigger, seedJob.BitbucketPushTrigger
+}
+
+// repositoryBranch returns the branch spec checked out by the seed job, the pinned revision takes precedence over the branch
+func repositoryBranch(seedJob v1alpha1.SeedJob) string {
+	revision := seedJob.Revision
+	if len(revision) == 0 {
+		return seedJob.RepositoryBranch
+	}
+	if commitSHARegexp.MatchString(revision) || strings.HasPrefix(revision, tagRefPrefix) {
+		return revision
+	}
+	return tagRefPrefix + revision
+}
+
+// pinnedRevision returns whether the seed job is pinned to the tag or the commit with the revision or checks out
+// the full commit SHA set as the repository branch, abbreviated SHAs aren't distinguishable from hex branch names
+func pinnedRevision(seedJob v1alpha1.SeedJob) string {
+	return boolParameter(len(seedJob.Revision) > 0 || commitSHARegexp.MatchString(seedJob.RepositoryBranch))
+}
+
+// shallowClone returns the shallow clone setting of the seed job, empty means the default
+func shallowClone(seedJob v1alpha1.SeedJob) string {
+	if seedJob.Shallow == nil {
+		return ""
+	}
+	return strconv.FormatBool(*seedJob.Shallow)
+}
+
+// cloneDepth returns the shallow clone depth of the seed job, empty means the default
+func cloneDepth(seedJob v1alpha1.SeedJob) string {
+	if seedJob.CloneDepth == nil {
+		return ""
+	}
+	return strconv.Itoa(*seedJob.CloneDepth)
+}
+
+// agentLabel returns the label expression restricting where the seed job can run, empty means Jenkins master
+func agentLabel(jenkins *v1alpha1.Jenkins, seedJob v1alpha1.SeedJob) string {
+	if len(seedJob.AgentLabel) > 0 {
+		return seedJob.AgentLabel
+	}
+	if len(jenkins.Spec.SeedJobAgent) > 0 {
+		return jenkins.Spec.SeedJobAgent
+	}
+	if jenkins.Spec.SeedAgent.Enabled {
+		return constants.SeedAgentLabel
+	}
+	return ""
+}
+
+// boolParameter converts flag to the job parameter, disabled flag is an empty value
+func boolParameter(value bool) string {
+	if value {
+		return "true"
+	}
+	return ""
+}
+
+// FIXME(antoniaklja) use mask-password plugin for params.PRIVATE_KEY and params.PASSPHRASE
 // seedJobConfigXML this is the XML representation of seed job
 var seedJobConfigXML = `
 <flow-definition plugin="workflow-job@2.30">
@@ -134,6 +753,7 @@ var seedJobConfigXML = `
   <description>Configure Seed Jobs</description>
   <keepDependencies>false</keepDependencies>
   <properties>
+    ` + jobs.BuildDiscarderPropertyXML(constants.DefaultBuildRetention) + `
     <hudson.model.ParametersDefinitionProperty>
       <parameterDefinitions>
         <hudson.model.StringParameterDefinition>
@@ -147,6 +767,28 @@ var seedJobConfigXML = `
           <description></description>
           <defaultValue></defaultValue>
         </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + passphraseParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + usernameParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + passwordParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + tokenCredentialParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
         <hudson.model.StringParameterDefinition>
           <name>` + repositoryURLParameterName + `</name>
           <description></description>
@@ -159,33 +801,244 @@ var seedJobConfigXML = `
           <defaultValue>master</defaultValue>
           <trim>false</trim>
         </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + pinnedRevisionParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
         <hudson.model.StringParameterDefinition>
           <name>` + displayNameParameterName + `</name>
           <description></description>
           <defaultValue></defaultValue>
           <trim>false</trim>
         </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + descriptionParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
         <hudson.model.StringParameterDefinition>
           <name>` + targetsParameterName + `</name>
           <description></description>
           <defaultValue>cicd/jobs/*.jenkins</defaultValue>
           <trim>false</trim>
         </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + pollSCMParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + buildPeriodicallyParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + githubPushTriggerParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + bitbucketPushTriggerParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + gerritServerParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + gerritProjectParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + gerritBranchPatternParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + agentLabelParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + additionalClasspathParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + ignoreMissingFilesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + failOnMissingPluginParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + sandboxParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + scriptSecurityParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + removedJobActionParameterName + `</name>
+          <description></description>
+          <defaultValue>DELETE</defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + removedViewActionParameterName + `</name>
+          <description></description>
+          <defaultValue>DELETE</defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + knownHostsParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + insecureSSHHostParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + shallowCloneParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + cloneDepthParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + referenceRepositoryParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + submodulesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + recursiveSubmodulesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + parentCredentialsParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + seedJobModeParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + jenkinsfilePathParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + includeBranchesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + excludeBranchesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + organizationParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + includeRepositoriesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + excludeRepositoriesParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + scanIntervalParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + buildRetentionParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>true</trim>
+        </hudson.model.StringParameterDefinition>
       </parameterDefinitions>
     </hudson.model.ParametersDefinitionProperty>
   </properties>
   <definition class="org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition" plugin="workflow-cps@2.61">
     <script>import com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey
 import com.cloudbees.jenkins.plugins.sshcredentials.impl.BasicSSHUserPrivateKey.DirectEntryPrivateKeySource
+import com.cloudbees.hudson.plugins.folder.computed.PeriodicFolderTrigger
 import com.cloudbees.plugins.credentials.CredentialsScope
 import com.cloudbees.plugins.credentials.SystemCredentialsProvider
 import com.cloudbees.plugins.credentials.domains.Domain
+import com.cloudbees.plugins.credentials.impl.UsernamePasswordCredentialsImpl
 import hudson.model.FreeStyleProject
 import hudson.model.labels.LabelAtom
+import hudson.tasks.LogRotator
 import hudson.plugins.git.BranchSpec
 import hudson.plugins.git.GitSCM
 import hudson.plugins.git.SubmoduleConfig
 import hudson.plugins.git.extensions.impl.CloneOption
+import hudson.plugins.git.extensions.impl.SubmoduleOption
+import hudson.triggers.SCMTrigger
+import hudson.triggers.TimerTrigger
+import hudson.util.Secret
 import javaposse.jobdsl.plugin.ExecuteDslScripts
 import javaposse.jobdsl.plugin.LookupStrategy
 import javaposse.jobdsl.plugin.RemovedJobAction
@@ -193,33 +1046,183 @@ import javaposse.jobdsl.plugin.RemovedViewAction
 import jenkins.model.Jenkins
 import javaposse.jobdsl.plugin.GlobalJobDslSecurityConfiguration
 import jenkins.model.GlobalConfiguration
+import org.jenkinsci.plugins.plaincredentials.impl.StringCredentialsImpl
 
 import static com.google.common.collect.Lists.newArrayList
 
-// https://javadoc.jenkins.io/plugin/ssh-credentials/com/cloudbees/jenkins/plugins/sshcredentials/impl/BasicSSHUserPrivateKey.html
-BasicSSHUserPrivateKey deployKeyPrivate = new BasicSSHUserPrivateKey(
-        CredentialsScope.GLOBAL,
-        &quot;${params.DEPLOY_KEY_ID}&quot;,
-        &quot;git&quot;,
-        new DirectEntryPrivateKeySource(&quot;${params.PRIVATE_KEY}&quot;),
-        &quot;&quot;,
-        &quot;${params.DEPLOY_KEY_ID}&quot;
-)
+def credentialsStore = SystemCredentialsProvider.getInstance().getStore()
+// credentials of the seed job are replaced, the credentials type could have changed
+def tokenCredentialsID = &quot;${params.DEPLOY_KEY_ID}-token&quot;
+for (credentials in credentialsStore.getCredentials(Domain.global()).findAll { it.id == &quot;${params.DEPLOY_KEY_ID}&quot; || it.id == tokenCredentialsID }) {
+        credentialsStore.removeCredentials(Domain.global(), credentials)
+}
+
+def seedJobCredentials
+if (&quot;${params.USERNAME}&quot;) {
+        // https://javadoc.jenkins.io/plugin/credentials/com/cloudbees/plugins/credentials/impl/UsernamePasswordCredentialsImpl.html
+        seedJobCredentials = new UsernamePasswordCredentialsImpl(
+                CredentialsScope.GLOBAL,
+                &quot;${params.DEPLOY_KEY_ID}&quot;,
+                &quot;${params.DEPLOY_KEY_ID}&quot;,
+                &quot;${params.USERNAME}&quot;,
+                &quot;${params.PASSWORD}&quot;
+        )
+} else {
+        // https://javadoc.jenkins.io/plugin/ssh-credentials/com/cloudbees/jenkins/plugins/sshcredentials/impl/BasicSSHUserPrivateKey.html
+        seedJobCredentials = new BasicSSHUserPrivateKey(
+                CredentialsScope.GLOBAL,
+                &quot;${params.DEPLOY_KEY_ID}&quot;,
+                &quot;git&quot;,
+                new DirectEntryPrivateKeySource(&quot;${params.PRIVATE_KEY}&quot;),
+                &quot;${params.PASSPHRASE}&quot;,
+                &quot;${params.DEPLOY_KEY_ID}&quot;
+        )
+}
 
 // https://javadoc.jenkins.io/plugin/credentials/index.html?com/cloudbees/plugins/credentials/SystemCredentialsProvider.html
-SystemCredentialsProvider.getInstance().getStore().addCredentials(Domain.global(), deployKeyPrivate)
+credentialsStore.addCredentials(Domain.global(), seedJobCredentials)
+if (&quot;${params.TOKEN_CREDENTIAL}&quot;) {
+        // the token is available as secret text for jobs calling the git provider API, the checkout uses username and token
+        // https://javadoc.jenkins.io/plugin/plain-credentials/org/jenkinsci/plugins/plaincredentials/impl/StringCredentialsImpl.html
+        credentialsStore.addCredentials(Domain.global(), new StringCredentialsImpl(
+                CredentialsScope.GLOBAL,
+                tokenCredentialsID,
+                tokenCredentialsID,
+                Secret.fromString(&quot;${params.PASSWORD}&quot;)
+        ))
+}
+
+// known hosts are appended, entries added by users or other seed jobs are kept
+def sshDirectory = new File(System.getProperty(&quot;user.home&quot;), &quot;.ssh&quot;)
+sshDirectory.mkdirs()
+def knownHostsFile = new File(sshDirectory, &quot;known_hosts&quot;)
+def knownHosts = knownHostsFile.exists() ? knownHostsFile.readLines() : []
+for (knownHost in &quot;${params.KNOWN_HOSTS}&quot;.readLines()*.trim()) {
+        if (knownHost &amp;&amp; !knownHost.startsWith(&quot;#&quot;) &amp;&amp; !knownHosts.contains(knownHost)) {
+                knownHostsFile.append(knownHost + &quot;\n&quot;)
+                knownHosts.add(knownHost)
+        }
+}
+def insecureSSHHost = &quot;${params.INSECURE_SSH_HOST}&quot;
+if (insecureSSHHost) {
+        def sshConfigFile = new File(sshDirectory, &quot;config&quot;)
+        def hostEntry = &quot;Host &quot; + insecureSSHHost
+        if (!sshConfigFile.exists() || !sshConfigFile.readLines().contains(hostEntry)) {
+                sshConfigFile.append(hostEntry + &quot;\n  StrictHostKeyChecking no\n  UserKnownHostsFile /dev/null\n&quot;)
+        }
+}
 
 Jenkins jenkins = Jenkins.instance
+def classLoader = jenkins.pluginManager.uberClassLoader
+// empty description leaves the description set in Jenkins untouched
+def seedJobDescription = &quot;${params.SEED_JOB_DESCRIPTION}&quot;
+
+// multibranch pipeline plugins are installed only when required, so the classes are loaded dynamically
+if (&quot;${params.SEED_JOB_MODE}&quot; == &quot;` + string(v1alpha1.SeedJobModeMultibranch) + `&quot;) {
+        def multibranchName = &quot;${params.DEPLOY_KEY_ID}&quot;
+        def multibranchClass = classLoader.loadClass(&quot;org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject&quot;)
+        def multibranchRef = jenkins.getItem(multibranchName)
+        if (multibranchRef == null) {
+                multibranchRef = jenkins.createProject(multibranchClass, multibranchName)
+        } else if (!multibranchClass.isInstance(multibranchRef)) {
+                throw new IllegalStateException(&quot;Job &quot; + multibranchName + &quot; already exists and it isn't a multibranch pipeline&quot;)
+        }
+
+        def scmSource = classLoader.loadClass(&quot;jenkins.plugins.git.GitSCMSource&quot;).newInstance(&quot;${params.REPOSITORY_URL}&quot;.toString())
+        scmSource.setId(multibranchName)
+        scmSource.setCredentialsId(multibranchName)
+        def includeBranches = &quot;${params.INCLUDE_BRANCHES}&quot;
+        scmSource.setTraits([
+                classLoader.loadClass(&quot;jenkins.plugins.git.traits.BranchDiscoveryTrait&quot;).newInstance(),
+                classLoader.loadClass(&quot;jenkins.scm.impl.trait.WildcardSCMHeadFilterTrait&quot;)
+                        .newInstance(includeBranches ? includeBranches : &quot;*&quot;, &quot;${params.EXCLUDE_BRANCHES}&quot;.toString())
+        ])
+        multibranchRef.getSourcesList().clear()
+        multibranchRef.getSourcesList().add(classLoader.loadClass(&quot;jenkins.branch.BranchSource&quot;).newInstance(scmSource))
+        def jenkinsfilePath = &quot;${params.JENKINSFILE_PATH}&quot;
+        multibranchRef.getProjectFactory().setScriptPath(jenkinsfilePath ? jenkinsfilePath : &quot;Jenkinsfile&quot;)
+        multibranchRef.setDisplayName(&quot;${params.SEED_JOB_DISPLAY_NAME}&quot;)
+        if (seedJobDescription) {
+                multibranchRef.setDescription(seedJobDescription)
+        }
+        multibranchRef.save()
+        // the branch indexing discovers branches and builds them
+        multibranchRef.scheduleBuild2(0)
+        return
+}
+
+// the organization folder requires GitHub branch source plugin which is installed only when required as well
+if (&quot;${params.SEED_JOB_MODE}&quot; == &quot;` + string(v1alpha1.SeedJobModeOrganizationFolder) + `&quot;) {
+        def organizationFolderName = &quot;${params.DEPLOY_KEY_ID}&quot;
+        def organizationFolderClass = classLoader.loadClass(&quot;jenkins.branch.OrganizationFolder&quot;)
+        def organizationFolderRef = jenkins.getItem(organizationFolderName)
+        if (organizationFolderRef == null) {
+                organizationFolderRef = jenkins.createProject(organizationFolderClass, organizationFolderName)
+        } else if (!organizationFolderClass.isInstance(organizationFolderRef)) {
+                throw new IllegalStateException(&quot;Job &quot; + organizationFolderName + &quot; already exists and it isn't an organization folder&quot;)
+        }
+
+        def githubBranchSourcePackage = &quot;org.jenkinsci.plugins.github_branch_source&quot;
+        def navigator = classLoader.loadClass(githubBranchSourcePackage + &quot;.GitHubSCMNavigator&quot;).newInstance(&quot;${params.ORGANIZATION}&quot;.toString())
+        navigator.setCredentialsId(organizationFolderName)
+        def includeRepositories = &quot;${params.INCLUDE_REPOSITORIES}&quot;
+        navigator.setTraits([
+                // 1 - exclude branches that are also filed as pull requests
+                classLoader.loadClass(githubBranchSourcePackage + &quot;.BranchDiscoveryTrait&quot;).newInstance(1),
+                classLoader.loadClass(&quot;jenkins.scm.impl.trait.WildcardSCMSourceFilterTrait&quot;)
+                        .newInstance(includeRepositories ? includeRepositories : &quot;*&quot;, &quot;${params.EXCLUDE_REPOSITORIES}&quot;.toString())
+        ])
+        organizationFolderRef.getNavigators().replace(navigator)
+        organizationFolderRef.getProjectFactories().replace(
+                classLoader.loadClass(&quot;org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProjectFactory&quot;).newInstance())
+        def scanInterval = &quot;${params.SCAN_INTERVAL}&quot;
+        organizationFolderRef.addTrigger(new PeriodicFolderTrigger(scanInterval ? scanInterval : &quot;1d&quot;))
+        organizationFolderRef.setDisplayName(&quot;${params.SEED_JOB_DISPLAY_NAME}&quot;)
+        if (seedJobDescription) {
+                organizationFolderRef.setDescription(seedJobDescription)
+        }
+        organizationFolderRef.save()
+        // the organization scan discovers repositories and their branches
+        organizationFolderRef.scheduleBuild2(0)
+        return
+}
 
 def jobDslSeedName = &quot;${params.DEPLOY_KEY_ID}-` + constants.SeedJobSuffix + `&quot;
 def jobDslDeployKeyName = &quot;${params.DEPLOY_KEY_ID}&quot;
 def jobRef = jenkins.getItem(jobDslSeedName)
 
+def repositoryBranch = &quot;${params.REPOSITORY_BRANCH}&quot;
+// tags and commits are not reachable from a shallow clone without tags, hex branch names aren't treated as commits
+def pinnedRevision = &quot;${params.PINNED_REVISION}&quot;.toBoolean() || repositoryBranch.startsWith(&quot;refs/tags/&quot;)
+
 def repoList = GitSCM.createRepoList(&quot;${params.REPOSITORY_URL}&quot;, jobDslDeployKeyName)
-def gitExtensions = [new CloneOption(true, true, &quot;&quot;, 10)]
+def shallowClone = &quot;${params.SHALLOW_CLONE}&quot;
+def shallow = shallowClone ? shallowClone.toBoolean() : !pinnedRevision
+def cloneOption = new CloneOption(shallow, !pinnedRevision, &quot;${params.REFERENCE_REPOSITORY}&quot;, 10)
+def cloneDepth = &quot;${params.CLONE_DEPTH}&quot;
+if (shallow &amp;&amp; cloneDepth) {
+        cloneOption.setDepth(cloneDepth.toInteger())
+}
+def gitExtensions = [cloneOption]
+if (&quot;${params.SUBMODULES}&quot;.toBoolean()) {
+        // https://javadoc.jenkins.io/plugin/git/hudson/plugins/git/extensions/impl/SubmoduleOption.html
+        def submoduleOption = new SubmoduleOption(
+                false,
+                &quot;${params.RECURSIVE_SUBMODULES}&quot;.toBoolean(),
+                false,
+                null,
+                null,
+                &quot;${params.SUBMODULES_PARENT_CREDENTIALS}&quot;.toBoolean()
+        )
+        if (shallow &amp;&amp; cloneDepth) {
+                submoduleOption.setShallow(true)
+                submoduleOption.setDepth(cloneDepth.toInteger())
+        }
+        gitExtensions.add(submoduleOption)
+}
 def scm = new GitSCM(
         repoList,
-        newArrayList(new BranchSpec(&quot;${params.REPOSITORY_BRANCH}&quot;)),
+        newArrayList(new BranchSpec(repositoryBranch)),
         false,
         Collections.&lt;SubmoduleConfig&gt; emptyList(),
         null,
@@ -229,11 +1232,20 @@ def scm = new GitSCM(
 
 def executeDslScripts = new ExecuteDslScripts()
 executeDslScripts.setTargets(&quot;${params.TARGETS}&quot;)
-executeDslScripts.setSandbox(false)
-executeDslScripts.setRemovedJobAction(RemovedJobAction.DELETE)
-executeDslScripts.setRemovedViewAction(RemovedViewAction.DELETE)
+def removedJobAction = &quot;${params.REMOVED_JOB_ACTION}&quot;
+def removedViewAction = &quot;${params.REMOVED_VIEW_ACTION}&quot;
+executeDslScripts.setRemovedJobAction(removedJobAction ? RemovedJobAction.valueOf(removedJobAction) : RemovedJobAction.DELETE)
+executeDslScripts.setRemovedViewAction(removedViewAction ? RemovedViewAction.valueOf(removedViewAction) : RemovedViewAction.DELETE)
+executeDslScripts.setIgnoreMissingFiles(&quot;${params.IGNORE_MISSING_FILES}&quot;.toBoolean())
+executeDslScripts.setFailOnMissingPlugin(&quot;${params.FAIL_ON_MISSING_PLUGIN}&quot;.toBoolean())
 executeDslScripts.setLookupStrategy(LookupStrategy.SEED_JOB)
-executeDslScripts.setAdditionalClasspath(&quot;src&quot;)
+def sandbox = &quot;${params.SANDBOX}&quot;.toBoolean()
+executeDslScripts.setSandbox(sandbox)
+def additionalClasspath = &quot;${params.ADDITIONAL_CLASSPATH}&quot;
+// additional classpath isn't allowed in the sandbox
+if (!sandbox) {
+        executeDslScripts.setAdditionalClasspath(additionalClasspath ? additionalClasspath : &quot;src&quot;)
+}
 
 if (jobRef == null) {
         jobRef = jenkins.createProject(FreeStyleProject, jobDslSeedName)
@@ -241,11 +1253,82 @@ if (jobRef == null) {
 jobRef.getBuildersList().clear()
 jobRef.getBuildersList().add(executeDslScripts)
 jobRef.setDisplayName(&quot;${params.SEED_JOB_DISPLAY_NAME}&quot;)
+if (seedJobDescription) {
+        jobRef.setDescription(seedJobDescription)
+}
 jobRef.setScm(scm)
-jobRef.setAssignedLabel(new LabelAtom(&quot;master&quot;))
+// the existing seed job is updated as well, builds exceeding the retention are discarded after its next build
+def buildRetention = &quot;${params.BUILD_RETENTION}&quot;
+jobRef.setBuildDiscarder(new LogRotator(-1, buildRetention ? buildRetention.toInteger() : ` + strconv.Itoa(constants.DefaultBuildRetention) + `, -1, -1))
+def agentLabel = &quot;${params.AGENT_LABEL}&quot;
+// seed jobs run on master unless the agent label expression is configured
+if (agentLabel) {
+        jobRef.setAssignedLabel(jenkins.getLabel(agentLabel))
+} else {
+        jobRef.setAssignedLabel(new LabelAtom(&quot;master&quot;))
+}
+
+def pollSCM = &quot;${params.POLL_SCM}&quot;
+jobRef.removeTrigger(jenkins.getDescriptorByType(SCMTrigger.DescriptorImpl))
+if (pollSCM) {
+        def scmTrigger = new SCMTrigger(pollSCM)
+        jobRef.addTrigger(scmTrigger)
+        scmTrigger.start(jobRef, true)
+}
+
+def buildPeriodically = &quot;${params.BUILD_PERIODICALLY}&quot;
+jobRef.removeTrigger(jenkins.getDescriptorByType(TimerTrigger.DescriptorImpl))
+if (buildPeriodically) {
+        def timerTrigger = new TimerTrigger(buildPeriodically)
+        jobRef.addTrigger(timerTrigger)
+        timerTrigger.start(jobRef, true)
+}
+
+def removeTriggers = { String triggerClassName ->
+        def triggerDescriptors = []
+        for (trigger in jobRef.getTriggers().entrySet()) {
+                if (trigger.value.getClass().getName() == triggerClassName) {
+                        triggerDescriptors.add(trigger.key)
+                }
+        }
+        for (descriptor in triggerDescriptors) {
+                jobRef.removeTrigger(descriptor)
+        }
+}
+
+// GitHub, Bitbucket and Gerrit plugins are installed only when required, so the trigger classes are loaded dynamically
+def pushTriggers = [
+        (&quot;com.cloudbees.jenkins.GitHubPushTrigger&quot;)        : &quot;${params.GITHUB_PUSH_TRIGGER}&quot;,
+        (&quot;com.cloudbees.jenkins.plugins.BitBucketTrigger&quot;): &quot;${params.BITBUCKET_PUSH_TRIGGER}&quot;,
+]
+for (pushTrigger in pushTriggers) {
+        removeTriggers(pushTrigger.key)
+        if (pushTrigger.value) {
+                def trigger = classLoader.loadClass(pushTrigger.key).newInstance()
+                jobRef.addTrigger(trigger)
+                trigger.start(jobRef, true)
+        }
+}
+
+def gerritTriggerPackage = &quot;com.sonyericsson.hudson.plugins.gerrit.trigger.hudsontrigger&quot;
+removeTriggers(gerritTriggerPackage + &quot;.GerritTrigger&quot;)
+def gerritServer = &quot;${params.GERRIT_SERVER}&quot;
+if (gerritServer) {
+        def compareType = classLoader.loadClass(gerritTriggerPackage + &quot;.data.CompareType&quot;)
+        def branchPattern = &quot;${params.GERRIT_BRANCH_PATTERN}&quot;
+        def branch = classLoader.loadClass(gerritTriggerPackage + &quot;.data.Branch&quot;)
+                .newInstance(Enum.valueOf(compareType, &quot;ANT&quot;), branchPattern ? branchPattern : &quot;**&quot;)
+        def gerritProject = classLoader.loadClass(gerritTriggerPackage + &quot;.data.GerritProject&quot;)
+                .newInstance(Enum.valueOf(compareType, &quot;PLAIN&quot;), &quot;${params.GERRIT_PROJECT}&quot;, [branch], [], [], [], false)
+        def gerritTrigger = classLoader.loadClass(gerritTriggerPackage + &quot;.GerritTrigger&quot;).newInstance([gerritProject])
+        gerritTrigger.setServerName(gerritServer)
+        gerritTrigger.setTriggerOnEvents([classLoader.loadClass(gerritTriggerPackage + &quot;.events.PluginChangeMergedEvent&quot;).newInstance()])
+        jobRef.addTrigger(gerritTrigger)
+        gerritTrigger.start(jobRef, true)
+}
 
-// disable Job DSL script approval
-GlobalConfiguration.all().get(GlobalJobDslSecurityConfiguration.class).useScriptSecurity=false
+// Job DSL script security is enabled only when any seed job runs in the sandbox, otherwise no script approval is needed
+GlobalConfiguration.all().get(GlobalJobDslSecurityConfiguration.class).useScriptSecurity=&quot;${params.JOB_DSL_SCRIPT_SECURITY}&quot;.toBoolean()
 GlobalConfiguration.all().get(GlobalJobDslSecurityConfiguration.class).save()
 jenkins.getQueue().schedule(jobRef)
 </script>
@@ -3,10 +3,14 @@ package seedjobs
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 
 	"github.com/bndr/gojenkins"
 	"github.com/golang/mock/gomock"
@@ -14,6 +18,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -23,6 +28,7 @@ import (
 func TestEnsureSeedJobs(t *testing.T) {
 	// given
 	logger := logf.ZapLogger(false)
+	events := &fakeRecorder{}
 	ctrl := gomock.NewController(t)
 	ctx := context.TODO()
 	defer ctrl.Finish()
@@ -40,18 +46,18 @@ func TestEnsureSeedJobs(t *testing.T) {
 	for reconcileAttempt := 1; reconcileAttempt <= 2; reconcileAttempt++ {
 		logger.Info(fmt.Sprintf("Reconcile attempt #%d", reconcileAttempt))
 
-		seedJobs := New(jenkinsClient, fakeClient, logger)
+		seedJobs := New(jenkinsClient, fakeClient, logger, events)
 
 		// first run - should create job and schedule build
 		if reconcileAttempt == 1 {
 			jenkinsClient.
 				EXPECT().
-				CreateOrUpdateJob(seedJobConfigXML, ConfigureSeedJobsName).
+				CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).
 				Return(nil, true, nil)
 
 			jenkinsClient.
 				EXPECT().
-				GetJob(ConfigureSeedJobsName).
+				GetJob(constants.ConfigureSeedJobsName).
 				Return(&gojenkins.Job{
 					Raw: &gojenkins.JobResponse{
 						NextBuildNumber: buildNumber,
@@ -60,7 +66,7 @@ func TestEnsureSeedJobs(t *testing.T) {
 
 			jenkinsClient.
 				EXPECT().
-				BuildJob(ConfigureSeedJobsName, gomock.Any()).
+				BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).
 				Return(int64(0), nil)
 		}
 
@@ -68,17 +74,27 @@ func TestEnsureSeedJobs(t *testing.T) {
 		if reconcileAttempt == 2 {
 			jenkinsClient.
 				EXPECT().
-				CreateOrUpdateJob(seedJobConfigXML, ConfigureSeedJobsName).
+				CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).
 				Return(nil, false, nil)
 
 			jenkinsClient.
 				EXPECT().
-				GetBuild(ConfigureSeedJobsName, gomock.Any()).
+				GetBuild(constants.ConfigureSeedJobsName, gomock.Any()).
 				Return(&gojenkins.Build{
 					Raw: &gojenkins.BuildResponse{
 						Result: string(v1alpha1.BuildSuccessStatus),
 					},
 				}, nil)
+
+			jenkinsClient.
+				EXPECT().
+				GetJob("jenkins-operator-e2e-"+constants.SeedJobSuffix).
+				Return(&gojenkins.Job{
+					Raw: &gojenkins.JobResponse{
+						DisplayName: "Seed Job from jenkins-operator-e2e",
+						Description: "Jenkins Operator e2e tests repository",
+					},
+				}, nil)
 		}
 
 		done, err := seedJobs.EnsureSeedJobs(jenkins)
@@ -90,12 +106,20 @@ func TestEnsureSeedJobs(t *testing.T) {
 		assert.Equal(t, 1, len(jenkins.Status.Builds), "There is one running job")
 		build := jenkins.Status.Builds[0]
 		assert.Equal(t, buildNumber, build.Number)
-		assert.Equal(t, ConfigureSeedJobsName, build.JobName)
+		assert.Equal(t, constants.ConfigureSeedJobsName, build.JobName)
 		assert.NotNil(t, build.CreateTime)
 		assert.NotEmpty(t, build.Hash)
 		assert.NotNil(t, build.LastUpdateTime)
 		assert.Equal(t, 0, build.Retires)
 
+		assert.Equal(t, 1, len(jenkins.Status.SeedJobs))
+		seedJobStatus := jenkins.Status.SeedJobs[0]
+		assert.Equal(t, "jenkins-operator-e2e", seedJobStatus.ID)
+		assert.Equal(t, buildNumber, seedJobStatus.LastBuildNumber)
+		assert.Equal(t, strings.ToUpper(string(build.Status)), seedJobStatus.LastBuildResult)
+		assert.NotNil(t, seedJobStatus.LastBuildTime)
+		assert.Empty(t, seedJobStatus.FailureReason)
+
 		// first run - should create job and schedule build
 		if reconcileAttempt == 1 {
 			assert.False(t, done)
@@ -111,6 +135,380 @@ func TestEnsureSeedJobs(t *testing.T) {
 	}
 }
 
+func TestEnsureSeedJobsRename(t *testing.T) {
+	// given
+	logger := logf.ZapLogger(false)
+	events := &fakeRecorder{}
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	oldSeedJobID := "jenkins-operator-e2e-old"
+	jenkins := jenkinsCustomResource()
+	jenkins.Status.SeedJobs = []v1alpha1.SeedJobStatus{{ID: oldSeedJobID, GCPolicy: v1alpha1.SeedJobGCPolicyDelete}}
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+	buildNumber := int64(1)
+
+	for reconcileAttempt := 1; reconcileAttempt <= 3; reconcileAttempt++ {
+		logger.Info(fmt.Sprintf("Reconcile attempt #%d", reconcileAttempt))
+
+		seedJobs := New(jenkinsClient, fakeClient, logger, events)
+
+		// first run - should create jobs and schedule builds for the removed and the new seed job
+		if reconcileAttempt == 1 {
+			jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, true, nil)
+			jenkinsClient.EXPECT().CreateOrUpdateJob(deleteSeedJobConfigXML, constants.DeleteSeedJobsName).Return(nil, true, nil)
+			jenkinsClient.EXPECT().GetJob(constants.DeleteSeedJobsName).
+				Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: buildNumber}}, nil)
+			jenkinsClient.EXPECT().BuildJob(constants.DeleteSeedJobsName, map[string]string{
+				seedJobIDParameterName:           oldSeedJobID,
+				deleteGeneratedJobsParameterName: "true",
+				deletedSeedJobModeParameterName:  "",
+			}).Return(int64(0), nil)
+			jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+				Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: buildNumber}}, nil)
+			jenkinsClient.EXPECT().BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).Return(int64(0), nil)
+		}
+
+		// second run - should finish both builds and forget the removed seed job
+		if reconcileAttempt == 2 {
+			jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+			jenkinsClient.EXPECT().CreateOrUpdateJob(deleteSeedJobConfigXML, constants.DeleteSeedJobsName).Return(nil, false, nil)
+			jenkinsClient.EXPECT().GetBuild(constants.DeleteSeedJobsName, buildNumber).
+				Return(&gojenkins.Build{Raw: &gojenkins.BuildResponse{Result: string(v1alpha1.BuildSuccessStatus)}}, nil)
+			jenkinsClient.EXPECT().GetBuild(constants.ConfigureSeedJobsName, buildNumber).
+				Return(&gojenkins.Build{Raw: &gojenkins.BuildResponse{Result: string(v1alpha1.BuildSuccessStatus)}}, nil)
+		}
+
+		// third run - nothing to delete
+		if reconcileAttempt == 3 {
+			jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+		}
+
+		if reconcileAttempt >= 2 {
+			jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e-"+constants.SeedJobSuffix).
+				Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{DisplayName: "Seed Job from jenkins-operator-e2e", Description: "Jenkins Operator e2e tests repository"}}, nil)
+		}
+
+		done, err := seedJobs.EnsureSeedJobs(jenkins)
+		assert.NoError(t, err)
+
+		err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+		assert.NoError(t, err)
+
+		if reconcileAttempt == 1 {
+			assert.False(t, done)
+			assert.Equal(t, 2, len(jenkins.Status.Builds))
+			assert.Equal(t, []string{oldSeedJobID, "jenkins-operator-e2e"}, seedJobIDs(jenkins))
+		} else {
+			assert.True(t, done)
+			assert.Equal(t, 1, len(jenkins.Status.Builds))
+			assert.Equal(t, constants.ConfigureSeedJobsName, jenkins.Status.Builds[0].JobName)
+			assert.Equal(t, []string{"jenkins-operator-e2e"}, seedJobIDs(jenkins))
+		}
+	}
+}
+
+func TestEnsureSeedJobsUnrecoverableBuildFailure(t *testing.T) {
+	// given
+	logger := logf.ZapLogger(false)
+	events := &fakeRecorder{}
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	jenkins := jenkinsCustomResource()
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+	buildNumber := int64(1)
+
+	// first run - should create job and schedule build
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, true, nil)
+	jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: buildNumber}}, nil)
+	jenkinsClient.EXPECT().BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).Return(int64(0), nil)
+
+	_, err = New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+
+	// build failed and the retries limit was reached
+	jenkins.Status.Builds[0].Status = v1alpha1.BuildFailureStatus
+	jenkins.Status.Builds[0].Retires = jobs.BuildRetires
+	err = fakeClient.Update(ctx, jenkins)
+	assert.NoError(t, err)
+
+	// second run - should report failure in status
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+
+	done, err := New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, len(jenkins.Status.SeedJobs))
+	seedJobStatus := jenkins.Status.SeedJobs[0]
+	assert.Equal(t, buildNumber, seedJobStatus.LastBuildNumber)
+	assert.Equal(t, "FAILURE", seedJobStatus.LastBuildResult)
+	assert.Contains(t, seedJobStatus.FailureReason, "retries limit")
+	assert.Equal(t, []event.Reason{reasonSandboxDisabled, reasonBuildFailure}, events.reasons)
+}
+
+func TestEnsureSeedJobsIndependentBuilds(t *testing.T) {
+	// given
+	logger := logf.ZapLogger(false)
+	events := &fakeRecorder{}
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	jenkins := jenkinsCustomResource()
+	secondSeedJob := jenkins.Spec.SeedJobs[0]
+	secondSeedJob.ID = "jenkins-operator-e2e-second"
+	jenkins.Spec.SeedJobs = append(jenkins.Spec.SeedJobs, secondSeedJob)
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+
+	// first run - should schedule builds of both seed jobs
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, true, nil)
+	jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: 1}}, nil)
+	jenkinsClient.EXPECT().GetJob(constants.ConfigureSeedJobsName).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{NextBuildNumber: 2}}, nil)
+	jenkinsClient.EXPECT().BuildJob(constants.ConfigureSeedJobsName, gomock.Any()).Return(int64(0), nil).Times(2)
+
+	done, err := New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 2, len(jenkins.Status.Builds))
+
+	// second run - the failed build of the first seed job doesn't hold back the second one
+	jenkinsClient.EXPECT().CreateOrUpdateJob(seedJobConfigXML, constants.ConfigureSeedJobsName).Return(nil, false, nil)
+	jenkinsClient.EXPECT().GetBuild(constants.ConfigureSeedJobsName, int64(1)).
+		Return(&gojenkins.Build{Raw: &gojenkins.BuildResponse{Result: "FAILURE"}}, nil)
+	jenkinsClient.EXPECT().GetBuildConsoleOutput(constants.ConfigureSeedJobsName, int64(1)).Return("Finished: FAILURE\n", nil)
+	jenkinsClient.EXPECT().GetBuild(constants.ConfigureSeedJobsName, int64(2)).
+		Return(&gojenkins.Build{Raw: &gojenkins.BuildResponse{Result: "SUCCESS"}}, nil)
+	jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e-second-"+constants.SeedJobSuffix).
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{
+			DisplayName: "Seed Job from jenkins-operator-e2e-second",
+			Description: "Jenkins Operator e2e tests repository",
+		}}, nil)
+
+	done, err = New(jenkinsClient, fakeClient, logger, events).EnsureSeedJobs(jenkins)
+	assert.Equal(t, jobs.ErrorBuildFailed, err)
+	assert.False(t, done)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"jenkins-operator-e2e", "jenkins-operator-e2e-second"}, seedJobIDs(jenkins))
+	assert.Equal(t, "FAILURE", jenkins.Status.SeedJobs[0].LastBuildResult)
+	assert.Equal(t, "build #1 failed", jenkins.Status.SeedJobs[0].FailureReason)
+	assert.Equal(t, "SUCCESS", jenkins.Status.SeedJobs[1].LastBuildResult)
+	assert.Empty(t, jenkins.Status.SeedJobs[1].FailureReason)
+	assert.Contains(t, events.reasons, reasonBuildRetry)
+}
+
+func TestInsecureSSHHost(t *testing.T) {
+	data := []struct {
+		seedJob      v1alpha1.SeedJob
+		expectedHost string
+	}{
+		{
+			seedJob:      v1alpha1.SeedJob{RepositoryURL: "git@git.example.com:oldsj/jenkins-operator.git", InsecureSkipHostKeyVerification: true},
+			expectedHost: "git.example.com",
+		},
+		{
+			seedJob:      v1alpha1.SeedJob{RepositoryURL: "ssh://git@git.example.com:7999/oldsj/jenkins-operator.git", InsecureSkipHostKeyVerification: true},
+			expectedHost: "git.example.com",
+		},
+		{
+			seedJob:      v1alpha1.SeedJob{RepositoryURL: "git@git.example.com:oldsj/jenkins-operator.git"},
+			expectedHost: "",
+		},
+		{
+			seedJob:      v1alpha1.SeedJob{RepositoryURL: "https://github.com/oldsj/jenkins-operator.git", InsecureSkipHostKeyVerification: true},
+			expectedHost: "",
+		},
+	}
+
+	for _, testingData := range data {
+		t.Run(fmt.Sprintf("Testing '%s'", testingData.seedJob.RepositoryURL), func(t *testing.T) {
+			assert.Equal(t, testingData.expectedHost, insecureSSHHost(testingData.seedJob))
+		})
+	}
+}
+
+func TestCloneOptionParameters(t *testing.T) {
+	shallow, depth := false, 5
+
+	assert.Equal(t, "", shallowClone(v1alpha1.SeedJob{}))
+	assert.Equal(t, "false", shallowClone(v1alpha1.SeedJob{Shallow: &shallow}))
+	assert.Equal(t, "", cloneDepth(v1alpha1.SeedJob{}))
+	assert.Equal(t, "5", cloneDepth(v1alpha1.SeedJob{CloneDepth: &depth}))
+}
+
+func TestAgentLabel(t *testing.T) {
+	jenkins := jenkinsCustomResource()
+	assert.Equal(t, "", agentLabel(jenkins, v1alpha1.SeedJob{}))
+
+	jenkins.Spec.SeedAgent.Enabled = true
+	assert.Equal(t, constants.SeedAgentLabel, agentLabel(jenkins, v1alpha1.SeedJob{}))
+
+	jenkins.Spec.SeedJobAgent = "linux"
+	assert.Equal(t, "linux", agentLabel(jenkins, v1alpha1.SeedJob{}))
+	assert.Equal(t, "docker", agentLabel(jenkins, v1alpha1.SeedJob{AgentLabel: "docker"}))
+}
+
+func TestRepositoryBranch(t *testing.T) {
+	sha := "0123456789abcdef0123456789abcdef01234567"
+	assert.Equal(t, "master", repositoryBranch(v1alpha1.SeedJob{RepositoryBranch: "master"}))
+	assert.Equal(t, "refs/tags/v1.0.0", repositoryBranch(v1alpha1.SeedJob{RepositoryBranch: "master", Revision: "v1.0.0"}))
+	assert.Equal(t, "refs/tags/v1.0.0", repositoryBranch(v1alpha1.SeedJob{Revision: "refs/tags/v1.0.0"}))
+	assert.Equal(t, sha, repositoryBranch(v1alpha1.SeedJob{RepositoryBranch: "master", Revision: sha}))
+}
+
+func TestPinnedRevision(t *testing.T) {
+	assert.Equal(t, "", pinnedRevision(v1alpha1.SeedJob{RepositoryBranch: "master"}))
+	// hex branch names are branches
+	assert.Equal(t, "", pinnedRevision(v1alpha1.SeedJob{RepositoryBranch: "deadbeef"}))
+	// the full commit SHA is checked out from the full clone
+	assert.Equal(t, "true", pinnedRevision(v1alpha1.SeedJob{RepositoryBranch: "0123456789abcdef0123456789abcdef01234567"}))
+	assert.Equal(t, "true", pinnedRevision(v1alpha1.SeedJob{RepositoryBranch: "master", Revision: "v1.0.0"}))
+	assert.Equal(t, "true", pinnedRevision(v1alpha1.SeedJob{Revision: "0123456789abcdef0123456789abcdef01234567"}))
+}
+
+func TestSCMTriggers(t *testing.T) {
+	seedJob := v1alpha1.SeedJob{
+		PollSCM:              "H/5 * * * *",
+		GitHubPushTrigger:    true,
+		BitbucketPushTrigger: true,
+		GerritTrigger:        &v1alpha1.GerritTrigger{ServerName: "gerrit", Project: "jenkins-operator"},
+	}
+	pollSCM, githubPushTrigger, bitbucketPushTrigger := scmTriggers(seedJob)
+	assert.Equal(t, "H/5 * * * *", pollSCM)
+	assert.True(t, githubPushTrigger)
+	assert.True(t, bitbucketPushTrigger)
+	server, _, _ := gerritTrigger(seedJob)
+	assert.Equal(t, "gerrit", server)
+
+	seedJob.Revision = "v1.0.0"
+	pollSCM, githubPushTrigger, bitbucketPushTrigger = scmTriggers(seedJob)
+	assert.Empty(t, pollSCM)
+	assert.False(t, githubPushTrigger)
+	assert.False(t, bitbucketPushTrigger)
+	server, _, _ = gerritTrigger(seedJob)
+	assert.Empty(t, server)
+}
+
+func TestTokenUsername(t *testing.T) {
+	assert.Equal(t, "x-access-token", TokenUsername(""))
+	assert.Equal(t, "x-access-token", TokenUsername(v1alpha1.TokenProviderGitHub))
+	assert.Equal(t, "oauth2", TokenUsername(v1alpha1.TokenProviderGitLab))
+}
+
+func TestUpdateDiscoveredBranches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+
+	jenkins := jenkinsCustomResource()
+	jenkins.Status.SeedJobs = []v1alpha1.SeedJobStatus{{ID: "jenkins-operator-e2e", Mode: v1alpha1.SeedJobModeMultibranch}}
+	err = fakeClient.Create(context.TODO(), jenkins)
+	assert.NoError(t, err)
+
+	jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e").
+		Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{Jobs: []gojenkins.InnerJob{{Name: "master"}, {Name: "develop"}}}}, nil)
+
+	seedJobs := New(jenkinsClient, fakeClient, logf.ZapLogger(false), &fakeRecorder{})
+	err = seedJobs.updateDiscoveredBranches(jenkins, v1alpha1.SeedJob{ID: "jenkins-operator-e2e", Mode: v1alpha1.SeedJobModeMultibranch})
+	assert.NoError(t, err)
+
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, jenkins.Status.SeedJobs[0].DiscoveredBranches)
+}
+
+func TestEnsureDisplayNameAndDescription(t *testing.T) {
+	t.Run("restores changed display name and description", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e-"+constants.SeedJobSuffix).
+			Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{DisplayName: "changed", Description: "changed"}}, nil)
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).
+			DoAndReturn(func(script string) (string, error) {
+				assert.Contains(t, script, "getItem('jenkins-operator-e2e-"+constants.SeedJobSuffix+"')")
+				assert.Contains(t, script, "setDisplayName('E2E seed job')")
+				assert.Contains(t, script, `setDescription('Jenkins Operator\'s\nseed job')`)
+				return "", nil
+			})
+
+		seedJobs := New(jenkinsClient, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{})
+		err := seedJobs.ensureDisplayNameAndDescription(v1alpha1.SeedJob{
+			ID:          "jenkins-operator-e2e",
+			DisplayName: "E2E seed job",
+			Description: "Jenkins Operator's\nseed job",
+		})
+		assert.NoError(t, err)
+	})
+	t.Run("leaves description untouched when empty", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob("jenkins-operator-e2e").
+			Return(&gojenkins.Job{Raw: &gojenkins.JobResponse{DisplayName: "Seed Job from jenkins-operator-e2e", Description: "manual"}}, nil)
+
+		seedJobs := New(jenkinsClient, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{})
+		err := seedJobs.ensureDisplayNameAndDescription(v1alpha1.SeedJob{ID: "jenkins-operator-e2e", Mode: v1alpha1.SeedJobModeMultibranch})
+		assert.NoError(t, err)
+	})
+}
+
+type fakeRecorder struct {
+	reasons []event.Reason
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func seedJobIDs(jenkins *v1alpha1.Jenkins) []string {
+	var ids []string
+	for _, seedJobStatus := range jenkins.Status.SeedJobs {
+		ids = append(ids, seedJobStatus.ID)
+	}
+	return ids
+}
+
 func jenkinsCustomResource() *v1alpha1.Jenkins {
 	return &v1alpha1.Jenkins{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,2 @@
+// Package systemmessage implements the system message of Jenkins declared in Jenkins CR
+package systemmessage
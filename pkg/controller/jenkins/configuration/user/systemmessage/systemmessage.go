@@ -0,0 +1,123 @@
+package systemmessage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonSystemMessageUpdated is the event which informs the system message of Jenkins has been set or cleared
+	// after Jenkins.Spec.Master.SystemMessage changed
+	reasonSystemMessageUpdated event.Reason = "SystemMessageUpdated"
+	// reasonSystemMessageReapplied is the event which informs the system message has been changed in Jenkins
+	// and operator applied Jenkins.Spec.Master.SystemMessage again
+	reasonSystemMessageReapplied event.Reason = "SystemMessageReapplied"
+
+	// appliedMarker is printed by setSystemMessageFmt when the message has been saved
+	appliedMarker = "system message applied"
+)
+
+// setSystemMessageFmt sets the system message passed as the base64 encoded JSON built by systemMessageSettings,
+// the empty message clears it
+const setSystemMessageFmt = `
+import jenkins.model.Jenkins
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def jenkins = Jenkins.getInstance()
+
+try {
+    jenkins.setSystemMessage(settings.message.isEmpty() ? null : settings.message)
+    jenkins.save()
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + appliedMarker + `'
+`
+
+// systemMessageSettings defines the message passed to setSystemMessageFmt
+type systemMessageSettings struct {
+	Message string `json:"message"`
+}
+
+// SystemMessage applies Jenkins.Spec.Master.SystemMessage to Jenkins
+type SystemMessage struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates SystemMessage object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *SystemMessage {
+	return &SystemMessage{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// EnsureSystemMessage compares the system message of Jenkins with Jenkins.Spec.Master.SystemMessage on every call using
+// the Jenkins API, the message is set again only when it has been changed in Jenkins or its checksum differs from
+// Jenkins.Status.SystemMessageChecksum, the message is left untouched until operator applied it for the first time
+func (s *SystemMessage) EnsureSystemMessage(jenkins *v1alpha1.Jenkins) error {
+	message := jenkins.Spec.Master.SystemMessage
+	if len(message) == 0 && len(jenkins.Status.SystemMessageChecksum) == 0 {
+		return nil
+	}
+
+	info, err := s.jenkinsClient.Info()
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	// the description of Jenkins is the system message, it's null when the message isn't set
+	current, _ := info.Description.(string)
+	checksum := calculateChecksum(message)
+	unchanged := jenkins.Status.SystemMessageChecksum == checksum
+	if unchanged && current == message {
+		return nil
+	}
+
+	script := groovy.SettingsScript{Format: setSystemMessageFmt, DoneMarker: appliedMarker}
+	if _, err := script.Execute(s.jenkinsClient, systemMessageSettings{Message: message}); err != nil {
+		return stackerr.Wrap(err, "couldn't set the system message")
+	}
+
+	switch {
+	case unchanged:
+		text := "System message has been changed in Jenkins, Jenkins.Spec.Master.SystemMessage applied again"
+		s.logger.V(log.VWarn).Info(text)
+		s.events.Emit(jenkins, event.TypeWarning, reasonSystemMessageReapplied, text)
+		return nil
+	case len(message) == 0:
+		s.logger.Info("System message has been cleared")
+		s.events.Emit(jenkins, event.TypeNormal, reasonSystemMessageUpdated, "System message has been cleared")
+	default:
+		s.logger.Info("System message has been set")
+		s.events.Emit(jenkins, event.TypeNormal, reasonSystemMessageUpdated, "System message has been set")
+	}
+
+	jenkins.Status.SystemMessageChecksum = checksum
+	return s.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// calculateChecksum returns the checksum of the system message, it's empty when the message isn't set
+func calculateChecksum(message string) string {
+	if len(message) == 0 {
+		return ""
+	}
+	hash := sha256.Sum256([]byte(message))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
@@ -0,0 +1,116 @@
+package systemmessage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/bndr/gojenkins"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureSystemMessage(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	message := "<b>Maintenance</b> on Saturday"
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec:       v1alpha1.JenkinsSpec{Master: v1alpha1.JenkinsMaster{SystemMessage: message}},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	systemMessage := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// the message is set
+	jenkinsClient.EXPECT().Info().Return(&gojenkins.ExecutorResponse{}, nil)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.JSONEq(t, fmt.Sprintf(`{"message":%q}`, message), groovytest.DecodeSettings(t, script))
+		return appliedMarker, nil
+	})
+
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonSystemMessageUpdated}, events.reasons)
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, calculateChecksum(message), updatedJenkins.Status.SystemMessageChecksum)
+
+	// the message in Jenkins matches, the script isn't executed
+	jenkinsClient.EXPECT().Info().Return(&gojenkins.ExecutorResponse{Description: message}, nil)
+
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.NoError(t, err)
+	assert.Len(t, events.reasons, 1)
+
+	// the message changed in Jenkins is reverted
+	jenkinsClient.EXPECT().Info().Return(&gojenkins.ExecutorResponse{Description: "changed"}, nil)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.JSONEq(t, fmt.Sprintf(`{"message":%q}`, message), groovytest.DecodeSettings(t, script))
+		return appliedMarker, nil
+	})
+
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonSystemMessageUpdated, reasonSystemMessageReapplied}, events.reasons)
+
+	// the empty message clears the message
+	jenkins.Spec.Master.SystemMessage = ""
+	jenkinsClient.EXPECT().Info().Return(&gojenkins.ExecutorResponse{Description: message}, nil)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.JSONEq(t, `{"message":""}`, groovytest.DecodeSettings(t, script))
+		return appliedMarker, nil
+	})
+
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, reasonSystemMessageUpdated, events.reasons[len(events.reasons)-1])
+	assert.Empty(t, jenkins.Status.SystemMessageChecksum)
+
+	// the message isn't managed anymore
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.NoError(t, err)
+
+	// the script failed
+	jenkins.Spec.Master.SystemMessage = message
+	jenkinsClient.EXPECT().Info().Return(&gojenkins.ExecutorResponse{}, nil)
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.io.IOException: can't save", nil)
+
+	err = systemMessage.EnsureSystemMessage(jenkins)
+	assert.EqualError(t, err, "couldn't set the system message: java.io.IOException: can't save")
+	assert.Empty(t, jenkins.Status.SystemMessageChecksum)
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,2 @@
+// Package tools implements the global tool configuration of Jenkins declared in Jenkins CR
+package tools
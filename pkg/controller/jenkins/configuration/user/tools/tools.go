@@ -0,0 +1,224 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonToolsConfigured is the event which informs the global tool configuration of Jenkins has been applied
+	reasonToolsConfigured event.Reason = "ToolsConfigured"
+	// reasonToolVersionUnknown is the event which informs the version of the tool installer isn't known by the update center
+	reasonToolVersionUnknown event.Reason = "ToolVersionUnknown"
+
+	// configuredMarker is printed by configureToolsFmt when all tools have been configured
+	configuredMarker = "tools configured"
+	// unknownVersionPrefix is printed by configureToolsFmt with the tool kind, the installation name and the version
+	// separated by tabs for every version the update center doesn't know
+	unknownVersionPrefix = "unknown version\t"
+)
+
+// Tool kinds of Jenkins.Spec.Tools
+const (
+	JDK    = "jdk"
+	Maven  = "maven"
+	Gradle = "gradle"
+	NodeJS = "nodejs"
+)
+
+// configureToolsFmt replaces installations of the global tool configuration with installations passed as the base64
+// encoded JSON built by toolsSettings, classes are loaded by name so tools of plugins which aren't installed don't
+// break the script when they have no installations, versions of installers are checked against the update center
+// metadata downloaded by Jenkins
+const configureToolsFmt = `
+import hudson.model.DownloadService
+import hudson.tools.InstallSourceProperty
+import jenkins.model.Jenkins
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def jenkins = Jenkins.getInstance()
+def classLoader = jenkins.getPluginManager().uberClassLoader
+
+def kinds = [
+    '` + JDK + `'   : [installation: 'hudson.model.JDK', installer: 'hudson.tools.JDKInstaller'],
+    '` + Maven + `' : [installation: 'hudson.tasks.Maven$MavenInstallation', installer: 'hudson.tasks.Maven$MavenInstaller'],
+    '` + Gradle + `': [installation: 'hudson.plugins.gradle.GradleInstallation', installer: 'hudson.plugins.gradle.GradleInstaller'],
+    '` + NodeJS + `': [installation: 'jenkins.plugins.nodejs.tools.NodeJSInstallation', installer: 'jenkins.plugins.nodejs.tools.NodeJSInstaller'],
+]
+
+def newInstaller = { kind, installerClass, version ->
+    switch (kind) {
+        case '` + JDK + `':
+            return installerClass.newInstance(version, true)
+        case '` + NodeJS + `':
+            return installerClass.newInstance(version, '', 72L)
+        default:
+            return installerClass.newInstance(version)
+    }
+}
+
+def collectVersions
+collectVersions = { data, versions ->
+    if (data instanceof Map) {
+        ['id', 'name'].each { key ->
+            if (data[key] instanceof String) {
+                versions.add(data[key])
+            }
+        }
+        data.values().each { collectVersions(it, versions) }
+    } else if (data instanceof List) {
+        data.each { collectVersions(it, versions) }
+    }
+}
+
+def unknown = []
+try {
+    settings.tools.each { kind, installations ->
+        def installationClass
+        try {
+            installationClass = classLoader.loadClass(kinds[kind].installation)
+        } catch (ClassNotFoundException e) {
+            if (installations.isEmpty()) {
+                return
+            }
+            throw e
+        }
+        def installerClass = classLoader.loadClass(kinds[kind].installer)
+        def descriptor = jenkins.getDescriptorOrDie(installationClass)
+
+        def configured = installations.collect { entry ->
+            def properties = []
+            if (entry.version) {
+                properties.add(new InstallSourceProperty([newInstaller(kind, installerClass, entry.version)]))
+            }
+            return installationClass.newInstance(entry.name, entry.home ?: '', properties)
+        }
+        descriptor.setInstallations(configured.toArray(java.lang.reflect.Array.newInstance(installationClass, 0)))
+        descriptor.save()
+
+        def downloadable = DownloadService.Downloadable.get(installerClass.getName())
+        def data = downloadable?.getData()
+        if (data == null) {
+            return
+        }
+        def versions = [] as Set
+        collectVersions(new groovy.json.JsonSlurper().parseText(data.toString()), versions)
+        installations.findAll { it.version && !versions.contains(it.version) }.each { entry ->
+            unknown.add([kind, entry.name, entry.version].join('\t'))
+        }
+    }
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+unknown.each { println '` + unknownVersionPrefix + `' + it }
+print '` + configuredMarker + `'
+`
+
+// toolsSettings defines installations passed to configureToolsFmt
+type toolsSettings struct {
+	Tools map[string][]v1alpha1.ToolInstallation `json:"tools"`
+}
+
+// Tools applies Jenkins.Spec.Tools to the global tool configuration of Jenkins
+type Tools struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates Tools object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *Tools {
+	return &Tools{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// Installations returns installations of Jenkins.Spec.Tools keyed by the tool kind
+func Installations(tools *v1alpha1.Tools) map[string][]v1alpha1.ToolInstallation {
+	if tools == nil {
+		tools = &v1alpha1.Tools{}
+	}
+	return map[string][]v1alpha1.ToolInstallation{
+		JDK:    tools.JDK,
+		Maven:  tools.Maven,
+		Gradle: tools.Gradle,
+		NodeJS: tools.NodeJS,
+	}
+}
+
+// EnsureTools applies Jenkins.Spec.Tools when its checksum differs from Jenkins.Status.ToolsChecksum, installations
+// of every tool are replaced, so removed entries are removed from Jenkins, when the section is removed all installations
+// of tools are removed, unknown installer versions are reported by warning events
+func (t *Tools) EnsureTools(jenkins *v1alpha1.Jenkins) error {
+	if jenkins.Spec.Tools == nil && len(jenkins.Status.ToolsChecksum) == 0 {
+		return nil
+	}
+	checksum, err := calculateChecksum(jenkins.Spec.Tools)
+	if err != nil {
+		return err
+	}
+	if jenkins.Status.ToolsChecksum == checksum {
+		return nil
+	}
+
+	settings := toolsSettings{Tools: map[string][]v1alpha1.ToolInstallation{}}
+	for kind, installations := range Installations(jenkins.Spec.Tools) {
+		settings.Tools[kind] = append([]v1alpha1.ToolInstallation{}, installations...)
+	}
+
+	script := groovy.SettingsScript{Format: configureToolsFmt, DoneMarker: configuredMarker}
+	lines, err := script.Execute(t.jenkinsClient, settings)
+	if err != nil {
+		return stackerr.Wrap(err, "couldn't configure tools")
+	}
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, unknownVersionPrefix) {
+			continue
+		}
+		fields := strings.Split(strings.TrimPrefix(line, unknownVersionPrefix), "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		message := fmt.Sprintf("Version '%s' of %s installation '%s' isn't known by the update center", fields[2], fields[0], fields[1])
+		t.logger.V(log.VWarn).Info(message)
+		t.events.Emit(jenkins, event.TypeWarning, reasonToolVersionUnknown, message)
+	}
+	t.logger.Info("Global tool configuration has been applied")
+	t.events.Emit(jenkins, event.TypeNormal, reasonToolsConfigured, "Global tool configuration has been applied")
+
+	jenkins.Status.ToolsChecksum = checksum
+	return t.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// calculateChecksum returns the checksum of Jenkins.Spec.Tools, it's empty when tools aren't configured
+func calculateChecksum(tools *v1alpha1.Tools) (string, error) {
+	if tools == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureTools(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Tools: &v1alpha1.Tools{
+				JDK:    []v1alpha1.ToolInstallation{{Name: "jdk8", Home: "/usr/lib/jvm/java-8-openjdk-amd64"}},
+				Maven:  []v1alpha1.ToolInstallation{{Name: "maven3", Version: "3.6.0"}},
+				NodeJS: []v1alpha1.ToolInstallation{{Name: "node10", Version: "10.99.0"}},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	tools := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// tools are configured, the unknown version is reported
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `"jdk":[{"name":"jdk8","home":"/usr/lib/jvm/java-8-openjdk-amd64"}]`)
+		assert.Contains(t, settings, `"maven":[{"name":"maven3","version":"3.6.0"}]`)
+		assert.Contains(t, settings, `"gradle":[]`)
+		assert.Contains(t, settings, `"nodejs":[{"name":"node10","version":"10.99.0"}]`)
+		return unknownVersionPrefix + "nodejs\tnode10\t10.99.0\n" + configuredMarker, nil
+	})
+
+	err = tools.EnsureTools(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonToolVersionUnknown, reasonToolsConfigured}, events.reasons)
+	assert.Equal(t, "Version '10.99.0' of nodejs installation 'node10' isn't known by the update center", events.messages[0])
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, updatedJenkins.Status.ToolsChecksum)
+
+	// nothing changed
+	err = tools.EnsureTools(jenkins)
+	assert.NoError(t, err)
+
+	// the section removed from Jenkins CR
+	jenkins.Spec.Tools = nil
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		for _, kind := range []string{JDK, Maven, Gradle, NodeJS} {
+			assert.Contains(t, settings, fmt.Sprintf(`"%s":[]`, kind))
+		}
+		return configuredMarker, nil
+	})
+
+	err = tools.EnsureTools(jenkins)
+	assert.NoError(t, err)
+	assert.Empty(t, jenkins.Status.ToolsChecksum)
+
+	// nothing to remove
+	err = tools.EnsureTools(jenkins)
+	assert.NoError(t, err)
+
+	// the script failed
+	jenkins.Spec.Tools = &v1alpha1.Tools{Gradle: []v1alpha1.ToolInstallation{{Name: "gradle5", Version: "5.1"}}}
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.lang.ClassNotFoundException: hudson.plugins.gradle.GradleInstallation", nil)
+
+	err = tools.EnsureTools(jenkins)
+	assert.EqualError(t, err, "couldn't configure tools: java.lang.ClassNotFoundException: hudson.plugins.gradle.GradleInstallation")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,2 @@
+// Package urls implements downloading of user configuration groovy scripts from HTTP(S) URLs
+package urls
@@ -0,0 +1,251 @@
+package urls
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PollInterval is the interval of downloading scripts again, the server is asked only for changed scripts
+	PollInterval = 5 * time.Minute
+
+	// downloadTimeout is the timeout of every download
+	downloadTimeout = 30 * time.Second
+	// maxScriptsSize is the maximal size of scripts copied into the ConfigMap, the object stored in etcd can't exceed 1MiB
+	maxScriptsSize = 1000 * 1024
+
+	// reasonDownloadFailed is the event which informs the script can't be downloaded, previously downloaded script is kept
+	reasonDownloadFailed event.Reason = "URLConfigurationDownloadFailed"
+)
+
+// download is the last download of the script, its ETag and Last-Modified headers are sent with the next request
+// so the server responds with the script only when it changed
+type download struct {
+	content      []byte
+	etag         string
+	lastModified string
+	time         time.Time
+	err          error
+	// reported is set when the failure of the download has been reported
+	reported bool
+}
+
+// checksum returns the hex encoded SHA-256 checksum of the downloaded script
+func (d *download) checksum() string {
+	return fmt.Sprintf("%x", sha256.Sum256(d.content))
+}
+
+// downloads are shared by all reconciliation loops, they are keyed by the namespace and the name of Jenkins CR and the URL
+var downloads = struct {
+	sync.Mutex
+	entries map[string]*download
+}{entries: map[string]*download{}}
+
+// URLs downloads user configuration groovy scripts from HTTP(S) URLs into the ConfigMap created by operator
+type URLs struct {
+	k8sClient  k8s.Client
+	logger     logr.Logger
+	events     event.Recorder
+	httpClient *http.Client
+}
+
+// New creates URLs object
+func New(k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *URLs {
+	return &URLs{
+		k8sClient:  k8sClient,
+		logger:     logger,
+		events:     events,
+		httpClient: &http.Client{Timeout: downloadTimeout},
+	}
+}
+
+// Key returns the ConfigMap key of the script downloaded from the URL, it's the last segment of the URL path
+func Key(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return path.Base(path.Clean("/" + parsedURL.Path))
+}
+
+// Verify downloads scripts which are due to be downloaded again and returns messages about scripts which don't match
+// their checksum, scripts which can't be downloaded are reported by EnsureURLConfiguration
+func (u *URLs) Verify(jenkins *v1alpha1.Jenkins, now time.Time) []string {
+	var messages []string
+	for _, urlConfiguration := range jenkins.Spec.Configuration.URLs {
+		downloaded, err := u.download(jenkins, urlConfiguration, now)
+		if err != nil || len(urlConfiguration.Checksum) == 0 {
+			continue
+		}
+		if checksum := downloaded.checksum(); !strings.EqualFold(checksum, urlConfiguration.Checksum) {
+			messages = append(messages, fmt.Sprintf("url '%s': checksum mismatch, expected '%s', got '%s'",
+				urlConfiguration.URL, strings.ToLower(urlConfiguration.Checksum), checksum))
+		}
+	}
+	return messages
+}
+
+// EnsureURLConfiguration downloads scripts referenced by Jenkins.Spec.Configuration.URLs when the poll interval elapsed
+// and copies them into the ConfigMap created by operator, the script which can't be downloaded or doesn't match
+// its checksum is reported as the warning event and previously downloaded one is kept,
+// returns the duration after which scripts are due to be downloaded again, zero means they aren't downloaded
+func (u *URLs) EnsureURLConfiguration(jenkins *v1alpha1.Jenkins, now time.Time) (time.Duration, error) {
+	if len(jenkins.Spec.Configuration.URLs) == 0 {
+		return 0, nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: resources.GetUserConfigurationURLsConfigMapName(jenkins)}
+	if err := u.k8sClient.Get(context.TODO(), namespaceName, configMap); err != nil {
+		return 0, stackerr.WithStack(err)
+	}
+
+	scripts := map[string]string{}
+	size := 0
+	after := PollInterval
+	for _, urlConfiguration := range jenkins.Spec.Configuration.URLs {
+		key := Key(urlConfiguration.URL)
+		downloaded, err := u.download(jenkins, urlConfiguration, now)
+		if due := downloaded.time.Add(PollInterval).Sub(now); due < after {
+			after = due
+		}
+		if err == nil && len(urlConfiguration.Checksum) > 0 && !strings.EqualFold(downloaded.checksum(), urlConfiguration.Checksum) {
+			err = stackerr.Errorf("checksum mismatch, expected '%s', got '%s'", strings.ToLower(urlConfiguration.Checksum), downloaded.checksum())
+		}
+		if err != nil {
+			// the failure is reported once per download attempt
+			if !downloaded.reported {
+				downloaded.reported = true
+				u.logger.V(log.VWarn).Info(fmt.Sprintf("Script '%s' can't be downloaded: %s", urlConfiguration.URL, err))
+				u.events.Emitf(jenkins, event.TypeWarning, reasonDownloadFailed, "Script '%s' can't be downloaded: %s", urlConfiguration.URL, err)
+			}
+			if previous, ok := configMap.Data[key]; ok {
+				scripts[key] = previous
+				size += len(key) + len(previous)
+			}
+			continue
+		}
+		scripts[key] = string(downloaded.content)
+		size += len(key) + len(downloaded.content)
+	}
+	if after <= 0 {
+		after = time.Second
+	}
+	if size > maxScriptsSize {
+		u.logger.V(log.VWarn).Info(fmt.Sprintf("Downloaded scripts exceed %d bytes, config map '%s' isn't updated", maxScriptsSize, configMap.Name))
+		u.events.Emitf(jenkins, event.TypeWarning, reasonDownloadFailed, "Downloaded scripts exceed %d bytes", maxScriptsSize)
+		return after, nil
+	}
+
+	if len(configMap.Data) == 0 && len(scripts) == 0 || reflect.DeepEqual(configMap.Data, scripts) {
+		return after, nil
+	}
+	configMap.Data = scripts
+	if err := u.k8sClient.Update(context.TODO(), configMap); err != nil {
+		return 0, stackerr.WithStack(err)
+	}
+	u.logger.Info(fmt.Sprintf("Config map '%s' has been updated with downloaded scripts", configMap.Name))
+	return after, nil
+}
+
+// download returns the last download of the script, the script is downloaded again when the poll interval elapsed
+func (u *URLs) download(jenkins *v1alpha1.Jenkins, urlConfiguration v1alpha1.URLConfiguration, now time.Time) (*download, error) {
+	downloads.Lock()
+	defer downloads.Unlock()
+
+	key := fmt.Sprintf("%s/%s/%s", jenkins.Namespace, jenkins.Name, urlConfiguration.URL)
+	downloaded, ok := downloads.entries[key]
+	if ok && now.Sub(downloaded.time) < PollInterval {
+		return downloaded, downloaded.err
+	}
+	if !ok {
+		downloaded = &download{}
+		downloads.entries[key] = downloaded
+	}
+	downloaded.time, downloaded.reported = now, false
+	downloaded.err = u.get(jenkins, urlConfiguration, downloaded)
+	return downloaded, downloaded.err
+}
+
+// get sends the conditional request for the script and updates the download when the script changed
+func (u *URLs) get(jenkins *v1alpha1.Jenkins, urlConfiguration v1alpha1.URLConfiguration, downloaded *download) error {
+	request, err := http.NewRequest(http.MethodGet, urlConfiguration.URL, nil)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if len(urlConfiguration.HeadersSecret) > 0 {
+		secret := &corev1.Secret{}
+		namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: urlConfiguration.HeadersSecret}
+		if err := u.k8sClient.Get(context.TODO(), namespaceName, secret); err != nil {
+			return stackerr.WithStack(err)
+		}
+		var values []string
+		for name, value := range secret.Data {
+			values = append(values, string(value))
+			request.Header.Set(name, string(value))
+		}
+		owner := log.SecretOwner{Namespace: jenkins.Namespace, Name: jenkins.Name, Source: "url headers " + urlConfiguration.URL}
+		log.ReplaceSecrets(owner, values...)
+	}
+	if downloaded.content != nil {
+		if len(downloaded.etag) > 0 {
+			request.Header.Set("If-None-Match", downloaded.etag)
+		}
+		if len(downloaded.lastModified) > 0 {
+			request.Header.Set("If-Modified-Since", downloaded.lastModified)
+		}
+	}
+
+	response, err := u.httpClient.Do(request)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		if downloaded.content == nil {
+			return stackerr.Errorf("unexpected status '%s'", response.Status)
+		}
+		return nil
+	case http.StatusOK:
+	default:
+		return stackerr.Errorf("unexpected status '%s'", response.Status)
+	}
+
+	content, err := ioutil.ReadAll(io.LimitReader(response.Body, maxScriptsSize+1))
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if len(content) > maxScriptsSize {
+		return stackerr.Errorf("script exceeds %d bytes", maxScriptsSize)
+	}
+	downloaded.content = content
+	downloaded.etag = response.Header.Get("ETag")
+	downloaded.lastModified = response.Header.Get("Last-Modified")
+	u.logger.V(log.VDebug).Info(fmt.Sprintf("Script '%s' has been downloaded, checksum '%s'", urlConfiguration.URL, downloaded.checksum()))
+	return nil
+}
@@ -0,0 +1,152 @@
+package urls
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureURLConfiguration(t *testing.T) {
+	// given
+	script := "println 'shared'"
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path != "/shared.groovy" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(script)))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(script))
+	}))
+	defer server.Close()
+
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "ensure", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Configuration: v1alpha1.Configuration{
+				URLs: []v1alpha1.URLConfiguration{
+					{URL: server.URL + "/shared.groovy", HeadersSecret: "headers"},
+					{URL: server.URL + "/missing.groovy"},
+				},
+			},
+		},
+	}
+	headers := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "headers", Namespace: "default"},
+		Data:       map[string][]byte{"Authorization": []byte("Bearer token")},
+	}
+	fakeClient := fake.NewFakeClient(headers, resources.NewUserConfigurationURLsConfigMap(jenkins))
+	events := &fakeRecorder{}
+	urls := New(fakeClient, logf.ZapLogger(false), events)
+	now := time.Now()
+	getScripts := func() map[string]string {
+		configMap := &corev1.ConfigMap{}
+		namespaceName := types.NamespacedName{Namespace: "default", Name: resources.GetUserConfigurationURLsConfigMapName(jenkins)}
+		assert.NoError(t, fakeClient.Get(context.TODO(), namespaceName, configMap))
+		return configMap.Data
+	}
+
+	// when
+	after, err := urls.EnsureURLConfiguration(jenkins, now)
+
+	// then the script which can't be downloaded is reported
+	assert.NoError(t, err)
+	assert.Equal(t, PollInterval, after)
+	assert.Equal(t, map[string]string{"shared.groovy": script}, getScripts())
+	assert.Equal(t, "Bearer token", requests[0].Header.Get("Authorization"))
+	assert.Len(t, requests, 2)
+	assert.Equal(t, []event.Reason{reasonDownloadFailed}, events.reasons)
+
+	// scripts aren't downloaded again until the poll interval elapses
+	after, err = urls.EnsureURLConfiguration(jenkins, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, PollInterval-time.Minute, after)
+	assert.Len(t, requests, 2)
+	assert.Len(t, events.reasons, 1)
+
+	// the unchanged script isn't sent again by the server
+	_, err = urls.EnsureURLConfiguration(jenkins, now.Add(PollInterval))
+	assert.NoError(t, err)
+	assert.Len(t, requests, 4)
+	assert.NotEmpty(t, requests[2].Header.Get("If-None-Match"))
+	assert.Equal(t, map[string]string{"shared.groovy": script}, getScripts())
+
+	// the changed script is downloaded again
+	script = "println 'changed'"
+	_, err = urls.EnsureURLConfiguration(jenkins, now.Add(2*PollInterval))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"shared.groovy": script}, getScripts())
+}
+
+func TestVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("println 'shared'"))
+	}))
+	defer server.Close()
+	checksum := fmt.Sprintf("%x", sha256.Sum256([]byte("println 'shared'")))
+	urls := New(fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{})
+
+	t.Run("happy", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "verify-happy", Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				Configuration: v1alpha1.Configuration{
+					URLs: []v1alpha1.URLConfiguration{{URL: server.URL + "/shared.groovy", Checksum: checksum}},
+				},
+			},
+		}
+		assert.Empty(t, urls.Verify(jenkins, time.Now()))
+	})
+	t.Run("checksum mismatch", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "verify-mismatch", Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				Configuration: v1alpha1.Configuration{
+					URLs: []v1alpha1.URLConfiguration{{URL: server.URL + "/shared.groovy", Checksum: fmt.Sprintf("%064d", 0)}},
+				},
+			},
+		}
+		assert.Equal(t, []string{fmt.Sprintf("url '%s/shared.groovy': checksum mismatch, expected '%064d', got '%s'", server.URL, 0, checksum)},
+			urls.Verify(jenkins, time.Now()))
+	})
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "shared.groovy", Key("https://example.com/scripts/shared.groovy?raw=true"))
+	assert.Equal(t, "shared.groovy", Key("https://example.com/shared.groovy/"))
+	assert.Equal(t, "/", Key("https://example.com"))
+}
+
+type fakeRecorder struct {
+	reasons []event.Reason
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.reasons = append(r.reasons, reason)
+}
@@ -2,93 +2,1106 @@ package user
 
 import (
 	"context"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/credentials"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/libraries"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/seedjobs"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/tools"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/urls"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/views"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	stackerr "github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 )
 
-// Validate validates Jenkins CR Spec section
-func (r *ReconcileUserConfiguration) Validate(jenkins *v1alpha1.Jenkins) (bool, error) {
-	valid, err := r.validateSeedJobs(jenkins)
-	if !valid || err != nil {
-		return valid, err
+var (
+	windowsDriveRegexp = regexp.MustCompile(`^[a-zA-Z]:`)
+	// scpRepositoryURLRegexp matches scp-like ssh repository urls e.g. git@github.com:org/repo.git
+	scpRepositoryURLRegexp = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]\S*$`)
+	removedJobActions      = []string{"IGNORE", "DISABLE", "DELETE"}
+	removedViewActions     = []string{"IGNORE", "DELETE"}
+	tokenProviders         = []string{v1alpha1.TokenProviderGitHub, v1alpha1.TokenProviderGitLab}
+	// scanIntervalRegexp matches intervals supported by the periodic folder trigger e.g. 30m, 4h or 1d
+	scanIntervalRegexp = regexp.MustCompile(`^[1-9][0-9]*[mhd]$`)
+	// revisionRegexp matches full commit SHAs and tag names, optionally prefixed with refs/tags/
+	revisionRegexp = regexp.MustCompile(`^([0-9a-f]{40}|(refs/tags/)?[\w][\w./-]*)$`)
+	// checksumRegexp matches hex encoded SHA-256 checksums
+	checksumRegexp = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+	// configMapKeyRegexp matches valid ConfigMap keys
+	configMapKeyRegexp = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+	// envVarNameRegexp matches environment variable names which can be used in shell steps of builds
+	envVarNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	// viewNameUnsafeCharacters are characters Jenkins doesn't allow in names of views
+	viewNameUnsafeCharacters = `?*/\%!@#$^&|<>[]:;`
+)
+
+// privateKeyFormats are tried in order while parsing the seed job private key
+var privateKeyFormats = []struct {
+	name  string
+	parse func(block *pem.Block) (interface{}, error)
+}{
+	{name: "PKCS#1", parse: func(block *pem.Block) (interface{}, error) { return x509.ParsePKCS1PrivateKey(block.Bytes) }},
+	{name: "PKCS#8", parse: func(block *pem.Block) (interface{}, error) { return x509.ParsePKCS8PrivateKey(block.Bytes) }},
+	{name: "EC", parse: func(block *pem.Block) (interface{}, error) { return x509.ParseECPrivateKey(block.Bytes) }},
+	{name: "OpenSSH", parse: func(block *pem.Block) (interface{}, error) { return ssh.ParseRawPrivateKey(pem.EncodeToMemory(block)) }},
+}
+
+// Validate validates Jenkins CR Spec section, returns list of validation failures
+func (r *ReconcileUserConfiguration) Validate(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	messages, err := r.validateVault(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages, err = r.validateSeedJobs(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages, err = r.validateSSHKnownHosts(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages, err = r.validateGitConfiguration(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages, err = r.validateURLConfiguration(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages, err = r.validateCredentials(jenkins)
+	if len(messages) > 0 || err != nil {
+		return messages, err
+	}
+
+	messages = r.validateSharedLibraries(jenkins)
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	messages = r.validateTools(jenkins)
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	messages = r.validateGlobalEnv(jenkins)
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	return r.validateViews(jenkins), nil
+}
+
+// Warnings returns list of issues in Jenkins CR Spec section which don't block reconciliation
+func (r *ReconcileUserConfiguration) Warnings(jenkins *v1alpha1.Jenkins) []string {
+	var warnings []string
+	seedJobs := jenkins.Spec.SeedJobs
+	for i := range seedJobs {
+		for j := i + 1; j < len(seedJobs); j++ {
+			if len(seedJobs[i].RepositoryURL) > 0 &&
+				normalizeRepositoryURL(seedJobs[i].RepositoryURL) == normalizeRepositoryURL(seedJobs[j].RepositoryURL) &&
+				strings.TrimSpace(seedJobs[i].Targets) == strings.TrimSpace(seedJobs[j].Targets) {
+				warning := fmt.Sprintf("seed jobs '%s' and '%s' use the same repository '%s' and targets '%s'",
+					seedJobs[i].ID, seedJobs[j].ID, seedJobs[i].RepositoryURL, seedJobs[i].Targets)
+				r.logger.V(log.VWarn).Info(warning)
+				warnings = append(warnings, warning)
+			}
+		}
+		// seed jobs created before the revision was set can have the default branch set by the operator
+		if len(seedJobs[i].Revision) > 0 && len(seedJobs[i].RepositoryBranch) > 0 &&
+			seedJobs[i].RepositoryBranch != constants.DefaultSeedJobRepositoryBranch {
+			warning := fmt.Sprintf("seed job '%s' is pinned to revision '%s', repository branch '%s' is ignored",
+				seedJobs[i].ID, seedJobs[i].Revision, seedJobs[i].RepositoryBranch)
+			r.logger.V(log.VWarn).Info(warning)
+			warnings = append(warnings, warning)
+		}
+		if len(seedJobs[i].Revision) > 0 && hasSCMTriggers(seedJobs[i]) {
+			warning := fmt.Sprintf("seed job '%s' is pinned to revision '%s', its SCM polling and push triggers are disabled",
+				seedJobs[i].ID, seedJobs[i].Revision)
+			r.logger.V(log.VWarn).Info(warning)
+			warnings = append(warnings, warning)
+		}
+		if seedJobs[i].InsecureSkipHostKeyVerification {
+			warning := fmt.Sprintf("seed job '%s' skips host key verification of repository '%s'", seedJobs[i].ID, seedJobs[i].RepositoryURL)
+			r.logger.V(log.VWarn).Info(warning)
+			warnings = append(warnings, warning)
+		}
+	}
+	if git := jenkins.Spec.Configuration.Git; git != nil && git.InsecureSkipHostKeyVerification {
+		warning := fmt.Sprintf("git configuration skips host key verification of repository '%s'", git.RepositoryURL)
+		r.logger.V(log.VWarn).Info(warning)
+		warnings = append(warnings, warning)
+	}
+	return warnings
+}
+
+func (r *ReconcileUserConfiguration) validateGitConfiguration(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	git := jenkins.Spec.Configuration.Git
+	if git == nil {
+		return nil, nil
+	}
+
+	var messages []string
+	invalid := func(message string) {
+		r.logger.V(log.VWarn).Info(message)
+		messages = append(messages, fmt.Sprintf("git configuration: %s", message))
+	}
+
+	sshURL, err := parseRepositoryURL(git.RepositoryURL)
+	if err != nil {
+		invalid(fmt.Sprintf("invalid repository url '%s': %s", git.RepositoryURL, err))
+	}
+	if len(git.Revision) > 0 {
+		if !isValidRevision(git.Revision) {
+			invalid(fmt.Sprintf("invalid revision '%s', it has to be a tag or a full commit SHA", git.Revision))
+		}
+	} else if len(git.RepositoryBranch) > 0 && (!isValidRepositoryBranch(git.RepositoryBranch) || strings.HasPrefix(git.RepositoryBranch, "-")) {
+		invalid(fmt.Sprintf("invalid repository branch '%s'", git.RepositoryBranch))
+	}
+	if len(git.Path) > 0 && !isValidAdditionalClasspath(git.Path) {
+		invalid(fmt.Sprintf("invalid path '%s', it has to be a path relative to the repository root", git.Path))
+	}
+	if git.PollInterval != nil && git.PollInterval.Duration <= 0 {
+		invalid(fmt.Sprintf("invalid poll interval '%s', it has to be positive", git.PollInterval.Duration))
+	}
+
+	// validate host key verification of ssh repository url
+	if len(git.KnownHosts) > 0 {
+		if err := validateKnownHosts(git.KnownHosts); err != nil {
+			invalid(fmt.Sprintf("invalid known hosts: %s", err))
+		}
+	}
+	if err == nil && sshURL && len(git.KnownHosts) == 0 && !git.InsecureSkipHostKeyVerification {
+		invalid(fmt.Sprintf("known hosts can't be empty while using ssh repository url '%s', set insecureSkipHostKeyVerification to skip the verification", git.RepositoryURL))
+	}
+	if err == nil && !sshURL && (len(git.KnownHosts) > 0 || git.InsecureSkipHostKeyVerification) {
+		invalid("knownHosts and insecureSkipHostKeyVerification can be used only with ssh repository url")
+	}
+
+	// validate credentials, ssh repository url requires the private key
+	if len(git.CredentialsSecret) == 0 {
+		if err == nil && sshURL {
+			invalid(fmt.Sprintf("credentials secret can't be empty while using ssh repository url '%s'", git.RepositoryURL))
+		}
+		return messages, nil
+	}
+	credentialsSecret := &v1.Secret{}
+	namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: git.CredentialsSecret}
+	err = r.k8sClient.Get(context.TODO(), namespaceName, credentialsSecret)
+	if err != nil && apierrors.IsNotFound(err) {
+		invalid(fmt.Sprintf("secret '%s' not found", git.CredentialsSecret))
+		return messages, nil
+	} else if err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	if sshURL {
+		if err := validatePrivateKey(string(credentialsSecret.Data[v1alpha1.PrivateKeySecretKey]), ""); err != nil {
+			invalid(fmt.Sprintf("secret '%s' has invalid '%s' key: %s", git.CredentialsSecret, v1alpha1.PrivateKeySecretKey, err))
+		}
+	} else {
+		for _, key := range []string{v1alpha1.UsernameSecretKey, v1alpha1.PasswordSecretKey} {
+			if len(credentialsSecret.Data[key]) == 0 {
+				invalid(fmt.Sprintf("secret '%s' is missing '%s' key", git.CredentialsSecret, key))
+			}
+		}
+	}
+	return messages, nil
+}
+
+func (r *ReconcileUserConfiguration) validateURLConfiguration(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	var messages []string
+	keys := map[string]int{}
+	for _, urlConfiguration := range jenkins.Spec.Configuration.URLs {
+		invalid := func(message string) {
+			r.logger.V(log.VWarn).Info(message)
+			messages = append(messages, fmt.Sprintf("url '%s': %s", urlConfiguration.URL, message))
+		}
+
+		parsedURL, err := url.Parse(urlConfiguration.URL)
+		if err != nil {
+			invalid(fmt.Sprintf("invalid url: %s", err))
+			continue
+		}
+		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			invalid(fmt.Sprintf("unsupported scheme '%s', allowed values are http and https", parsedURL.Scheme))
+		}
+		if len(parsedURL.Host) == 0 {
+			invalid("host can't be empty")
+		}
+
+		// the script is stored under the last segment of the URL path
+		key := urls.Key(urlConfiguration.URL)
+		if !configMapKeyRegexp.MatchString(key) || !strings.HasSuffix(key, ".groovy") {
+			invalid(fmt.Sprintf("invalid script name '%s', the last segment of the url path has to be a .groovy file", key))
+		}
+		keys[key]++
+		if keys[key] == 2 {
+			invalid(fmt.Sprintf("duplicate script name '%s'", key))
+		}
+
+		if len(urlConfiguration.Checksum) > 0 && !checksumRegexp.MatchString(urlConfiguration.Checksum) {
+			invalid(fmt.Sprintf("invalid checksum '%s', it has to be hex encoded SHA-256 checksum", urlConfiguration.Checksum))
+		}
+		if len(urlConfiguration.HeadersSecret) > 0 {
+			headersSecret := &v1.Secret{}
+			namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: urlConfiguration.HeadersSecret}
+			err := r.k8sClient.Get(context.TODO(), namespaceName, headersSecret)
+			if err != nil && apierrors.IsNotFound(err) {
+				invalid(fmt.Sprintf("secret '%s' not found", urlConfiguration.HeadersSecret))
+			} else if err != nil {
+				return nil, stackerr.WithStack(err)
+			}
+		}
+	}
+	if len(messages) > 0 {
+		return messages, nil
+	}
+
+	// downloaded scripts have to match their checksums
+	for _, message := range urls.New(r.k8sClient, r.logger, r.events).Verify(jenkins, time.Now()) {
+		r.logger.V(log.VWarn).Info(message)
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (r *ReconcileUserConfiguration) validateCredentials(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	var messages []string
+	ids := map[string]int{}
+	for _, jenkinsCredentials := range jenkins.Spec.Credentials {
+		invalid := func(message string) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("credentials '%s': %s", jenkinsCredentials.ID, message))
+			messages = append(messages, fmt.Sprintf("credentials '%s': %s", jenkinsCredentials.ID, message))
+		}
+
+		if len(jenkinsCredentials.ID) == 0 {
+			invalid("id can't be empty")
+		}
+		ids[jenkinsCredentials.ID]++
+		if ids[jenkinsCredentials.ID] == 2 {
+			invalid("duplicate id")
+		}
+		requiredKeys, ok := credentials.RequiredKeys(jenkinsCredentials.Type)
+		if !ok {
+			invalid(fmt.Sprintf("unsupported type '%s', allowed values are %s", jenkinsCredentials.Type, strings.Join(credentials.Types(), ", ")))
+			continue
+		}
+		if len(jenkinsCredentials.SecretRef.Name) == 0 {
+			invalid("secretRef.name can't be empty")
+			continue
+		}
+		// values stored in Vault are read when credentials are synchronized
+		if vault.IsReference(jenkinsCredentials.SecretRef.Name) {
+			if message := validateVaultReference(jenkins, jenkinsCredentials.SecretRef.Name); len(message) > 0 {
+				invalid(message)
+			}
+			continue
+		}
+
+		secret := &v1.Secret{}
+		namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: jenkinsCredentials.SecretRef.Name}
+		err := r.k8sClient.Get(context.TODO(), namespaceName, secret)
+		if err != nil && apierrors.IsNotFound(err) {
+			invalid(fmt.Sprintf("secret '%s' not found", jenkinsCredentials.SecretRef.Name))
+			continue
+		} else if err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		var missingKeys []string
+		for _, key := range requiredKeys {
+			if len(secret.Data[key]) == 0 {
+				missingKeys = append(missingKeys, key)
+			}
+		}
+		if len(missingKeys) > 0 {
+			invalid(fmt.Sprintf("secret '%s' doesn't contain %s keys", jenkinsCredentials.SecretRef.Name, strings.Join(missingKeys, ", ")))
+			continue
+		}
+		if jenkinsCredentials.Type == v1alpha1.CredentialsTypeBasicSSHUserPrivateKey {
+			err := validatePrivateKey(string(secret.Data[credentials.PrivateKeyKey]), string(secret.Data[credentials.PassphraseKey]))
+			if err != nil {
+				invalid(fmt.Sprintf("private key in secret '%s' is invalid: %s", jenkinsCredentials.SecretRef.Name, err))
+			}
+		}
+	}
+	return messages, nil
+}
+
+func (r *ReconcileUserConfiguration) validateSharedLibraries(jenkins *v1alpha1.Jenkins) []string {
+	var messages []string
+	names := map[string]int{}
+	for _, library := range jenkins.Spec.SharedLibraries {
+		invalid := func(message string) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("shared library '%s': %s", library.Name, message))
+			messages = append(messages, fmt.Sprintf("shared library '%s': %s", library.Name, message))
+		}
+
+		// pipelines select the version by name@version
+		if len(library.Name) == 0 || strings.ContainsAny(library.Name, "@ \t\n") {
+			invalid("name can't be empty and can't contain @ and whitespaces")
+		}
+		names[library.Name]++
+		if names[library.Name] == 2 {
+			invalid("duplicate name")
+		}
+		if len(library.DefaultVersion) > 0 && !isValidRepositoryBranch(library.DefaultVersion) {
+			invalid(fmt.Sprintf("invalid default version '%s'", library.DefaultVersion))
+		}
+		if len(library.DefaultVersion) == 0 && library.Implicit {
+			invalid("default version can't be empty while the library is implicit")
+		}
+		if len(library.DefaultVersion) == 0 && !libraries.AllowVersionOverride(library) {
+			invalid("default version can't be empty while the version can't be overridden")
+		}
+		if library.Retrieval.Git == nil {
+			invalid("retrieval.git has to be set")
+			continue
+		}
+		if _, err := parseRepositoryURL(library.Retrieval.Git.RepositoryURL); err != nil {
+			invalid(fmt.Sprintf("invalid repository url '%s': %s", library.Retrieval.Git.RepositoryURL, err))
+		}
+	}
+	return messages
+}
+
+func (r *ReconcileUserConfiguration) validateTools(jenkins *v1alpha1.Jenkins) []string {
+	var messages []string
+	installations := tools.Installations(jenkins.Spec.Tools)
+	for _, kind := range []string{tools.JDK, tools.Maven, tools.Gradle, tools.NodeJS} {
+		names := map[string]int{}
+		for _, installation := range installations[kind] {
+			invalid := func(message string) {
+				r.logger.V(log.VWarn).Info(fmt.Sprintf("%s installation '%s': %s", kind, installation.Name, message))
+				messages = append(messages, fmt.Sprintf("%s installation '%s': %s", kind, installation.Name, message))
+			}
+
+			if len(strings.TrimSpace(installation.Name)) == 0 {
+				invalid("name can't be empty")
+			}
+			names[installation.Name]++
+			if names[installation.Name] == 2 {
+				invalid("duplicate name")
+			}
+			if (len(installation.Version) == 0) == (len(installation.Home) == 0) {
+				invalid("exactly one of version and home has to be set")
+			}
+			if strings.ContainsAny(installation.Version, " \t\n") {
+				invalid(fmt.Sprintf("invalid version '%s'", installation.Version))
+			}
+		}
+	}
+	return messages
+}
+
+func (r *ReconcileUserConfiguration) validateGlobalEnv(jenkins *v1alpha1.Jenkins) []string {
+	var names []string
+	for name := range jenkins.Spec.Master.GlobalEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var messages []string
+	for _, name := range names {
+		if !envVarNameRegexp.MatchString(name) {
+			message := fmt.Sprintf("global environment variable '%s': invalid name, it has to match '%s'", name, envVarNameRegexp)
+			r.logger.V(log.VWarn).Info(message)
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+func (r *ReconcileUserConfiguration) validateViews(jenkins *v1alpha1.Jenkins) []string {
+	var messages []string
+	names := map[string]int{}
+	for _, view := range jenkins.Spec.Views {
+		invalid := func(message string) {
+			r.logger.V(log.VWarn).Info(fmt.Sprintf("view '%s': %s", view.Name, message))
+			messages = append(messages, fmt.Sprintf("view '%s': %s", view.Name, message))
+		}
+
+		// Jenkins rejects names with these characters
+		if len(strings.TrimSpace(view.Name)) == 0 || strings.ContainsAny(view.Name, viewNameUnsafeCharacters) {
+			invalid(fmt.Sprintf("name can't be empty and can't contain any of '%s'", viewNameUnsafeCharacters))
+		}
+		names[view.Name]++
+		if names[view.Name] == 2 {
+			invalid("duplicate name")
+		}
+		if views.Type(view) != v1alpha1.ViewTypeList {
+			invalid(fmt.Sprintf("unsupported type '%s', supported types: %s", view.Type, v1alpha1.ViewTypeList))
+		}
+		if len(view.IncludeRegex) == 0 && len(view.JobNames) == 0 {
+			invalid("includeRegex or jobNames has to be set")
+		}
+		for _, jobName := range view.JobNames {
+			if len(strings.TrimSpace(jobName)) == 0 {
+				invalid("job names can't be empty")
+				break
+			}
+		}
+	}
+	return messages
+}
+
+func (r *ReconcileUserConfiguration) validateVault(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	vaultSpec := jenkins.Spec.Vault
+	if vaultSpec == nil {
+		return nil, nil
+	}
+
+	var messages []string
+	invalid := func(message string) {
+		r.logger.V(log.VWarn).Info(message)
+		messages = append(messages, fmt.Sprintf("vault: %s", message))
+	}
+
+	if err := vault.ValidateAddress(vaultSpec.Address); err != nil {
+		invalid(fmt.Sprintf("invalid address '%s': %s", vaultSpec.Address, err))
+	}
+	if vaultSpec.Auth.Kubernetes == nil {
+		invalid("auth.kubernetes has to be set")
+	} else if len(strings.TrimSpace(vaultSpec.Auth.Kubernetes.Role)) == 0 {
+		invalid("auth.kubernetes.role can't be empty")
+	}
+	if vaultSpec.RefreshInterval != nil && vaultSpec.RefreshInterval.Duration <= 0 {
+		invalid(fmt.Sprintf("invalid refresh interval '%s', it has to be positive", vaultSpec.RefreshInterval.Duration))
+	}
+	if vaultSpec.TLS != nil && vaultSpec.TLS.CACertSecretRef != nil {
+		caCertSecretRef := vaultSpec.TLS.CACertSecretRef
+		secret := &v1.Secret{}
+		namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: caCertSecretRef.Name}
+		err := r.k8sClient.Get(context.TODO(), namespaceName, secret)
+		if err != nil && apierrors.IsNotFound(err) {
+			invalid(fmt.Sprintf("secret '%s' not found", caCertSecretRef.Name))
+		} else if err != nil {
+			return nil, stackerr.WithStack(err)
+		} else if !x509.NewCertPool().AppendCertsFromPEM(secret.Data[caCertSecretRef.Key]) {
+			invalid(fmt.Sprintf("secret '%s' key '%s' doesn't contain PEM encoded certificates", caCertSecretRef.Name, caCertSecretRef.Key))
+		}
+	}
+	return messages, nil
+}
+
+func (r *ReconcileUserConfiguration) validateSSHKnownHosts(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	sshKnownHosts := jenkins.Spec.Master.SSHKnownHosts
+	if sshKnownHosts == nil {
+		return nil, nil
+	}
+
+	var messages []string
+	invalid := func(message string) {
+		r.logger.V(log.VWarn).Info(message)
+		messages = append(messages, fmt.Sprintf("ssh known hosts: %s", message))
+	}
+
+	var knownHosts string
+	switch {
+	case sshKnownHosts.ConfigMapKeyRef != nil && sshKnownHosts.SecretKeyRef != nil:
+		invalid("only one of configMapKeyRef and secretKeyRef can be set")
+		return messages, nil
+	case sshKnownHosts.ConfigMapKeyRef != nil:
+		configMap := &v1.ConfigMap{}
+		namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: sshKnownHosts.ConfigMapKeyRef.Name}
+		err := r.k8sClient.Get(context.TODO(), namespaceName, configMap)
+		if err != nil && apierrors.IsNotFound(err) {
+			invalid(fmt.Sprintf("config map '%s' not found", sshKnownHosts.ConfigMapKeyRef.Name))
+			return messages, nil
+		} else if err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		knownHosts = configMap.Data[sshKnownHosts.ConfigMapKeyRef.Key]
+	case sshKnownHosts.SecretKeyRef != nil && vault.IsReference(sshKnownHosts.SecretKeyRef.Name):
+		// known hosts stored in Vault are read when seed jobs are built
+		if message := validateVaultSelector(jenkins, sshKnownHosts.SecretKeyRef); len(message) > 0 {
+			invalid(message)
+		}
+		return messages, nil
+	case sshKnownHosts.SecretKeyRef != nil:
+		secret := &v1.Secret{}
+		namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: sshKnownHosts.SecretKeyRef.Name}
+		err := r.k8sClient.Get(context.TODO(), namespaceName, secret)
+		if err != nil && apierrors.IsNotFound(err) {
+			invalid(fmt.Sprintf("secret '%s' not found", sshKnownHosts.SecretKeyRef.Name))
+			return messages, nil
+		} else if err != nil {
+			return nil, stackerr.WithStack(err)
+		}
+		knownHosts = string(secret.Data[sshKnownHosts.SecretKeyRef.Key])
+	default:
+		invalid("one of configMapKeyRef and secretKeyRef has to be set")
+		return messages, nil
 	}
 
-	return true, nil
+	if len(strings.TrimSpace(knownHosts)) == 0 {
+		invalid("known hosts are empty")
+	} else if err := validateKnownHosts(knownHosts); err != nil {
+		invalid(fmt.Sprintf("invalid known hosts: %s", err))
+	}
+	return messages, nil
 }
 
-func (r *ReconcileUserConfiguration) validateSeedJobs(jenkins *v1alpha1.Jenkins) (bool, error) {
-	valid := true
+func (r *ReconcileUserConfiguration) validateSeedJobs(jenkins *v1alpha1.Jenkins) ([]string, error) {
+	var messages []string
+
+	// validate seed job ids are unique, jobs with the same id overwrite each other in Jenkins
+	ids := map[string]int{}
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		ids[seedJob.ID]++
+		if ids[seedJob.ID] == 2 {
+			message := fmt.Sprintf("duplicate seed job id '%s'", seedJob.ID)
+			r.logger.V(log.VWarn).Info(message)
+			messages = append(messages, message)
+		}
+	}
+
+	// validate default agent label expression
+	if len(jenkins.Spec.SeedJobAgent) > 0 && !isValidLabelExpression(jenkins.Spec.SeedJobAgent) {
+		message := fmt.Sprintf("invalid seed job agent label '%s'", jenkins.Spec.SeedJobAgent)
+		r.logger.V(log.VWarn).Info(message)
+		messages = append(messages, message)
+	}
+
 	if jenkins.Spec.SeedJobs != nil {
 		for _, seedJob := range jenkins.Spec.SeedJobs {
 			logger := r.logger.WithValues("seedJob", fmt.Sprintf("%+v", seedJob)).V(log.VWarn)
+			invalid := func(message string) {
+				logger.Info(message)
+				messages = append(messages, fmt.Sprintf("seed job '%s': %s", seedJob.ID, message))
+			}
+			failures := len(messages)
+			credentials := repositoryCredentials{}
+			// values referenced with the vault: scheme are read when the seed job is built, only references are validated
+			vaultReferences := false
+			vaultReference := func(selector *v1.SecretKeySelector) bool {
+				if selector == nil || !vault.IsReference(selector.Name) {
+					return false
+				}
+				if message := validateVaultSelector(jenkins, selector); len(message) > 0 {
+					invalid(message)
+				}
+				vaultReferences = true
+				return true
+			}
 
 			// validate seed job id is not empty
 			if len(seedJob.ID) == 0 {
-				logger.Info("seed job id can't be empty")
-				valid = false
+				invalid("seed job id can't be empty")
+			}
+
+			// validate seed job mode, Job DSL targets are required only in Job DSL mode
+			switch seedJob.Mode {
+			case "", v1alpha1.SeedJobModeJobDSL:
+				if len(strings.TrimSpace(seedJob.Targets)) == 0 {
+					invalid("seed job targets can't be empty")
+				}
+			case v1alpha1.SeedJobModeMultibranch, v1alpha1.SeedJobModeOrganizationFolder:
+			default:
+				invalid(fmt.Sprintf("invalid mode '%s', allowed values are '%s', '%s' and '%s'",
+					seedJob.Mode, v1alpha1.SeedJobModeJobDSL, v1alpha1.SeedJobModeMultibranch, v1alpha1.SeedJobModeOrganizationFolder))
+			}
+
+			// validate repository branch, it can be a branch name, a tag ref or a full commit SHA,
+			// the branch isn't needed when the seed job is pinned to a revision
+			if len(seedJob.Revision) > 0 {
+				if seedJob.Mode == v1alpha1.SeedJobModeMultibranch || seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+					invalid(fmt.Sprintf("revision is supported only in '%s' mode", v1alpha1.SeedJobModeJobDSL))
+				} else if !isValidRevision(seedJob.Revision) {
+					invalid(fmt.Sprintf("invalid revision '%s', it has to be a tag or a full commit SHA", seedJob.Revision))
+				}
+			} else if seedJob.Mode != v1alpha1.SeedJobModeOrganizationFolder && !isValidRepositoryBranch(seedJob.RepositoryBranch) {
+				invalid(fmt.Sprintf("invalid repository branch '%s'", seedJob.RepositoryBranch))
+			}
+
+			// validate garbage collection policy
+			if len(seedJob.GCPolicy) > 0 && seedJob.GCPolicy != v1alpha1.SeedJobGCPolicyOrphan && seedJob.GCPolicy != v1alpha1.SeedJobGCPolicyDelete {
+				invalid(fmt.Sprintf("invalid gcPolicy '%s', allowed values are '%s' and '%s'",
+					seedJob.GCPolicy, v1alpha1.SeedJobGCPolicyOrphan, v1alpha1.SeedJobGCPolicyDelete))
+			}
+
+			// validate agent label expression
+			if len(seedJob.AgentLabel) > 0 && !isValidLabelExpression(seedJob.AgentLabel) {
+				invalid(fmt.Sprintf("invalid agent label '%s'", seedJob.AgentLabel))
+			}
+
+			// validate sandbox, Job DSL doesn't allow additional classpath in the sandbox
+			if seedJob.Sandbox {
+				if seedJob.Mode == v1alpha1.SeedJobModeMultibranch || seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+					invalid(fmt.Sprintf("sandbox is supported only in '%s' mode", v1alpha1.SeedJobModeJobDSL))
+				}
+				if len(seedJob.AdditionalClasspath) > 0 {
+					invalid("additional classpath can't be used with sandbox")
+				}
 			}
 
-			// validate repository url match private key
-			if strings.Contains(seedJob.RepositoryURL, "git@") {
-				if seedJob.PrivateKey.SecretKeyRef == nil {
-					logger.Info("private key can't be empty while using ssh repository url")
-					valid = false
+			// validate additional classpath entries stay inside the workspace
+			for _, classpath := range strings.Split(seedJob.AdditionalClasspath, "\n") {
+				if !isValidAdditionalClasspath(classpath) {
+					invalid(fmt.Sprintf("invalid additional classpath '%s', it has to be a path relative to the workspace", classpath))
 				}
 			}
 
-			// validate private key from secret
-			if seedJob.PrivateKey.SecretKeyRef != nil {
+			// validate Job DSL settings
+			if !isOneOf(seedJob.JobDSLSettings.RemovedJobAction, removedJobActions) {
+				invalid(fmt.Sprintf("invalid removedJobAction '%s', allowed values are %s",
+					seedJob.JobDSLSettings.RemovedJobAction, strings.Join(removedJobActions, ", ")))
+			}
+			if !isOneOf(seedJob.JobDSLSettings.RemovedViewAction, removedViewActions) {
+				invalid(fmt.Sprintf("invalid removedViewAction '%s', allowed values are %s",
+					seedJob.JobDSLSettings.RemovedViewAction, strings.Join(removedViewActions, ", ")))
+			}
+
+			// validate build timeout
+			if seedJob.BuildTimeout != nil && seedJob.BuildTimeout.Duration <= 0 {
+				invalid(fmt.Sprintf("invalid build timeout '%s', it has to be positive", seedJob.BuildTimeout.Duration))
+			}
+
+			// validate re-run interval
+			if seedJob.ReRunInterval != nil && seedJob.ReRunInterval.Duration <= 0 {
+				invalid(fmt.Sprintf("invalid reRunInterval '%s', it has to be positive", seedJob.ReRunInterval.Duration))
+			}
+
+			// validate retry policy
+			if seedJob.Retries != nil && *seedJob.Retries < 0 {
+				invalid(fmt.Sprintf("invalid retries '%d', it can't be negative", *seedJob.Retries))
+			}
+			if seedJob.RetryBackoff != nil && seedJob.RetryBackoff.Duration < 0 {
+				invalid(fmt.Sprintf("invalid retry backoff '%s', it can't be negative", seedJob.RetryBackoff.Duration))
+			}
+
+			// validate build retention
+			if seedJob.BuildRetention != nil && *seedJob.BuildRetention <= 0 {
+				invalid(fmt.Sprintf("invalid build retention '%d', it has to be positive", *seedJob.BuildRetention))
+			}
+
+			// validate clone options
+			if seedJob.CloneDepth != nil {
+				if *seedJob.CloneDepth <= 0 {
+					invalid(fmt.Sprintf("invalid clone depth '%d', it has to be positive", *seedJob.CloneDepth))
+				}
+				if seedJob.Shallow != nil && !*seedJob.Shallow {
+					invalid("cloneDepth can be used only with shallow clone")
+				}
+			}
+			if len(seedJob.ReferenceRepository) > 0 && !path.IsAbs(seedJob.ReferenceRepository) {
+				invalid(fmt.Sprintf("invalid reference repository '%s', it has to be an absolute path", seedJob.ReferenceRepository))
+			}
+
+			// validate SCM polling and periodic build schedules
+			if len(seedJob.PollSCM) > 0 {
+				if err := validateCronSpec(seedJob.PollSCM); err != nil {
+					invalid(fmt.Sprintf("invalid pollSCM '%s': %s", seedJob.PollSCM, err))
+				}
+			}
+			if len(seedJob.BuildPeriodically) > 0 {
+				if err := validateCronSpec(seedJob.BuildPeriodically); err != nil {
+					invalid(fmt.Sprintf("invalid buildPeriodically '%s': %s", seedJob.BuildPeriodically, err))
+				}
+			}
+
+			// validate repository url and match it with private key, the organization folder scans
+			// repositories of the GitHub organization using username and password or token
+			if seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+				settings := seedJob.OrganizationFolderSettings
+				if len(strings.TrimSpace(settings.Organization)) == 0 {
+					invalid("organization can't be empty")
+				}
+				if len(settings.ScanInterval) > 0 && !scanIntervalRegexp.MatchString(settings.ScanInterval) {
+					invalid(fmt.Sprintf("invalid scan interval '%s', it has to be a number followed by m, h or d", settings.ScanInterval))
+				}
+				if seedJob.UsernamePassword.SecretRef == nil && seedJob.Token.SecretKeyRef == nil {
+					invalid("usernamePassword or token is required to scan the organization")
+				}
+				if seedJob.PrivateKey.SecretKeyRef != nil {
+					invalid("private key can't be used to scan the organization")
+				}
+				if seedJob.Token.Provider == v1alpha1.TokenProviderGitLab {
+					invalid("GitLab token can't be used to scan the GitHub organization")
+				}
+			} else if sshURL, err := parseRepositoryURL(seedJob.RepositoryURL); err != nil {
+				invalid(fmt.Sprintf("invalid repository url '%s': %s", seedJob.RepositoryURL, err))
+			} else if sshURL && seedJob.PrivateKey.SecretKeyRef == nil {
+				invalid(fmt.Sprintf("private key can't be empty while using ssh repository url '%s'", seedJob.RepositoryURL))
+			} else if !sshURL && seedJob.PrivateKey.SecretKeyRef != nil {
+				invalid(fmt.Sprintf("private key can be used only with ssh repository url, got '%s'", seedJob.RepositoryURL))
+			} else if sshURL && seedJob.UsernamePassword.SecretRef != nil {
+				invalid(fmt.Sprintf("username and password can't be used with ssh repository url '%s'", seedJob.RepositoryURL))
+			} else if sshURL && seedJob.Token.SecretKeyRef != nil {
+				invalid(fmt.Sprintf("token can't be used with ssh repository url '%s'", seedJob.RepositoryURL))
+			}
+			if countCredentials(seedJob) > 1 {
+				invalid("only one of privateKey, usernamePassword and token can be set")
+			}
+
+			// validate submodules, private submodules of https repositories can't be cloned without credentials
+			if seedJob.Submodules.Enabled {
+				if seedJob.Mode == v1alpha1.SeedJobModeMultibranch || seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+					invalid(fmt.Sprintf("submodules are supported only in '%s' mode", v1alpha1.SeedJobModeJobDSL))
+				} else if sshURL, err := parseRepositoryURL(seedJob.RepositoryURL); err == nil && !sshURL && countCredentials(seedJob) == 0 {
+					invalid(fmt.Sprintf("submodules require usernamePassword or token while using https repository url '%s'", seedJob.RepositoryURL))
+				}
+			} else if seedJob.Submodules.Recursive || seedJob.Submodules.ParentCredentials {
+				invalid("submodules settings can be used only with submodules enabled")
+			}
+
+			// validate known hosts and host key verification
+			if len(seedJob.KnownHosts) > 0 {
+				if err := validateKnownHosts(seedJob.KnownHosts); err != nil {
+					invalid(fmt.Sprintf("invalid known hosts: %s", err))
+				}
+			}
+			if seedJob.InsecureSkipHostKeyVerification {
+				if sshURL, err := parseRepositoryURL(seedJob.RepositoryURL); err == nil && !sshURL {
+					invalid("insecureSkipHostKeyVerification can be used only with ssh repository url")
+				}
+			}
+
+			// validate private key from secret, the key can't be checked when it or its passphrase is stored in Vault
+			privateKeyInVault := vaultReference(seedJob.PrivateKey.SecretKeyRef)
+			passphraseInVault := vaultReference(seedJob.PrivateKey.PassphraseSecretKeyRef)
+			if seedJob.PrivateKey.SecretKeyRef != nil && !privateKeyInVault && !passphraseInVault {
 				deployKeySecret := &v1.Secret{}
 				namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.PrivateKey.SecretKeyRef.Name}
 				err := r.k8sClient.Get(context.TODO(), namespaceName, deployKeySecret)
 				if err != nil && apierrors.IsNotFound(err) {
-					logger.Info("secret not found")
-					valid = false
+					invalid(fmt.Sprintf("secret '%s' not found", seedJob.PrivateKey.SecretKeyRef.Name))
 				} else if err != nil {
-					return false, stackerr.WithStack(err)
+					return nil, stackerr.WithStack(err)
 				}
 
 				privateKey := string(deployKeySecret.Data[seedJob.PrivateKey.SecretKeyRef.Key])
 				if privateKey == "" {
-					logger.Info("private key is empty")
-					valid = false
+					invalid("private key is empty")
+				}
+
+				passphrase := ""
+				if seedJob.PrivateKey.PassphraseSecretKeyRef != nil {
+					passphraseSecret := &v1.Secret{}
+					namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.PrivateKey.PassphraseSecretKeyRef.Name}
+					err := r.k8sClient.Get(context.TODO(), namespaceName, passphraseSecret)
+					if err != nil && apierrors.IsNotFound(err) {
+						invalid(fmt.Sprintf("secret '%s' not found", seedJob.PrivateKey.PassphraseSecretKeyRef.Name))
+					} else if err != nil {
+						return nil, stackerr.WithStack(err)
+					} else if passphrase = string(passphraseSecret.Data[seedJob.PrivateKey.PassphraseSecretKeyRef.Key]); passphrase == "" {
+						invalid("private key passphrase is empty")
+					}
+				}
+
+				if err := validatePrivateKey(privateKey, passphrase); err != nil {
+					invalid(fmt.Sprintf("private key is invalid: %s", err))
+				}
+				credentials.privateKey, credentials.passphrase = privateKey, passphrase
+			}
+
+			// validate username and password from secret
+			if seedJob.UsernamePassword.SecretRef != nil && vault.IsReference(seedJob.UsernamePassword.SecretRef.Name) {
+				if message := validateVaultReference(jenkins, seedJob.UsernamePassword.SecretRef.Name); len(message) > 0 {
+					invalid(message)
+				}
+				vaultReferences = true
+			} else if seedJob.UsernamePassword.SecretRef != nil {
+				credentialsSecret := &v1.Secret{}
+				namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.UsernamePassword.SecretRef.Name}
+				err := r.k8sClient.Get(context.TODO(), namespaceName, credentialsSecret)
+				if err != nil && apierrors.IsNotFound(err) {
+					invalid(fmt.Sprintf("secret '%s' not found", seedJob.UsernamePassword.SecretRef.Name))
+				} else if err != nil {
+					return nil, stackerr.WithStack(err)
+				} else {
+					for _, key := range []string{v1alpha1.UsernameSecretKey, v1alpha1.PasswordSecretKey} {
+						if len(credentialsSecret.Data[key]) == 0 {
+							invalid(fmt.Sprintf("secret '%s' is missing '%s' key", seedJob.UsernamePassword.SecretRef.Name, key))
+						}
+					}
+					credentials.username = string(credentialsSecret.Data[v1alpha1.UsernameSecretKey])
+					credentials.password = string(credentialsSecret.Data[v1alpha1.PasswordSecretKey])
+				}
+			}
+
+			// validate token from secret
+			if seedJob.Token.SecretKeyRef != nil {
+				if !isOneOf(seedJob.Token.Provider, tokenProviders) {
+					invalid(fmt.Sprintf("invalid token provider '%s', allowed values are %s",
+						seedJob.Token.Provider, strings.Join(tokenProviders, ", ")))
+				}
+				if !vaultReference(seedJob.Token.SecretKeyRef) {
+					tokenSecret := &v1.Secret{}
+					namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.Token.SecretKeyRef.Name}
+					err := r.k8sClient.Get(context.TODO(), namespaceName, tokenSecret)
+					if err != nil && apierrors.IsNotFound(err) {
+						invalid(fmt.Sprintf("secret '%s' not found", seedJob.Token.SecretKeyRef.Name))
+					} else if err != nil {
+						return nil, stackerr.WithStack(err)
+					} else if len(tokenSecret.Data[seedJob.Token.SecretKeyRef.Key]) == 0 {
+						invalid("token is empty")
+					} else {
+						credentials.username = seedjobs.TokenUsername(seedJob.Token.Provider)
+						credentials.password = string(tokenSecret.Data[seedJob.Token.SecretKeyRef.Key])
+					}
+				}
+			}
+
+			// validate Gerrit trigger
+			if seedJob.GerritTrigger != nil {
+				if len(strings.TrimSpace(seedJob.GerritTrigger.ServerName)) == 0 {
+					invalid("Gerrit server name can't be empty")
+				}
+				if len(strings.TrimSpace(seedJob.GerritTrigger.Project)) == 0 {
+					invalid("Gerrit project can't be empty")
 				}
+			}
 
-				if err := validatePrivateKey(privateKey); err != nil {
-					logger.Info(fmt.Sprintf("private key is invalid: %s", err))
-					valid = false
+			// validate GitHub webhook management
+			if seedJob.ManageWebhook {
+				if !seedJob.GitHubPushTrigger {
+					invalid("manageWebhook requires githubPushTrigger")
+				}
+				if seedJob.GitHubAPIToken.SecretKeyRef == nil {
+					invalid("GitHub API token can't be empty while managing webhook")
+				} else if !vaultReference(seedJob.GitHubAPIToken.SecretKeyRef) {
+					tokenSecret := &v1.Secret{}
+					namespaceName := types.NamespacedName{Namespace: jenkins.Namespace, Name: seedJob.GitHubAPIToken.SecretKeyRef.Name}
+					err := r.k8sClient.Get(context.TODO(), namespaceName, tokenSecret)
+					if err != nil && apierrors.IsNotFound(err) {
+						invalid(fmt.Sprintf("secret '%s' not found", seedJob.GitHubAPIToken.SecretKeyRef.Name))
+					} else if err != nil {
+						return nil, stackerr.WithStack(err)
+					} else if len(tokenSecret.Data[seedJob.GitHubAPIToken.SecretKeyRef.Key]) == 0 {
+						invalid("GitHub API token is empty")
+					}
 				}
 			}
+
+			// validate the repository can be read with the seed job credentials, the check is done only
+			// when the rest of the seed job is valid to not report the same issue twice and it's skipped
+			// when credentials are stored in Vault, the result is cached until the credentials change or the check interval elapses
+			if seedJob.ValidateConnectivity {
+				if seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+					invalid(fmt.Sprintf("validateConnectivity isn't supported in '%s' mode", v1alpha1.SeedJobModeOrganizationFolder))
+				} else if len(messages) == failures && !vaultReferences {
+					if err := repositoryConnectivity(jenkins, seedJob.RepositoryURL, credentials, time.Now(), checkRepositoryConnectivity); err != nil {
+						invalid(fmt.Sprintf("repository '%s' is not reachable: %s", seedJob.RepositoryURL, err))
+					}
+				}
+			}
+		}
+	}
+	return messages, nil
+}
+
+// validateVaultReference returns why the vault: reference of the secret with predefined keys is invalid,
+// Vault isn't contacted, values are checked when they are read
+func validateVaultReference(jenkins *v1alpha1.Jenkins, name string) string {
+	if jenkins.Spec.Vault == nil {
+		return fmt.Sprintf("vault reference '%s' requires spec.vault", name)
+	}
+	reference, err := vault.ParseReference(name)
+	if err != nil {
+		return err.Error()
+	}
+	if len(reference.Key) > 0 {
+		return fmt.Sprintf("vault reference '%s' can't contain the key, keys are predefined", name)
+	}
+	return ""
+}
+
+// validateVaultSelector returns why the selector which name uses the vault: scheme is invalid
+func validateVaultSelector(jenkins *v1alpha1.Jenkins, selector *v1.SecretKeySelector) string {
+	if jenkins.Spec.Vault == nil {
+		return fmt.Sprintf("vault reference '%s' requires spec.vault", selector.Name)
+	}
+	if _, err := vault.SelectorReference(selector); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+func isValidAdditionalClasspath(classpath string) bool {
+	classpath = strings.TrimSpace(classpath)
+	if len(classpath) == 0 {
+		return true
+	}
+	if path.IsAbs(classpath) || strings.HasPrefix(classpath, "\\") || windowsDriveRegexp.MatchString(classpath) {
+		return false
+	}
+	cleaned := path.Clean(strings.Replace(classpath, "\\", "/", -1))
+	return cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// hasSCMTriggers returns true when the seed job is built on changes in the repository
+func hasSCMTriggers(seedJob v1alpha1.SeedJob) bool {
+	return len(seedJob.PollSCM) > 0 || seedJob.GitHubPushTrigger || seedJob.BitbucketPushTrigger || seedJob.GerritTrigger != nil
+}
+
+// countCredentials returns the number of credentials configured for the seed job
+func countCredentials(seedJob v1alpha1.SeedJob) int {
+	count := 0
+	if seedJob.PrivateKey.SecretKeyRef != nil {
+		count++
+	}
+	if seedJob.UsernamePassword.SecretRef != nil {
+		count++
+	}
+	if seedJob.Token.SecretKeyRef != nil {
+		count++
+	}
+	return count
+}
+
+// isOneOf checks if value is one of allowed values, empty value means default
+func isOneOf(value string, allowed []string) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, allowedValue := range allowed {
+		if value == allowedValue {
+			return true
 		}
 	}
-	return valid, nil
+	return false
 }
 
-func validatePrivateKey(privateKey string) error {
+func isValidLabelExpression(label string) bool {
+	if len(strings.TrimSpace(label)) == 0 {
+		return false
+	}
+	return !strings.ContainsAny(label, "\"\n\r")
+}
+
+func normalizeRepositoryURL(repositoryURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repositoryURL), "/"), ".git")
+}
+
+// parseRepositoryURL checks the repository url is one of git@host:path, ssh://, https:// or http:// forms
+// and returns whether it's accessed over ssh
+func parseRepositoryURL(repositoryURL string) (sshURL bool, err error) {
+	if len(repositoryURL) == 0 {
+		return false, stackerr.New("it can't be empty")
+	}
+	if strings.ContainsAny(repositoryURL, " \t\n") {
+		return false, stackerr.New("it can't contain whitespaces")
+	}
+	if scpRepositoryURLRegexp.MatchString(repositoryURL) {
+		return true, nil
+	}
+
+	parsedURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return false, stackerr.WithStack(err)
+	}
+	switch parsedURL.Scheme {
+	case "ssh", "https", "http":
+	default:
+		return false, stackerr.Errorf("unsupported scheme '%s', allowed forms are git@host:path, ssh://, https:// and http://", parsedURL.Scheme)
+	}
+	if len(parsedURL.Host) == 0 {
+		return false, stackerr.New("host can't be empty")
+	}
+	if len(strings.Trim(parsedURL.Path, "/")) == 0 {
+		return false, stackerr.New("repository path can't be empty")
+	}
+
+	return parsedURL.Scheme == "ssh", nil
+}
+
+func isValidRepositoryBranch(branch string) bool {
+	if len(strings.TrimSpace(branch)) == 0 {
+		return false
+	}
+	return !strings.ContainsAny(branch, " \t\n")
+}
+
+// isValidRevision checks the revision is a full commit SHA or a valid tag name
+func isValidRevision(revision string) bool {
+	return revisionRegexp.MatchString(revision) && !strings.Contains(revision, "..") &&
+		!strings.HasSuffix(revision, ".lock") && !strings.HasSuffix(revision, "/")
+}
+
+// validateKnownHosts checks every known_hosts entry can be parsed, comments and empty lines are skipped
+func validateKnownHosts(knownHosts string) error {
+	rest := []byte(knownHosts)
+	for {
+		_, _, _, _, next, err := ssh.ParseKnownHosts(rest)
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return stackerr.WithStack(err)
+		}
+		rest = next
+	}
+}
+
+// validatePrivateKey checks the private key can be parsed, the encrypted private key is decrypted with the passphrase
+func validatePrivateKey(privateKey, passphrase string) error {
 	block, _ := pem.Decode([]byte(privateKey))
 	if block == nil {
 		return stackerr.New("failed to decode PEM block")
 	}
 
-	priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
-	if err != nil {
-		return stackerr.WithStack(err)
+	if isEncryptedPrivateKey(block) {
+		if passphrase == "" {
+			return stackerr.New("private key is encrypted, passphrase is required")
+		}
+		key, err := ssh.ParseRawPrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+		if err == x509.IncorrectPasswordError {
+			return stackerr.New("wrong private key passphrase")
+		} else if err != nil {
+			return stackerr.WithStack(err)
+		}
+		return validateParsedPrivateKey(key)
 	}
 
-	err = priv.Validate()
-	if err != nil {
-		return stackerr.WithStack(err)
+	var formats []string
+	var lastErr error
+	for _, format := range privateKeyFormats {
+		key, err := format.parse(block)
+		if err != nil {
+			formats = append(formats, format.name)
+			lastErr = err
+			continue
+		}
+		return validateParsedPrivateKey(key)
 	}
 
+	return stackerr.Errorf("unsupported private key format, tried %s: %s", strings.Join(formats, ", "), lastErr)
+}
+
+// isEncryptedPrivateKey returns true for legacy encrypted PEM and encrypted OpenSSH private keys
+func isEncryptedPrivateKey(block *pem.Block) bool {
+	if x509.IsEncryptedPEMBlock(block) {
+		return true
+	}
+	_, err := ssh.ParseRawPrivateKey(pem.EncodeToMemory(block))
+	_, ok := err.(*ssh.PassphraseMissingError)
+	return ok
+}
+
+func validateParsedPrivateKey(key interface{}) error {
+	if rsaKey, ok := key.(*rsa.PrivateKey); ok {
+		return stackerr.WithStack(rsaKey.Validate())
+	}
 	return nil
 }
@@ -0,0 +1,2 @@
+// Package views implements views of Jenkins declared in Jenkins CR
+package views
@@ -0,0 +1,176 @@
+package views
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// reasonViewsUpdated is the event which informs views have been created, configured again or deleted in Jenkins
+	reasonViewsUpdated event.Reason = "ViewsUpdated"
+
+	// syncedMarker is printed by syncViewsFmt when all views have been synchronized
+	syncedMarker = "views synchronized"
+)
+
+// syncViewsFmt creates or configures changed views and deletes removed views, views are passed as the base64 encoded
+// JSON built by syncSettings, the existing view of another type is replaced, views created otherwise are kept
+const syncViewsFmt = `
+import hudson.model.ListView
+import jenkins.model.Jenkins
+
+def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+def jenkins = Jenkins.getInstance()
+
+try {
+    settings.views.each { entry ->
+        def view = jenkins.getView(entry.name)
+        if (!(view instanceof ListView)) {
+            if (view != null) {
+                jenkins.deleteView(view)
+            }
+            view = new ListView(entry.name, jenkins)
+            jenkins.addView(view)
+        }
+        view.setIncludeRegex(entry.includeRegex ?: null)
+        view.setRecurse(entry.recurse)
+        view.@jobNames.clear()
+        view.@jobNames.addAll(entry.jobNames)
+        view.save()
+    }
+    settings.remove.each { name ->
+        def view = jenkins.getView(name)
+        if (view != null && view != jenkins.getPrimaryView()) {
+            jenkins.deleteView(view)
+        }
+    }
+    jenkins.save()
+} catch (Exception e) {
+    print '` + groovy.SettingsFailedPrefix + `' + e.toString().readLines().join(' ')
+    return
+}
+print '` + syncedMarker + `'
+`
+
+// syncSettings defines views passed to syncViewsFmt
+type syncSettings struct {
+	Views  []viewSettings `json:"views"`
+	Remove []string       `json:"remove"`
+}
+
+type viewSettings struct {
+	Name         string            `json:"name"`
+	Type         v1alpha1.ViewType `json:"type"`
+	IncludeRegex string            `json:"includeRegex"`
+	JobNames     []string          `json:"jobNames"`
+	Recurse      bool              `json:"recurse"`
+}
+
+// Views synchronizes views of Jenkins with Jenkins.Spec.Views
+type Views struct {
+	jenkinsClient jenkinsclient.Jenkins
+	k8sClient     k8s.Client
+	logger        logr.Logger
+	events        event.Recorder
+}
+
+// New creates Views object
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *Views {
+	return &Views{
+		jenkinsClient: jenkinsClient,
+		k8sClient:     k8sClient,
+		logger:        logger,
+		events:        events,
+	}
+}
+
+// Type returns the type of the view, it's list by default
+func Type(view v1alpha1.View) v1alpha1.ViewType {
+	if len(view.Type) == 0 {
+		return v1alpha1.ViewTypeList
+	}
+	return view.Type
+}
+
+// EnsureViews creates or configures again views which checksum differs from Jenkins.Status.Views and deletes views
+// which have been removed from Jenkins.Spec.Views
+func (v *Views) EnsureViews(jenkins *v1alpha1.Jenkins) error {
+	checksums := map[string]string{}
+	for _, status := range jenkins.Status.Views {
+		checksums[status.Name] = status.Checksum
+	}
+
+	settings := syncSettings{}
+	var managed []v1alpha1.ViewStatus
+	var updated []string
+	declared := map[string]bool{}
+	for _, view := range jenkins.Spec.Views {
+		entry := viewSettings{
+			Name:         view.Name,
+			Type:         Type(view),
+			IncludeRegex: view.IncludeRegex,
+			JobNames:     append([]string{}, view.JobNames...),
+			Recurse:      view.Recurse,
+		}
+		checksum, err := calculateChecksum(entry)
+		if err != nil {
+			return err
+		}
+		declared[view.Name] = true
+		managed = append(managed, v1alpha1.ViewStatus{Name: view.Name, Checksum: checksum})
+		if previous, ok := checksums[view.Name]; ok && previous == checksum {
+			continue
+		}
+		settings.Views = append(settings.Views, entry)
+		updated = append(updated, view.Name)
+	}
+	for _, status := range jenkins.Status.Views {
+		if !declared[status.Name] {
+			settings.Remove = append(settings.Remove, status.Name)
+		}
+	}
+	if len(settings.Views) == 0 && len(settings.Remove) == 0 {
+		return nil
+	}
+
+	script := groovy.SettingsScript{Format: syncViewsFmt, DoneMarker: syncedMarker}
+	if _, err := script.Execute(v.jenkinsClient, settings); err != nil {
+		return stackerr.Wrapf(err, "couldn't synchronize views %v", append(updated, settings.Remove...))
+	}
+
+	var messages []string
+	if len(updated) > 0 {
+		messages = append(messages, fmt.Sprintf("created or updated: %s", strings.Join(updated, ", ")))
+	}
+	if len(settings.Remove) > 0 {
+		messages = append(messages, fmt.Sprintf("deleted: %s", strings.Join(settings.Remove, ", ")))
+	}
+	v.logger.Info(fmt.Sprintf("Views %s", strings.Join(messages, "; ")))
+	v.events.Emitf(jenkins, event.TypeNormal, reasonViewsUpdated, "Views %s", strings.Join(messages, "; "))
+
+	jenkins.Status.Views = managed
+	return v.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// calculateChecksum returns the checksum of the view configuration applied by syncViewsFmt
+func calculateChecksum(view viewSettings) (string, error) {
+	data, err := json.Marshal(view)
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	hash := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(hash[:]), nil
+}
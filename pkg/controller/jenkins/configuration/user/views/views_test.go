@@ -0,0 +1,115 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureViews(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	ctx := context.TODO()
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Views: []v1alpha1.View{
+				{Name: "team-a", IncludeRegex: "team-a/.*", Recurse: true},
+				{Name: "release", Type: v1alpha1.ViewTypeList, JobNames: []string{"release-app", "release-docs"}},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	views := New(jenkinsClient, fakeClient, logf.ZapLogger(false), events)
+
+	// views are created
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `{"name":"team-a","type":"list","includeRegex":"team-a/.*","jobNames":[],"recurse":true}`)
+		assert.Contains(t, settings, `{"name":"release","type":"list","includeRegex":"","jobNames":["release-app","release-docs"],"recurse":false}`)
+		return syncedMarker + "\n", nil
+	})
+
+	err = views.EnsureViews(jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, []event.Reason{reasonViewsUpdated}, events.reasons)
+	assert.Equal(t, "Views created or updated: team-a, release", events.messages[0])
+
+	updatedJenkins := &v1alpha1.Jenkins{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, updatedJenkins)
+	assert.NoError(t, err)
+	assert.Len(t, updatedJenkins.Status.Views, 2)
+	assert.Equal(t, "team-a", updatedJenkins.Status.Views[0].Name)
+	assert.Equal(t, "release", updatedJenkins.Status.Views[1].Name)
+
+	// nothing changed
+	err = views.EnsureViews(jenkins)
+	assert.NoError(t, err)
+
+	// only the changed view is configured again
+	jenkins.Spec.Views[1].JobNames = append(jenkins.Spec.Views[1].JobNames, "release-charts")
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		settings := groovytest.DecodeSettings(t, script)
+		assert.Contains(t, settings, `"jobNames":["release-app","release-docs","release-charts"]`)
+		assert.NotContains(t, settings, `"name":"team-a"`)
+		return syncedMarker, nil
+	})
+
+	err = views.EnsureViews(jenkins)
+	assert.NoError(t, err)
+
+	// the view removed from Jenkins CR
+	jenkins.Spec.Views = jenkins.Spec.Views[1:]
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+		assert.Contains(t, groovytest.DecodeSettings(t, script), `"views":null,"remove":["team-a"]`)
+		return syncedMarker, nil
+	})
+
+	err = views.EnsureViews(jenkins)
+	assert.NoError(t, err)
+	assert.Len(t, jenkins.Status.Views, 1)
+	assert.Equal(t, "release", jenkins.Status.Views[0].Name)
+	assert.Equal(t, "Views deleted: team-a", events.messages[len(events.messages)-1])
+
+	// the script failed
+	jenkins.Spec.Views[0].IncludeRegex = "release-(.*"
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return(groovy.SettingsFailedPrefix+"java.util.regex.PatternSyntaxException: Unclosed group", nil)
+
+	err = views.EnsureViews(jenkins)
+	assert.EqualError(t, err, "couldn't synchronize views [release]: java.util.regex.PatternSyntaxException: Unclosed group")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
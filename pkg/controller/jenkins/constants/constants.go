@@ -1,5 +1,7 @@
 package constants
 
+import "time"
+
 const (
 	// OperatorName is a operator name
 	OperatorName = "jenkins-operator"
@@ -9,6 +11,26 @@ const (
 	SeedJobSuffix = "job-dsl-seed"
 	// DefaultJenkinsMasterImage is the default Jenkins master docker image
 	DefaultJenkinsMasterImage = "jenkins/jenkins:lts"
+	// DefaultJenkinsAgentImage is the default JNLP agent docker image used by pod templates configured by operator
+	DefaultJenkinsAgentImage = "jenkins/jnlp-slave:3.27-1"
+	// SeedAgentLabel is the name and the label of Kubernetes pod template used to run seed jobs
+	SeedAgentLabel = OperatorName + "-seed-agent"
+	// DefaultSeedJobRepositoryBranch is the default branch used by seed jobs
+	DefaultSeedJobRepositoryBranch = "master"
+	// DefaultSeedJobBuildTimeout is the default maximum duration of the seed job build
+	DefaultSeedJobBuildTimeout = 30 * time.Minute
+	// DefaultPluginDownloadAttempts is the default maximum number of attempts to download a single plugin
+	DefaultPluginDownloadAttempts = 5
+	// DefaultPluginDownloadDelaySeconds is the default delay in seconds before the first retry of the plugin download
+	DefaultPluginDownloadDelaySeconds = 2
+	// DefaultBuildRetention is the default number of the last builds kept by jobs created by operator
+	DefaultBuildRetention = 10
+	// BaseConfigurationJobName is the Jenkins job name used to configure Jenkins by groovy scripts provided by operator
+	BaseConfigurationJobName = OperatorName + "-base-configuration"
 	// UserConfigurationJobName is the Jenkins job name used to configure Jenkins by groovy scripts provided by user
 	UserConfigurationJobName = OperatorName + "-user-configuration"
+	// ConfigureSeedJobsName is the Jenkins job name used to configure seed jobs and deploy keys
+	ConfigureSeedJobsName = OperatorName + "-configure-seed-job"
+	// DeleteSeedJobsName is the Jenkins job name used to delete seed jobs removed from Jenkins CR
+	DeleteSeedJobsName = OperatorName + "-delete-seed-job"
 )
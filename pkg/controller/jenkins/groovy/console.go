@@ -0,0 +1,99 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+// scriptConsoleScript evaluates the base64 encoded script, the exception is printed after scriptFailureMarker
+// because the script console responds with the stack trace as a regular output
+const scriptConsoleScript = `
+def script = new String('%s'.decodeBase64(), 'UTF-8')
+try {
+    evaluate(script)
+} catch (Throwable e) {
+    print '` + scriptFailureMarker + `' + e
+}
+`
+
+// DeleteGroovyJob deletes jenkins job for executing groovy scripts if it exists
+func (g *Groovy) DeleteGroovyJob() error {
+	_, err := g.jenkinsClient.GetJob(g.jobName)
+	if err != nil && err.Error() == jobs.ErrorNotFound.Error() {
+		return nil
+	}
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+
+	if _, err = g.jenkinsClient.DeleteJob(g.jobName); err != nil {
+		return stackerr.WithStack(err)
+	}
+	g.logger.Info(fmt.Sprintf("'%s' job has been deleted", g.jobName))
+	return nil
+}
+
+// executeScripts executes changed scripts one by one via the Jenkins script console in the given order,
+// the result of every script is set right after it's executed, in fail fast mode the execution stops on the first failure
+func (g *Groovy) executeScripts(scripts map[string]Script, changed []string, secrets, checksums map[string]string,
+	jenkins *v1alpha1.Jenkins, options Options) (bool, error) {
+	contents := map[string]string{}
+	var failed []string
+	for _, path := range changed {
+		contents[path] = scripts[path].Content
+	}
+	invalid, err := g.checkSyntax(contents, changed, jenkins)
+	if err != nil {
+		return false, err
+	}
+	if len(invalid) > 0 {
+		if err = g.setAppliedScriptsResult(scripts, invalid, checksums, v1alpha1.AppliedScriptSyntaxErrorResult, jenkins); err != nil {
+			return false, err
+		}
+		return false, ErrorScriptSyntax
+	}
+
+	for _, path := range changed {
+		content := substituteSecrets(scripts[path].Content, secrets)
+		output, err := g.jenkinsClient.ExecuteScript(fmt.Sprintf(scriptConsoleScript, base64.StdEncoding.EncodeToString([]byte(content))))
+		if err != nil {
+			return false, err
+		}
+
+		// the output and the exception can contain values of secret references
+		output = log.MaskSecrets(output)
+		if index := strings.Index(output, scriptFailureMarker); index >= 0 {
+			g.reportScriptFailure(path, strings.TrimSpace(output[index+len(scriptFailureMarker):]), jenkins)
+			if err = g.setAppliedScriptsResult(scripts, []string{path}, checksums, v1alpha1.AppliedScriptFailureResult, jenkins); err != nil {
+				return false, err
+			}
+			if options.FailFast {
+				return false, ErrorScriptFailed
+			}
+			failed = append(failed, path)
+			continue
+		}
+
+		g.logger.V(log.VDebug).Info(fmt.Sprintf("Script '%s' has been executed, output: %s", path, strings.TrimSpace(output)))
+		if err = g.setAppliedScriptsResult(scripts, []string{path}, checksums, v1alpha1.AppliedScriptSuccessResult, jenkins); err != nil {
+			return false, err
+		}
+	}
+
+	return g.ensureFailedScripts(scripts, failed, jenkins, options)
+}
+
+// substituteSecrets replaces secret references in the script with their values
+func substituteSecrets(script string, secrets map[string]string) string {
+	for token, value := range secrets {
+		script = strings.Replace(script, token, value, -1)
+	}
+	return script
+}
@@ -0,0 +1,148 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureChangedGroovyScriptsViaScriptConsole(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("bind-password")},
+	}
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	groovyClient := New(jenkinsClient, fake.NewFakeClient(jenkins, secret), logf.ZapLogger(false), events, "user-configuration", "/scripts")
+	scripts := map[string]Script{
+		"1-first.groovy": {Source: "configmap/scripts", Key: "1-first.groovy", Content: "println 'first'"},
+		"2-ldap.groovy":  {Source: "configmap/scripts", Key: "2-ldap.groovy", Content: "login('${secret:ldap:password}')"},
+	}
+	encode := func(script string) string {
+		return base64.StdEncoding.EncodeToString([]byte(script))
+	}
+
+	// scripts are checked before they are executed in the path order, the secret value is substituted
+	gomock.InOrder(
+		jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, encode("println 'first'"))).Return("", nil),
+		jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, encode("login('****')"))).Return("", nil),
+		jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(scriptConsoleScript, encode("println 'first'"))).Return("first\n", nil),
+		jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(scriptConsoleScript, encode("login('bind-password')"))).
+			Return(scriptFailureMarker+"java.lang.IllegalStateException: invalid password bind-password", nil),
+	)
+
+	// when
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true, FailFast: true})
+
+	// then
+	assert.Equal(t, ErrorScriptFailed, err)
+	assert.False(t, done)
+	assert.Equal(t, []string{"Script '2-ldap.groovy' failed: java.lang.IllegalStateException: invalid password ****"}, events.messages)
+	applied := jenkins.Status.AppliedConfiguration
+	assert.Len(t, applied, 2)
+	assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, applied[0].Result)
+	assert.NotNil(t, applied[0].LastAppliedTime)
+	assert.Equal(t, v1alpha1.AppliedScriptFailureResult, applied[1].Result)
+	assert.NotEmpty(t, applied[1].Checksum)
+
+	// the failed script isn't executed again until it changes
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true, FailFast: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestEnsureChangedGroovyScriptsViaScriptConsoleIndependently(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	groovyClient := New(jenkinsClient, fake.NewFakeClient(jenkins), logf.ZapLogger(false), events, "user-configuration", "/scripts")
+	scripts := map[string]Script{
+		"1-broken.groovy": {Source: "configmap/scripts", Key: "1-broken.groovy", Content: "fail()"},
+		"2-second.groovy": {Source: "configmap/scripts", Key: "2-second.groovy", Content: "println 'second'"},
+	}
+	encode := func(script string) string {
+		return base64.StdEncoding.EncodeToString([]byte(script))
+	}
+	failure := scriptFailureMarker + "groovy.lang.MissingMethodException: No signature of method: fail()"
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, encode("fail()"))).Return("", nil).Times(scriptRetries + 1)
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, encode("println 'second'"))).Return("", nil)
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(scriptConsoleScript, encode("fail()"))).Return(failure, nil).Times(scriptRetries + 1)
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(scriptConsoleScript, encode("println 'second'"))).Return("second\n", nil)
+
+	// when
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true})
+
+	// then the failed script doesn't stop other scripts and it's retried
+	assert.NoError(t, err)
+	assert.False(t, done)
+	applied := jenkins.Status.AppliedConfiguration
+	assert.Equal(t, v1alpha1.AppliedScriptFailureResult, applied[0].Result)
+	assert.Equal(t, 1, applied[0].Failures)
+	assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, applied[1].Result)
+
+	for retry := 1; retry < scriptRetries; retry++ {
+		done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true})
+		assert.NoError(t, err)
+		assert.False(t, done)
+	}
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true})
+	assert.Equal(t, ErrorScriptFailed, err)
+	assert.False(t, done)
+	assert.Equal(t, scriptRetries+1, jenkins.Status.AppliedConfiguration[0].Failures)
+	assert.Len(t, events.messages, scriptRetries+1)
+
+	// the script isn't retried anymore until it changes
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{ScriptConsole: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestDeleteGroovyJob(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("happy", func(t *testing.T) {
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob("user-configuration").Return(nil, nil)
+		jenkinsClient.EXPECT().DeleteJob("user-configuration").Return(true, nil)
+		groovyClient := New(jenkinsClient, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{}, "user-configuration", "/scripts")
+
+		assert.NoError(t, groovyClient.DeleteGroovyJob())
+	})
+	t.Run("happy, job doesn't exist", func(t *testing.T) {
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().GetJob("user-configuration").Return(nil, jobs.ErrorNotFound)
+		groovyClient := New(jenkinsClient, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{}, "user-configuration", "/scripts")
+
+		assert.NoError(t, groovyClient.DeleteGroovyJob())
+	})
+}
@@ -1,46 +1,86 @@
 package groovy
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	"github.com/go-logr/logr"
+	stackerr "github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
 	jobHashParameterName = "hash"
+	// jobScriptsParameterName is the parameter with newline separated scripts executed by the job, all of them when empty
+	jobScriptsParameterName = "scripts"
+	// jobSecretsParameterName is the password parameter with values of secret references used in scripts,
+	// the job substitutes them before the script is executed
+	jobSecretsParameterName = "secrets"
+	// jobContinueOnFailureParameterName is the boolean parameter which makes the job report failed scripts
+	// in the console output and continue with other scripts instead of failing the build
+	jobContinueOnFailureParameterName = "continueOnFailure"
+
+	// scriptRetries is the number of times the failed script is applied again when scripts are applied independently
+	scriptRetries = 3
+
+	// scriptFailureMarker precedes the path or the exception of the failed script in the output of the job
+	// and the script console
+	scriptFailureMarker = "jenkins-operator:script-failure:"
+
+	// scriptFailureMessageSize is the maximum size of the failure message passed to the event
+	scriptFailureMessageSize = 1024
+
+	// ScriptExtension is the extension of groovy scripts, only files with it are executed by the job
+	ScriptExtension = ".groovy"
+
+	// reasonScriptFailed is the event which informs the groovy script failed
+	reasonScriptFailed event.Reason = "GroovyScriptFailed"
 )
 
+// ErrorScriptFailed - this is custom error returned when groovy scripts failed and none of them is retried,
+// failed scripts aren't applied again until they change
+var ErrorScriptFailed = fmt.Errorf("groovy script failed")
+
 // Groovy defines API for groovy scripts execution via jenkins job
 type Groovy struct {
 	jenkinsClient jenkinsclient.Jenkins
 	k8sClient     k8s.Client
 	logger        logr.Logger
+	events        event.Recorder
 	jobName       string
 	scriptsPath   string
 }
 
 // New creates new instance of Groovy
-func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, jobName, scriptsPath string) *Groovy {
+func New(jenkinsClient jenkinsclient.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder, jobName, scriptsPath string) *Groovy {
 	return &Groovy{
 		jenkinsClient: jenkinsClient,
 		k8sClient:     k8sClient,
 		logger:        logger,
+		events:        events,
 		jobName:       jobName,
 		scriptsPath:   scriptsPath,
 	}
 }
 
-// ConfigureGroovyJob configures jenkins job for executing groovy scripts
-func (g *Groovy) ConfigureGroovyJob() error {
-	_, created, err := g.jenkinsClient.CreateOrUpdateJob(fmt.Sprintf(configurationJobXMLFmt, g.scriptsPath), g.jobName)
+// ConfigureGroovyJob configures jenkins job for executing groovy scripts, the job keeps the last buildRetention builds,
+// the existing job is updated so changed retention applies to it as well
+func (g *Groovy) ConfigureGroovyJob(buildRetention int) error {
+	config := fmt.Sprintf(configurationJobXMLFmt, jobs.BuildDiscarderPropertyXML(buildRetention), g.scriptsPath)
+	_, created, err := g.jenkinsClient.CreateOrUpdateJob(config, g.jobName)
 	if err != nil {
 		return err
 	}
@@ -50,19 +90,339 @@ func (g *Groovy) ConfigureGroovyJob() error {
 	return nil
 }
 
-// EnsureGroovyJob executes groovy script and verifies jenkins job status according to reconciliation loop lifecycle
+// EnsureGroovyJob executes groovy script and verifies jenkins job status according to reconciliation loop lifecycle,
+// secret references in scripts are resolved and their values are passed to the job
 func (g *Groovy) EnsureGroovyJob(secretOrConfigMapData map[string]string, jenkins *v1alpha1.Jenkins) (bool, error) {
-	jobsClient := jobs.New(g.jenkinsClient, g.k8sClient, g.logger)
+	secrets, resolved, err := g.resolveSecretReferences(secretOrConfigMapData, jenkins)
+	if err != nil {
+		return false, err
+	}
+	if !resolved {
+		return false, nil
+	}
+
+	parameters, buildHash, err := buildJobParameters(secretOrConfigMapData, secrets, nil, nil, false)
+	if err != nil {
+		return false, err
+	}
+	return g.ensureGroovyJob(parameters, buildHash, jenkins)
+}
+
+// Script is the groovy script with its source
+type Script struct {
+	// Source is the kind and the name of the object with the script, e.g. configmap/name or secret/name
+	Source string
+	// Key is the key of the script in the source
+	Key string
+	// Content is the script itself
+	Content string
+	// Priority overrides the order of scripts, scripts with lower priority are applied first
+	Priority int
+}
+
+// Options defines how changed groovy scripts are executed
+type Options struct {
+	// ScriptConsole executes scripts via the Jenkins script console instead of the job
+	ScriptConsole bool
+	// FailFast stops the execution on the first failed script, failed scripts aren't retried until they change
+	FailFast bool
+}
+
+// EnsureChangedGroovyScripts executes only new scripts and scripts which checksum differs from the applied one,
+// scripts are keyed by their path relative to the scripts directory and applied sorted by the priority and the path,
+// the checksum covers also values of secret references used by the script, the state of applied scripts is kept
+// in Jenkins.Status.AppliedConfiguration
+func (g *Groovy) EnsureChangedGroovyScripts(scripts map[string]Script, jenkins *v1alpha1.Jenkins, options Options) (bool, error) {
+	contents := map[string]string{}
+	for path, script := range scripts {
+		contents[path] = script.Content
+	}
+	secrets, resolved, err := g.resolveSecretReferences(contents, jenkins)
+	if err != nil {
+		return false, err
+	}
+	if !resolved {
+		return false, nil
+	}
+
+	var paths []string
+	for path := range scripts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	sort.SliceStable(paths, func(i, j int) bool {
+		return scripts[paths[i]].Priority < scripts[paths[j]].Priority
+	})
+
+	// removed scripts are dropped from the status
+	var appliedScripts []v1alpha1.AppliedScript
+	var changed []string
+	checksums := map[string]string{}
+	applied := map[string]string{}
+	syntaxError := false
+	for _, path := range paths {
+		script := scripts[path]
+		checksums[path] = calculateScriptChecksum(script.Content, secrets)
+		appliedScript, found := getAppliedScript(jenkins, script.Source, script.Key)
+		if !found {
+			appliedScript = v1alpha1.AppliedScript{Source: script.Source, Key: script.Key}
+		}
+		appliedScripts = append(appliedScripts, appliedScript)
+		applied[path] = fmt.Sprintf("%s/%d", appliedScript.Checksum, appliedScript.Failures)
+
+		if appliedScript.Checksum != checksums[path] || isScriptRetried(appliedScript, options) {
+			changed = append(changed, path)
+		} else if appliedScript.Result == v1alpha1.AppliedScriptSyntaxErrorResult {
+			syntaxError = true
+		}
+	}
+	if err = g.updateAppliedConfiguration(appliedScripts, jenkins); err != nil {
+		return false, err
+	}
+	// scripts aren't executed until all of them can be compiled
+	if syntaxError {
+		return false, ErrorScriptSyntax
+	}
+	if len(changed) == 0 {
+		return true, nil
+	}
 
-	hash := g.calculateHash(secretOrConfigMapData)
-	done, err := jobsClient.EnsureBuildJob(g.jobName, hash, map[string]string{jobHashParameterName: hash}, jenkins, true)
+	g.logger.V(log.VDebug).Info(fmt.Sprintf("Ensuring changed groovy scripts %v", changed))
+	if options.ScriptConsole {
+		return g.executeScripts(scripts, changed, secrets, checksums, jenkins, options)
+	}
+
+	parameters, buildHash, err := buildJobParameters(contents, secrets, changed, applied, !options.FailFast)
+	if err != nil {
+		return false, err
+	}
+	// scripts are checked only once before the build is started
+	if !g.isBuildStarted(buildHash, jenkins) {
+		invalid, err := g.checkSyntax(contents, changed, jenkins)
+		if err != nil {
+			return false, err
+		}
+		if len(invalid) > 0 {
+			err = g.setAppliedScriptsResult(scripts, invalid, checksums, v1alpha1.AppliedScriptSyntaxErrorResult, jenkins)
+			if err != nil {
+				return false, err
+			}
+			return false, ErrorScriptSyntax
+		}
+		err = g.setAppliedScriptsResult(scripts, changed, nil, v1alpha1.AppliedScriptRunningResult, jenkins)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	done, err := g.ensureGroovyJob(parameters, buildHash, jenkins)
+	if err == jobs.ErrorUnrecoverableBuildFailed {
+		if err := g.setAppliedScriptsResult(scripts, changed, checksums, v1alpha1.AppliedScriptFailureResult, jenkins); err != nil {
+			return false, err
+		}
+		return false, jobs.ErrorUnrecoverableBuildFailed
+	}
+	if err != nil || !done {
+		return false, err
+	}
+
+	var failed, succeeded []string
+	messages := map[string]string{}
+	if !options.FailFast {
+		messages, err = g.getFailedScripts(buildHash, jenkins)
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, path := range changed {
+		if message, ok := messages[path]; ok {
+			g.reportScriptFailure(path, message, jenkins)
+			failed = append(failed, path)
+		} else {
+			succeeded = append(succeeded, path)
+		}
+	}
+	if err = g.setAppliedScriptsResult(scripts, succeeded, checksums, v1alpha1.AppliedScriptSuccessResult, jenkins); err != nil {
+		return false, err
+	}
+	if err = g.setAppliedScriptsResult(scripts, failed, checksums, v1alpha1.AppliedScriptFailureResult, jenkins); err != nil {
+		return false, err
+	}
+	return g.ensureFailedScripts(scripts, failed, jenkins, options)
+}
+
+// isScriptRetried checks whether the script is applied again although it didn't change, the running script
+// is applied again because its result is unknown, the failed one only when scripts are applied independently
+func isScriptRetried(appliedScript v1alpha1.AppliedScript, options Options) bool {
+	switch appliedScript.Result {
+	case v1alpha1.AppliedScriptRunningResult:
+		return true
+	case v1alpha1.AppliedScriptFailureResult:
+		return !options.FailFast && appliedScript.Failures <= scriptRetries
+	default:
+		return false
+	}
+}
+
+// ensureFailedScripts returns false when any of failed scripts is retried and ErrorScriptFailed when none of them is
+func (g *Groovy) ensureFailedScripts(scripts map[string]Script, failed []string, jenkins *v1alpha1.Jenkins, options Options) (bool, error) {
+	if len(failed) == 0 {
+		return true, nil
+	}
+	for _, path := range failed {
+		appliedScript, _ := getAppliedScript(jenkins, scripts[path].Source, scripts[path].Key)
+		if isScriptRetried(appliedScript, options) {
+			g.logger.V(log.VDebug).Info(fmt.Sprintf("Script '%s' will be applied again, failures: %d", path, appliedScript.Failures))
+			return false, nil
+		}
+	}
+	return false, ErrorScriptFailed
+}
+
+// reportScriptFailure logs the failure of the script and emits the event, registered secret values are masked
+// in the message before it's cut
+func (g *Groovy) reportScriptFailure(path, message string, jenkins *v1alpha1.Jenkins) {
+	message = log.MaskSecrets(message)
+	if len(message) > scriptFailureMessageSize {
+		message = message[:scriptFailureMessageSize]
+	}
+	g.logger.V(log.VWarn).Info(fmt.Sprintf("Script '%s' failed: %s", path, message))
+	g.events.Emitf(jenkins, event.TypeWarning, reasonScriptFailed, "Script '%s' failed: %s", path, message)
+}
+
+func getAppliedScript(jenkins *v1alpha1.Jenkins, source, key string) (v1alpha1.AppliedScript, bool) {
+	for _, appliedScript := range jenkins.Status.AppliedConfiguration {
+		if appliedScript.Source == source && appliedScript.Key == key {
+			return appliedScript, true
+		}
+	}
+	return v1alpha1.AppliedScript{}, false
+}
+
+// setAppliedScriptsResult sets the result of scripts at given paths, the checksum and the last applied time
+// are set only when checksums are given
+func (g *Groovy) setAppliedScriptsResult(scripts map[string]Script, paths []string, checksums map[string]string,
+	result v1alpha1.AppliedScriptResult, jenkins *v1alpha1.Jenkins) error {
+	now := metav1.Now()
+	appliedScripts := make([]v1alpha1.AppliedScript, len(jenkins.Status.AppliedConfiguration))
+	copy(appliedScripts, jenkins.Status.AppliedConfiguration)
+	for _, path := range paths {
+		script := scripts[path]
+		for i := range appliedScripts {
+			if appliedScripts[i].Source != script.Source || appliedScripts[i].Key != script.Key {
+				continue
+			}
+			appliedScripts[i].Result = result
+			if checksums != nil {
+				// the script with the same checksum is applied again only when it failed
+				if result != v1alpha1.AppliedScriptFailureResult {
+					appliedScripts[i].Failures = 0
+				} else if appliedScripts[i].Checksum == checksums[path] {
+					appliedScripts[i].Failures++
+				} else {
+					appliedScripts[i].Failures = 1
+				}
+				appliedScripts[i].Checksum = checksums[path]
+				if result != v1alpha1.AppliedScriptSyntaxErrorResult {
+					appliedScripts[i].LastAppliedTime = &now
+				}
+			}
+		}
+	}
+	return g.updateAppliedConfiguration(appliedScripts, jenkins)
+}
+
+func (g *Groovy) updateAppliedConfiguration(appliedScripts []v1alpha1.AppliedScript, jenkins *v1alpha1.Jenkins) error {
+	if reflect.DeepEqual(appliedScripts, jenkins.Status.AppliedConfiguration) {
+		return nil
+	}
+	jenkins.Status.AppliedConfiguration = appliedScripts
+	return g.k8sClient.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in jenkins_controller
+}
+
+// buildJobParameters returns parameters of the job running selected scripts or all of them when none is selected,
+// and the hash identifying its build
+func buildJobParameters(scripts, secrets map[string]string, selected []string, applied map[string]string,
+	continueOnFailure bool) (map[string]string, string, error) {
+	hash := CalculateHash(scripts)
+	buildHash := hash
+	parameters := map[string]string{jobHashParameterName: hash}
+	if len(secrets) > 0 {
+		secretsJSON, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, "", stackerr.WithStack(err)
+		}
+		parameters[jobSecretsParameterName] = string(secretsJSON)
+	}
+	if len(selected) > 0 {
+		parameters[jobScriptsParameterName] = strings.Join(selected, "\n")
+	}
+	if continueOnFailure {
+		parameters[jobContinueOnFailureParameterName] = "true"
+	}
+	if len(parameters) > 1 {
+		// the job runs again when values of secret references change or the same scripts change again
+		identity := map[string]string{}
+		for key, value := range parameters {
+			identity[key] = value
+		}
+		for _, key := range selected {
+			identity["applied/"+key] = applied[key]
+		}
+		buildHash = CalculateHash(identity)
+	}
+	return parameters, buildHash, nil
+}
+
+func (g *Groovy) ensureGroovyJob(parameters map[string]string, buildHash string, jenkins *v1alpha1.Jenkins) (bool, error) {
+	jobsClient := jobs.New(g.jenkinsClient, g.k8sClient, g.logger, g.events)
+	done, err := jobsClient.EnsureBuildJob(g.jobName, buildHash, parameters, jenkins, true, jobs.BuildOptions{})
 	if err != nil {
 		return false, err
 	}
 	return done, nil
 }
 
-func (g *Groovy) calculateHash(secretOrConfigMapData map[string]string) string {
+// getFailedScripts returns messages of scripts reported as failed in the console output of the build keyed by the script
+func (g *Groovy) getFailedScripts(buildHash string, jenkins *v1alpha1.Jenkins) (map[string]string, error) {
+	var number int64
+	for _, build := range jenkins.Status.Builds {
+		if build.JobName == g.jobName && build.Hash == buildHash {
+			number = build.Number
+		}
+	}
+	output, err := g.jenkinsClient.GetBuildConsoleOutput(g.jobName, number)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		index := strings.Index(line, scriptFailureMarker)
+		if index < 0 {
+			continue
+		}
+		parts := strings.SplitN(line[index+len(scriptFailureMarker):], ": ", 2)
+		if len(parts) == 2 {
+			messages[parts[0]] = strings.TrimSpace(parts[1])
+		} else {
+			messages[strings.TrimSpace(parts[0])] = ""
+		}
+	}
+	return messages, nil
+}
+
+func (g *Groovy) isBuildStarted(buildHash string, jenkins *v1alpha1.Jenkins) bool {
+	for _, build := range jenkins.Status.Builds {
+		if build.JobName == g.jobName && build.Hash == buildHash {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateHash returns the checksum of scripts verified by the groovy job before they are executed
+func CalculateHash(secretOrConfigMapData map[string]string) string {
 	hash := sha256.New()
 
 	var keys []string
@@ -77,6 +437,16 @@ func (g *Groovy) calculateHash(secretOrConfigMapData map[string]string) string {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
 }
 
+// calculateScriptChecksum returns the checksum of the script content and values of secret references used by it
+func calculateScriptChecksum(script string, secrets map[string]string) string {
+	hash := sha256.New()
+	hash.Write([]byte(script))
+	for _, token := range secretReferenceRegexp.FindAllString(script, -1) {
+		hash.Write([]byte(secrets[token]))
+	}
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
 const configurationJobXMLFmt = `<?xml version='1.1' encoding='UTF-8'?>
 <flow-definition plugin="workflow-job@2.31">
   <actions/>
@@ -84,6 +454,7 @@ const configurationJobXMLFmt = `<?xml version='1.1' encoding='UTF-8'?>
   <keepDependencies>false</keepDependencies>
   <properties>
     <org.jenkinsci.plugins.workflow.job.properties.DisableConcurrentBuildsJobProperty/>
+    %s
     <hudson.model.ParametersDefinitionProperty>
       <parameterDefinitions>
         <hudson.model.StringParameterDefinition>
@@ -92,15 +463,37 @@ const configurationJobXMLFmt = `<?xml version='1.1' encoding='UTF-8'?>
           <defaultValue></defaultValue>
           <trim>false</trim>
         </hudson.model.StringParameterDefinition>
+        <hudson.model.StringParameterDefinition>
+          <name>` + jobScriptsParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+          <trim>false</trim>
+        </hudson.model.StringParameterDefinition>
+        <hudson.model.PasswordParameterDefinition>
+          <name>` + jobSecretsParameterName + `</name>
+          <description></description>
+          <defaultValue></defaultValue>
+        </hudson.model.PasswordParameterDefinition>
+        <hudson.model.BooleanParameterDefinition>
+          <name>` + jobContinueOnFailureParameterName + `</name>
+          <description></description>
+          <defaultValue>false</defaultValue>
+        </hudson.model.BooleanParameterDefinition>
       </parameterDefinitions>
     </hudson.model.ParametersDefinitionProperty>
   </properties>
   <definition class="org.jenkinsci.plugins.workflow.cps.CpsFlowDefinition" plugin="workflow-cps@2.61">
     <script>def scriptsPath = &apos;%s&apos;
 def expectedHash = params.hash
+// only selected scripts are executed in the given order, all of them sorted by the path when none is selected
+def selectedScripts = params.` + jobScriptsParameterName + ` ? params.` + jobScriptsParameterName + `.tokenize(&apos;\n&apos;) : []
+// failed scripts are reported in the console output instead of failing the build
+def continueOnFailure = params.` + jobContinueOnFailureParameterName + `.toString() == &apos;true&apos;
 
 node(&apos;master&apos;) {
-    def scriptsText = sh(script: &quot;ls ${scriptsPath} | sort&quot;, returnStdout: true).trim()
+    // scripts can be placed in subdirectories, hidden entries are created by Kubernetes in mounted ConfigMaps,
+    // files other than groovy scripts, e.g. JCasC YAML files, are applied by operator
+    def scriptsText = sh(script: &quot;cd ${scriptsPath} &amp;&amp; find -L . -mindepth 1 -maxdepth 2 -type f -name &apos;*` + ScriptExtension + `&apos; -not -path &apos;*/.*&apos; | cut -c 3- | LC_ALL=C sort&quot;, returnStdout: true).trim()
     def scripts = []
     scripts.addAll(scriptsText.tokenize(&apos;\n&apos;))
     
@@ -120,9 +513,27 @@ node(&apos;master&apos;) {
         }
     }
     
-    for(script in scripts) {
+    for(script in (selectedScripts ?: scripts)) {
         stage(script) {
-            load &quot;${scriptsPath}/${script}&quot;
+            try {
+                def content = readScript(scriptsPath, script)
+                if(content.contains(&apos;${secret:&apos;)) {
+                    // secret values are substituted in memory only, the error can contain the script source so values are masked
+                    try {
+                        evaluate(substituteSecrets(content, params.` + jobSecretsParameterName + `))
+                    } catch(e) {
+                        error(&quot;Script &apos;${script}&apos; failed: ${maskSecrets(e.toString(), params.` + jobSecretsParameterName + `)}&quot;)
+                    }
+                } else {
+                    load &quot;${scriptsPath}/${script}&quot;
+                }
+            } catch(e) {
+                if(!continueOnFailure || e instanceof org.jenkinsci.plugins.workflow.steps.FlowInterruptedException) {
+                    throw e
+                }
+                def message = maskSecrets(e.toString(), params.` + jobSecretsParameterName + `).replace(&apos;\n&apos;, &apos; &apos;)
+                println &quot;` + scriptFailureMarker + `${script}: ${message}&quot;
+            }
         }
     }
 }
@@ -137,6 +548,39 @@ def calculateHash(String[] scripts, String scriptsPath) {
         hash.update(fileData)
     }
     return Base64.getEncoder().encodeToString(hash.digest())
+}
+
+@NonCPS
+def readScript(String scriptsPath, String script) {
+    def fileLocation = java.nio.file.Paths.get(&quot;${scriptsPath}/${script}&quot;)
+    return new String(java.nio.file.Files.readAllBytes(fileLocation), &quot;UTF-8&quot;)
+}
+
+@NonCPS
+def parseSecrets(parameter) {
+    def text = parameter instanceof hudson.util.Secret ? parameter.getPlainText() : parameter
+    if(!text) {
+        return [:]
+    }
+    return new groovy.json.JsonSlurperClassic().parseText(text)
+}
+
+@NonCPS
+def substituteSecrets(String content, parameter) {
+    parseSecrets(parameter).each { token, value -&gt;
+        content = content.replace(token, value)
+    }
+    return content
+}
+
+@NonCPS
+def maskSecrets(String text, parameter) {
+    parseSecrets(parameter).each { token, value -&gt;
+        if(value) {
+            text = text.replace(value, &apos;****&apos;)
+        }
+    }
+    return text
 }</script>
     <sandbox>false</sandbox>
   </definition>
@@ -0,0 +1,278 @@
+package groovy
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+
+	"github.com/bndr/gojenkins"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestEnsureChangedGroovyScripts(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jobName := "user-configuration"
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient()
+	err = fakeClient.Create(context.TODO(), jenkins)
+	assert.NoError(t, err)
+
+	// every build uses a new Jenkins client, so expectations of previous builds don't match
+	expectBuild := func(buildNumber int64, scripts map[string]Script, selected string, result v1alpha1.BuildStatus) *Groovy {
+		return expectGroovyBuild(ctrl, fakeClient, jobName, buildNumber, scripts, selected, result, false, "failed")
+	}
+
+	// all scripts are applied for the first time, the execution stops on the first failure
+	scripts := map[string]Script{
+		"000-first/1-first.groovy":   {Source: "configmap/first", Key: "1-first.groovy", Content: "println 'first'"},
+		"001-second/1-second.groovy": {Source: "secret/second", Key: "1-second.groovy", Content: "println 'second'"},
+	}
+	groovyClient := expectBuild(1, scripts, "000-first/1-first.groovy\n001-second/1-second.groovy", v1alpha1.BuildSuccessStatus)
+
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	assert.Len(t, jenkins.Status.AppliedConfiguration, 2)
+	for _, appliedScript := range jenkins.Status.AppliedConfiguration {
+		assert.Equal(t, v1alpha1.AppliedScriptRunningResult, appliedScript.Result)
+		assert.Empty(t, appliedScript.Checksum)
+	}
+
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	err = fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	applied := jenkins.Status.AppliedConfiguration
+	assert.Len(t, applied, 2)
+	assert.Equal(t, "configmap/first", applied[0].Source)
+	assert.Equal(t, "1-first.groovy", applied[0].Key)
+	assert.Equal(t, "secret/second", applied[1].Source)
+	assert.Equal(t, "1-second.groovy", applied[1].Key)
+	for _, appliedScript := range applied {
+		assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, appliedScript.Result)
+		assert.NotEmpty(t, appliedScript.Checksum)
+		assert.NotNil(t, appliedScript.LastAppliedTime)
+	}
+
+	// nothing changed
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, applied, jenkins.Status.AppliedConfiguration)
+
+	// only the changed script is applied, it fails
+	scripts["001-second/1-second.groovy"] = Script{Source: "secret/second", Key: "1-second.groovy", Content: "println 'changed'"}
+	groovyClient = expectBuild(2, scripts, "001-second/1-second.groovy", v1alpha1.BuildFailureStatus)
+
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	for retry := 0; retry < jobs.BuildRetires; retry++ {
+		_, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+		assert.Equal(t, jobs.ErrorBuildFailed, err)
+		_, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+		assert.NoError(t, err)
+	}
+	_, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.Equal(t, jobs.ErrorBuildFailed, err)
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.Equal(t, jobs.ErrorUnrecoverableBuildFailed, err)
+	assert.False(t, done)
+	assert.Equal(t, applied[0], jenkins.Status.AppliedConfiguration[0])
+	assert.Equal(t, v1alpha1.AppliedScriptFailureResult, jenkins.Status.AppliedConfiguration[1].Result)
+	assert.NotEqual(t, applied[1].Checksum, jenkins.Status.AppliedConfiguration[1].Checksum)
+
+	// the failed script isn't applied again until it changes
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// removed script is dropped from the status
+	delete(scripts, "001-second/1-second.groovy")
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, applied[:1], jenkins.Status.AppliedConfiguration)
+}
+
+func TestEnsureChangedGroovyScriptsIndependently(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jobName := "user-configuration"
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	scripts := map[string]Script{
+		"1-first.groovy":  {Source: "configmap/scripts", Key: "1-first.groovy", Content: "println 'first'"},
+		"2-second.groovy": {Source: "configmap/scripts", Key: "2-second.groovy", Content: "fail()"},
+	}
+
+	// the failed script is reported in the console output, the build succeeds
+	output := "[Pipeline] stage\n" + scriptFailureMarker + "2-second.groovy: groovy.lang.MissingMethodException: No signature of method: fail()\n"
+	groovyClient := expectGroovyBuild(ctrl, fakeClient, jobName, 1, scripts, "1-first.groovy\n2-second.groovy", v1alpha1.BuildSuccessStatus, true, output)
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, done)
+	applied := jenkins.Status.AppliedConfiguration
+	assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, applied[0].Result)
+	assert.Equal(t, v1alpha1.AppliedScriptFailureResult, applied[1].Result)
+	assert.Equal(t, 1, applied[1].Failures)
+
+	// only the failed script is retried
+	groovyClient = expectGroovyBuild(ctrl, fakeClient, jobName, 2, scripts, "2-second.groovy", v1alpha1.BuildSuccessStatus, true, "")
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+	assert.NoError(t, err)
+	assert.False(t, done)
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, applied[0], jenkins.Status.AppliedConfiguration[0])
+	assert.Equal(t, v1alpha1.AppliedScriptSuccessResult, jenkins.Status.AppliedConfiguration[1].Result)
+	assert.Equal(t, 0, jenkins.Status.AppliedConfiguration[1].Failures)
+}
+
+func TestEnsureChangedGroovyScriptsOrder(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	scripts := map[string]Script{
+		"000-tools/2-maven.groovy":        {Source: "configmap/tools", Key: "2-maven.groovy"},
+		"000-tools/10-docker.groovy":      {Source: "configmap/tools", Key: "10-docker.groovy"},
+		"001-credentials/1-github.groovy": {Source: "configmap/credentials", Key: "1-github.groovy", Priority: -1},
+	}
+	// scripts are sorted lexicographically, the source priority goes first
+	selected := "001-credentials/1-github.groovy\n000-tools/10-docker.groovy\n000-tools/2-maven.groovy"
+	groovyClient := expectGroovyBuild(ctrl, fake.NewFakeClient(jenkins), "user-configuration", 1, scripts, selected, v1alpha1.BuildSuccessStatus, true, "")
+
+	// when
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, done)
+	var keys []string
+	for _, appliedScript := range jenkins.Status.AppliedConfiguration {
+		keys = append(keys, appliedScript.Key)
+	}
+	assert.Equal(t, []string{"1-github.groovy", "10-docker.groovy", "2-maven.groovy"}, keys)
+}
+
+// expectGroovyBuild returns the groovy client with a new Jenkins client expecting the build of selected scripts
+func expectGroovyBuild(ctrl *gomock.Controller, k8sClient k8s.Client, jobName string, buildNumber int64, scripts map[string]Script,
+	selected string, result v1alpha1.BuildStatus, continueOnFailure bool, output string) *Groovy {
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	contents := map[string]string{}
+	for path, script := range scripts {
+		contents[path] = script.Content
+	}
+	parameters := map[string]string{
+		jobHashParameterName:    CalculateHash(contents),
+		jobScriptsParameterName: selected,
+	}
+	if continueOnFailure {
+		parameters[jobContinueOnFailureParameterName] = "true"
+	}
+	// the syntax check of every selected script
+	jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("", nil).Times(len(strings.Split(selected, "\n")))
+	jenkinsClient.EXPECT().GetJob(jobName).Return(&gojenkins.Job{
+		Raw: &gojenkins.JobResponse{NextBuildNumber: buildNumber, URL: "http://jenkins:8080/job/user-configuration/"},
+	}, nil).AnyTimes()
+	jenkinsClient.EXPECT().BuildJob(jobName, parameters).Return(int64(0), nil).AnyTimes()
+	jenkinsClient.EXPECT().GetBuild(jobName, buildNumber).Return(&gojenkins.Build{
+		Raw: &gojenkins.BuildResponse{Result: string(result)},
+	}, nil).AnyTimes()
+	jenkinsClient.EXPECT().GetBuildConsoleOutput(jobName, buildNumber).Return(output, nil).AnyTimes()
+	return New(jenkinsClient, k8sClient, logf.ZapLogger(false), &fakeRecorder{}, jobName, "/scripts")
+}
+
+func TestEnsureChangedGroovyScriptsWithSyntaxError(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	events := &fakeRecorder{}
+	groovyClient := New(jenkinsClient, fake.NewFakeClient(jenkins), logf.ZapLogger(false), events, "user-configuration", "/scripts")
+	scripts := map[string]Script{
+		"1-broken.groovy":  {Source: "configmap/scripts", Key: "1-broken.groovy", Content: "println 'broken'\n}"},
+		"2-runtime.groovy": {Source: "configmap/scripts", Key: "2-runtime.groovy", Content: "// " + skipSyntaxCheckMarker + "\nnew RuntimeOnlyClass()"},
+	}
+
+	// only the script without the marker is checked
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, base64.StdEncoding.EncodeToString([]byte(scripts["1-broken.groovy"].Content)))).
+		Return("unexpected token: } @ line 2, column 1.", nil)
+
+	// when
+	done, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+
+	// then
+	assert.Equal(t, ErrorScriptSyntax, err)
+	assert.False(t, done)
+	assert.Equal(t, []string{"Script '1-broken.groovy' can't be compiled: unexpected token: } @ line 2, column 1."}, events.messages)
+	assert.Equal(t, v1alpha1.AppliedScriptSyntaxErrorResult, jenkins.Status.AppliedConfiguration[0].Result)
+	assert.NotEmpty(t, jenkins.Status.AppliedConfiguration[0].Checksum)
+	assert.Empty(t, jenkins.Status.AppliedConfiguration[1].Result)
+
+	// scripts aren't applied until the broken one is fixed
+	done, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+	assert.Equal(t, ErrorScriptSyntax, err)
+	assert.False(t, done)
+}
+
+func TestCheckSyntaxReplacesSecretReferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	groovyClient := New(jenkinsClient, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{}, "user-configuration", "/scripts")
+	scripts := map[string]string{"1-ldap.groovy": "def password = '${secret:ldap:password}'"}
+	jenkinsClient.EXPECT().ExecuteScript(fmt.Sprintf(syntaxCheckScript, base64.StdEncoding.EncodeToString([]byte("def password = '****'")))).
+		Return("", nil)
+
+	invalid, err := groovyClient.checkSyntax(scripts, []string{"1-ldap.groovy"}, &v1alpha1.Jenkins{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, invalid)
+}
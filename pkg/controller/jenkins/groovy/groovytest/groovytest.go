@@ -0,0 +1,23 @@
+// Package groovytest provides utilities for testing groovy scripts executed by operator in the script console
+package groovytest
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var settingsRegexp = regexp.MustCompile(`'([A-Za-z0-9+/=]*)'\.decodeBase64\(\)`)
+
+// DecodeSettings returns the JSON settings passed to groovy.SettingsScript by the script
+func DecodeSettings(t *testing.T, script string) string {
+	match := settingsRegexp.FindStringSubmatch(script)
+	if !assert.NotNil(t, match) {
+		return ""
+	}
+	data, err := base64.StdEncoding.DecodeString(match[1])
+	assert.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,110 @@
+package groovy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// reasonSecretReferenceNotResolved is the event which informs the script references a secret or a key which doesn't exist
+const reasonSecretReferenceNotResolved event.Reason = "SecretReferenceNotResolved"
+
+// secretReferenceRegexp matches ${secret:<secret name>:<key>} tokens in groovy scripts
+var secretReferenceRegexp = regexp.MustCompile(`\$\{secret:([-.a-z0-9]+):([-._a-zA-Z0-9]+)\}`)
+
+// vaultReferenceRegexp matches ${vault:<path>#<key>} tokens in groovy scripts
+var vaultReferenceRegexp = regexp.MustCompile(`\$\{(vault:[-._/a-zA-Z0-9]+#[-._a-zA-Z0-9]+)\}`)
+
+// SubstituteSecretReferences returns values with secret references replaced by their values, kind names values
+// in events, e.g. "Global environment variable", it returns false when any reference can't be resolved
+func (g *Groovy) SubstituteSecretReferences(values map[string]string, kind string, jenkins *v1alpha1.Jenkins) (map[string]string, bool, error) {
+	secrets, resolved, err := g.resolveReferences(values, kind, jenkins)
+	if err != nil || !resolved {
+		return nil, resolved, err
+	}
+
+	substituted := map[string]string{}
+	for name, value := range values {
+		substituted[name] = substituteSecrets(value, secrets)
+	}
+	return substituted, true, nil
+}
+
+// resolveSecretReferences returns values of secret references used in scripts keyed by the token,
+// it returns false when any of them can't be resolved, values are never logged
+func (g *Groovy) resolveSecretReferences(scripts map[string]string, jenkins *v1alpha1.Jenkins) (map[string]string, bool, error) {
+	return g.resolveReferences(scripts, "Script", jenkins)
+}
+
+// resolveReferences returns values of secret references used in sources keyed by the token, kind names sources in events
+func (g *Groovy) resolveReferences(sources map[string]string, kind string, jenkins *v1alpha1.Jenkins) (map[string]string, bool, error) {
+	var names []string
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := map[string]string{}
+	var registered []string
+	resolved := true
+	for _, name := range names {
+		for _, match := range secretReferenceRegexp.FindAllStringSubmatch(sources[name], -1) {
+			token, secretName, key := match[0], match[1], match[2]
+			if _, ok := values[token]; ok {
+				continue
+			}
+
+			secret := &corev1.Secret{}
+			err := g.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: jenkins.Namespace, Name: secretName}, secret)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, false, stackerr.WithStack(err)
+			}
+			value, ok := secret.Data[key]
+			if err != nil || !ok {
+				message := fmt.Sprintf("%s '%s' can't be applied, secret reference '%s' can't be resolved", kind, name, token)
+				g.logger.V(log.VWarn).Info(message)
+				g.events.Emit(jenkins, event.TypeWarning, reasonSecretReferenceNotResolved, message)
+				resolved = false
+				continue
+			}
+			values[token] = string(value)
+			registered = append(registered, values[token])
+		}
+		for _, match := range vaultReferenceRegexp.FindAllStringSubmatch(sources[name], -1) {
+			token := match[0]
+			if _, ok := values[token]; ok {
+				continue
+			}
+
+			reference, err := vault.ParseReference(match[1])
+			if err == nil {
+				values[token], err = vault.New(g.k8sClient, jenkins).Resolve(reference)
+			}
+			if vault.IsUnavailable(err) {
+				return nil, false, err
+			} else if err != nil {
+				delete(values, token)
+				message := fmt.Sprintf("%s '%s' can't be applied, secret reference '%s' can't be resolved: %s", kind, name, token, err)
+				g.logger.V(log.VWarn).Info(message)
+				g.events.Emit(jenkins, event.TypeWarning, reasonSecretReferenceNotResolved, message)
+				resolved = false
+			}
+		}
+	}
+	// values of Vault references are registered by the Vault client
+	owner := log.SecretOwner{Namespace: jenkins.Namespace, Name: jenkins.Name, Source: fmt.Sprintf("%s secret references %s", kind, g.jobName)}
+	log.ReplaceSecrets(owner, registered...)
+
+	return values, resolved, nil
+}
@@ -0,0 +1,209 @@
+package groovy
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/jobs"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+
+	"github.com/bndr/gojenkins"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestResolveSecretReferences(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap", Namespace: "default"},
+		Data: map[string][]byte{
+			"password": []byte("bind-password"),
+		},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		events := &fakeRecorder{}
+		groovyClient := New(nil, fake.NewFakeClient(secret), logf.ZapLogger(false), events, "job", "/scripts")
+		scripts := map[string]string{
+			"1-ldap.groovy":  "def password = '${secret:ldap:password}'",
+			"2-other.groovy": "println '${secret:ldap:password}' + '$notASecret'",
+		}
+
+		values, resolved, err := groovyClient.resolveSecretReferences(scripts, jenkins)
+
+		assert.NoError(t, err)
+		assert.True(t, resolved)
+		assert.Equal(t, map[string]string{"${secret:ldap:password}": "bind-password"}, values)
+		assert.Empty(t, events.messages)
+	})
+	t.Run("happy, no references", func(t *testing.T) {
+		groovyClient := New(nil, fake.NewFakeClient(), logf.ZapLogger(false), &fakeRecorder{}, "job", "/scripts")
+
+		values, resolved, err := groovyClient.resolveSecretReferences(map[string]string{"1.groovy": "println 'hello'"}, jenkins)
+
+		assert.NoError(t, err)
+		assert.True(t, resolved)
+		assert.Empty(t, values)
+	})
+	t.Run("fail, missing key", func(t *testing.T) {
+		events := &fakeRecorder{}
+		groovyClient := New(nil, fake.NewFakeClient(secret), logf.ZapLogger(false), events, "job", "/scripts")
+		scripts := map[string]string{
+			"1-ldap.groovy": "def password = '${secret:ldap:password}'\ndef user = '${secret:ldap:user}'",
+		}
+
+		_, resolved, err := groovyClient.resolveSecretReferences(scripts, jenkins)
+
+		assert.NoError(t, err)
+		assert.False(t, resolved)
+		assert.Equal(t, []string{"Script '1-ldap.groovy' can't be applied, secret reference '${secret:ldap:user}' can't be resolved"}, events.messages)
+	})
+	t.Run("fail, missing secret", func(t *testing.T) {
+		events := &fakeRecorder{}
+		groovyClient := New(nil, fake.NewFakeClient(), logf.ZapLogger(false), events, "job", "/scripts")
+		scripts := map[string]string{
+			"1-ldap.groovy": "def password = '${secret:ldap:password}'",
+		}
+
+		_, resolved, err := groovyClient.resolveSecretReferences(scripts, jenkins)
+
+		assert.NoError(t, err)
+		assert.False(t, resolved)
+		assert.Len(t, events.messages, 1)
+		assert.NotContains(t, events.messages[0], "bind-password")
+	})
+}
+
+func TestSecretValuesAreMaskedInEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ldap", Namespace: "default"},
+		Data:       map[string][]byte{"password": []byte("bind-password-value")},
+	}
+	scripts := map[string]Script{
+		"1-ldap.groovy":  {Source: "configmap/scripts", Key: "1-ldap.groovy", Content: "login('${secret:ldap:password}')"},
+		"2-token.groovy": {Source: "secret/scripts", Key: "2-token.groovy", Content: "def token = '${secret:ldap:password}'"},
+		// string literals of scripts aren't masked
+		"3-job.groovy": {Source: "secret/scripts", Key: "3-job.groovy", Content: "def job = 'deploy-application'"},
+	}
+	// every secret value is printed by scripts
+	output := scriptFailureMarker + "1-ldap.groovy: login failed, password bind-password-value\n" +
+		scriptFailureMarker + "2-token.groovy: invalid token bind-password-value\n" +
+		scriptFailureMarker + "3-job.groovy: job deploy-application not found\n"
+	newGroovyClient := func(jenkins *v1alpha1.Jenkins, result v1alpha1.BuildStatus, events *fakeRecorder) *Groovy {
+		jenkinsClient := client.NewMockJenkins(ctrl)
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("", nil).AnyTimes()
+		jenkinsClient.EXPECT().GetJob("user-configuration").Return(&gojenkins.Job{
+			Raw: &gojenkins.JobResponse{NextBuildNumber: 1, URL: "http://jenkins:8080/job/user-configuration/"},
+		}, nil).AnyTimes()
+		jenkinsClient.EXPECT().BuildJob("user-configuration", gomock.Any()).Return(int64(0), nil).AnyTimes()
+		jenkinsClient.EXPECT().GetBuild("user-configuration", int64(1)).Return(&gojenkins.Build{
+			Raw: &gojenkins.BuildResponse{Result: string(result)},
+		}, nil).AnyTimes()
+		jenkinsClient.EXPECT().GetBuildConsoleOutput("user-configuration", int64(1)).Return(output, nil).AnyTimes()
+		return New(jenkinsClient, fake.NewFakeClient(jenkins, secret), logf.ZapLogger(false), events, "user-configuration", "/scripts")
+	}
+	assertMasked := func(t *testing.T, messages []string) {
+		assert.NotEmpty(t, messages)
+		for _, message := range messages {
+			assert.NotContains(t, message, "bind-password-value")
+		}
+	}
+
+	t.Run("console output of the failed build", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"}}
+		events := &fakeRecorder{}
+		groovyClient := newGroovyClient(jenkins, v1alpha1.BuildFailureStatus, events)
+
+		_, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+		assert.NoError(t, err)
+		_, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{FailFast: true})
+
+		assert.Equal(t, jobs.ErrorBuildFailed, err)
+		assertMasked(t, events.messages)
+	})
+	t.Run("scripts reported as failed by the job", func(t *testing.T) {
+		jenkins := &v1alpha1.Jenkins{ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"}}
+		events := &fakeRecorder{}
+		groovyClient := newGroovyClient(jenkins, v1alpha1.BuildSuccessStatus, events)
+
+		_, err := groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+		assert.NoError(t, err)
+		_, err = groovyClient.EnsureChangedGroovyScripts(scripts, jenkins, Options{})
+
+		assert.NoError(t, err)
+		assert.Len(t, events.messages, 3)
+		assertMasked(t, events.messages)
+		assert.Contains(t, events.messages[2], "deploy-application")
+	})
+}
+
+type fakeRecorder struct {
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSubstituteSecretReferences(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "artifactory", Namespace: "default"},
+		Data: map[string][]byte{
+			"token": []byte("artifactory-token"),
+		},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+
+	t.Run("happy", func(t *testing.T) {
+		events := &fakeRecorder{}
+		groovyClient := New(nil, fake.NewFakeClient(secret), logf.ZapLogger(false), events, "job", "/scripts")
+		values := map[string]string{
+			"ARTIFACTORY_URL":   "https://artifactory.example.com",
+			"ARTIFACTORY_TOKEN": "${secret:artifactory:token}",
+		}
+
+		substituted, resolved, err := groovyClient.SubstituteSecretReferences(values, "Global environment variable", jenkins)
+
+		assert.NoError(t, err)
+		assert.True(t, resolved)
+		assert.Equal(t, map[string]string{
+			"ARTIFACTORY_URL":   "https://artifactory.example.com",
+			"ARTIFACTORY_TOKEN": "artifactory-token",
+		}, substituted)
+		assert.Empty(t, events.messages)
+	})
+	t.Run("fail, missing key", func(t *testing.T) {
+		events := &fakeRecorder{}
+		groovyClient := New(nil, fake.NewFakeClient(secret), logf.ZapLogger(false), events, "job", "/scripts")
+		values := map[string]string{"ARTIFACTORY_USER": "${secret:artifactory:user}"}
+
+		substituted, resolved, err := groovyClient.SubstituteSecretReferences(values, "Global environment variable", jenkins)
+
+		assert.NoError(t, err)
+		assert.False(t, resolved)
+		assert.Nil(t, substituted)
+		assert.Equal(t, []string{"Global environment variable 'ARTIFACTORY_USER' can't be applied, " +
+			"secret reference '${secret:artifactory:user}' can't be resolved"}, events.messages)
+	})
+}
@@ -0,0 +1,62 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+)
+
+// SettingsFailedPrefix is printed by settings scripts with the exception when settings couldn't be applied
+const SettingsFailedPrefix = "failed\t"
+
+// SettingsScript is the groovy script executed in the script console which applies settings passed as the base64
+// encoded JSON, the script prints lines which report changes made in Jenkins followed by DoneMarker
+// or SettingsFailedPrefix with the exception
+type SettingsScript struct {
+	// Format is the script with the verb replaced by settings, they are read with
+	// new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))
+	Format string
+	// DoneMarker is printed by the script when settings have been applied
+	DoneMarker string
+	// HideOutput omits the unexpected output of the script from the error, the compilation error would contain
+	// the script with settings
+	HideOutput bool
+}
+
+// Execute applies settings with the script and returns lines printed by the script before DoneMarker,
+// the output is masked because the exception can contain values of secret references
+func (s SettingsScript) Execute(jenkinsClient jenkinsclient.Jenkins, settings interface{}) ([]string, error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+	output, err := jenkinsClient.ExecuteScript(fmt.Sprintf(s.Format, base64.StdEncoding.EncodeToString(data)))
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(log.MaskSecrets(output), "\n") {
+		if line = strings.TrimSpace(line); len(line) > 0 {
+			lines = append(lines, line)
+		}
+	}
+	last := ""
+	if len(lines) > 0 {
+		last = lines[len(lines)-1]
+	}
+	if strings.HasPrefix(last, SettingsFailedPrefix) {
+		return nil, stackerr.New(strings.TrimPrefix(last, SettingsFailedPrefix))
+	} else if last != s.DoneMarker {
+		if s.HideOutput {
+			return nil, stackerr.New("unexpected output of the script")
+		}
+		return nil, stackerr.Errorf("unexpected output of the script: %s", strings.Join(lines, "\n"))
+	}
+	return lines[:len(lines)-1], nil
+}
@@ -0,0 +1,64 @@
+package groovy
+
+import (
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/groovy/groovytest"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettingsScriptExecute(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	script := SettingsScript{
+		Format:     "def settings = new groovy.json.JsonSlurper().parseText(new String('%s'.decodeBase64(), 'UTF-8'))",
+		DoneMarker: "settings applied",
+	}
+	settings := map[string]string{"name": "value"}
+
+	t.Run("lines printed before the marker are returned", func(t *testing.T) {
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).DoAndReturn(func(script string) (string, error) {
+			assert.Equal(t, `{"name":"value"}`, groovytest.DecodeSettings(t, script))
+			return "changed\tfirst\nchanged\tsecond\nsettings applied\n", nil
+		})
+
+		lines, err := script.Execute(jenkinsClient, settings)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"changed\tfirst", "changed\tsecond"}, lines)
+	})
+	t.Run("nothing changed", func(t *testing.T) {
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("settings applied", nil)
+
+		lines, err := script.Execute(jenkinsClient, settings)
+		assert.NoError(t, err)
+		assert.Empty(t, lines)
+	})
+	t.Run("failed with masked exception", func(t *testing.T) {
+		log.ReplaceSecrets(log.SecretOwner{Namespace: "default", Name: "settings", Source: "test"}, "settings-secret")
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("changed\tfirst\n"+SettingsFailedPrefix+
+			"java.io.IOException: can't save settings-secret", nil)
+
+		_, err := script.Execute(jenkinsClient, settings)
+		assert.EqualError(t, err, "java.io.IOException: can't save "+log.SecretPlaceholder)
+	})
+	t.Run("unexpected output", func(t *testing.T) {
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("groovy.lang.MissingPropertyException", nil)
+
+		_, err := script.Execute(jenkinsClient, settings)
+		assert.EqualError(t, err, "unexpected output of the script: groovy.lang.MissingPropertyException")
+	})
+	t.Run("unexpected output is hidden", func(t *testing.T) {
+		hidden := script
+		hidden.HideOutput = true
+		jenkinsClient.EXPECT().ExecuteScript(gomock.Any()).Return("startup failed: 'c2VjcmV0'.decodeBase64()", nil)
+
+		_, err := hidden.Execute(jenkinsClient, settings)
+		assert.EqualError(t, err, "unexpected output of the script")
+	})
+}
@@ -0,0 +1,64 @@
+package groovy
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/event"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+)
+
+const (
+	// skipSyntaxCheckMarker disables the syntax check of the script which contains it, e.g. in a comment,
+	// it's meant for scripts using classes which are available only when the script is executed
+	skipSyntaxCheckMarker = "jenkins-operator:skip-syntax-check"
+
+	// reasonScriptSyntaxError is the event which informs the groovy script can't be compiled
+	reasonScriptSyntaxError event.Reason = "GroovyScriptSyntaxError"
+
+	// secretReferencePlaceholder replaces secret references in scripts sent to the syntax check
+	secretReferencePlaceholder = "****"
+)
+
+// ErrorScriptSyntax - this is custom error returned when groovy script can't be compiled, scripts aren't executed
+var ErrorScriptSyntax = fmt.Errorf("groovy script syntax error")
+
+// syntaxCheckScript compiles the base64 encoded script without running it, only compiler messages are printed
+// without source lines because the script can contain credentials
+const syntaxCheckScript = `
+import org.codehaus.groovy.control.MultipleCompilationErrorsException
+
+def script = new String('%s'.decodeBase64(), 'UTF-8')
+try {
+    new GroovyShell(Jenkins.instance.pluginManager.uberClassLoader).parse(script)
+} catch (MultipleCompilationErrorsException e) {
+    print e.errorCollector.errors.collect { it.respondsTo('getCause') ? it.cause.message : it.message }.join('\n')
+}
+`
+
+// checkSyntax compiles selected scripts in Jenkins script console, it returns scripts which can't be compiled
+func (g *Groovy) checkSyntax(scripts map[string]string, selected []string, jenkins *v1alpha1.Jenkins) ([]string, error) {
+	var invalid []string
+	for _, key := range selected {
+		script := scripts[key]
+		if strings.Contains(script, skipSyntaxCheckMarker) {
+			g.logger.V(log.VDebug).Info(fmt.Sprintf("Skipping syntax check of script '%s'", key))
+			continue
+		}
+
+		script = secretReferenceRegexp.ReplaceAllString(script, secretReferencePlaceholder)
+		output, err := g.jenkinsClient.ExecuteScript(fmt.Sprintf(syntaxCheckScript, base64.StdEncoding.EncodeToString([]byte(script))))
+		if err != nil {
+			return nil, err
+		}
+		if message := strings.TrimSpace(output); len(message) > 0 {
+			g.logger.V(log.VWarn).Info(fmt.Sprintf("Script '%s' can't be compiled: %s", key, message))
+			g.events.Emitf(jenkins, event.TypeWarning, reasonScriptSyntaxError, "Script '%s' can't be compiled: %s", key, message)
+			invalid = append(invalid, key)
+		}
+	}
+
+	return invalid, nil
+}
@@ -1,11 +1,17 @@
 package jenkins
 
 import (
+	"context"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/log"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -52,3 +58,106 @@ func (e *enqueueRequestForJenkins) getOwnerReconcileRequests(object metav1.Objec
 
 	return nil
 }
+
+// enqueueRequestForReferences enqueues a Request for every Jenkins CR which references the object by its name,
+// references are ConfigMaps or Secrets listed in Jenkins.Spec, e.g. user configuration or JCasC files.
+type enqueueRequestForReferences struct {
+	client     client.Client
+	references func(jenkins *v1alpha1.Jenkins) []string
+}
+
+func (e *enqueueRequestForReferences) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.addReferencingJenkins(evt.Meta, q)
+}
+
+func (e *enqueueRequestForReferences) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.addReferencingJenkins(evt.MetaNew, q)
+}
+
+func (e *enqueueRequestForReferences) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.addReferencingJenkins(evt.Meta, q)
+}
+
+func (e *enqueueRequestForReferences) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.addReferencingJenkins(evt.Meta, q)
+}
+
+func (e *enqueueRequestForReferences) addReferencingJenkins(object metav1.Object, q workqueue.RateLimitingInterface) {
+	jenkinsList := &v1alpha1.JenkinsList{}
+	err := e.client.List(context.TODO(), client.InNamespace(object.GetNamespace()), jenkinsList)
+	if err != nil {
+		log.Log.Error(err, "Failed to list Jenkins CRs", "namespace", object.GetNamespace())
+		return
+	}
+
+	for i := range jenkinsList.Items {
+		jenkins := &jenkinsList.Items[i]
+		for _, name := range e.references(jenkins) {
+			if name == object.GetName() {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: jenkins.Namespace,
+					Name:      jenkins.Name,
+				}})
+				break
+			}
+		}
+	}
+}
+
+func localObjectReferenceNames(references []corev1.LocalObjectReference) []string {
+	var names []string
+	for _, reference := range references {
+		names = append(names, reference.Name)
+	}
+	return names
+}
+
+// enqueueRequestForLabels enqueues a Request for every Jenkins CR in the namespace of the object which selects
+// the object by the label key, both old and new labels are checked so removing the label is noticed as well.
+type enqueueRequestForLabels struct {
+	client   client.Client
+	labelKey func(jenkins *v1alpha1.Jenkins) string
+}
+
+func (e *enqueueRequestForLabels) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.addSelectingJenkins(q, evt.Meta)
+}
+
+func (e *enqueueRequestForLabels) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	e.addSelectingJenkins(q, evt.MetaOld, evt.MetaNew)
+}
+
+func (e *enqueueRequestForLabels) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.addSelectingJenkins(q, evt.Meta)
+}
+
+func (e *enqueueRequestForLabels) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.addSelectingJenkins(q, evt.Meta)
+}
+
+func (e *enqueueRequestForLabels) addSelectingJenkins(q workqueue.RateLimitingInterface, objects ...metav1.Object) {
+	namespace := objects[0].GetNamespace()
+	jenkinsList := &v1alpha1.JenkinsList{}
+	err := e.client.List(context.TODO(), client.InNamespace(namespace), jenkinsList)
+	if err != nil {
+		log.Log.Error(err, "Failed to list Jenkins CRs", "namespace", namespace)
+		return
+	}
+
+	for i := range jenkinsList.Items {
+		jenkins := &jenkinsList.Items[i]
+		labelKey := e.labelKey(jenkins)
+		if len(labelKey) == 0 {
+			continue
+		}
+		for _, object := range objects {
+			if _, ok := object.GetLabels()[labelKey]; ok {
+				q.Add(reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: jenkins.Namespace,
+					Name:      jenkins.Name,
+				}})
+				break
+			}
+		}
+	}
+}
@@ -3,12 +3,18 @@ package jenkins
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	jenkinsclient "github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/base/resources"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/credentials"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/vault"
 	"github.com/oldsj/jenkins-operator/pkg/event"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
@@ -34,6 +40,10 @@ const (
 	reasonUserConfigurationSuccess event.Reason = "BaseConfigurationFailure"
 	// reasonCRValidationFailure is the event which informs user has provided invalid configuration in Jenkins CR
 	reasonCRValidationFailure event.Reason = "CRValidationFailure"
+	// reasonCRValidationWarning is the event which informs user has provided questionable configuration in Jenkins CR
+	reasonCRValidationWarning event.Reason = "CRValidationWarning"
+	// reasonVaultUnavailable is the event which informs secret references can't be resolved because Vault is unavailable
+	reasonVaultUnavailable event.Reason = "VaultUnavailable"
 )
 
 // Add creates a new Jenkins Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -87,6 +97,106 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return errors.WithStack(err)
 	}
 
+	// Watch for changes to user configuration configmaps and secrets listed in Jenkins CRs
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			return jenkins.Spec.Configuration.ConfigMaps
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			return jenkins.Spec.Configuration.Secrets
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Watch for changes to the admin credentials secret referenced by Jenkins CRs
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			if jenkins.Spec.Master.AdminCredentialsSecretRef == nil {
+				return nil
+			}
+			return []string{jenkins.Spec.Master.AdminCredentialsSecretRef.Name}
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Watch for changes to secrets of Jenkins credentials referenced by Jenkins CRs
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			var names []string
+			for _, jenkinsCredentials := range jenkins.Spec.Credentials {
+				names = append(names, jenkinsCredentials.SecretRef.Name)
+			}
+			return names
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Watch for changes to secrets discovered as Jenkins credentials by the label
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &enqueueRequestForLabels{
+		client:   mgr.GetClient(),
+		labelKey: credentials.GetTypeLabel,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Watch for changes to JCasC configmaps and secrets referenced by Jenkins CRs
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			if jenkins.Spec.ConfigurationAsCode == nil {
+				return nil
+			}
+			return localObjectReferenceNames(jenkins.Spec.ConfigurationAsCode.ConfigMaps)
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			if jenkins.Spec.ConfigurationAsCode == nil {
+				return nil
+			}
+			return localObjectReferenceNames(jenkins.Spec.ConfigurationAsCode.Secrets)
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Watch for changes to the plugins.txt configmap referenced by Jenkins CRs
+	err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, &enqueueRequestForReferences{
+		client: mgr.GetClient(),
+		references: func(jenkins *v1alpha1.Jenkins) []string {
+			if jenkins.Spec.Master.PluginsConfigMapRef == nil {
+				return nil
+			}
+			return []string{jenkins.Spec.Master.PluginsConfigMapRef.Name}
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
 	return nil
 }
 
@@ -129,6 +239,8 @@ func (r *ReconcileJenkins) reconcile(request reconcile.Request, logger logr.Logg
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			vault.Forget(request.Namespace, request.Name)
+			log.UnregisterJenkinsSecrets(request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -141,24 +253,56 @@ func (r *ReconcileJenkins) reconcile(request reconcile.Request, logger logr.Logg
 	}
 
 	// Reconcile base configuration
-	baseConfiguration := base.New(r.client, r.scheme, logger, jenkins, r.local, r.minikube)
+	baseConfiguration := base.New(r.client, r.scheme, logger, jenkins, r.local, r.minikube, r.events)
 
 	valid, err := baseConfiguration.Validate(jenkins)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 	if !valid {
-		r.events.Emit(jenkins, event.TypeWarning, reasonCRValidationFailure, "Base CR validation failed")
+		messages := baseConfiguration.ValidationErrors()
+		message := "Base CR validation failed"
+		if len(messages) > 0 {
+			message = fmt.Sprintf("%s: %s", message, strings.Join(messages, "; "))
+		}
+		r.events.Emit(jenkins, event.TypeWarning, reasonCRValidationFailure, message)
 		logger.V(log.VWarn).Info("Validation of base configuration failed, please correct Jenkins CR")
-		return reconcile.Result{}, nil // don't requeue
+		if err := r.updateValidationErrors(jenkins, messages); err != nil {
+			return reconcile.Result{}, err
+		}
+		// don't requeue
+		return reconcile.Result{}, r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+			corev1.ConditionFalse, conditionReasonValidationFailed, message))
 	}
 
 	result, jenkinsClient, err := baseConfiguration.Reconcile()
+	apiReachable := newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionTrue, conditionReasonReachable,
+		"Jenkins API is reachable")
+	if jenkinsClient == nil && err != nil {
+		apiReachable = newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionUnknown,
+			conditionReasonReconcileFailed, err.Error())
+	} else if jenkinsClient == nil {
+		apiReachable = newCondition(v1alpha1.JenkinsConditionJenkinsAPIReachable, corev1.ConditionFalse,
+			conditionReasonPodNotReady, "Jenkins master pod isn't ready")
+	}
+	if jenkinsClient != nil && err == nil {
+		if err := r.updateJenkinsVersion(jenkins, jenkinsClient); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, r.reportFailure(jenkins, logger, err, apiReachable,
+			newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady, corev1.ConditionFalse, conditionReasonReconcileFailed, err.Error()))
 	}
 	if result.Requeue {
-		return result, nil
+		conditions := []v1alpha1.JenkinsCondition{apiReachable, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+			corev1.ConditionFalse, conditionReasonInProgress, "Base configuration is in progress")}
+		// the user configuration of the new Jenkins master pod is applied after the base configuration
+		if jenkins.Status.UserConfigurationCompletedTime == nil {
+			conditions = append(conditions, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+				corev1.ConditionFalse, conditionReasonInProgress, "User configuration waits for the base configuration"))
+		}
+		return result, r.updateConditions(jenkins, conditions...)
 	}
 
 	if jenkins.Status.BaseConfigurationCompletedTime == nil {
@@ -172,25 +316,74 @@ func (r *ReconcileJenkins) reconcile(request reconcile.Request, logger logr.Logg
 			jenkins.Status.BaseConfigurationCompletedTime.Sub(jenkins.Status.ProvisionStartTime.Time)))
 		r.events.Emit(jenkins, event.TypeNormal, reasonBaseConfigurationSuccess, "Base configuration completed")
 	}
+	err = r.updateConditions(jenkins, apiReachable, newCondition(v1alpha1.JenkinsConditionBaseConfigurationReady,
+		corev1.ConditionTrue, conditionReasonCompleted, "Base configuration has been applied"))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	// User configuration phase is skipped when disabled, it completes immediately
+	if !resources.IsUserConfigurationEnabled(jenkins) {
+		if err := r.updateValidationErrors(jenkins, nil); err != nil {
+			return reconcile.Result{}, err
+		}
+		if jenkins.Status.UserConfigurationCompletedTime == nil {
+			now := metav1.Now()
+			jenkins.Status.UserConfigurationCompletedTime = &now
+			err = r.client.Update(context.TODO(), jenkins)
+			if err != nil {
+				return reconcile.Result{}, errors.WithStack(err)
+			}
+			logger.Info("User configuration phase is disabled")
+			r.events.Emit(jenkins, event.TypeNormal, reasonUserConfigurationSuccess, "User configuration disabled")
+		}
+		return reconcile.Result{}, r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+			corev1.ConditionTrue, conditionReasonDisabled, "User configuration is disabled"))
+	}
+
 	// Reconcile user configuration
-	userConfiguration := user.New(r.client, jenkinsClient, logger, jenkins)
+	userConfiguration := user.New(r.client, jenkinsClient, logger, jenkins, r.events)
 
-	valid, err = userConfiguration.Validate(jenkins)
+	messages, err := userConfiguration.Validate(jenkins)
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	if !valid {
+	if len(messages) > 0 {
+		message := fmt.Sprintf("User CR validation failed: %s", strings.Join(messages, "; "))
 		logger.V(log.VWarn).Info("Validation of user configuration failed, please correct Jenkins CR")
-		r.events.Emit(jenkins, event.TypeWarning, reasonCRValidationFailure, "User CR validation failed")
-		return reconcile.Result{}, nil // don't requeue
+		r.events.Emit(jenkins, event.TypeWarning, reasonCRValidationFailure, message)
+		if err := r.updateValidationErrors(jenkins, messages); err != nil {
+			return reconcile.Result{}, err
+		}
+		// don't requeue
+		return reconcile.Result{}, r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+			corev1.ConditionFalse, conditionReasonValidationFailed, message))
+	}
+	if err := r.updateValidationErrors(jenkins, nil); err != nil {
+		return reconcile.Result{}, err
+	}
+	if warnings := userConfiguration.Warnings(jenkins); len(warnings) > 0 {
+		r.events.Emitf(jenkins, event.TypeWarning, reasonCRValidationWarning, "User CR validation warning: %s", strings.Join(warnings, "; "))
 	}
 
 	result, err = userConfiguration.Reconcile()
+	if vault.IsUnavailable(err) {
+		// Vault outages are transient, the reconciliation is retried with the backoff
+		logger.V(log.VWarn).Info(err.Error())
+		r.events.Emit(jenkins, event.TypeWarning, reasonVaultUnavailable, err.Error())
+		err = r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+			corev1.ConditionFalse, conditionReasonVaultUnavailable, err.Error()))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{Requeue: true}, nil
+	}
 	if err != nil {
-		return reconcile.Result{}, err
+		return reconcile.Result{}, r.reportFailure(jenkins, logger, err, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+			corev1.ConditionFalse, conditionReasonReconcileFailed, err.Error()))
 	}
 	if result.Requeue {
-		return result, nil
+		return result, r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+			corev1.ConditionFalse, conditionReasonInProgress, "User configuration is in progress"))
 	}
 
 	if jenkins.Status.UserConfigurationCompletedTime == nil {
@@ -205,7 +398,31 @@ func (r *ReconcileJenkins) reconcile(request reconcile.Request, logger logr.Logg
 		r.events.Emit(jenkins, event.TypeNormal, reasonUserConfigurationSuccess, "User configuration completed")
 	}
 
-	return reconcile.Result{}, nil
+	return result, r.updateConditions(jenkins, newCondition(v1alpha1.JenkinsConditionUserConfigurationReady,
+		corev1.ConditionTrue, conditionReasonCompleted, "User configuration has been applied"))
+}
+
+// updateValidationErrors saves reasons why Jenkins CR is invalid in Jenkins.Status.ValidationErrors,
+// the status is updated only when the reasons have changed
+func (r *ReconcileJenkins) updateValidationErrors(jenkins *v1alpha1.Jenkins, messages []string) error {
+	if len(messages) == 0 && len(jenkins.Status.ValidationErrors) == 0 || reflect.DeepEqual(messages, jenkins.Status.ValidationErrors) {
+		return nil
+	}
+
+	jenkins.Status.ValidationErrors = messages
+	return errors.WithStack(r.client.Update(context.TODO(), jenkins))
+}
+
+// updateJenkinsVersion saves the version of Jenkins reported by Jenkins API in Jenkins.Status.JenkinsVersion,
+// the status is updated only when the version has changed
+func (r *ReconcileJenkins) updateJenkinsVersion(jenkins *v1alpha1.Jenkins, jenkinsClient jenkinsclient.Jenkins) error {
+	version := jenkinsClient.GetVersion()
+	if len(version) == 0 || version == jenkins.Status.JenkinsVersion {
+		return nil
+	}
+
+	jenkins.Status.JenkinsVersion = version
+	return r.client.Update(context.TODO(), jenkins) // don't wrap because apierrors.IsConflict(err) won't work in Reconcile
 }
 
 func (r *ReconcileJenkins) buildLogger(jenkinsName string) logr.Logger {
@@ -224,10 +441,42 @@ func (r *ReconcileJenkins) setDefaults(jenkins *v1alpha1.Jenkins, logger logr.Lo
 		changed = true
 		jenkins.Spec.Master.OperatorPlugins = plugins.BasePlugins()
 	}
-	if len(jenkins.Spec.Master.Plugins) == 0 {
+	// the explicitly empty map keeps Jenkins without user plugins
+	if jenkins.Spec.Master.Plugins == nil {
+		logger.Info("Setting default user plugins")
 		changed = true
 		jenkins.Spec.Master.Plugins = map[string][]string{"simple-theme-plugin:0.5.1": {}}
 	}
+	if jenkins.Spec.Master.NumExecutors == nil {
+		numExecutors := int32(resources.GetMasterExecutors(jenkins))
+		logger.Info(fmt.Sprintf("Setting default number of Jenkins master executors: %d", numExecutors))
+		changed = true
+		jenkins.Spec.Master.NumExecutors = &numExecutors
+	}
+	if jenkins.Spec.Master.DisableUsageStats == nil {
+		disableUsageStats := true
+		logger.Info("Setting default usage stats: disabled")
+		changed = true
+		jenkins.Spec.Master.DisableUsageStats = &disableUsageStats
+	}
+	for i, seedJob := range jenkins.Spec.SeedJobs {
+		// the branch is ignored when the seed job is pinned to a revision
+		if len(seedJob.RepositoryBranch) == 0 && len(seedJob.Revision) == 0 {
+			logger.Info(fmt.Sprintf("Setting default repository branch for seed job '%s': %s", seedJob.ID, constants.DefaultSeedJobRepositoryBranch))
+			changed = true
+			jenkins.Spec.SeedJobs[i].RepositoryBranch = constants.DefaultSeedJobRepositoryBranch
+		}
+		if len(seedJob.GCPolicy) == 0 {
+			logger.Info(fmt.Sprintf("Setting default GC policy for seed job '%s': %s", seedJob.ID, v1alpha1.SeedJobGCPolicyOrphan))
+			changed = true
+			jenkins.Spec.SeedJobs[i].GCPolicy = v1alpha1.SeedJobGCPolicyOrphan
+		}
+		if seedJob.BuildTimeout == nil {
+			logger.Info(fmt.Sprintf("Setting default build timeout for seed job '%s': %s", seedJob.ID, constants.DefaultSeedJobBuildTimeout))
+			changed = true
+			jenkins.Spec.SeedJobs[i].BuildTimeout = &metav1.Duration{Duration: constants.DefaultSeedJobBuildTimeout}
+		}
+	}
 	_, requestCPUSet := jenkins.Spec.Master.Resources.Requests[corev1.ResourceCPU]
 	_, requestMemporySet := jenkins.Spec.Master.Resources.Requests[corev1.ResourceMemory]
 	_, limitCPUSet := jenkins.Spec.Master.Resources.Limits[corev1.ResourceCPU]
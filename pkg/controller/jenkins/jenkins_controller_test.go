@@ -0,0 +1,150 @@
+package jenkins
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+func TestSetDefaultPlugins(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	getPlugins := func(plugins map[string][]string) map[string][]string {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+			Spec:       v1alpha1.JenkinsSpec{Master: v1alpha1.JenkinsMaster{Plugins: plugins}},
+		}
+		fakeClient := fake.NewFakeClient(jenkins)
+		reconciler := &ReconcileJenkins{client: fakeClient}
+		assert.NoError(t, reconciler.setDefaults(jenkins, logf.ZapLogger(false)))
+
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		return stored.Spec.Master.Plugins
+	}
+
+	// the theme plugin is installed only when plugins aren't set
+	assert.Equal(t, map[string][]string{"simple-theme-plugin:0.5.1": {}}, getPlugins(nil))
+	assert.Equal(t, map[string][]string{}, getPlugins(map[string][]string{}))
+	assert.Equal(t, map[string][]string{"git:3.9.1": {}}, getPlugins(map[string][]string{"git:3.9.1": {}}))
+}
+
+func TestSetDefaultNumExecutors(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	getNumExecutors := func(numExecutors *int32, seedAgent v1alpha1.SeedAgent) *int32 {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				Master:    v1alpha1.JenkinsMaster{NumExecutors: numExecutors},
+				SeedAgent: seedAgent,
+			},
+		}
+		fakeClient := fake.NewFakeClient(jenkins)
+		reconciler := &ReconcileJenkins{client: fakeClient}
+		assert.NoError(t, reconciler.setDefaults(jenkins, logf.ZapLogger(false)))
+
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		return stored.Spec.Master.NumExecutors
+	}
+	numExecutors := func(value int32) *int32 {
+		return &value
+	}
+
+	assert.Equal(t, numExecutors(3), getNumExecutors(nil, v1alpha1.SeedAgent{}))
+	assert.Equal(t, numExecutors(1), getNumExecutors(nil, v1alpha1.SeedAgent{Enabled: true, RestrictMasterExecutors: true}))
+	// zero executors are kept
+	assert.Equal(t, numExecutors(0), getNumExecutors(numExecutors(0), v1alpha1.SeedAgent{}))
+}
+
+func TestSetDefaultDisableUsageStats(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	getDisableUsageStats := func(disableUsageStats *bool) *bool {
+		jenkins := &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+			Spec:       v1alpha1.JenkinsSpec{Master: v1alpha1.JenkinsMaster{DisableUsageStats: disableUsageStats}},
+		}
+		fakeClient := fake.NewFakeClient(jenkins)
+		reconciler := &ReconcileJenkins{client: fakeClient}
+		assert.NoError(t, reconciler.setDefaults(jenkins, logf.ZapLogger(false)))
+
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		return stored.Spec.Master.DisableUsageStats
+	}
+	disableUsageStats := func(value bool) *bool {
+		return &value
+	}
+
+	assert.Equal(t, disableUsageStats(true), getDisableUsageStats(nil))
+	// enabled usage stats are kept
+	assert.Equal(t, disableUsageStats(false), getDisableUsageStats(disableUsageStats(false)))
+}
+
+func TestSetDefaultRepositoryBranch(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			SeedJobs: []v1alpha1.SeedJob{
+				{ID: "branch"},
+				{ID: "revision", Revision: "v1.0.0"},
+				{ID: "release", RepositoryBranch: "release"},
+			},
+		},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	reconciler := &ReconcileJenkins{client: fakeClient}
+	assert.NoError(t, reconciler.setDefaults(jenkins, logf.ZapLogger(false)))
+
+	stored := &v1alpha1.Jenkins{}
+	assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+	assert.Equal(t, constants.DefaultSeedJobRepositoryBranch, stored.Spec.SeedJobs[0].RepositoryBranch)
+	// the branch is ignored when the seed job is pinned to a revision
+	assert.Empty(t, stored.Spec.SeedJobs[1].RepositoryBranch)
+	assert.Equal(t, "release", stored.Spec.SeedJobs[2].RepositoryBranch)
+}
+
+func TestUpdateJenkinsVersion(t *testing.T) {
+	assert.NoError(t, v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme))
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+	}
+	fakeClient := fake.NewFakeClient(jenkins)
+	reconciler := &ReconcileJenkins{client: fakeClient}
+	getVersion := func() string {
+		stored := &v1alpha1.Jenkins{}
+		assert.NoError(t, fakeClient.Get(context.TODO(), types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, stored))
+		return stored.Status.JenkinsVersion
+	}
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	gomock.InOrder(
+		jenkinsClient.EXPECT().GetVersion().Return("2.150.1"),
+		jenkinsClient.EXPECT().GetVersion().Return(""),
+		jenkinsClient.EXPECT().GetVersion().Return("2.150.2"),
+	)
+
+	assert.NoError(t, reconciler.updateJenkinsVersion(jenkins, jenkinsClient))
+	assert.Equal(t, "2.150.1", getVersion())
+
+	// the version isn't cleared when Jenkins API doesn't report it
+	assert.NoError(t, reconciler.updateJenkinsVersion(jenkins, jenkinsClient))
+	assert.Equal(t, "2.150.1", getVersion())
+
+	assert.NoError(t, reconciler.updateJenkinsVersion(jenkins, jenkinsClient))
+	assert.Equal(t, "2.150.2", getVersion())
+}
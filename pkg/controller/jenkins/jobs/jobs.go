@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
+	"github.com/bndr/gojenkins"
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,25 +28,85 @@ var (
 	ErrorAbortBuildFailed = fmt.Errorf("build abort failed")
 	// ErrorUnrecoverableBuildFailed - this is custom error returned when jenkins build has failed and cannot be recovered
 	ErrorUnrecoverableBuildFailed = fmt.Errorf("build failed and cannot be recovered")
+	// ErrorBuildTimeout - this is custom error returned when jenkins build exceeded timeout and has been aborted
+	ErrorBuildTimeout = fmt.Errorf("build timed out and has been aborted")
 	// ErrorNotFound - this is error returned when jenkins build couldn't be found
 	ErrorNotFound = fmt.Errorf("404")
 	// BuildRetires - determines max amount of retires for failed build
 	BuildRetires = 3
 )
 
+const (
+	// consoleOutputLogSize is the maximum size of the failed build console output written to the operator log
+	consoleOutputLogSize = 64 * 1024
+	// consoleOutputEventSize is the maximum size of the failed build console output excerpt attached to the event
+	consoleOutputEventSize = 1024
+
+	// reasonBuildFailed is the event which informs the build failed, it contains the console output excerpt
+	reasonBuildFailed event.Reason = "BuildFailed"
+)
+
+// BuildOptions defines optional limits of the build and its retries
+type BuildOptions struct {
+	// Timeout is the maximum duration of the build, running build is aborted when exceeded, zero means no timeout
+	Timeout time.Duration
+	// Retries is the maximum amount of retries of the failed build, nil means BuildRetires
+	Retries *int
+	// RetryBackoff is the delay before the first retry of the failed build, it's doubled for every next retry
+	RetryBackoff time.Duration
+}
+
+// MaxRetries returns the maximum amount of retries of the failed build
+func (options BuildOptions) MaxRetries() int {
+	if options.Retries != nil {
+		return *options.Retries
+	}
+	return BuildRetires
+}
+
+// retryDelay returns the delay before the given retry of the failed build
+func (options BuildOptions) retryDelay(retry int) time.Duration {
+	return options.RetryBackoff * time.Duration(1<<uint(retry))
+}
+
+// BuildRetention returns the number of the last builds kept by the job, constants.DefaultBuildRetention when not set
+func BuildRetention(buildRetention *int) int {
+	if buildRetention != nil {
+		return *buildRetention
+	}
+	return constants.DefaultBuildRetention
+}
+
+// BuildDiscarderPropertyXML returns the job property which makes Jenkins discard all but the last numToKeep builds,
+// Jenkins applies it to existing builds after the next build of the job
+func BuildDiscarderPropertyXML(numToKeep int) string {
+	return fmt.Sprintf(buildDiscarderPropertyXMLFmt, numToKeep)
+}
+
+const buildDiscarderPropertyXMLFmt = `<jenkins.model.BuildDiscarderProperty>
+      <strategy class="hudson.tasks.LogRotator">
+        <daysToKeep>-1</daysToKeep>
+        <numToKeep>%d</numToKeep>
+        <artifactDaysToKeep>-1</artifactDaysToKeep>
+        <artifactNumToKeep>-1</artifactNumToKeep>
+      </strategy>
+    </jenkins.model.BuildDiscarderProperty>`
+
 // Jobs defines Jobs API tailored for operator sdk
 type Jobs struct {
 	jenkinsClient client.Jenkins
 	logger        logr.Logger
 	k8sClient     k8s.Client
+	events        event.Recorder
 }
 
 // New creates jobs client
-func New(jenkinsClient client.Jenkins, k8sClient k8s.Client, logger logr.Logger) *Jobs {
+func New(jenkinsClient client.Jenkins, k8sClient k8s.Client, logger logr.Logger, events event.Recorder) *Jobs {
 	return &Jobs{
 		jenkinsClient: jenkinsClient,
 		k8sClient:     k8sClient,
 		logger:        logger,
+		events:        events,
 	}
 }
 
@@ -51,7 +115,8 @@ func New(jenkinsClient client.Jenkins, k8sClient k8s.Client, logger logr.Logger)
 // entire state is saved in Jenkins.Status.Builds section
 // function return 'true' when build finished successfully or false when reconciliation loop should requeue this function
 // preserveStatus determines that build won't be removed from Jenkins.Status.Builds section
-func (jobs *Jobs) EnsureBuildJob(jobName, hash string, parameters map[string]string, jenkins *v1alpha1.Jenkins, preserveStatus bool) (done bool, err error) {
+// options determines timeout of the build and retries of the failed build
+func (jobs *Jobs) EnsureBuildJob(jobName, hash string, parameters map[string]string, jenkins *v1alpha1.Jenkins, preserveStatus bool, options BuildOptions) (done bool, err error) {
 	jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Ensuring build, name:'%s' hash:'%s'", jobName, hash))
 
 	build := jobs.getBuildFromStatus(jobName, hash, jenkins)
@@ -61,9 +126,9 @@ func (jobs *Jobs) EnsureBuildJob(jobName, hash string, parameters map[string]str
 		case v1alpha1.BuildSuccessStatus:
 			return jobs.ensureSuccessBuild(*build, jenkins, preserveStatus)
 		case v1alpha1.BuildRunningStatus:
-			return jobs.ensureRunningBuild(*build, jenkins, preserveStatus)
+			return jobs.ensureRunningBuild(*build, jenkins, preserveStatus, options.Timeout)
 		case v1alpha1.BuildUnstableStatus, v1alpha1.BuildNotBuildStatus, v1alpha1.BuildFailureStatus, v1alpha1.BuildAbortedStatus:
-			return jobs.ensureFailedBuild(*build, jenkins, parameters, preserveStatus)
+			return jobs.ensureFailedBuild(*build, jenkins, parameters, preserveStatus, options)
 		case v1alpha1.BuildExpiredStatus:
 			return jobs.ensureExpiredBuild(*build, jenkins, preserveStatus)
 		default:
@@ -82,6 +147,20 @@ func (jobs *Jobs) EnsureBuildJob(jobName, hash string, parameters map[string]str
 	return jobs.buildJob(newBuild, parameters, jenkins)
 }
 
+// RerunBuildJob runs a new build of the job with the same hash, the build in Jenkins.Status.Builds section is replaced
+// and its retries are reset, the new build is tracked by EnsureBuildJob
+func (jobs *Jobs) RerunBuildJob(jobName, hash string, parameters map[string]string, jenkins *v1alpha1.Jenkins) (done bool, err error) {
+	jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Rerunning build, name:'%s' hash:'%s'", jobName, hash))
+
+	created := metav1.Now()
+	build := v1alpha1.Build{
+		JobName:    jobName,
+		Hash:       hash,
+		CreateTime: &created,
+	}
+	return jobs.buildJob(build, parameters, jenkins)
+}
+
 func (jobs *Jobs) getBuildFromStatus(jobName string, hash string, jenkins *v1alpha1.Jenkins) *v1alpha1.Build {
 	if jenkins != nil {
 		builds := jenkins.Status.Builds
@@ -107,9 +186,8 @@ func (jobs *Jobs) ensureSuccessBuild(build v1alpha1.Build, jenkins *v1alpha1.Jen
 	return true, nil
 }
 
-func (jobs *Jobs) ensureRunningBuild(build v1alpha1.Build, jenkins *v1alpha1.Jenkins, preserveStatus bool) (bool, error) {
+func (jobs *Jobs) ensureRunningBuild(build v1alpha1.Build, jenkins *v1alpha1.Jenkins, preserveStatus bool, timeout time.Duration) (bool, error) {
 	jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Ensuring running build, %+v", build))
-	// FIXME (antoniaklja) implement build expiration
 
 	jenkinsBuild, err := jobs.jenkinsClient.GetBuild(build.JobName, build.Number)
 	if isNotFoundError(err) {
@@ -122,6 +200,8 @@ func (jobs *Jobs) ensureRunningBuild(build v1alpha1.Build, jenkins *v1alpha1.Jen
 
 	if jenkinsBuild.GetResult() != "" {
 		build.Status = v1alpha1.BuildStatus(strings.ToLower(jenkinsBuild.GetResult()))
+	} else if timeout > 0 && time.Since(jenkinsBuild.GetTimestamp()) > timeout {
+		return jobs.abortBuild(build, jenkinsBuild, jenkins, timeout)
 	}
 
 	err = jobs.updateBuildStatus(build, jenkins)
@@ -138,16 +218,42 @@ func (jobs *Jobs) ensureRunningBuild(build v1alpha1.Build, jenkins *v1alpha1.Jen
 	if build.Status == v1alpha1.BuildFailureStatus || build.Status == v1alpha1.BuildUnstableStatus ||
 		build.Status == v1alpha1.BuildNotBuildStatus || build.Status == v1alpha1.BuildAbortedStatus {
 		jobs.logger.V(log.VWarn).Info(fmt.Sprintf("Build failed, %+v", build))
+		jobs.reportFailedBuild(build, jenkins)
 		return false, ErrorBuildFailed
 	}
 
 	return false, nil
 }
 
-func (jobs *Jobs) ensureFailedBuild(build v1alpha1.Build, jenkins *v1alpha1.Jenkins, parameters map[string]string, preserveStatus bool) (bool, error) {
+// abortBuild stops the build which exceeded timeout and marks it as aborted, so it can be retried like the failed one
+func (jobs *Jobs) abortBuild(build v1alpha1.Build, jenkinsBuild *gojenkins.Build, jenkins *v1alpha1.Jenkins, timeout time.Duration) (bool, error) {
+	jobs.logger.V(log.VWarn).Info(fmt.Sprintf("Build exceeded timeout %s, aborting, %+v", timeout, build))
+
+	_, err := jenkinsBuild.Stop()
+	if err != nil {
+		jobs.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't abort build, %+v", build))
+		return false, errors.WithStack(err)
+	}
+
+	build.Status = v1alpha1.BuildAbortedStatus
+	err = jobs.updateBuildStatus(build, jenkins)
+	if err != nil {
+		jobs.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't update build status, %+v", build))
+		return false, err
+	}
+
+	return false, ErrorBuildTimeout
+}
+
+func (jobs *Jobs) ensureFailedBuild(build v1alpha1.Build, jenkins *v1alpha1.Jenkins, parameters map[string]string, preserveStatus bool, options BuildOptions) (bool, error) {
 	jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Ensuring failed build, %+v", build))
 
-	if build.Retires < BuildRetires {
+	if build.Retires < options.MaxRetries() {
+		delay := options.retryDelay(build.Retires)
+		if build.LastUpdateTime != nil && time.Since(build.LastUpdateTime.Time) < delay {
+			jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Waiting %s before retrying build, %+v", delay, build))
+			return false, nil
+		}
 		jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Retrying build, %+v", build))
 		build.Retires = build.Retires + 1
 		_, err := jobs.buildJob(build, parameters, jenkins)
@@ -245,6 +351,7 @@ func (jobs *Jobs) buildJob(build v1alpha1.Build, parameters map[string]string, j
 
 	build.Status = v1alpha1.BuildRunningStatus
 	build.Number = nextBuildNumber
+	build.URL = buildURL(job.GetDetails().URL, nextBuildNumber)
 
 	err = jobs.updateBuildStatus(build, jenkins)
 	if err != nil {
@@ -281,6 +388,30 @@ func (jobs *Jobs) updateBuildStatus(build v1alpha1.Build, jenkins *v1alpha1.Jenk
 	return nil
 }
 
+// reportFailedBuild writes the console output of the failed build to the operator log and attaches its excerpt to the event,
+// credentials found in the console output and registered secret values are masked before the excerpt is cut
+func (jobs *Jobs) reportFailedBuild(build v1alpha1.Build, jenkins *v1alpha1.Jenkins) {
+	consoleOutput, err := jobs.jenkinsClient.GetBuildConsoleOutput(build.JobName, build.Number)
+	if err != nil {
+		jobs.logger.V(log.VWarn).Info(fmt.Sprintf("Couldn't get console output of the failed build, %+v: %s", build, err))
+		consoleOutput = ""
+	}
+	consoleOutput = log.MaskSecrets(maskCredentials(consoleOutput))
+
+	jobs.logger.V(log.VDebug).Info(fmt.Sprintf("Console output of the failed build '%s' #%d:\n%s",
+		build.JobName, build.Number, tail(consoleOutput, consoleOutputLogSize)))
+	jobs.events.Emitf(jenkins, event.TypeWarning, reasonBuildFailed, "Build '%s' #%d failed, console output:\n%s",
+		build.JobName, build.Number, tail(consoleOutput, consoleOutputEventSize))
+}
+
+// buildURL returns the Jenkins URL of the build, empty when the job URL is unknown
+func buildURL(jobURL string, number int64) string {
+	if len(jobURL) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d/", strings.TrimSuffix(jobURL, "/"), number)
+}
+
 func isNotFoundError(err error) bool {
 	if err != nil {
 		return err.Error() == ErrorNotFound.Error()
@@ -5,10 +5,15 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/client"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
+	"github.com/oldsj/jenkins-operator/pkg/event"
 
 	"github.com/bndr/gojenkins"
 	"github.com/golang/mock/gomock"
@@ -16,6 +21,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -46,7 +52,7 @@ func TestSuccessEnsureJob(t *testing.T) {
 		logger.Info(fmt.Sprintf("Reconcile attempt #%d", reconcileAttempt))
 		buildNumber := int64(1)
 		jenkinsClient := client.NewMockJenkins(ctrl)
-		jobs := New(jenkinsClient, fakeClient, logger)
+		jobs := New(jenkinsClient, fakeClient, logger, &fakeRecorder{})
 
 		jenkinsClient.
 			EXPECT().
@@ -54,6 +60,7 @@ func TestSuccessEnsureJob(t *testing.T) {
 			Return(&gojenkins.Job{
 				Raw: &gojenkins.JobResponse{
 					NextBuildNumber: buildNumber,
+					URL:             "http://jenkins:8080/job/Test%20Job/",
 				},
 			}, nil).AnyTimes()
 
@@ -71,7 +78,7 @@ func TestSuccessEnsureJob(t *testing.T) {
 				},
 			}, nil).AnyTimes()
 
-		done, err := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true)
+		done, err := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, BuildOptions{})
 		assert.NoError(t, err)
 
 		err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
@@ -84,6 +91,7 @@ func TestSuccessEnsureJob(t *testing.T) {
 		assert.Equal(t, build.JobName, jobName)
 		assert.Equal(t, build.Hash, encodedHash)
 		assert.Equal(t, build.Number, buildNumber)
+		assert.Equal(t, "http://jenkins:8080/job/Test%20Job/1/", build.URL)
 		assert.Equal(t, build.Retires, 0)
 		assert.NotNil(t, build.CreateTime)
 		assert.NotNil(t, build.LastUpdateTime)
@@ -120,10 +128,11 @@ func TestEnsureJobWithFailedBuild(t *testing.T) {
 	err := fakeClient.Create(ctx, jenkins)
 	assert.NoError(t, err)
 
+	events := &fakeRecorder{}
 	for reconcileAttempt := 1; reconcileAttempt <= 4; reconcileAttempt++ {
 		logger.Info(fmt.Sprintf("Reconcile attempt #%d", reconcileAttempt))
 		jenkinsClient := client.NewMockJenkins(ctrl)
-		jobs := New(jenkinsClient, fakeClient, logger)
+		jobs := New(jenkinsClient, fakeClient, logger, events)
 
 		// first run - build should be scheduled and status updated
 		if reconcileAttempt == 1 {
@@ -152,6 +161,11 @@ func TestEnsureJobWithFailedBuild(t *testing.T) {
 						Result: string(v1alpha1.BuildFailureStatus),
 					},
 				}, nil)
+
+			jenkinsClient.
+				EXPECT().
+				GetBuildConsoleOutput(jobName, int64(1)).
+				Return("Started by user admin\nERROR: password=secret\nFinished: FAILURE\n", nil)
 		}
 
 		// third run - build should be rescheduled and status updated
@@ -183,7 +197,7 @@ func TestEnsureJobWithFailedBuild(t *testing.T) {
 				}, nil)
 		}
 
-		done, errEnsureBuildJob := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true)
+		done, errEnsureBuildJob := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, BuildOptions{})
 		assert.NoError(t, err)
 
 		err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
@@ -213,6 +227,9 @@ func TestEnsureJobWithFailedBuild(t *testing.T) {
 			assert.False(t, done)
 			assert.Equal(t, build.Number, int64(1))
 			assert.Equal(t, build.Status, v1alpha1.BuildFailureStatus)
+			assert.Equal(t, []event.Reason{reasonBuildFailed}, events.reasons)
+			assert.Contains(t, events.messages[0], "ERROR: password=****")
+			assert.NotContains(t, events.messages[0], "secret")
 		}
 
 		// third run - build should be rescheduled and status updated
@@ -255,7 +272,7 @@ func TestEnsureJobFailedWithMaxRetries(t *testing.T) {
 	for reconcileAttempt := 1; reconcileAttempt <= 5; reconcileAttempt++ {
 		logger.Info(fmt.Sprintf("Reconcile attempt #%d", reconcileAttempt))
 		jenkinsClient := client.NewMockJenkins(ctrl)
-		jobs := New(jenkinsClient, fakeClient, logger)
+		jobs := New(jenkinsClient, fakeClient, logger, &fakeRecorder{})
 
 		// first run - build should be scheduled and status updated
 		if reconcileAttempt == 1 {
@@ -284,6 +301,11 @@ func TestEnsureJobFailedWithMaxRetries(t *testing.T) {
 						Result: string(v1alpha1.BuildFailureStatus),
 					},
 				}, nil)
+
+			jenkinsClient.
+				EXPECT().
+				GetBuildConsoleOutput(buildName, int64(1)).
+				Return("Finished: FAILURE\n", nil)
 		}
 
 		// third run - build should be rescheduled and status updated
@@ -313,9 +335,14 @@ func TestEnsureJobFailedWithMaxRetries(t *testing.T) {
 						Result: string(v1alpha1.BuildFailureStatus),
 					},
 				}, nil)
+
+			jenkinsClient.
+				EXPECT().
+				GetBuildConsoleOutput(buildName, int64(2)).
+				Return("Finished: FAILURE\n", nil)
 		}
 
-		done, errEnsureBuildJob := jobs.EnsureBuildJob(buildName, encodedHash, nil, jenkins, true)
+		done, errEnsureBuildJob := jobs.EnsureBuildJob(buildName, encodedHash, nil, jenkins, true, BuildOptions{})
 		assert.NoError(t, err)
 
 		err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
@@ -379,6 +406,133 @@ func TestEnsureJobFailedWithMaxRetries(t *testing.T) {
 	}
 }
 
+func TestEnsureJobTimeout(t *testing.T) {
+	// given
+	ctx := context.TODO()
+	logger := logf.ZapLogger(false)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobName := "Test Job"
+	buildNumber := int64(1)
+	hash := sha256.New()
+	hash.Write([]byte(jobName))
+	encodedHash := base64.URLEncoding.EncodeToString(hash.Sum(nil))
+
+	stopped := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/job/test/1/stop":
+			stopped = true
+		case r.URL.Path == "/job/test/1/api/json":
+			_, _ = w.Write([]byte(`{"building": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	jenkins := jenkinsCustomResource()
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	jenkinsClient.
+		EXPECT().
+		GetJob(jobName).
+		Return(&gojenkins.Job{
+			Raw: &gojenkins.JobResponse{
+				NextBuildNumber: buildNumber,
+			},
+		}, nil)
+	jenkinsClient.
+		EXPECT().
+		BuildJob(jobName, gomock.Any()).
+		Return(int64(0), nil)
+	jenkinsClient.
+		EXPECT().
+		GetBuild(jobName, buildNumber).
+		Return(&gojenkins.Build{
+			Jenkins: gojenkins.CreateJenkins(nil, server.URL),
+			Base:    "/job/test/1",
+			Raw: &gojenkins.BuildResponse{
+				Building:  true,
+				Timestamp: time.Now().Add(-time.Hour).UnixNano() / int64(time.Millisecond),
+			},
+		}, nil)
+
+	// when
+	jobs := New(jenkinsClient, fakeClient, logger, &fakeRecorder{})
+	done, err := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, BuildOptions{Timeout: time.Minute})
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	done, err = jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, BuildOptions{Timeout: time.Minute})
+
+	// then
+	assert.Equal(t, ErrorBuildTimeout, err)
+	assert.False(t, done)
+	assert.True(t, stopped)
+
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: jenkins.Name, Namespace: jenkins.Namespace}, jenkins)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jenkins.Status.Builds))
+	assert.Equal(t, v1alpha1.BuildAbortedStatus, jenkins.Status.Builds[0].Status)
+}
+
+func TestEnsureJobRetryPolicy(t *testing.T) {
+	// given
+	ctx := context.TODO()
+	logger := logf.ZapLogger(false)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	jobName := "Test Job"
+	hash := sha256.New()
+	hash.Write([]byte(jobName))
+	encodedHash := base64.URLEncoding.EncodeToString(hash.Sum(nil))
+	retries := 1
+	options := BuildOptions{Retries: &retries, RetryBackoff: time.Hour}
+
+	jenkins := jenkinsCustomResource()
+	now := metav1.Now()
+	jenkins.Status.Builds = []v1alpha1.Build{
+		{
+			JobName:        jobName,
+			Hash:           encodedHash,
+			Number:         1,
+			Status:         v1alpha1.BuildFailureStatus,
+			LastUpdateTime: &now,
+		},
+	}
+	fakeClient := fake.NewFakeClient()
+	err := v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+	assert.NoError(t, err)
+	err = fakeClient.Create(ctx, jenkins)
+	assert.NoError(t, err)
+
+	jenkinsClient := client.NewMockJenkins(ctrl)
+	jobs := New(jenkinsClient, fakeClient, logger, &fakeRecorder{})
+
+	// when - retry backoff hasn't elapsed yet, build isn't retried
+	done, err := jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, options)
+
+	// then
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	// when - retries limit was reached
+	jenkins.Status.Builds[0].Retires = retries
+	done, err = jobs.EnsureBuildJob(jobName, encodedHash, nil, jenkins, true, options)
+
+	// then
+	assert.Equal(t, ErrorUnrecoverableBuildFailed, err)
+	assert.False(t, done)
+}
+
 func jenkinsCustomResource() *v1alpha1.Jenkins {
 	return &v1alpha1.Jenkins{
 		ObjectMeta: metav1.ObjectMeta{
@@ -412,3 +566,24 @@ func jenkinsCustomResource() *v1alpha1.Jenkins {
 		},
 	}
 }
+
+func TestBuildRetention(t *testing.T) {
+	buildRetention := 3
+	assert.Equal(t, constants.DefaultBuildRetention, BuildRetention(nil))
+	assert.Equal(t, 3, BuildRetention(&buildRetention))
+	assert.Contains(t, BuildDiscarderPropertyXML(3), "<numToKeep>3</numToKeep>")
+}
+
+type fakeRecorder struct {
+	reasons  []event.Reason
+	messages []string
+}
+
+func (r *fakeRecorder) Emit(object runtime.Object, eventType event.Type, reason event.Reason, message string) {
+	r.reasons = append(r.reasons, reason)
+	r.messages = append(r.messages, message)
+}
+
+func (r *fakeRecorder) Emitf(object runtime.Object, eventType event.Type, reason event.Reason, format string, args ...interface{}) {
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
+}
@@ -1,5 +1,9 @@
 package plugins
 
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+)
+
 const (
 	apacheComponentsClientPlugin = "apache-httpcomponents-client-4-api:4.5.5-3.0"
 	jackson2ADIPlugin            = "jackson2-api:2.9.8"
@@ -123,15 +127,41 @@ var BasePluginsMap = map[string][]Plugin{
 }
 
 // BasePlugins returns map of plugins to install by operator
-func BasePlugins() (plugins map[string][]string) {
-	plugins = map[string][]string{}
+func BasePlugins() map[string][]string {
+	return pluginsToStrings(BasePluginsMap)
+}
+
+// OperatorPlugins returns plugins to install by operator from Jenkins.Spec.Master.OperatorPlugins
+// with versions replaced by Jenkins.Spec.Master.BasePluginsOverride
+func OperatorPlugins(jenkins *v1alpha1.Jenkins) map[string][]string {
+	overrides := jenkins.Spec.Master.BasePluginsOverride
+	if len(overrides) == 0 {
+		return jenkins.Spec.Master.OperatorPlugins
+	}
 
-	for rootPluginName, dependentPlugins := range BasePluginsMap {
-		plugins[rootPluginName] = []string{}
+	override := func(pluginNameWithVersion string) string {
+		plugin, err := New(pluginNameWithVersion)
+		if err != nil {
+			return pluginNameWithVersion
+		}
+		return overrideVersion(*plugin, overrides).StringWithChecksum()
+	}
+	plugins := map[string][]string{}
+	for rootPluginName, dependentPlugins := range jenkins.Spec.Master.OperatorPlugins {
+		overriddenDependentPlugins := []string{}
 		for _, pluginName := range dependentPlugins {
-			plugins[rootPluginName] = append(plugins[rootPluginName], pluginName.String())
+			overriddenDependentPlugins = append(overriddenDependentPlugins, override(pluginName))
 		}
+		plugins[override(rootPluginName)] = overriddenDependentPlugins
 	}
+	return plugins
+}
 
-	return
+// overrideVersion returns the plugin in the version from Jenkins.Spec.Master.BasePluginsOverride,
+// the overridden plugin is downloaded from the update center without the checksum
+func overrideVersion(plugin Plugin, overrides map[string]string) Plugin {
+	if version, ok := overrides[plugin.Name]; ok {
+		return Plugin{Name: plugin.Name, Version: version}
+	}
+	return plugin
 }
@@ -0,0 +1,226 @@
+package plugins
+
+import (
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+)
+
+// githubPluginsMap contains plugins required by GitHub push trigger
+var githubPluginsMap = map[string][]Plugin{
+	Must(New("github:1.29.3")).String(): {
+		Must(New(credentialsPlugin)),
+		Must(New(displayURLAPIPlugin)),
+		Must(New("git:3.9.1")),
+		Must(New("github-api:1.95")),
+		Must(New(jackson2ADIPlugin)),
+		Must(New(plainCredentialsPlugin)),
+		Must(New(scmAPIPlugin)),
+		Must(New(structsPlugin)),
+		Must(New("token-macro:2.6")),
+	},
+}
+
+// bitbucketPluginsMap contains plugins required by Bitbucket push trigger
+var bitbucketPluginsMap = map[string][]Plugin{
+	Must(New("bitbucket:1.1.8")).String(): {
+		Must(New(credentialsPlugin)),
+		Must(New("git:3.9.1")),
+		Must(New(scmAPIPlugin)),
+		Must(New(structsPlugin)),
+	},
+}
+
+// gerritPluginsMap contains plugins required by Gerrit trigger
+var gerritPluginsMap = map[string][]Plugin{
+	Must(New("gerrit-trigger:2.29.0")).String(): {
+		Must(New(credentialsPlugin)),
+		Must(New("git:3.9.1")),
+		Must(New(scmAPIPlugin)),
+		Must(New(sshCredentialsPlugin)),
+		Must(New(structsPlugin)),
+		Must(New(workflowStepAPIPlugin)),
+	},
+}
+
+// multibranchPluginsMap contains plugins required by multibranch seed job mode
+var multibranchPluginsMap = map[string][]Plugin{
+	Must(New("workflow-multibranch:2.20")).String(): {
+		Must(New("branch-api:2.1.2")),
+		Must(New(cloudBeesFolderPlugin)),
+		Must(New("git:3.9.1")),
+		Must(New(scmAPIPlugin)),
+		Must(New(scriptSecurityPlugin)),
+		Must(New(structsPlugin)),
+		Must(New(workflowAPIPlugin)),
+		Must(New("workflow-job:2.31")),
+		Must(New(workflowSCMStepPlugin)),
+		Must(New(workflowStepAPIPlugin)),
+		Must(New(workflowSupportPlugin)),
+	},
+}
+
+// organizationFolderPluginsMap contains plugins required by organizationFolder seed job mode
+var organizationFolderPluginsMap = map[string][]Plugin{
+	Must(New("github-branch-source:2.4.2")).String(): {
+		Must(New("branch-api:2.1.2")),
+		Must(New(cloudBeesFolderPlugin)),
+		Must(New(credentialsPlugin)),
+		Must(New(displayURLAPIPlugin)),
+		Must(New("git:3.9.1")),
+		Must(New("github:1.29.3")),
+		Must(New("github-api:1.95")),
+		Must(New(jackson2ADIPlugin)),
+		Must(New("okhttp-api:3.12.1")),
+		Must(New(plainCredentialsPlugin)),
+		Must(New(scmAPIPlugin)),
+		Must(New(structsPlugin)),
+		Must(New("token-macro:2.6")),
+	},
+}
+
+// ldapPluginsMap contains plugins required by the LDAP security realm
+var ldapPluginsMap = map[string][]Plugin{
+	Must(New("ldap:1.20")).String(): {
+		Must(New(mailerPlugin)),
+	},
+}
+
+// oidcPluginsMap contains plugins required by the OpenID Connect security realm
+var oidcPluginsMap = map[string][]Plugin{
+	Must(New("oic-auth:1.6")).String(): {},
+}
+
+// matrixAuthorizationPluginsMap contains plugins required by the matrix authorization strategy
+var matrixAuthorizationPluginsMap = map[string][]Plugin{
+	Must(New("matrix-auth:2.3")).String(): {},
+}
+
+// roleStrategyPluginsMap contains plugins required by the role-based authorization strategy
+var roleStrategyPluginsMap = map[string][]Plugin{
+	Must(New("role-strategy:2.10")).String(): {},
+}
+
+// mailerPluginsMap contains plugins required by the SMTP server configuration
+var mailerPluginsMap = map[string][]Plugin{
+	Must(New(mailerPlugin)).String(): {
+		Must(New(displayURLAPIPlugin)),
+	},
+}
+
+// sharedLibrariesPluginsMap contains plugins required by global pipeline shared libraries retrieved from git
+var sharedLibrariesPluginsMap = map[string][]Plugin{
+	Must(New("workflow-cps-global-lib:2.12")).String(): {
+		Must(New("git:3.9.1")),
+		Must(New(scmAPIPlugin)),
+		Must(New(scriptSecurityPlugin)),
+		Must(New(structsPlugin)),
+		Must(New(workflowAPIPlugin)),
+		Must(New(workflowSCMStepPlugin)),
+		Must(New(workflowStepAPIPlugin)),
+		Must(New(workflowSupportPlugin)),
+	},
+}
+
+// gradlePluginsMap contains plugins required by Gradle installations
+var gradlePluginsMap = map[string][]Plugin{
+	Must(New("gradle:1.29")).String(): {
+		Must(New(structsPlugin)),
+		Must(New(workflowAPIPlugin)),
+		Must(New(workflowStepAPIPlugin)),
+	},
+}
+
+// nodeJSPluginsMap contains plugins required by NodeJS installations
+var nodeJSPluginsMap = map[string][]Plugin{
+	Must(New("nodejs:1.2.7")).String(): {
+		Must(New(structsPlugin)),
+	},
+}
+
+// FeaturePluginsMap returns plugins required by features enabled in Jenkins CR
+func FeaturePluginsMap(jenkins *v1alpha1.Jenkins) map[string][]Plugin {
+	plugins := map[string][]Plugin{}
+
+	for _, seedJob := range jenkins.Spec.SeedJobs {
+		if seedJob.GitHubPushTrigger {
+			mergePlugins(plugins, githubPluginsMap)
+		}
+		if seedJob.BitbucketPushTrigger {
+			mergePlugins(plugins, bitbucketPluginsMap)
+		}
+		if seedJob.GerritTrigger != nil {
+			mergePlugins(plugins, gerritPluginsMap)
+		}
+		if seedJob.Mode == v1alpha1.SeedJobModeMultibranch {
+			mergePlugins(plugins, multibranchPluginsMap)
+		}
+		if seedJob.Mode == v1alpha1.SeedJobModeOrganizationFolder {
+			mergePlugins(plugins, multibranchPluginsMap)
+			mergePlugins(plugins, organizationFolderPluginsMap)
+		}
+	}
+
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.LDAP != nil {
+		mergePlugins(plugins, ldapPluginsMap)
+	}
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.OIDC != nil {
+		mergePlugins(plugins, oidcPluginsMap)
+	}
+	if jenkins.Spec.Security != nil && jenkins.Spec.Security.Authorization != nil {
+		if len(jenkins.Spec.Security.Authorization.Matrix) > 0 {
+			mergePlugins(plugins, matrixAuthorizationPluginsMap)
+		}
+		if jenkins.Spec.Security.Authorization.RoleStrategy != nil {
+			mergePlugins(plugins, roleStrategyPluginsMap)
+		}
+	}
+	if jenkins.Spec.Notifications != nil && jenkins.Spec.Notifications.SMTP != nil {
+		mergePlugins(plugins, mailerPluginsMap)
+	}
+	if len(jenkins.Spec.SharedLibraries) > 0 {
+		mergePlugins(plugins, sharedLibrariesPluginsMap)
+	}
+	if jenkins.Spec.Tools != nil && len(jenkins.Spec.Tools.Gradle) > 0 {
+		mergePlugins(plugins, gradlePluginsMap)
+	}
+	if jenkins.Spec.Tools != nil && len(jenkins.Spec.Tools.NodeJS) > 0 {
+		mergePlugins(plugins, nodeJSPluginsMap)
+	}
+
+	overrides := jenkins.Spec.Master.BasePluginsOverride
+	if len(overrides) == 0 {
+		return plugins
+	}
+	overriddenPlugins := map[string][]Plugin{}
+	for rootPluginName, dependentPlugins := range plugins {
+		var overriddenDependentPlugins []Plugin
+		for _, plugin := range dependentPlugins {
+			overriddenDependentPlugins = append(overriddenDependentPlugins, overrideVersion(plugin, overrides))
+		}
+		overriddenPlugins[overrideVersion(Must(New(rootPluginName)), overrides).String()] = overriddenDependentPlugins
+	}
+	return overriddenPlugins
+}
+
+// FeaturePlugins returns plugins required by features enabled in Jenkins CR
+func FeaturePlugins(jenkins *v1alpha1.Jenkins) map[string][]string {
+	return pluginsToStrings(FeaturePluginsMap(jenkins))
+}
+
+func mergePlugins(plugins map[string][]Plugin, featurePlugins map[string][]Plugin) {
+	for rootPluginName, dependentPlugins := range featurePlugins {
+		plugins[rootPluginName] = dependentPlugins
+	}
+}
+
+func pluginsToStrings(pluginsMap map[string][]Plugin) (plugins map[string][]string) {
+	plugins = map[string][]string{}
+
+	for rootPluginName, dependentPlugins := range pluginsMap {
+		plugins[rootPluginName] = []string{}
+		for _, pluginName := range dependentPlugins {
+			plugins[rootPluginName] = append(plugins[rootPluginName], pluginName.String())
+		}
+	}
+
+	return
+}
@@ -2,34 +2,120 @@ package plugins
 
 import (
 	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 
-	"github.com/oldsj/jenkins-operator/pkg/log"
-
 	"github.com/pkg/errors"
 )
 
+const (
+	// LatestVersion is the version of the plugin resolved to the latest version available in the update center
+	LatestVersion = "latest"
+
+	// checksumSeparator separates the version of the plugin from the SHA-256 checksum of its archive
+	checksumSeparator = "@sha256:"
+)
+
+var (
+	checksumRegexp = regexp.MustCompile("^[a-f0-9]{64}$")
+	// versionRegexp matches versions of plugins, e.g. 3.9.1, 2.0-beta-1, 2019.01.15 or 1.2.3-rc1234.abcdef123456,
+	// the build metadata can follow the plus sign
+	versionRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*(\+[a-zA-Z0-9._-]+)?$`)
+	// constraintRegexp matches version constraints of dependent plugins, e.g. >=2.40 or 2.x
+	constraintRegexp = regexp.MustCompile(`^((>=|>|<=|<)[a-zA-Z0-9][a-zA-Z0-9._-]*|[0-9]+(\.[0-9]+)*\.x)$`)
+)
+
 // Plugin represents jenkins plugin
 type Plugin struct {
-	Name                     string `json:"name"`
-	Version                  string `json:"version"`
-	rootPluginNameAndVersion string
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// SHA256 is the hex encoded SHA-256 checksum of the plugin archive, the downloaded archive is verified against it
+	SHA256 string `json:"sha256,omitempty"`
+	// URL is the address of the plugin archive downloaded instead of the archive from the update center
+	URL string `json:"url,omitempty"`
+	// Constraint is the range of versions of the dependent plugin, e.g. >=2.40 or 2.x, instead of the version,
+	// the version of the plugin has to be declared elsewhere
+	Constraint string `json:"constraint,omitempty"`
 }
 
 func (p Plugin) String() string {
+	if len(p.Constraint) > 0 {
+		return fmt.Sprintf("%s:%s", p.Name, p.Constraint)
+	}
 	return fmt.Sprintf("%s:%s", p.Name, p.Version)
 }
 
-// New creates plugin from string, for example "name-of-plugin:0.0.1"
+// StringWithChecksum returns the plugin in the format accepted by New including the URL and the checksum when they're set,
+// for example "name-of-plugin:0.0.1@sha256:<checksum>"
+func (p Plugin) StringWithChecksum() string {
+	value := p.String()
+	if len(p.URL) > 0 {
+		value += ":" + p.URL
+	}
+	if len(p.SHA256) > 0 {
+		value += checksumSeparator + p.SHA256
+	}
+	return value
+}
+
+// New creates plugin from string, for example "name-of-plugin:0.0.1" or "name-of-plugin:0.0.1@sha256:<checksum>",
+// the version can be followed by the URL of the plugin archive, for example "name-of-plugin:0.0.1:https://example.com/name-of-plugin.hpi",
+// or replaced by the version constraint, for example "name-of-plugin:>=0.0.1" or "name-of-plugin:0.x"
 func New(nameWithVersion string) (*Plugin, error) {
 	val := strings.SplitN(nameWithVersion, ":", 2)
 	if val == nil || len(val) != 2 {
 		return nil, errors.Errorf("invalid plugin format '%s'", nameWithVersion)
 	}
-	return &Plugin{
+	plugin := &Plugin{
 		Name:    val[0],
 		Version: val[1],
-	}, nil
+	}
+	if constraintRegexp.MatchString(plugin.Version) {
+		plugin.Constraint = plugin.Version
+		plugin.Version = ""
+		return plugin, nil
+	}
+	if index := strings.LastIndex(plugin.Version, checksumSeparator); index >= 0 {
+		plugin.SHA256 = plugin.Version[index+len(checksumSeparator):]
+		plugin.Version = plugin.Version[:index]
+		if !checksumRegexp.MatchString(plugin.SHA256) {
+			return nil, errors.Errorf("invalid plugin checksum '%s', expected hex encoded SHA-256 checksum", nameWithVersion)
+		}
+	}
+	if index := strings.Index(plugin.Version, ":"); index >= 0 {
+		plugin.URL = plugin.Version[index+1:]
+		plugin.Version = plugin.Version[:index]
+		if parsed, err := url.Parse(plugin.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+			return nil, errors.Errorf("invalid plugin url '%s', expected absolute http or https url", nameWithVersion)
+		}
+	}
+	if !versionRegexp.MatchString(plugin.Version) {
+		return nil, errors.Errorf("invalid plugin version '%s'", nameWithVersion)
+	}
+	return plugin, nil
+}
+
+// ParseFile parses plugins in the plugins.txt format of Jenkins docker image, one plugin per line in the format accepted by New,
+// empty lines and comments starting with # are skipped, the error contains the number of the invalid line
+func ParseFile(content string) ([]Plugin, error) {
+	var parsed []Plugin
+	for number, line := range strings.Split(content, "\n") {
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		plugin, err := New(line)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d", number+1)
+		}
+		parsed = append(parsed, *plugin)
+	}
+	return parsed, nil
 }
 
 // Must returns plugin from pointer and throws panic when error is set
@@ -41,47 +127,183 @@ func Must(plugin *Plugin, err error) Plugin {
 	return *plugin
 }
 
-// VerifyDependencies checks if all plugins have compatible versions
-func VerifyDependencies(values ...map[Plugin][]Plugin) bool {
-	// key - plugin name, value array of versions
-	allPlugins := make(map[string][]Plugin)
-	valid := true
+// DeclaredPlugins are root plugins with dependent plugins declared in one place, e.g. spec.master.plugins,
+// the source is included in messages about conflicting versions
+type DeclaredPlugins struct {
+	Source  string
+	Plugins map[Plugin][]Plugin
+}
+
+// ConflictKind is the kind of incompatible requirements of the plugin
+type ConflictKind string
+
+const (
+	// VersionConflict means the plugin is required in different versions
+	VersionConflict ConflictKind = "version"
+	// ChecksumConflict means the plugin is required in the same version with different checksums
+	ChecksumConflict ConflictKind = "checksum"
+	// ConstraintConflict means the declared version of the plugin doesn't satisfy the version constraint
+	// of the dependent plugin or the plugin isn't declared in any version
+	ConstraintConflict ConflictKind = "constraint"
+)
+
+// Requirement is the version, the checksum or the version constraint of the plugin required by the root plugin
+type Requirement struct {
+	Version    string
+	SHA256     string
+	Constraint string
+	// RequiredBy is the chain of plugins from the root plugin to the required plugin, e.g. [workflow-job:2.32 scm-api:2.4.1]
+	RequiredBy []string
+	// Source is the place where the root plugin is declared, e.g. spec.master.plugins
+	Source string
+}
 
+// Conflict describes incompatible requirements of the plugin found by VerifyDependencies
+type Conflict struct {
+	Kind         ConflictKind
+	Plugin       string
+	Requirements []Requirement
+}
+
+// String returns the message about the conflict for users, e.g.
+// "Plugin 'scm-api' is required in conflicting versions: '2.4.1' by workflow-job:2.32 -> scm-api:2.4.1, '2.3.0' by git:3.9.1 -> scm-api:2.3.0"
+func (c Conflict) String() string {
+	var requirements []string
+	for _, requirement := range c.Requirements {
+		value := requirement.Version
+		if len(requirement.Constraint) > 0 {
+			value = requirement.Constraint
+		} else if c.Kind == ChecksumConflict {
+			value = requirement.SHA256
+		}
+		requirements = append(requirements, fmt.Sprintf("'%s' by %s%s", value, strings.Join(requirement.RequiredBy, " -> "), inSource(requirement.Source)))
+	}
+
+	switch c.Kind {
+	case ChecksumConflict:
+		return fmt.Sprintf("Plugin '%s' is required with conflicting checksums: %s", c.Plugin, strings.Join(requirements, ", "))
+	case ConstraintConflict:
+		if len(requirements) == 1 {
+			return fmt.Sprintf("Plugin '%s' isn't declared in any version: %s", c.Plugin, requirements[0])
+		}
+		return fmt.Sprintf("Plugin '%s' is declared in version %s which does not satisfy %s", c.Plugin, requirements[0], strings.Join(requirements[1:], ", "))
+	default:
+		return fmt.Sprintf("Plugin '%s' is required in conflicting versions: %s", c.Plugin, strings.Join(requirements, ", "))
+	}
+}
+
+// VerifyDependencies checks if all plugins have compatible versions and versions satisfy constraints of dependent plugins,
+// it returns conflicts sorted by the name of the plugin
+func VerifyDependencies(values ...map[Plugin][]Plugin) []Conflict {
+	var declaredPlugins []DeclaredPlugins
 	for _, value := range values {
-		for rootPlugin, plugins := range value {
-			allPlugins[rootPlugin.Name] = append(allPlugins[rootPlugin.Name], Plugin{
-				Name:                     rootPlugin.Name,
-				Version:                  rootPlugin.Version,
-				rootPluginNameAndVersion: rootPlugin.String()})
+		declaredPlugins = append(declaredPlugins, DeclaredPlugins{Plugins: value})
+	}
+	return VerifyDeclaredDependencies(declaredPlugins...)
+}
+
+// VerifyDeclaredDependencies checks if all plugins have compatible versions and versions satisfy constraints
+// of dependent plugins, the same plugin in the same version can be declared in many places
+func VerifyDeclaredDependencies(values ...DeclaredPlugins) []Conflict {
+	// key - plugin name, value array of requirements
+	allPlugins := make(map[string][]Requirement)
+	// key - plugin name, value array of version constraints
+	constraints := make(map[string][]Requirement)
+
+	for _, value := range values {
+		for rootPlugin, plugins := range value.Plugins {
+			allPlugins[rootPlugin.Name] = append(allPlugins[rootPlugin.Name], Requirement{
+				Version:    rootPlugin.Version,
+				SHA256:     rootPlugin.SHA256,
+				RequiredBy: []string{rootPlugin.String()},
+				Source:     value.Source,
+			})
 			for _, plugin := range plugins {
-				allPlugins[plugin.Name] = append(allPlugins[plugin.Name], Plugin{
-					Name:                     plugin.Name,
-					Version:                  plugin.Version,
-					rootPluginNameAndVersion: rootPlugin.String()})
+				requirement := Requirement{
+					Version:    plugin.Version,
+					SHA256:     plugin.SHA256,
+					Constraint: plugin.Constraint,
+					RequiredBy: []string{rootPlugin.String(), plugin.String()},
+					Source:     value.Source,
+				}
+				if len(plugin.Constraint) > 0 {
+					constraints[plugin.Name] = append(constraints[plugin.Name], requirement)
+				} else {
+					allPlugins[plugin.Name] = append(allPlugins[plugin.Name], requirement)
+				}
 			}
 		}
 	}
 
-	for pluginName, versions := range allPlugins {
-		if len(versions) == 1 {
-			continue
+	var conflicts []Conflict
+	for pluginName, requirements := range allPlugins {
+		sortRequirements(requirements)
+		for i, first := range requirements {
+			for _, second := range requirements[i+1:] {
+				if first.Version != second.Version {
+					conflicts = append(conflicts, Conflict{Kind: VersionConflict, Plugin: pluginName, Requirements: []Requirement{first, second}})
+				} else if len(first.SHA256) > 0 && len(second.SHA256) > 0 && first.SHA256 != second.SHA256 {
+					conflicts = append(conflicts, Conflict{Kind: ChecksumConflict, Plugin: pluginName, Requirements: []Requirement{first, second}})
+				}
+			}
 		}
+	}
 
-		for _, firstVersion := range versions {
-			for _, secondVersion := range versions {
-				if firstVersion.Version != secondVersion.Version {
-					log.Log.V(log.VWarn).Info(fmt.Sprintf("Plugin '%s' requires version '%s' but plugin '%s' requires '%s' for plugin '%s'",
-						firstVersion.rootPluginNameAndVersion,
-						firstVersion.Version,
-						secondVersion.rootPluginNameAndVersion,
-						secondVersion.Version,
-						pluginName,
-					))
-					valid = false
+	for pluginName, requirements := range constraints {
+		sortRequirements(requirements)
+		for _, constraint := range requirements {
+			if len(allPlugins[pluginName]) == 0 {
+				conflicts = append(conflicts, Conflict{Kind: ConstraintConflict, Plugin: pluginName, Requirements: []Requirement{constraint}})
+			}
+			for _, declared := range allPlugins[pluginName] {
+				if !SatisfiesConstraint(declared.Version, constraint.Constraint) {
+					conflicts = append(conflicts, Conflict{Kind: ConstraintConflict, Plugin: pluginName, Requirements: []Requirement{declared, constraint}})
+					break
 				}
 			}
 		}
 	}
 
-	return valid
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		return conflicts[i].Plugin < conflicts[j].Plugin
+	})
+	return conflicts
+}
+
+// sortRequirements sorts requirements by the chain of plugins to report conflicts in the same order
+// in every reconciliation loop, maps of plugins are iterated in random order
+func sortRequirements(requirements []Requirement) {
+	sort.Slice(requirements, func(i, j int) bool {
+		first, second := strings.Join(requirements[i].RequiredBy, " "), strings.Join(requirements[j].RequiredBy, " ")
+		if first != second {
+			return first < second
+		}
+		return requirements[i].Source < requirements[j].Source
+	})
+}
+
+// inSource returns the suffix of messages with the place where the plugin is declared
+func inSource(source string) string {
+	if len(source) == 0 {
+		return ""
+	}
+	return " in " + source
+}
+
+// SplitConstraints splits dependent plugins with version constraints from plugins with versions,
+// constraints are returned under the same root plugins
+func SplitConstraints(pluginsWithVersions map[string][]string) (map[string][]string, map[string][]string) {
+	withVersions := map[string][]string{}
+	constraints := map[string][]string{}
+	for rootPlugin, dependentPlugins := range pluginsWithVersions {
+		withVersions[rootPlugin] = []string{}
+		for _, pluginNameWithVersion := range dependentPlugins {
+			if plugin, err := New(pluginNameWithVersion); err == nil && len(plugin.Constraint) > 0 {
+				constraints[rootPlugin] = append(constraints[rootPlugin], pluginNameWithVersion)
+			} else {
+				withVersions[rootPlugin] = append(withVersions[rootPlugin], pluginNameWithVersion)
+			}
+		}
+	}
+	return withVersions, constraints
 }
@@ -1,8 +1,10 @@
 package plugins
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
 	"github.com/oldsj/jenkins-operator/pkg/log"
 
 	"github.com/stretchr/testify/assert"
@@ -18,7 +20,7 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins)
-		assert.Equal(t, true, got)
+		assert.Empty(t, got)
 	})
 	t.Run("happy, two root plugins with one depended plugin with the same version", func(t *testing.T) {
 		basePlugins := map[Plugin][]Plugin{
@@ -30,7 +32,7 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins)
-		assert.Equal(t, true, got)
+		assert.Empty(t, got)
 	})
 	t.Run("fail, two root plugins have different versions", func(t *testing.T) {
 		basePlugins := map[Plugin][]Plugin{
@@ -42,7 +44,7 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins)
-		assert.Equal(t, false, got)
+		assert.NotEmpty(t, got)
 	})
 	t.Run("happy, no version collision with two sperate plugins lists", func(t *testing.T) {
 		basePlugins := map[Plugin][]Plugin{
@@ -56,7 +58,7 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins, extraPlugins)
-		assert.Equal(t, true, got)
+		assert.Empty(t, got)
 	})
 	t.Run("fail, dependent plugins have different versions", func(t *testing.T) {
 		basePlugins := map[Plugin][]Plugin{
@@ -68,7 +70,7 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins)
-		assert.Equal(t, false, got)
+		assert.NotEmpty(t, got)
 	})
 	t.Run("fail, root and dependent plugins have different versions", func(t *testing.T) {
 		basePlugins := map[Plugin][]Plugin{
@@ -82,6 +84,241 @@ func TestVerifyDependencies(t *testing.T) {
 			},
 		}
 		got := VerifyDependencies(basePlugins, extraPlugins)
-		assert.Equal(t, false, got)
+		assert.NotEmpty(t, got)
 	})
+	t.Run("happy, plugin downloaded from url satisfies dependent plugin", func(t *testing.T) {
+		basePlugins := map[Plugin][]Plugin{
+			Must(New("first-root-plugin:1.0.0")): {
+				Must(New("first-plugin:0.0.1")),
+			},
+			Must(New("first-plugin:0.0.1:https://artifacts.example.com/first-plugin-0.0.1.hpi")): {},
+		}
+		got := VerifyDependencies(basePlugins)
+		assert.Empty(t, got)
+	})
+	t.Run("fail, dependent plugins have different checksums", func(t *testing.T) {
+		basePlugins := map[Plugin][]Plugin{
+			Must(New("first-root-plugin:1.0.0")): {
+				Must(New("first-plugin:0.0.1@sha256:" + strings.Repeat("a", 64))),
+			},
+			Must(New("second-root-plugin:1.0.0")): {
+				Must(New("first-plugin:0.0.1@sha256:" + strings.Repeat("b", 64))),
+			},
+		}
+		got := VerifyDependencies(basePlugins)
+		assert.NotEmpty(t, got)
+	})
+}
+
+func TestVerifyDeclaredDependencies(t *testing.T) {
+	log.SetupLogger(false)
+
+	basePlugins := DeclaredPlugins{Source: "spec.master.basePlugins", Plugins: map[Plugin][]Plugin{
+		Must(New("git:4.11.0")): {Must(New("scm-api:2.6.5"))},
+	}}
+	assert.Empty(t, VerifyDeclaredDependencies(basePlugins, DeclaredPlugins{Source: "spec.master.plugins", Plugins: map[Plugin][]Plugin{
+		Must(New("git:4.11.0")): {Must(New("scm-api:2.6.5"))},
+	}}))
+	assert.NotEmpty(t, VerifyDeclaredDependencies(basePlugins, DeclaredPlugins{Source: "spec.master.plugins", Plugins: map[Plugin][]Plugin{
+		Must(New("git:4.10.0")): {},
+	}}))
+	assert.Equal(t, " in spec.master.plugins", inSource("spec.master.plugins"))
+	assert.Equal(t, "", inSource(""))
+}
+
+func TestDependencyConflicts(t *testing.T) {
+	t.Run("diamond dependency", func(t *testing.T) {
+		conflicts := VerifyDeclaredDependencies(
+			DeclaredPlugins{Source: "spec.master.basePlugins", Plugins: map[Plugin][]Plugin{
+				Must(New("workflow-job:2.32")): {Must(New("scm-api:2.4.1")), Must(New("structs:1.17"))},
+			}},
+			DeclaredPlugins{Source: "spec.master.plugins", Plugins: map[Plugin][]Plugin{
+				Must(New("git:3.9.1")): {Must(New("scm-api:2.3.0")), Must(New("structs:1.17"))},
+			}},
+		)
+
+		assert.Equal(t, []Conflict{{
+			Kind:   VersionConflict,
+			Plugin: "scm-api",
+			Requirements: []Requirement{
+				{Version: "2.3.0", RequiredBy: []string{"git:3.9.1", "scm-api:2.3.0"}, Source: "spec.master.plugins"},
+				{Version: "2.4.1", RequiredBy: []string{"workflow-job:2.32", "scm-api:2.4.1"}, Source: "spec.master.basePlugins"},
+			},
+		}}, conflicts)
+		assert.Equal(t, "Plugin 'scm-api' is required in conflicting versions: "+
+			"'2.3.0' by git:3.9.1 -> scm-api:2.3.0 in spec.master.plugins, "+
+			"'2.4.1' by workflow-job:2.32 -> scm-api:2.4.1 in spec.master.basePlugins", conflicts[0].String())
+	})
+	t.Run("checksums", func(t *testing.T) {
+		conflicts := VerifyDependencies(map[Plugin][]Plugin{
+			Must(New("git:3.9.1")):         {Must(New("scm-api:2.3.0@sha256:" + strings.Repeat("a", 64)))},
+			Must(New("workflow-job:2.32")): {Must(New("scm-api:2.3.0@sha256:" + strings.Repeat("b", 64)))},
+		})
+
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, "Plugin 'scm-api' is required with conflicting checksums: "+
+			"'"+strings.Repeat("a", 64)+"' by git:3.9.1 -> scm-api:2.3.0, "+
+			"'"+strings.Repeat("b", 64)+"' by workflow-job:2.32 -> scm-api:2.3.0", conflicts[0].String())
+	})
+	t.Run("constraints", func(t *testing.T) {
+		constrained := map[Plugin][]Plugin{Must(New("plain-credentials:1.5")): {Must(New("credentials:>=2.6"))}}
+
+		conflicts := VerifyDependencies(constrained, map[Plugin][]Plugin{Must(New("credentials:2.3.1")): {}})
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, "Plugin 'credentials' is declared in version '2.3.1' by credentials:2.3.1 "+
+			"which does not satisfy '>=2.6' by plain-credentials:1.5 -> credentials:>=2.6", conflicts[0].String())
+
+		conflicts = VerifyDependencies(constrained)
+		assert.Len(t, conflicts, 1)
+		assert.Equal(t, "Plugin 'credentials' isn't declared in any version: '>=2.6' by plain-credentials:1.5 -> credentials:>=2.6",
+			conflicts[0].String())
+	})
+}
+
+func TestNew(t *testing.T) {
+	checksum := strings.Repeat("0123456789abcdef", 4)
+
+	plugin, err := New("git:3.9.1")
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "git", Version: "3.9.1"}, *plugin)
+
+	plugin, err = New("git:3.9.1@sha256:" + checksum)
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "git", Version: "3.9.1", SHA256: checksum}, *plugin)
+	assert.Equal(t, "git:3.9.1", plugin.String())
+	assert.Equal(t, "git:3.9.1@sha256:"+checksum, plugin.StringWithChecksum())
+
+	plugin, err = New("git:3.9.1-patched:https://artifacts.example.com/git-3.9.1-patched.hpi@sha256:" + checksum)
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "git", Version: "3.9.1-patched", URL: "https://artifacts.example.com/git-3.9.1-patched.hpi", SHA256: checksum}, *plugin)
+	assert.Equal(t, "git:3.9.1-patched", plugin.String())
+	assert.Equal(t, "git:3.9.1-patched:https://artifacts.example.com/git-3.9.1-patched.hpi@sha256:"+checksum, plugin.StringWithChecksum())
+
+	for _, version := range []string{"1.2.3-rc1234.abcdef123456", "2019.01.15", "2.0-beta-1", "1.0+build.5", "4.5.5-3.0", "latest"} {
+		plugin, err = New("git:" + version)
+		assert.NoError(t, err, version)
+		assert.Equal(t, version, plugin.Version)
+	}
+	for _, version := range []string{"", "-1.0", "1.0 ", "1.0/../..", "1.0+", "1.0+build+5", "1.0;rm"} {
+		_, err = New("git:" + version)
+		assert.Error(t, err, version)
+	}
+
+	_, err = New("git")
+	assert.Error(t, err)
+	_, err = New("git:3.9.1:artifacts.example.com/git.hpi")
+	assert.Error(t, err)
+	_, err = New("git:3.9.1:ftp://artifacts.example.com/git.hpi")
+	assert.Error(t, err)
+	_, err = New("git:3.9.1@sha256:" + strings.ToUpper(checksum))
+	assert.Error(t, err)
+	_, err = New("git:3.9.1@sha256:abc")
+	assert.Error(t, err)
+}
+
+func TestParseFile(t *testing.T) {
+	parsed, err := ParseFile("# plugins of the team\ngit:3.9.1\n\n  job-dsl:1.71 # seed jobs\r\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []Plugin{{Name: "git", Version: "3.9.1"}, {Name: "job-dsl", Version: "1.71"}}, parsed)
+
+	_, err = ParseFile("git:3.9.1\n\nworkflow-job\n")
+	assert.EqualError(t, err, "line 3: invalid plugin format 'workflow-job'")
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		older, newer string
+	}{
+		{"3.9.1", "3.10.0"},
+		{"1.8", "1.71"},
+		{"2.0", "2.0.1"},
+		{"1.4.1-alpha", "1.4.1-beta"},
+		{"2.0-beta-1", "2.0-beta-2"},
+		{"2.0-beta-2", "2.0-rc-1"},
+		{"2.0-rc-1", "2.0"},
+		{"1.0-SNAPSHOT", "1.0"},
+		{"1.2.3-rc1234.abcdef123456", "1.2.3"},
+		{"1.2.2", "1.2.3-rc1234.abcdef123456"},
+		{"1.2.3-rc1234.abcdef123456", "1.2.3-rc1235.0123456789ab"},
+		{"2.6.4", "2.6.4-sp1"},
+		{"1.13.8", "1.13.8.1"},
+		{"1.3.4.1", "1.3.5"},
+		{"4.5.5-3.0", "4.5.5-3.1"},
+		{"2019.01.15", "2019.02.01"},
+		{"20190115", "20190201"},
+		{"1.0", "1.0-patched"},
+		{"2.7.6", "123456789012345678901234567890"},
+	}
+	for _, test := range tests {
+		assert.True(t, CompareVersions(test.older, test.newer) < 0, "%s < %s", test.older, test.newer)
+		assert.True(t, CompareVersions(test.newer, test.older) > 0, "%s > %s", test.newer, test.older)
+	}
+
+	equal := [][2]string{
+		{"3.9.1", "3.9.1"},
+		{"2.0", "2.0.0"},
+		{"1.01", "1.1"},
+		{"2.0-RC-1", "2.0-cr1"},
+		{"1.0", "1.0-final"},
+		{"1.0+build.5", "1.0"},
+	}
+	for _, test := range equal {
+		assert.Equal(t, 0, CompareVersions(test[0], test[1]), "%s == %s", test[0], test[1])
+	}
+}
+
+func TestSatisfiesConstraint(t *testing.T) {
+	tests := []struct {
+		version, constraint string
+		satisfies           bool
+	}{
+		{"2.40", ">=2.40", true},
+		{"2.39", ">=2.40", false},
+		{"2.41", ">2.40", true},
+		{"2.40", ">2.40", false},
+		{"2.40", "<=2.40", true},
+		{"2.40-rc1234.abcdef123456", "<2.40", true},
+		{"2.3.1", "2.x", true},
+		{"3.0-beta-1", "2.x", false},
+		{"2.6.3", "2.6.x", true},
+		{"2.60", "2.6.x", false},
+		{"2", "2.6.x", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.satisfies, SatisfiesConstraint(test.version, test.constraint), "%s %s", test.version, test.constraint)
+	}
+}
+
+func TestVerifyConstraints(t *testing.T) {
+	plugin, err := New("workflow-api:>=2.40")
+	assert.NoError(t, err)
+	assert.Equal(t, Plugin{Name: "workflow-api", Constraint: ">=2.40"}, *plugin)
+	assert.Equal(t, "workflow-api:>=2.40", plugin.String())
+	_, err = New("workflow-api:>=2.40@sha256:" + strings.Repeat("a", 64))
+	assert.Error(t, err)
+
+	withVersions, constraints := SplitConstraints(map[string][]string{"plain-credentials:1.5": {"credentials:>=2.6", "structs:1.17"}})
+	assert.Equal(t, map[string][]string{"plain-credentials:1.5": {"structs:1.17"}}, withVersions)
+	assert.Equal(t, map[string][]string{"plain-credentials:1.5": {"credentials:>=2.6"}}, constraints)
+
+	constrained := map[Plugin][]Plugin{Must(New("plain-credentials:1.5")): {Must(New("credentials:>=2.6"))}}
+	assert.Empty(t, VerifyDependencies(constrained, map[Plugin][]Plugin{Must(New("credentials:2.6.1")): {}}))
+	assert.NotEmpty(t, VerifyDependencies(constrained, map[Plugin][]Plugin{Must(New("credentials:2.3.1")): {}}))
+	assert.NotEmpty(t, VerifyDependencies(constrained))
+}
+
+func TestBasePluginsOverride(t *testing.T) {
+	jenkins := &v1alpha1.Jenkins{
+		Spec: v1alpha1.JenkinsSpec{
+			Master: v1alpha1.JenkinsMaster{
+				OperatorPlugins:     map[string][]string{"kubernetes:1.13.8": {"credentials:2.1.18", "structs:1.17"}},
+				BasePluginsOverride: map[string]string{"kubernetes": "1.14.0", "credentials": "2.1.19"},
+			},
+			SeedJobs: []v1alpha1.SeedJob{{BitbucketPushTrigger: true}},
+		},
+	}
+
+	assert.Equal(t, map[string][]string{"kubernetes:1.14.0": {"credentials:2.1.19", "structs:1.17"}}, OperatorPlugins(jenkins))
+	assert.Equal(t, map[string][]string{"bitbucket:1.1.8": {"credentials:2.1.19", "git:3.9.1", "scm-api:2.3.0", "structs:1.17"}},
+		FeaturePlugins(jenkins))
 }
@@ -0,0 +1,171 @@
+package plugins
+
+import (
+	"strings"
+	"unicode"
+)
+
+// qualifierOrder orders qualifiers of versions like hudson.util.VersionNumber of Jenkins, pre-releases precede the release
+// which is the empty qualifier, unknown qualifiers follow known ones and are compared alphabetically
+var qualifierOrder = map[string]int{
+	"alpha":     1,
+	"a":         1,
+	"beta":      2,
+	"b":         2,
+	"milestone": 3,
+	"m":         3,
+	"rc":        4,
+	"cr":        4,
+	"snapshot":  5,
+	"":          6,
+	"ga":        6,
+	"final":     6,
+	"sp":        7,
+}
+
+// unknownQualifierOrder is the order of qualifiers missing in qualifierOrder
+const unknownQualifierOrder = 8
+
+// CompareVersions compares versions of plugins like Jenkins does, versions are split into numeric and qualifier parts
+// by dots, dashes, underscores and transitions between digits and letters, the build metadata after the plus sign is ignored,
+// returns a negative number when a is older than b, zero when they're equal and a positive number otherwise,
+// e.g. 1.2.3-rc1234.abcdef123456 precedes 1.2.3 and 1.10 follows 1.9
+func CompareVersions(a, b string) int {
+	aParts := splitVersion(a)
+	bParts := splitVersion(b)
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aPart, bPart *string
+		if i < len(aParts) {
+			aPart = &aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = &bParts[i]
+		}
+		if compared := compareVersionParts(aPart, bPart); compared != 0 {
+			return compared
+		}
+	}
+	return 0
+}
+
+// splitVersion returns lowercase numeric and qualifier parts of the version, leading zeros of numbers are trimmed
+func splitVersion(version string) []string {
+	if index := strings.Index(version, "+"); index >= 0 {
+		version = version[:index]
+	}
+
+	var parts []string
+	var part strings.Builder
+	flush := func() {
+		if part.Len() > 0 {
+			value := part.String()
+			if isNumber(value) {
+				value = strings.TrimLeft(value, "0")
+			}
+			parts = append(parts, value)
+			part.Reset()
+		}
+	}
+	for _, character := range strings.ToLower(version) {
+		switch {
+		case character == '.' || character == '-' || character == '_':
+			flush()
+			continue
+		case part.Len() > 0 && unicode.IsDigit(character) != isNumber(part.String()):
+			flush()
+		}
+		part.WriteRune(character)
+	}
+	flush()
+	return parts
+}
+
+// compareVersionParts compares parts of versions, nil is the missing part which equals zero and the release,
+// numbers follow qualifiers
+func compareVersionParts(a, b *string) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -compareVersionParts(b, a)
+	case isNumber(*a) && b == nil:
+		return compareNumbers(*a, "")
+	case isNumber(*a) && isNumber(*b):
+		return compareNumbers(*a, *b)
+	case isNumber(*a):
+		return 1
+	case b == nil:
+		return compareQualifiers(*a, "")
+	case isNumber(*b):
+		return -1
+	default:
+		return compareQualifiers(*a, *b)
+	}
+}
+
+// compareNumbers compares numbers without leading zeros of any length, the empty string is zero
+func compareNumbers(a, b string) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return strings.Compare(a, b)
+}
+
+// compareQualifiers compares qualifiers by qualifierOrder, aliases like rc and cr are equal
+func compareQualifiers(a, b string) int {
+	aOrder, aKnown := qualifierOrder[a]
+	if !aKnown {
+		aOrder = unknownQualifierOrder
+	}
+	bOrder, bKnown := qualifierOrder[b]
+	if !bKnown {
+		bOrder = unknownQualifierOrder
+	}
+	if aOrder != bOrder || aKnown {
+		return aOrder - bOrder
+	}
+	return strings.Compare(a, b)
+}
+
+// isNumber checks whether the part of the version is a number, numbers without leading zeros can be empty
+func isNumber(part string) bool {
+	for _, character := range part {
+		if !unicode.IsDigit(character) {
+			return false
+		}
+	}
+	return true
+}
+
+// SatisfiesConstraint checks whether the version satisfies the constraint, e.g. >=2.40, >2.40, <=2.40, <2.40 or 2.x,
+// the wildcard matches versions which start with the same numbers
+func SatisfiesConstraint(version, constraint string) bool {
+	for _, operator := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(constraint, operator) {
+			continue
+		}
+		compared := CompareVersions(version, strings.TrimPrefix(constraint, operator))
+		switch operator {
+		case ">=":
+			return compared >= 0
+		case "<=":
+			return compared <= 0
+		case ">":
+			return compared > 0
+		default:
+			return compared < 0
+		}
+	}
+
+	prefix := splitVersion(strings.TrimSuffix(constraint, ".x"))
+	parts := splitVersion(version)
+	if len(parts) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if !isNumber(parts[i]) || compareNumbers(parts[i], prefix[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
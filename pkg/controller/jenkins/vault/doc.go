@@ -0,0 +1,2 @@
+// Package vault implements resolving of vault:<path>#<key> secret references from HashiCorp Vault
+package vault
@@ -0,0 +1,424 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	stackerr "github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ReferencePrefix is the scheme of secret references resolved from Vault
+	ReferencePrefix = "vault:"
+	// DefaultKubernetesMountPath is the default path of the Vault Kubernetes auth method
+	DefaultKubernetesMountPath = "kubernetes"
+	// DefaultRefreshInterval is the default time values read from Vault are cached
+	DefaultRefreshInterval = 5 * time.Minute
+
+	requestTimeout = 10 * time.Second
+)
+
+// serviceAccountTokenPath is the path of the operator ServiceAccount token used to log in to Vault
+var serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// cache contains Vault tokens and secrets keyed by Jenkins CR and the Vault address, entries expire after
+// the refresh interval so rotated values are read again, values of secret entries are masked until they're evicted
+var cache = struct {
+	sync.Mutex
+	tokens  map[string]cacheEntry
+	secrets map[string]cacheEntry
+}{tokens: map[string]cacheEntry{}, secrets: map[string]cacheEntry{}}
+
+type cacheEntry struct {
+	token   string
+	values  map[string]string
+	expires time.Time
+	// owner of values registered to be masked
+	owner log.SecretOwner
+}
+
+// Reference points at the key of the Vault secret
+type Reference struct {
+	// Path is the API path of the secret without the v1/ prefix, e.g. secret/data/jenkins for the KV version 2 engine
+	Path string
+	// Key is the key of the secret
+	Key string
+}
+
+// String returns the reference in the vault:<path>#<key> format
+func (r Reference) String() string {
+	if len(r.Key) == 0 {
+		return ReferencePrefix + r.Path
+	}
+	return fmt.Sprintf("%s%s#%s", ReferencePrefix, r.Path, r.Key)
+}
+
+// UnavailableError is returned when Vault can't be reached or is sealed, such errors are transient
+type UnavailableError struct {
+	Address string
+	Err     error
+}
+
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("vault '%s' is unavailable: %s", e.Address, e.Err)
+}
+
+// IsUnavailable returns true when the error was caused by unavailable Vault
+func IsUnavailable(err error) bool {
+	_, ok := stackerr.Cause(err).(*UnavailableError)
+	return ok
+}
+
+// IsReference returns true when the value uses the vault: scheme
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, ReferencePrefix)
+}
+
+// ParseReference parses the vault:<path>#<key> reference, the key is optional
+func ParseReference(value string) (Reference, error) {
+	if !IsReference(value) {
+		return Reference{}, stackerr.Errorf("vault reference '%s' has to start with '%s'", value, ReferencePrefix)
+	}
+	path := strings.TrimPrefix(value, ReferencePrefix)
+	reference := Reference{}
+	if index := strings.Index(path, "#"); index >= 0 {
+		reference.Key = path[index+1:]
+		path = path[:index]
+		if len(reference.Key) == 0 {
+			return Reference{}, stackerr.Errorf("vault reference '%s' has empty key", value)
+		}
+	}
+	reference.Path = strings.Trim(path, "/")
+	if len(reference.Path) == 0 {
+		return Reference{}, stackerr.Errorf("vault reference '%s' has empty path", value)
+	}
+	return reference, nil
+}
+
+// SelectorReference returns the reference of the selector which name uses the vault: scheme,
+// the key of the selector is used when the name doesn't contain it
+func SelectorReference(selector *corev1.SecretKeySelector) (Reference, error) {
+	reference, err := ParseReference(selector.Name)
+	if err != nil {
+		return Reference{}, err
+	}
+	if len(reference.Key) == 0 {
+		reference.Key = selector.Key
+	}
+	if len(reference.Key) == 0 {
+		return Reference{}, stackerr.Errorf("vault reference '%s' has no key", selector.Name)
+	}
+	return reference, nil
+}
+
+// RefreshInterval returns how long values read from Vault are cached, it's zero when Vault isn't configured
+func RefreshInterval(jenkins *v1alpha1.Jenkins) time.Duration {
+	if jenkins.Spec.Vault == nil {
+		return 0
+	}
+	if jenkins.Spec.Vault.RefreshInterval != nil && jenkins.Spec.Vault.RefreshInterval.Duration > 0 {
+		return jenkins.Spec.Vault.RefreshInterval.Duration
+	}
+	return DefaultRefreshInterval
+}
+
+// Client reads secrets from Vault configured by Jenkins.Spec.Vault
+type Client struct {
+	k8sClient k8s.Client
+	jenkins   *v1alpha1.Jenkins
+}
+
+// New creates Vault client
+func New(k8sClient k8s.Client, jenkins *v1alpha1.Jenkins) *Client {
+	return &Client{
+		k8sClient: k8sClient,
+		jenkins:   jenkins,
+	}
+}
+
+// Resolve returns the value of the reference, values are registered to be masked in logs
+func (c *Client) Resolve(reference Reference) (string, error) {
+	values, err := c.Read(reference.Path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[reference.Key]
+	if !ok {
+		return "", stackerr.Errorf("key '%s' not found in vault secret '%s'", reference.Key, reference.Path)
+	}
+	return value, nil
+}
+
+// Read returns values of the secret, values of the KV version 2 engine are unwrapped, values are cached for
+// the refresh interval and registered to be masked in logs
+func (c *Client) Read(path string) (map[string]string, error) {
+	if c.jenkins.Spec.Vault == nil {
+		return nil, stackerr.Errorf("vault secret '%s' is referenced but spec.vault isn't set", path)
+	}
+
+	key := c.cacheKey() + "/" + path
+	cache.Lock()
+	entry, ok := cache.secrets[key]
+	cache.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.values, nil
+	}
+
+	httpClient, err := c.newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.login(httpClient, false)
+	if err != nil {
+		return nil, err
+	}
+	status, body, err := c.do(httpClient, http.MethodGet, "v1/"+path, token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusForbidden {
+		// the cached token could have expired or been revoked
+		if token, err = c.login(httpClient, true); err != nil {
+			return nil, err
+		}
+		if status, body, err = c.do(httpClient, http.MethodGet, "v1/"+path, token, nil); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case status == http.StatusNotFound:
+		return nil, stackerr.Errorf("vault secret '%s' not found", path)
+	case status != http.StatusOK:
+		return nil, stackerr.Errorf("couldn't read vault secret '%s', status code %d", path, status)
+	}
+
+	values, err := parseSecret(body)
+	if err != nil {
+		return nil, stackerr.Wrapf(err, "couldn't read vault secret '%s'", path)
+	}
+	var registered []string
+	for _, value := range values {
+		registered = append(registered, value)
+	}
+	owner := log.SecretOwner{Namespace: c.jenkins.Namespace, Name: c.jenkins.Name, Source: "vault secret " + key}
+
+	cache.Lock()
+	defer cache.Unlock()
+	evictExpired(time.Now())
+	// values replace the values of the expired entry
+	log.ReplaceSecrets(owner, registered...)
+	cache.secrets[key] = cacheEntry{values: values, expires: time.Now().Add(RefreshInterval(c.jenkins)), owner: owner}
+	return values, nil
+}
+
+// login returns the Vault token of the operator ServiceAccount, the cached token is used unless renew is set
+func (c *Client) login(httpClient *http.Client, renew bool) (string, error) {
+	vault := c.jenkins.Spec.Vault
+	if vault.Auth.Kubernetes == nil {
+		return "", stackerr.New("spec.vault.auth.kubernetes isn't set")
+	}
+
+	key := c.cacheKey()
+	cache.Lock()
+	entry, ok := cache.tokens[key]
+	cache.Unlock()
+	if ok && !renew && time.Now().Before(entry.expires) {
+		return entry.token, nil
+	}
+
+	jwt, err := ioutil.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return "", stackerr.Wrap(err, "couldn't read the service account token")
+	}
+	mountPath := vault.Auth.Kubernetes.MountPath
+	if len(mountPath) == 0 {
+		mountPath = DefaultKubernetesMountPath
+	}
+	request, err := json.Marshal(map[string]string{"role": vault.Auth.Kubernetes.Role, "jwt": strings.TrimSpace(string(jwt))})
+	if err != nil {
+		return "", stackerr.WithStack(err)
+	}
+	status, body, err := c.do(httpClient, http.MethodPost, fmt.Sprintf("v1/auth/%s/login", strings.Trim(mountPath, "/")), "", request)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", stackerr.Errorf("couldn't log in to vault with role '%s', status code %d", vault.Auth.Kubernetes.Role, status)
+	}
+
+	response := struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", stackerr.Wrap(err, "couldn't log in to vault")
+	}
+	if len(response.Auth.ClientToken) == 0 {
+		return "", stackerr.New("couldn't log in to vault, the token is empty")
+	}
+	// the token isn't registered to be masked, it's short-lived and it's never logged
+
+	// the token is renewed before it expires
+	expires := time.Now().Add(RefreshInterval(c.jenkins))
+	if lease := time.Duration(response.Auth.LeaseDuration) * time.Second / 2; lease > 0 && time.Now().Add(lease).Before(expires) {
+		expires = time.Now().Add(lease)
+	}
+	cache.Lock()
+	cache.tokens[key] = cacheEntry{token: response.Auth.ClientToken, expires: expires}
+	cache.Unlock()
+	return response.Auth.ClientToken, nil
+}
+
+// do sends the request to Vault, network errors, server errors and too many requests are returned as UnavailableError
+func (c *Client) do(httpClient *http.Client, method, path, token string, body []byte) (int, []byte, error) {
+	address := c.jenkins.Spec.Vault.Address
+	request, err := http.NewRequest(method, strings.TrimRight(address, "/")+"/"+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, stackerr.WithStack(err)
+	}
+	if len(token) > 0 {
+		request.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, nil, stackerr.WithStack(&UnavailableError{Address: address, Err: err})
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode >= http.StatusInternalServerError || response.StatusCode == http.StatusTooManyRequests {
+		return 0, nil, stackerr.WithStack(&UnavailableError{Address: address, Err: fmt.Errorf("status code %d", response.StatusCode)})
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return 0, nil, stackerr.WithStack(&UnavailableError{Address: address, Err: err})
+	}
+	return response.StatusCode, data, nil
+}
+
+// newHTTPClient creates the HTTP client which verifies the certificate of Vault according to Jenkins.Spec.Vault.TLS
+func (c *Client) newHTTPClient() (*http.Client, error) {
+	vault := c.jenkins.Spec.Vault
+	tlsConfig := &tls.Config{}
+	if vault.TLS != nil {
+		tlsConfig.ServerName = vault.TLS.ServerName
+		tlsConfig.InsecureSkipVerify = vault.TLS.InsecureSkipVerify
+		if vault.TLS.CACertSecretRef != nil {
+			secret := &corev1.Secret{}
+			namespaceName := types.NamespacedName{Namespace: c.jenkins.Namespace, Name: vault.TLS.CACertSecretRef.Name}
+			if err := c.k8sClient.Get(context.TODO(), namespaceName, secret); err != nil {
+				return nil, stackerr.WithStack(err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(secret.Data[vault.TLS.CACertSecretRef.Key]) {
+				return nil, stackerr.Errorf("secret '%s' key '%s' doesn't contain PEM encoded certificates",
+					vault.TLS.CACertSecretRef.Name, vault.TLS.CACertSecretRef.Key)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig}
+	return &http.Client{Transport: transport, Timeout: requestTimeout}, nil
+}
+
+// evictExpired removes expired entries from the cache and unregisters their values, it has to be called with the lock held
+func evictExpired(now time.Time) {
+	for key, entry := range cache.tokens {
+		if !now.Before(entry.expires) {
+			delete(cache.tokens, key)
+		}
+	}
+	for key, entry := range cache.secrets {
+		if !now.Before(entry.expires) {
+			delete(cache.secrets, key)
+			log.UnregisterSecrets(entry.owner)
+		}
+	}
+}
+
+// Forget removes cached tokens and secrets of the Jenkins CR and unregisters their values, e.g. after it has been deleted
+func Forget(namespace, name string) {
+	cache.Lock()
+	defer cache.Unlock()
+
+	prefix := fmt.Sprintf("%s/%s/", namespace, name)
+	for key := range cache.tokens {
+		if strings.HasPrefix(key, prefix) {
+			delete(cache.tokens, key)
+		}
+	}
+	for key, entry := range cache.secrets {
+		if strings.HasPrefix(key, prefix) {
+			delete(cache.secrets, key)
+			log.UnregisterSecrets(entry.owner)
+		}
+	}
+}
+
+func (c *Client) cacheKey() string {
+	return fmt.Sprintf("%s/%s/%s", c.jenkins.Namespace, c.jenkins.Name, c.jenkins.Spec.Vault.Address)
+}
+
+// parseSecret returns values of the Vault secret response, values which aren't strings are JSON encoded
+func parseSecret(body []byte) (map[string]string, error) {
+	response := struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, stackerr.WithStack(err)
+	}
+
+	data := response.Data
+	// the KV version 2 engine wraps values with metadata
+	if nested, ok := data["data"]; ok {
+		if _, ok := data["metadata"]; ok {
+			data = map[string]json.RawMessage{}
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, stackerr.WithStack(err)
+			}
+		}
+	}
+
+	values := map[string]string{}
+	for key, raw := range data {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			value = string(raw)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// ValidateAddress checks the address of Vault is the HTTP or HTTPS URL
+func ValidateAddress(address string) error {
+	parsed, err := url.Parse(address)
+	if err != nil {
+		return stackerr.WithStack(err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || len(parsed.Host) == 0 {
+		return stackerr.New("has to be http or https URL")
+	}
+	return nil
+}
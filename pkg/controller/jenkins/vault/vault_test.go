@@ -0,0 +1,199 @@
+package vault
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Run("path and key", func(t *testing.T) {
+		reference, err := ParseReference("vault:secret/data/jenkins#deploy-key")
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Path: "secret/data/jenkins", Key: "deploy-key"}, reference)
+		assert.Equal(t, "vault:secret/data/jenkins#deploy-key", reference.String())
+	})
+	t.Run("path only", func(t *testing.T) {
+		reference, err := ParseReference("vault:/secret/data/jenkins/")
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Path: "secret/data/jenkins"}, reference)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, value := range []string{"secret/data/jenkins#key", "vault:", "vault:#key", "vault:secret/data/jenkins#"} {
+			_, err := ParseReference(value)
+			assert.Error(t, err, value)
+		}
+	})
+	t.Run("selector", func(t *testing.T) {
+		reference, err := SelectorReference(&corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "vault:secret/data/jenkins"},
+			Key:                  "token",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Path: "secret/data/jenkins", Key: "token"}, reference)
+
+		reference, err = SelectorReference(&corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "vault:secret/data/jenkins#deploy-key"},
+			Key:                  "token",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, Reference{Path: "secret/data/jenkins", Key: "deploy-key"}, reference)
+
+		_, err = SelectorReference(&corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "vault:secret/data/jenkins"},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_Read(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "token")
+	assert.NoError(t, err)
+	defer func() { _ = os.Remove(tokenFile.Name()) }()
+	_, err = tokenFile.WriteString("service-account-token\n")
+	assert.NoError(t, err)
+	assert.NoError(t, tokenFile.Close())
+	serviceAccountTokenPath = tokenFile.Name()
+
+	newJenkins := func(name, address string) *v1alpha1.Jenkins {
+		return &v1alpha1.Jenkins{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1alpha1.JenkinsSpec{
+				Vault: &v1alpha1.Vault{
+					Address:         address,
+					Auth:            v1alpha1.VaultAuth{Kubernetes: &v1alpha1.VaultKubernetesAuth{Role: "jenkins"}},
+					RefreshInterval: &metav1.Duration{Duration: time.Hour},
+				},
+			},
+		}
+	}
+
+	t.Run("KV version 2 secret is read and cached", func(t *testing.T) {
+		logins, reads := 0, 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/auth/kubernetes/login":
+				logins++
+				login := map[string]string{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+				assert.Equal(t, map[string]string{"role": "jenkins", "jwt": "service-account-token"}, login)
+				_, _ = w.Write([]byte(`{"auth": {"client_token": "vault-token", "lease_duration": 3600}}`))
+			case "/v1/secret/data/jenkins":
+				reads++
+				assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+				_, _ = w.Write([]byte(`{"data": {"data": {"deploy-key": "private key", "port": 22}, "metadata": {"version": 1}}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		client := New(fake.NewFakeClient(), newJenkins("kv2", server.URL))
+
+		value, err := client.Resolve(Reference{Path: "secret/data/jenkins", Key: "deploy-key"})
+		assert.NoError(t, err)
+		assert.Equal(t, "private key", value)
+		values, err := client.Read("secret/data/jenkins")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"deploy-key": "private key", "port": "22"}, values)
+		assert.Equal(t, 1, logins)
+		assert.Equal(t, 1, reads)
+
+		_, err = client.Resolve(Reference{Path: "secret/data/jenkins", Key: "missing"})
+		assert.EqualError(t, err, "key 'missing' not found in vault secret 'secret/data/jenkins'")
+		_, err = client.Read("secret/data/missing")
+		assert.EqualError(t, err, "vault secret 'secret/data/missing' not found")
+		assert.False(t, IsUnavailable(err))
+	})
+	t.Run("values are masked until the entry is evicted", func(t *testing.T) {
+		value := "first-secret-value"
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/auth/kubernetes/login":
+				_, _ = w.Write([]byte(`{"auth": {"client_token": "short-lived-vault-token", "lease_duration": 3600}}`))
+			case "/v1/kv/jenkins":
+				_, _ = w.Write([]byte(`{"data": {"token": "` + value + `"}}`))
+			}
+		}))
+		defer server.Close()
+		jenkins := newJenkins("masked", server.URL)
+		client := New(fake.NewFakeClient(), jenkins)
+
+		_, err := client.Read("kv/jenkins")
+		assert.NoError(t, err)
+		assert.Equal(t, log.SecretPlaceholder, log.MaskSecrets("first-secret-value"))
+		assert.Equal(t, "short-lived-vault-token", log.MaskSecrets("short-lived-vault-token"))
+
+		// the rotated value replaces the value of the expired entry
+		value = "second-secret-value"
+		cache.Lock()
+		entry := cache.secrets[client.cacheKey()+"/kv/jenkins"]
+		entry.expires = time.Now()
+		cache.secrets[client.cacheKey()+"/kv/jenkins"] = entry
+		cache.Unlock()
+		_, err = client.Read("kv/jenkins")
+		assert.NoError(t, err)
+		assert.Equal(t, "first-secret-value "+log.SecretPlaceholder, log.MaskSecrets("first-secret-value second-secret-value"))
+
+		// values of the deleted Jenkins CR are unregistered
+		Forget(jenkins.Namespace, jenkins.Name)
+		assert.Equal(t, "second-secret-value", log.MaskSecrets("second-secret-value"))
+		assert.NotContains(t, cache.secrets, client.cacheKey()+"/kv/jenkins")
+	})
+	t.Run("token is renewed when it's rejected", func(t *testing.T) {
+		logins := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v1/auth/custom/login":
+				logins++
+				_, _ = w.Write([]byte(`{"auth": {"client_token": "vault-token", "lease_duration": 3600}}`))
+			case "/v1/kv/jenkins":
+				if logins == 1 {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				_, _ = w.Write([]byte(`{"data": {"token": "value"}}`))
+			}
+		}))
+		defer server.Close()
+		jenkins := newJenkins("renew", server.URL)
+		jenkins.Spec.Vault.Auth.Kubernetes.MountPath = "custom"
+
+		values, err := New(fake.NewFakeClient(), jenkins).Read("kv/jenkins")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"token": "value"}, values)
+		assert.Equal(t, 2, logins)
+	})
+	t.Run("unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := New(fake.NewFakeClient(), newJenkins("sealed", server.URL)).Read("secret/data/jenkins")
+		assert.True(t, IsUnavailable(err))
+
+		server.Close()
+		_, err = New(fake.NewFakeClient(), newJenkins("unreachable", server.URL)).Read("secret/data/jenkins")
+		assert.True(t, IsUnavailable(err))
+	})
+	t.Run("vault not configured", func(t *testing.T) {
+		jenkins := newJenkins("not-configured", "")
+		jenkins.Spec.Vault = nil
+
+		_, err := New(fake.NewFakeClient(), jenkins).Read("secret/data/jenkins")
+		assert.EqualError(t, err, "vault secret 'secret/data/jenkins' is referenced but spec.vault isn't set")
+		assert.Equal(t, time.Duration(0), RefreshInterval(jenkins))
+	})
+}
@@ -3,6 +3,8 @@ package event
 import (
 	"fmt"
 
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
 	"github.com/pkg/errors"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -11,6 +13,7 @@ import (
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -34,25 +37,29 @@ type Recorder interface {
 
 type recorder struct {
 	recorder record.EventRecorder
+	notifier *notifier
 }
 
-// New returns recorder used to emit events
+// New returns recorder used to emit events, events of Jenkins CRs are also forwarded to webhooks
+// defined in Jenkins.Spec.Notifications
 func New(config *rest.Config, component string) (Recorder, error) {
-	eventRecorder, err := initializeEventRecorder(config, component)
+	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, errors.WithStack(err)
+	}
+
+	k8sClient, err := k8s.New(config, k8s.Options{})
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	return &recorder{
-		recorder: eventRecorder,
+		recorder: initializeEventRecorder(client, component),
+		notifier: newNotifier(k8sClient, notificationWorkers, notificationQueueSize),
 	}, nil
 }
 
-func initializeEventRecorder(config *rest.Config, component string) (record.EventRecorder, error) {
-	client, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
+func initializeEventRecorder(client kubernetes.Interface, component string) record.EventRecorder {
 	eventBroadcaster := record.NewBroadcaster()
 	//eventBroadcaster.StartLogging(glog.Infof) TODO integrate with proper logger
 	eventBroadcaster.StartRecordingToSink(
@@ -62,13 +69,17 @@ func initializeEventRecorder(config *rest.Config, component string) (record.Even
 		scheme.Scheme,
 		v1.EventSource{Component: component},
 	)
-	return eventRecorder, nil
+	return eventRecorder
 }
 
+// Emit emits the event, registered secret values are masked in the message
 func (r recorder) Emit(object runtime.Object, eventType Type, reason Reason, message string) {
+	message = log.MaskSecrets(message)
 	r.recorder.Event(object, string(eventType), string(reason), message)
+	r.notifier.notify(object, eventType, reason, message)
 }
 
+// Emitf emits the event, registered secret values are masked in the message
 func (r recorder) Emitf(object runtime.Object, eventType Type, reason Reason, format string, args ...interface{}) {
-	r.recorder.Event(object, string(eventType), string(reason), fmt.Sprintf(format, args...))
+	r.Emit(object, eventType, reason, fmt.Sprintf(format, args...))
 }
@@ -0,0 +1,93 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	"github.com/pkg/errors"
+)
+
+// formatter builds the payload of the notification posted to the webhook
+type formatter func(notification notification) ([]byte, error)
+
+// formatters contains payload formats of webhooks, new webhook formats are registered here
+var formatters = map[v1alpha1.WebhookFormat]formatter{
+	v1alpha1.WebhookFormatGeneric: formatGeneric,
+	v1alpha1.WebhookFormatMSTeams: formatMSTeams,
+	v1alpha1.WebhookFormatSlack:   formatSlack,
+}
+
+// genericPayload is posted to webhooks with the generic format
+type genericPayload struct {
+	Jenkins   genericJenkinsReference `json:"jenkins"`
+	Type      Type                    `json:"type"`
+	Reason    Reason                  `json:"reason"`
+	Message   string                  `json:"message"`
+	Timestamp string                  `json:"timestamp"`
+}
+
+type genericJenkinsReference struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+func formatGeneric(notification notification) ([]byte, error) {
+	return marshal(genericPayload{
+		Jenkins:   genericJenkinsReference{Namespace: notification.Namespace, Name: notification.Name},
+		Type:      notification.Type,
+		Reason:    notification.Reason,
+		Message:   notification.Message,
+		Timestamp: notification.Timestamp.UTC().Format(time.RFC3339),
+	})
+}
+
+// msTeamsMessageCard is the legacy actionable message card accepted by Microsoft Teams incoming webhooks
+type msTeamsMessageCard struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+func formatMSTeams(notification notification) ([]byte, error) {
+	themeColor := "0078D7"
+	if notification.Type == TypeWarning {
+		themeColor = "D83B01"
+	}
+	return marshal(msTeamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    string(notification.Reason),
+		ThemeColor: themeColor,
+		Title:      fmt.Sprintf("Jenkins %s/%s: %s", notification.Namespace, notification.Name, notification.Reason),
+		Text:       notification.Message,
+	})
+}
+
+// slackMessage is the payload of the Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+func formatSlack(notification notification) ([]byte, error) {
+	icon := ":information_source:"
+	if notification.Type == TypeWarning {
+		icon = ":warning:"
+	}
+	return marshal(slackMessage{
+		Text: fmt.Sprintf("%s Jenkins `%s/%s` *%s*\n%s", icon, notification.Namespace, notification.Name, notification.Reason, notification.Message),
+	})
+}
+
+func marshal(payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return data, nil
+}
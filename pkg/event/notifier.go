@@ -0,0 +1,198 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+	"github.com/oldsj/jenkins-operator/pkg/log"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8s "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// notificationTimeout is the timeout of posting the notification to the webhook
+	notificationTimeout = 10 * time.Second
+	// notificationQueueSize bounds notifications waiting for delivery, new notifications are dropped when the queue is full
+	notificationQueueSize = 100
+	// notificationWorkers is the number of notifications delivered concurrently
+	notificationWorkers = 4
+	// slackSinkName is the name of the sink defined by Jenkins.Spec.Notifications.Slack
+	slackSinkName = "slack"
+)
+
+// notification is the event of the Jenkins CR delivered to notification sinks
+type notification struct {
+	Namespace string
+	Name      string
+	Type      Type
+	Reason    Reason
+	Message   string
+	Timestamp time.Time
+}
+
+// sink is the webhook of the Jenkins CR notifications are posted to
+type sink struct {
+	name            string
+	namespace       string
+	urlSecretKeyRef corev1.SecretKeySelector
+	format          formatter
+}
+
+type delivery struct {
+	sink         sink
+	notification notification
+}
+
+// notifier fans out events of Jenkins CRs to webhooks defined in Jenkins.Spec.Notifications, notifications are
+// delivered asynchronously from the bounded queue, so slow webhooks never stall the reconcile loop
+type notifier struct {
+	k8sClient  k8s.Client
+	httpClient *http.Client
+	queue      chan delivery
+}
+
+func newNotifier(k8sClient k8s.Client, workers, queueSize int) *notifier {
+	n := &notifier{
+		k8sClient:  k8sClient,
+		httpClient: &http.Client{Timeout: notificationTimeout},
+		queue:      make(chan delivery, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		go n.run()
+	}
+	return n
+}
+
+// notify queues the event for sinks of the Jenkins CR which forward its reason,
+// delivery failures are logged and never returned to the caller
+func (n *notifier) notify(object runtime.Object, eventType Type, reason Reason, message string) {
+	jenkins, ok := object.(*v1alpha1.Jenkins)
+	if !ok {
+		return
+	}
+
+	current := notification{
+		Namespace: jenkins.Namespace,
+		Name:      jenkins.Name,
+		Type:      eventType,
+		Reason:    reason,
+		Message:   message,
+		Timestamp: time.Now(),
+	}
+	for _, sink := range sinks(jenkins, reason) {
+		select {
+		case n.queue <- delivery{sink: sink, notification: current}:
+		default:
+			log.Log.V(log.VWarn).Info(fmt.Sprintf("Notification queue is full, dropping notification '%s' of Jenkins '%s/%s' for webhook '%s'",
+				reason, jenkins.Namespace, jenkins.Name, sink.name))
+		}
+	}
+}
+
+func (n *notifier) run() {
+	for delivery := range n.queue {
+		if err := n.deliver(delivery); err != nil {
+			log.Log.V(log.VWarn).Info(fmt.Sprintf("Couldn't send notification '%s' of Jenkins '%s/%s' to webhook '%s': %s",
+				delivery.notification.Reason, delivery.notification.Namespace, delivery.notification.Name, delivery.sink.name, err))
+		}
+	}
+}
+
+func (n *notifier) deliver(delivery delivery) error {
+	selector := delivery.sink.urlSecretKeyRef
+	secret := &corev1.Secret{}
+	err := n.k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: delivery.sink.namespace, Name: selector.Name}, secret)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	url := string(secret.Data[selector.Key])
+	if len(url) == 0 {
+		return errors.Errorf("key '%s' not found in secret '%s'", selector.Key, selector.Name)
+	}
+	log.ReplaceSecrets(log.SecretOwner{
+		Namespace: delivery.notification.Namespace,
+		Name:      delivery.notification.Name,
+		Source:    "notification webhook " + delivery.sink.name,
+	}, url)
+
+	body, err := delivery.sink.format(delivery.notification)
+	if err != nil {
+		return err
+	}
+	response, err := n.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer func() { _ = response.Body.Close() }()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return errors.Errorf("webhook responded with status '%s'", response.Status)
+	}
+	return nil
+}
+
+// sinks returns webhooks of Jenkins.Spec.Notifications which forward events with the reason
+func sinks(jenkins *v1alpha1.Jenkins, reason Reason) []sink {
+	notifications := jenkins.Spec.Notifications
+	if notifications == nil {
+		return nil
+	}
+
+	var result []sink
+	if slack := notifications.Slack; slack != nil && containsReason(slack.Reasons, reason) {
+		result = append(result, sink{
+			name:            slackSinkName,
+			namespace:       jenkins.Namespace,
+			urlSecretKeyRef: *slack.WebhookURLSecretKeyRef.DeepCopy(),
+			format:          formatters[v1alpha1.WebhookFormatSlack],
+		})
+	}
+	for _, webhook := range notifications.Webhooks {
+		if !containsReason(webhook.Reasons, reason) {
+			continue
+		}
+		format, ok := formatters[WebhookFormat(webhook)]
+		if !ok {
+			log.Log.V(log.VWarn).Info(fmt.Sprintf("Unknown format '%s' of webhook '%s' of Jenkins '%s/%s'",
+				webhook.Format, webhook.Name, jenkins.Namespace, jenkins.Name))
+			continue
+		}
+		result = append(result, sink{
+			name:            webhook.Name,
+			namespace:       jenkins.Namespace,
+			urlSecretKeyRef: *webhook.URLSecretKeyRef.DeepCopy(),
+			format:          format,
+		})
+	}
+	return result
+}
+
+// WebhookFormat returns the payload format of the webhook, the generic format is used when it isn't set
+func WebhookFormat(webhook v1alpha1.Webhook) v1alpha1.WebhookFormat {
+	if len(webhook.Format) == 0 {
+		return v1alpha1.WebhookFormatGeneric
+	}
+	return webhook.Format
+}
+
+// IsWebhookFormatSupported returns true when the payload format of the webhook is known
+func IsWebhookFormatSupported(webhook v1alpha1.Webhook) bool {
+	_, ok := formatters[WebhookFormat(webhook)]
+	return ok
+}
+
+func containsReason(reasons []string, reason Reason) bool {
+	for _, value := range reasons {
+		if value == string(reason) {
+			return true
+		}
+	}
+	return false
+}
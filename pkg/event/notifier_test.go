@@ -0,0 +1,195 @@
+package event
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type request struct {
+	path string
+	body map[string]interface{}
+}
+
+func TestRecorder_Notifications(t *testing.T) {
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests <- request{path: r.URL.Path, body: body}
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhooks", Namespace: "default"},
+		Data: map[string][]byte{
+			"slack":   []byte(server.URL + "/slack"),
+			"teams":   []byte(server.URL + "/teams"),
+			"generic": []byte(server.URL + "/generic"),
+		},
+	}
+	selector := func(key string) corev1.SecretKeySelector {
+		return corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "webhooks"}, Key: key}
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Notifications: &v1alpha1.Notifications{
+				Slack: &v1alpha1.Slack{WebhookURLSecretKeyRef: selector("slack"), Reasons: []string{"CRValidationFailure"}},
+				Webhooks: []v1alpha1.Webhook{
+					{Name: "teams", URLSecretKeyRef: selector("teams"), Format: v1alpha1.WebhookFormatMSTeams, Reasons: []string{"CRValidationFailure"}},
+					{Name: "generic", URLSecretKeyRef: selector("generic"), Reasons: []string{"CRValidationFailure", "JenkinsPodRecreated"}},
+				},
+			},
+		},
+	}
+	fakeRecorder := record.NewFakeRecorder(10)
+	events := recorder{
+		recorder: fakeRecorder,
+		notifier: newNotifier(fake.NewFakeClient(secret), notificationWorkers, notificationQueueSize),
+	}
+	receive := func(count int) map[string]map[string]interface{} {
+		received := map[string]map[string]interface{}{}
+		for i := 0; i < count; i++ {
+			select {
+			case request := <-requests:
+				received[request.path] = request.body
+			case <-time.After(5 * time.Second):
+				assert.FailNow(t, "notification hasn't been sent")
+			}
+		}
+		select {
+		case request := <-requests:
+			assert.Fail(t, "unexpected notification", request.path)
+		case <-time.After(100 * time.Millisecond):
+		}
+		return received
+	}
+
+	t.Run("fan out to all webhooks", func(t *testing.T) {
+		events.Emitf(jenkins, TypeWarning, "CRValidationFailure", "Invalid %s", "spec")
+
+		assert.Equal(t, "Warning CRValidationFailure Invalid spec", <-fakeRecorder.Events)
+		received := receive(3)
+		assert.Equal(t, map[string]interface{}{"text": ":warning: Jenkins `default/jenkins` *CRValidationFailure*\nInvalid spec"}, received["/slack"])
+		assert.Equal(t, "MessageCard", received["/teams"]["@type"])
+		assert.Equal(t, "Jenkins default/jenkins: CRValidationFailure", received["/teams"]["title"])
+		assert.Equal(t, "Invalid spec", received["/teams"]["text"])
+		assert.Equal(t, map[string]interface{}{"namespace": "default", "name": "jenkins"}, received["/generic"]["jenkins"])
+		assert.Equal(t, "Warning", received["/generic"]["type"])
+		assert.Equal(t, "CRValidationFailure", received["/generic"]["reason"])
+		assert.Equal(t, "Invalid spec", received["/generic"]["message"])
+		_, err := time.Parse(time.RFC3339, received["/generic"]["timestamp"].(string))
+		assert.NoError(t, err)
+	})
+	t.Run("reason filter of each webhook", func(t *testing.T) {
+		events.Emit(jenkins, TypeNormal, "JenkinsPodRecreated", "Jenkins master pod is recreated")
+
+		assert.Equal(t, "Normal JenkinsPodRecreated Jenkins master pod is recreated", <-fakeRecorder.Events)
+		received := receive(1)
+		assert.Contains(t, received, "/generic")
+	})
+	t.Run("not forwarded reason", func(t *testing.T) {
+		events.Emit(jenkins, TypeNormal, "BaseConfigurationSuccess", "Base configuration completed")
+
+		assert.Equal(t, "Normal BaseConfigurationSuccess Base configuration completed", <-fakeRecorder.Events)
+		receive(0)
+	})
+}
+
+func TestNotifier_BoundedQueue(t *testing.T) {
+	received := make(chan struct{}, 10)
+	release := make(chan struct{})
+	posted := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		received <- struct{}{}
+		<-release
+		posted <- struct{}{}
+	}))
+	defer server.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "webhooks", Namespace: "default"},
+		Data:       map[string][]byte{"generic": []byte(server.URL)},
+	}
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Notifications: &v1alpha1.Notifications{
+				Webhooks: []v1alpha1.Webhook{{
+					Name:            "slow",
+					URLSecretKeyRef: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "webhooks"}, Key: "generic"},
+					Reasons:         []string{"CRValidationFailure"},
+				}},
+			},
+		},
+	}
+	notifier := newNotifier(fake.NewFakeClient(secret), 1, 2)
+	notifier.notify(jenkins, TypeWarning, "CRValidationFailure", "Invalid spec")
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "notification hasn't been sent")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			notifier.notify(jenkins, TypeWarning, "CRValidationFailure", "Invalid spec")
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "slow webhook blocked notify")
+	}
+	close(release)
+
+	// one notification is being posted by the worker and two are queued, the rest is dropped
+	for i := 0; i < 3; i++ {
+		select {
+		case <-posted:
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "notification hasn't been sent")
+		}
+	}
+	select {
+	case <-posted:
+		assert.Fail(t, "dropped notification has been sent")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSinks(t *testing.T) {
+	jenkins := &v1alpha1.Jenkins{
+		ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: "default"},
+		Spec: v1alpha1.JenkinsSpec{
+			Notifications: &v1alpha1.Notifications{
+				Webhooks: []v1alpha1.Webhook{
+					{Name: "unknown", Format: "xml", Reasons: []string{"CRValidationFailure"}},
+					{Name: "generic", Reasons: []string{"CRValidationFailure"}},
+				},
+			},
+		},
+	}
+
+	result := sinks(jenkins, "CRValidationFailure")
+
+	assert.Len(t, result, 1)
+	assert.Equal(t, "generic", result[0].name)
+	assert.Equal(t, "default", result[0].namespace)
+	assert.Empty(t, sinks(&v1alpha1.Jenkins{}, "CRValidationFailure"))
+}
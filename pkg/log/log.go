@@ -46,6 +46,6 @@ func fatalIfErr(err error, f func(format string, v ...interface{})) {
 // SetupLogger setups global logger
 func SetupLogger(debug bool) {
 	Debug = debug
-	logf.SetLogger(zapLogger(debug))
+	logf.SetLogger(NewMaskingLogger(zapLogger(debug)))
 	Log = logf.Log.WithName("controller-jenkins")
 }
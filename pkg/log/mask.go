@@ -0,0 +1,216 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// SecretPlaceholder replaces registered secret values
+	SecretPlaceholder = "****"
+
+	// minSecretLength is the minimal length of the registered secret value, shorter values would mask unrelated text
+	minSecretLength = 4
+)
+
+// SecretOwner identifies values registered from one source of the Jenkins CR, e.g. its credentials or a Vault secret
+type SecretOwner struct {
+	Namespace string
+	Name      string
+	Source    string
+}
+
+var secrets = struct {
+	sync.RWMutex
+	// owners contains deduplicated values keyed by their owner
+	owners map[SecretOwner][]string
+	// values are deduplicated values of all owners sorted from the longest one, so the value containing another one
+	// is masked as a whole
+	values []string
+}{owners: map[SecretOwner][]string{}}
+
+// ReplaceSecrets registers values sourced from Kubernetes Secrets or Vault, they are masked in operator logs, events
+// and console output excerpts fetched from Jenkins, values previously registered by the owner are unregistered,
+// so rotated values don't stay registered
+func ReplaceSecrets(owner SecretOwner, values ...string) {
+	var registered []string
+	for _, value := range values {
+		if len(value) < minSecretLength || containsString(registered, value) {
+			continue
+		}
+		registered = append(registered, value)
+	}
+	sort.Strings(registered)
+
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	if equalStrings(secrets.owners[owner], registered) {
+		return
+	}
+	if len(registered) == 0 {
+		delete(secrets.owners, owner)
+	} else {
+		secrets.owners[owner] = registered
+	}
+	updateValues()
+}
+
+// UnregisterSecrets removes values registered by the owner
+func UnregisterSecrets(owner SecretOwner) {
+	ReplaceSecrets(owner)
+}
+
+// UnregisterJenkinsSecrets removes values registered by all owners of the Jenkins CR, e.g. after it has been deleted
+func UnregisterJenkinsSecrets(namespace, name string) {
+	secrets.Lock()
+	defer secrets.Unlock()
+
+	removed := false
+	for owner := range secrets.owners {
+		if owner.Namespace == namespace && owner.Name == name {
+			delete(secrets.owners, owner)
+			removed = true
+		}
+	}
+	if removed {
+		updateValues()
+	}
+}
+
+// updateValues rebuilds the list of masked values from values of all owners, it has to be called with the lock held
+func updateValues() {
+	unique := map[string]bool{}
+	var values []string
+	for _, owned := range secrets.owners {
+		for _, value := range owned {
+			if !unique[value] {
+				unique[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	secrets.values = values
+}
+
+// MaskSecrets replaces registered secret values in the text with SecretPlaceholder
+func MaskSecrets(text string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+
+	for _, value := range secrets.values {
+		text = strings.Replace(text, value, SecretPlaceholder, -1)
+	}
+	return text
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NewMaskingLogger returns logger which masks registered secret values in messages, values and errors
+func NewMaskingLogger(logger logr.Logger) logr.Logger {
+	return maskingLogger{logger: logger}
+}
+
+type maskingLogger struct {
+	logger logr.Logger
+}
+
+func (l maskingLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(MaskSecrets(msg), maskValues(keysAndValues)...)
+}
+
+func (l maskingLogger) Enabled() bool {
+	return l.logger.Enabled()
+}
+
+func (l maskingLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	if err != nil {
+		err = maskedError{err: err}
+	}
+	l.logger.Error(err, MaskSecrets(msg), maskValues(keysAndValues)...)
+}
+
+func (l maskingLogger) V(level int) logr.InfoLogger {
+	return maskingInfoLogger{logger: l.logger.V(level)}
+}
+
+func (l maskingLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	return maskingLogger{logger: l.logger.WithValues(maskValues(keysAndValues)...)}
+}
+
+func (l maskingLogger) WithName(name string) logr.Logger {
+	return maskingLogger{logger: l.logger.WithName(name)}
+}
+
+type maskingInfoLogger struct {
+	logger logr.InfoLogger
+}
+
+func (l maskingInfoLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.logger.Info(MaskSecrets(msg), maskValues(keysAndValues)...)
+}
+
+func (l maskingInfoLogger) Enabled() bool {
+	return l.logger.Enabled()
+}
+
+func maskValues(keysAndValues []interface{}) []interface{} {
+	masked := make([]interface{}, len(keysAndValues))
+	for i, value := range keysAndValues {
+		switch typed := value.(type) {
+		case string:
+			masked[i] = MaskSecrets(typed)
+		case error:
+			masked[i] = maskedError{err: typed}
+		default:
+			masked[i] = value
+		}
+	}
+	return masked
+}
+
+// maskedError masks registered secret values in the message and the stack trace of the error
+type maskedError struct {
+	err error
+}
+
+func (e maskedError) Error() string {
+	return MaskSecrets(e.err.Error())
+}
+
+func (e maskedError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		fmt.Fprint(s, MaskSecrets(fmt.Sprintf("%+v", e.err)))
+		return
+	}
+	fmt.Fprint(s, e.Error())
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskSecrets(t *testing.T) {
+	owner := SecretOwner{Namespace: "default", Name: "mask", Source: "credentials"}
+	ReplaceSecrets(owner, "token-value", "token-value-extended", "abc", "", "token-value")
+
+	masked := MaskSecrets("token: token-value, extended: token-value-extended, short: abc")
+
+	assert.Equal(t, "token: ****, extended: ****, short: abc", masked)
+	assert.Equal(t, []string{"token-value", "token-value-extended"}, secrets.owners[owner])
+}
+
+func TestReplaceSecrets(t *testing.T) {
+	credentials := SecretOwner{Namespace: "default", Name: "replace", Source: "credentials"}
+	vault := SecretOwner{Namespace: "default", Name: "replace", Source: "vault"}
+	other := SecretOwner{Namespace: "other", Name: "replace", Source: "credentials"}
+	ReplaceSecrets(credentials, "rotated-password", "shared-value")
+	ReplaceSecrets(vault, "shared-value")
+	ReplaceSecrets(other, "other-password")
+
+	// the rotated value isn't masked anymore, the value shared with another owner stays masked
+	ReplaceSecrets(credentials, "new-password")
+	assert.Equal(t, "rotated-password **** ****", MaskSecrets("rotated-password new-password shared-value"))
+
+	UnregisterSecrets(vault)
+	assert.Equal(t, "shared-value", MaskSecrets("shared-value"))
+
+	// values of the deleted Jenkins CR are unregistered, values of other Jenkins CRs stay registered
+	UnregisterJenkinsSecrets("default", "replace")
+	assert.Equal(t, "new-password ****", MaskSecrets("new-password other-password"))
+	UnregisterJenkinsSecrets("other", "replace")
+	assert.NotContains(t, secrets.values, "other-password")
+}
+
+func TestMaskedError(t *testing.T) {
+	ReplaceSecrets(SecretOwner{Namespace: "default", Name: "error", Source: "credentials"}, "error-secret")
+	err := maskedError{err: fmt.Errorf("couldn't login with error-secret")}
+
+	assert.Equal(t, "couldn't login with ****", err.Error())
+	assert.Equal(t, "couldn't login with ****", fmt.Sprintf("%+v", err))
+}
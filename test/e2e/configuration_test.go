@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/oldsj/jenkins-operator/pkg/apis/jenkinsio/v1alpha1"
-	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/configuration/user/seedjobs"
+	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/constants"
 	"github.com/oldsj/jenkins-operator/pkg/controller/jenkins/plugins"
 
 	"github.com/bndr/gojenkins"
@@ -135,9 +135,9 @@ func isPluginValid(plugins *gojenkins.Plugins, requiredPlugin plugins.Plugin) (*
 }
 
 func verifyJenkinsSeedJobs(t *testing.T, client *gojenkins.Jenkins, jenkins *v1alpha1.Jenkins) {
-	t.Logf("Attempting to get configure seed job status '%v'", seedjobs.ConfigureSeedJobsName)
+	t.Logf("Attempting to get configure seed job status '%v'", constants.ConfigureSeedJobsName)
 
-	configureSeedJobs, err := client.GetJob(seedjobs.ConfigureSeedJobsName)
+	configureSeedJobs, err := client.GetJob(constants.ConfigureSeedJobsName)
 	assert.NoError(t, err)
 	assert.NotNil(t, configureSeedJobs)
 	build, err := configureSeedJobs.GetLastSuccessfulBuild()